@@ -0,0 +1,11 @@
+// Package api holds the interface descriptions of memo's network services:
+// the OpenAPI document of the REST API and the gRPC service definition in
+// memo/v1.
+package api
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI 3 document describing the REST API, in YAML
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AppendCommand adds text to the end (or, for prepend, the start) of a note
+// without an interactive edit session
+type AppendCommand struct {
+	ctx     *CommandContext
+	prepend bool
+}
+
+func NewAppendCommand(ctx *CommandContext) *AppendCommand {
+	return &AppendCommand{ctx: ctx}
+}
+
+func NewPrependCommand(ctx *CommandContext) *AppendCommand {
+	return &AppendCommand{ctx: ctx, prepend: true}
+}
+
+func (c *AppendCommand) Execute(args []string) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	name := "append"
+	if c.prepend {
+		name = "prepend"
+	}
+
+	p, err := parseArgsWithText(args)
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("note-id or number required\nUsage: memo %s <note-id|number> [text|-]", name)
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(p.Positional[0])
+	if err != nil {
+		return err
+	}
+
+	text := strings.Join(p.Positional[1:], " ")
+	if text == "" || text == "-" {
+		data, err := readInput("-")
+		if err != nil {
+			return fmt.Errorf("error reading stdin: %w", err)
+		}
+		text = string(data)
+	}
+	text = strings.TrimRight(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("nothing to %s", name)
+	}
+
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	if err := c.ctx.UnlockNote(n); err != nil {
+		return err
+	}
+
+	n.UpdateContent(joinText(n.Content, text, c.prepend))
+
+	if err := c.ctx.Storage.SaveNote(n); err != nil {
+		return fmt.Errorf("error saving note: %w", err)
+	}
+
+	fmt.Println("Note updated successfully!")
+	c.ctx.RecordAccess(noteID, "edited")
+	return nil
+}
+
+// joinText adds text on lines of its own to the end of content, or to the
+// start when prepend is set
+func joinText(content, text string, prepend bool) string {
+	content = strings.TrimRight(content, "\n")
+	switch {
+	case content == "":
+		return text
+	case prepend:
+		return text + "\n" + content
+	default:
+		return content + "\n" + text
+	}
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import "testing"
+
+func TestAppendListItem(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepend bool
+		args    []string
+		content string
+	}{
+		{name: "quoted", args: []string{"- [ ] buy milk"}, content: "first line\n- [ ] buy milk"},
+		{name: "unquoted", args: []string{"-", "[", "]", "buy", "milk"}, content: "first line\n- [ ] buy milk"},
+		{name: "flag-like text", args: []string{"--all", "done"}, content: "first line\n--all done"},
+		{name: "prepend", prepend: true, args: []string{"- [x] call back"}, content: "- [x] call back\nfirst line"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, id := newTestContext(t, "Shopping")
+			c := &AppendCommand{ctx: ctx, prepend: tt.prepend}
+			if err := c.Execute(append([]string{id}, tt.args...)); err != nil {
+				t.Fatalf("Execute = %v", err)
+			}
+			if got := findNote(t, ctx, id).Content; got != tt.content {
+				t.Errorf("content = %q, want %q", got, tt.content)
+			}
+		})
+	}
+}
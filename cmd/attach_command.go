@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// imageExtensions are attached as Markdown images rather than links
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true,
+}
+
+// AttachCommand stores files among the attachments and links them at the
+// end of a note. Identical files are stored only once.
+type AttachCommand struct {
+	ctx *CommandContext
+}
+
+func NewAttachCommand(ctx *CommandContext) *AttachCommand {
+	return &AttachCommand{ctx: ctx}
+}
+
+func (c *AttachCommand) Execute(args []string) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	p, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 2 {
+		return fmt.Errorf("note and file required\nUsage: memo attach <note-id|number> <file>...")
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(p.Positional[0])
+	if err != nil {
+		return err
+	}
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	if err := c.ctx.UnlockNote(n); err != nil {
+		return err
+	}
+
+	files := p.Positional[1:]
+	links := make([]string, len(files))
+	reused := make([]bool, len(files))
+	for i, file := range files {
+		link, wasStored, err := c.ctx.Storage.AttachFile(file)
+		if err != nil {
+			return fmt.Errorf("error attaching %s: %w", file, err)
+		}
+		links[i], reused[i] = link, wasStored
+	}
+
+	content := strings.TrimRight(n.Content, "\n")
+	if content != "" {
+		content += "\n\n"
+	}
+	var lines []string
+	for i, file := range files {
+		name := filepath.Base(file)
+		if imageExtensions[strings.ToLower(filepath.Ext(file))] {
+			lines = append(lines, fmt.Sprintf("![%s](%s)", name, links[i]))
+		} else {
+			lines = append(lines, fmt.Sprintf("[%s](%s)", name, links[i]))
+		}
+	}
+	n.UpdateContent(content + strings.Join(lines, "\n"))
+	if err := c.ctx.Storage.SaveNote(n); err != nil {
+		return fmt.Errorf("error saving note: %w", err)
+	}
+
+	for i, file := range files {
+		if !reused[i] {
+			fmt.Printf("Attached %s as %s\n", file, links[i])
+			continue
+		}
+		count, err := c.ctx.Storage.AttachmentRefCount(links[i])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Attached %s; the same file was already stored as %s and is now shared by %d note(s)\n", file, links[i], count)
+	}
+	c.ctx.RecordAccess(noteID, "edited")
+	return nil
+}
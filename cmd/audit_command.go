@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"memo/internal/audit"
+	"memo/internal/ui"
+)
+
+type AuditCommand struct {
+	ctx *CommandContext
+}
+
+func NewAuditCommand(ctx *CommandContext) *AuditCommand {
+	return &AuditCommand{ctx: ctx}
+}
+
+// Execute shows the recorded changes, newest last, optionally only those of
+// one note; renames are followed so a note's entries under its old IDs show
+// up too
+func (c *AuditCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--id=", "--limit=", "--format=")
+	if err != nil {
+		return fmt.Errorf("%v\nUsage: memo audit [--id <note>] [--limit <n>] [--format text|json]", err)
+	}
+
+	entries, err := c.ctx.Storage.AuditLog()
+	if err != nil {
+		return err
+	}
+
+	if id := p.Value("--id"); id != "" {
+		// Deleted notes cannot be resolved, but their ID still finds them
+		if noteID, err := c.ctx.ResolveNoteID(id); err == nil {
+			id = noteID
+		}
+		entries = entriesFor(entries, id)
+	}
+
+	if limit := p.Value("--limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return fmt.Errorf("--limit must be a positive number\nUsage: memo audit [--id <note>] [--limit <n>]")
+		}
+		if len(entries) > n {
+			entries = entries[len(entries)-n:]
+		}
+	}
+
+	switch format := p.Value("--format"); format {
+	case "", "text":
+		ui.DisplayAuditLog(entries)
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown format '%s' (use text or json)", format)
+	}
+	return nil
+}
+
+// entriesFor returns the entries of the note with the given ID, including
+// those recorded under the IDs it had before being renamed
+func entriesFor(entries []audit.Entry, id string) []audit.Entry {
+	var matched []audit.Entry
+	for _, i := range entryIndexes(entries, id) {
+		matched = append(matched, entries[i])
+	}
+	return matched
+}
+
+// entryIndexes returns the positions in entries of those entriesFor picks
+func entryIndexes(entries []audit.Entry, id string) []int {
+	ids := map[string]bool{id: true}
+	var matched []int
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Op == audit.OpRename && ids[e.NewID] {
+			ids[e.ID] = true
+		}
+		if ids[e.ID] || (e.NewID != "" && ids[e.NewID]) {
+			matched = append(matched, i)
+		}
+	}
+	slices.Reverse(matched)
+	return matched
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"memo/internal/autotag"
+	"memo/internal/storage"
+)
+
+// AutotagCommand tags notes by the rules in the store's autotag file
+type AutotagCommand struct {
+	ctx *CommandContext
+}
+
+func NewAutotagCommand(ctx *CommandContext) *AutotagCommand {
+	return &AutotagCommand{ctx: ctx}
+}
+
+// Execute gives every note the tags of the rules it matches. With
+// --dry-run the tags are only listed.
+func (c *AutotagCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--dry-run", "-n")
+	if err != nil {
+		return fmt.Errorf("%v\nUsage: memo autotag [--dry-run]", err)
+	}
+	dryRun := p.Bool("--dry-run", "-n") || c.ctx.DryRun
+	if !dryRun {
+		if err := c.ctx.Storage.CheckWritable(); err != nil {
+			return err
+		}
+	}
+
+	path := c.ctx.Storage.StorePath(autotag.FileName)
+	rules, err := autotag.Load(path)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return fmt.Errorf("no tagging rules in %s; add some like:\n- pattern: standup\n  tags: [meeting]", path)
+	}
+
+	notes, err := c.ctx.Storage.GetAllNotes()
+	if err != nil {
+		return fmt.Errorf("error loading notes: %w", err)
+	}
+
+	tagged := 0
+	for _, n := range notes {
+		missing := autotag.Missing(rules, n)
+		if len(missing) == 0 {
+			continue
+		}
+		fmt.Printf("  %s (%s): +%s\n", n.Metadata.Title, n.ID(), strings.Join(missing, " +"))
+		if dryRun {
+			tagged++
+			continue
+		}
+
+		for _, tag := range missing {
+			n.AddTag(tag)
+		}
+		if err := c.ctx.Storage.SaveNote(n); err != nil {
+			var locked *storage.LockedError
+			if errors.As(err, &locked) {
+				slog.Warn("skipping locked note", "id", n.ID())
+				continue
+			}
+			return fmt.Errorf("error saving note: %w", err)
+		}
+		tagged++
+	}
+
+	switch {
+	case tagged == 0:
+		fmt.Println("No notes need new tags.")
+	case dryRun:
+		fmt.Printf("Would tag %d note(s).\n", tagged)
+	default:
+		fmt.Printf("Tagged %d note(s).\n", tagged)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"memo/internal/history"
+)
+
+// BackCommand reads the note before (or, for forward, after) the current
+// one on the trail of read notes, like the buttons of a web browser
+type BackCommand struct {
+	ctx     *CommandContext
+	forward bool
+}
+
+func NewBackCommand(ctx *CommandContext) *BackCommand {
+	return &BackCommand{ctx: ctx}
+}
+
+func NewForwardCommand(ctx *CommandContext) *BackCommand {
+	return &BackCommand{ctx: ctx, forward: true}
+}
+
+func (c *BackCommand) Execute(args []string) error {
+	name, direction := "back", -1
+	if c.forward {
+		name, direction = "forward", 1
+	}
+	p, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	steps := 1
+	if len(p.Positional) > 0 {
+		if steps, err = strconv.Atoi(p.Positional[0]); err != nil || steps < 1 {
+			return fmt.Errorf("invalid number of steps '%s'\nUsage: memo %s [<steps>]", p.Positional[0], name)
+		}
+	}
+
+	path := c.ctx.Storage.StorePath(history.NavigationFileName)
+	nav, err := history.LoadNavigation(path)
+	if err != nil {
+		return err
+	}
+
+	// Deleted notes are passed over without counting as a step
+	var noteID string
+	for taken := 0; taken < steps; {
+		id, ok := nav.Step(direction)
+		if !ok {
+			break
+		}
+		if c.ctx.Storage.NoteExists(id) {
+			noteID = id
+			taken++
+		}
+	}
+	if noteID == "" {
+		if c.forward {
+			return fmt.Errorf("no note to go forward to")
+		}
+		return fmt.Errorf("no note to go back to")
+	}
+	// Stay on the last note found rather than past it at the end
+	for nav.Trail[nav.Current] != noteID {
+		nav.Step(-direction)
+	}
+
+	if !c.ctx.Storage.ReadOnly() && !c.ctx.DryRun {
+		if err := nav.Save(path); err != nil {
+			return err
+		}
+	}
+	return NewReadCommand(c.ctx).Execute([]string{noteID})
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"memo/internal/backup"
+	"memo/internal/config"
+	"memo/internal/ui"
+)
+
+type BackupCommand struct {
+	ctx *CommandContext
+}
+
+func NewBackupCommand(ctx *CommandContext) *BackupCommand {
+	return &BackupCommand{ctx: ctx}
+}
+
+func (c *BackupCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--dir=", "--format=", "--keep=", "--list")
+	if err != nil {
+		return fmt.Errorf("%v\nUsage: memo backup [--dir <dir>] [--format tar.gz|zip] [--keep <n>] [--list]", err)
+	}
+
+	dir := p.Value("--dir")
+	if dir == "" {
+		dir = defaultBackupDir(c.ctx.Storage.NotesDir())
+	}
+
+	if p.Bool("--list") {
+		backups, err := backup.List(dir)
+		if err != nil {
+			return err
+		}
+		ui.DisplayBackups(backups, dir)
+		return nil
+	}
+
+	format, err := backup.ParseFormat(p.Value("--format"))
+	if err != nil {
+		return err
+	}
+	keep := 0
+	if v := p.Value("--keep"); v != "" {
+		if keep, err = strconv.Atoi(v); err != nil || keep < 1 {
+			return fmt.Errorf("--keep must be a positive number")
+		}
+	}
+
+	archive, count, err := backup.Create(c.ctx.Storage.NotesDir(), config.Path(), dir, format, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backed up %d files to %s\n", count, archive)
+
+	if keep > 0 {
+		removed, err := backup.Rotate(dir, keep)
+		if err != nil {
+			return err
+		}
+		if len(removed) > 0 {
+			fmt.Printf("Removed %d old backup(s), keeping the newest %d\n", len(removed), keep)
+		}
+	}
+	return nil
+}
+
+// defaultBackupDir keeps backups next to the notes directory rather than
+// inside it, so a backup never contains the previous ones
+func defaultBackupDir(notesDir string) string {
+	return notesDir + "-backups"
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"memo/internal/note"
+)
+
+const (
+	// captureTag marks captured notes for later triage
+	captureTag = "inbox"
+	// maxCaptureTitle is the length, in characters, beyond which the first
+	// line is shortened to make the title
+	maxCaptureTitle = 80
+)
+
+// CaptureCommand creates a note from its arguments or stdin without asking
+// anything, for keyboard launchers and shell aliases
+type CaptureCommand struct {
+	ctx *CommandContext
+}
+
+func NewCaptureCommand(ctx *CommandContext) *CaptureCommand {
+	return &CaptureCommand{ctx: ctx}
+}
+
+func (c *CaptureCommand) Execute(args []string) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	p, err := parseArgsWithText(args, "--tag=", "--notebook=", "--location=")
+	if err != nil {
+		return fmt.Errorf("%v\nUsage: memo capture [--tag <tag>] [--notebook <name>] [--location <place>] <text|->", err)
+	}
+
+	text := strings.Join(p.Positional, " ")
+	if text == "" || text == "-" {
+		data, err := readInput("-")
+		if err != nil {
+			return fmt.Errorf("error reading stdin: %w", err)
+		}
+		text = string(data)
+	}
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return fmt.Errorf("nothing to capture\nUsage: memo capture [--tag <tag>] [--notebook <name>] [--location <place>] <text|->")
+	}
+
+	title, content := captureTitle(text)
+	tags := append([]string{captureTag}, p.Values("--tag")...)
+
+	n := note.New(title, content, tags)
+	if value := p.Value("--location"); value != "" {
+		location := c.ctx.ResolveLocation(value)
+		n.Metadata.Location = &location
+	}
+	c.ctx.ApplyDefaults(n, p.Value("--notebook"))
+
+	noteID, err := c.ctx.Storage.CreateNoteIn(n, p.Value("--notebook"))
+	if err != nil {
+		return fmt.Errorf("error creating note: %w", err)
+	}
+
+	fmt.Printf("Captured %s: %s\n", noteID, title)
+	c.ctx.RecordAccess(noteID, "created")
+	return nil
+}
+
+// captureTitle takes the first line of text as the title and the rest as
+// content. A first line too long for a title is shortened at a word
+// boundary and kept in full in the content.
+func captureTitle(text string) (title, content string) {
+	first, rest, _ := strings.Cut(text, "\n")
+	// A Markdown heading loses its marker, a #hashtag keeps it
+	if heading := strings.TrimLeft(first, "#"); heading != first && strings.HasPrefix(heading, " ") {
+		first = heading
+	}
+	first = strings.TrimSpace(first)
+	rest = strings.TrimSpace(rest)
+
+	runes := []rune(first)
+	if len(runes) <= maxCaptureTitle {
+		return first, rest
+	}
+	title = string(runes[:maxCaptureTitle])
+	if i := strings.LastIndexByte(title, ' '); i > maxCaptureTitle/2 {
+		title = title[:i]
+	}
+	return strings.TrimRight(title, " ,.;:") + "...", text
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCaptureListItem(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		title string
+		tags  []string
+	}{
+		{name: "quoted", args: []string{"- [ ] call dentist"}, title: "- [ ] call dentist", tags: []string{captureTag}},
+		{name: "unquoted", args: []string{"-", "[", "]", "call", "dentist"}, title: "- [ ] call dentist", tags: []string{captureTag}},
+		{name: "flags first", args: []string{"--tag", "health", "- [ ] call dentist"}, title: "- [ ] call dentist", tags: []string{captureTag, "health"}},
+		{name: "flag-like text", args: []string{"call", "--tag", "x"}, title: "call --tag x", tags: []string{captureTag}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := newTestContext(t, "Existing")
+			if err := NewCaptureCommand(ctx).Execute(tt.args); err != nil {
+				t.Fatalf("Execute = %v", err)
+			}
+			notes, err := ctx.Storage.FilterNotesByTag(captureTag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(notes) != 1 {
+				t.Fatalf("captured %d notes, want 1", len(notes))
+			}
+			if n := notes[0]; n.Metadata.Title != tt.title || !slices.Equal(n.Metadata.Tags, tt.tags) {
+				t.Errorf("captured %q with tags %q, want %q with %q", n.Metadata.Title, n.Metadata.Tags, tt.title, tt.tags)
+			}
+		})
+	}
+}
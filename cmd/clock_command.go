@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"memo/internal/note"
+	"memo/internal/stats"
+	"memo/internal/ui"
+)
+
+const clockUsage = "Usage: memo clock in <note-id|number|title> | out [<note-id|number|title>] | status | report [--week|--month|--since YYYY-MM-DD [--until YYYY-MM-DD]] [--previous] [--tag <tag>]"
+
+// ClockCommand tracks the time spent on notes, for notes kept as project
+// logs. Time entries are stored in the notes' front matter.
+type ClockCommand struct {
+	ctx *CommandContext
+}
+
+func NewClockCommand(ctx *CommandContext) *ClockCommand {
+	return &ClockCommand{ctx: ctx}
+}
+
+func (c *ClockCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--since=", "--until=", "--tag=", "--week", "--month", "--previous")
+	if err != nil {
+		return fmt.Errorf("%v\n%s", err, clockUsage)
+	}
+	action := "status"
+	if len(p.Positional) > 0 {
+		action = p.Positional[0]
+	}
+
+	switch action {
+	case "in":
+		if len(p.Positional) < 2 {
+			return fmt.Errorf("note required\n%s", clockUsage)
+		}
+		return c.clockIn(p.Positional[1])
+	case "out":
+		target := ""
+		if len(p.Positional) > 1 {
+			target = p.Positional[1]
+		}
+		return c.clockOut(target)
+	case "status":
+		return c.status()
+	case "report":
+		_, period, err := reportPeriod(p, clockUsage)
+		if err != nil {
+			return err
+		}
+		notes, err := c.ctx.Storage.GetAllNotes()
+		if err != nil {
+			return fmt.Errorf("error loading notes: %w", err)
+		}
+		if tag := p.Value("--tag"); tag != "" {
+			var tagged []*note.Note
+			for _, n := range notes {
+				if n.HasTag(tag) {
+					tagged = append(tagged, n)
+				}
+			}
+			notes = tagged
+		}
+		ui.DisplayClockReport(stats.NewClockReport(notes, period, time.Now()))
+		return nil
+	}
+	return fmt.Errorf("unknown clock action '%s'\n%s", action, clockUsage)
+}
+
+// clockIn starts the clock of a note, stopping any other running one
+func (c *ClockCommand) clockIn(identifier string) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+	noteID, err := c.ctx.ResolveNoteID(identifier)
+	if err != nil {
+		return err
+	}
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	if n.Running() {
+		return fmt.Errorf("the clock of %s is already running", noteID)
+	}
+
+	running, err := c.running()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, other := range running {
+		if err := c.stop(other, now); err != nil {
+			return err
+		}
+	}
+
+	if err := n.ClockIn(now); err != nil {
+		return err
+	}
+	if err := c.ctx.Storage.SaveNote(n); err != nil {
+		return fmt.Errorf("error saving note: %w", err)
+	}
+	fmt.Printf("Clocked in to %s (%s) at %s\n", n.Metadata.Title, noteID, now.Format("15:04"))
+	c.ctx.RecordAccess(noteID, "edited")
+	return nil
+}
+
+// clockOut stops the clock of a note, or of every note whose clock runs
+func (c *ClockCommand) clockOut(identifier string) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	var running []*note.Note
+	if identifier != "" {
+		noteID, err := c.ctx.ResolveNoteID(identifier)
+		if err != nil {
+			return err
+		}
+		n, err := c.ctx.Storage.FindNoteByID(noteID)
+		if err != nil {
+			return err
+		}
+		if !n.Running() {
+			return fmt.Errorf("the clock of %s is not running", noteID)
+		}
+		running = []*note.Note{n}
+	} else {
+		var err error
+		if running, err = c.running(); err != nil {
+			return err
+		}
+		if len(running) == 0 {
+			return errors.New("no clock is running")
+		}
+	}
+
+	now := time.Now()
+	for _, n := range running {
+		if err := c.stop(n, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stop clocks out of n and saves it
+func (c *ClockCommand) stop(n *note.Note, now time.Time) error {
+	spent, err := n.ClockOut(now)
+	if err != nil {
+		return err
+	}
+	if err := c.ctx.Storage.SaveNote(n); err != nil {
+		return fmt.Errorf("error saving note: %w", err)
+	}
+	fmt.Printf("Clocked out of %s (%s) after %s\n", n.Metadata.Title, n.ID(), ui.FormatClock(spent))
+	return nil
+}
+
+func (c *ClockCommand) status() error {
+	running, err := c.running()
+	if err != nil {
+		return err
+	}
+	if len(running) == 0 {
+		fmt.Println("No clock is running.")
+		return nil
+	}
+	now := time.Now()
+	for _, n := range running {
+		start := n.Metadata.Clock[len(n.Metadata.Clock)-1].Start
+		fmt.Printf("Clocked in to %s (%s) since %s (%s)\n", n.Metadata.Title, n.ID(), start.Local().Format("2006-01-02 15:04"), ui.FormatClock(now.Sub(start)))
+	}
+	return nil
+}
+
+// running returns the notes whose clock is running
+func (c *ClockCommand) running() ([]*note.Note, error) {
+	notes, err := c.ctx.Storage.GetAllNotes()
+	if err != nil {
+		return nil, fmt.Errorf("error loading notes: %w", err)
+	}
+	var running []*note.Note
+	for _, n := range notes {
+		if n.Running() {
+			running = append(running, n)
+		}
+	}
+	return running, nil
+}
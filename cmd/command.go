@@ -1,8 +1,22 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"memo/internal/analysis"
+	"memo/internal/config"
+	"memo/internal/history"
+	"memo/internal/keychain"
 	"memo/internal/note"
 	"memo/internal/storage"
+	"memo/internal/ui"
 )
 
 // Command interface defines the contract for all CLI commands
@@ -13,7 +27,13 @@ type Command interface {
 // CommandContext provides shared dependencies for all commands
 type CommandContext struct {
 	Storage        *storage.FileStorage
+	Config         *config.Config
+	Profile        config.Profile
+	ProfileName    string
 	CurrentListing []*note.Note
+	// DryRun is set by the global --dry-run flag; the store then only
+	// reports changes, and commands skip their other writes
+	DryRun bool
 }
 
 // SetCurrentListing updates the current listing (used by list command)
@@ -24,4 +44,293 @@ func (ctx *CommandContext) SetCurrentListing(notes []*note.Note) {
 // GetCurrentListing returns the current listing
 func (ctx *CommandContext) GetCurrentListing() []*note.Note {
 	return ctx.CurrentListing
-}
\ No newline at end of file
+}
+
+// ResolveNoteID maps a listing number, note ID, former ID of a renamed note
+// or note title to a note ID.
+// An ID may be shortened to a prefix only one note's ID starts with, as
+// with git's short hashes. Titles are matched exactly, then by prefix,
+// then fuzzily; when several notes match, the user picks one.
+func (ctx *CommandContext) ResolveNoteID(identifier string) (string, error) {
+	if num, err := strconv.Atoi(identifier); err == nil {
+		if len(ctx.CurrentListing) == 0 {
+			return "", fmt.Errorf("no current note listing. Please run 'memo list' first")
+		}
+
+		if num < 1 || num > len(ctx.CurrentListing) {
+			return "", fmt.Errorf("number %d is out of range. Valid range: 1-%d", num, len(ctx.CurrentListing))
+		}
+
+		return ctx.CurrentListing[num-1].ID(), nil
+	}
+
+	if ctx.Storage.NoteExists(identifier) {
+		return identifier, nil
+	}
+	if id, ok := ctx.Storage.ResolveAlias(identifier); ok {
+		return id, nil
+	}
+	ids, err := ctx.Storage.NoteIDsWithPrefix(identifier)
+	if err != nil {
+		return "", err
+	}
+	if len(ids) == 1 {
+		return ids[0], nil
+	}
+
+	notes, err := ctx.Storage.GetAllNotes()
+	if err != nil {
+		return "", fmt.Errorf("error loading notes: %w", err)
+	}
+	matches := analysis.MatchTitles(notes, identifier)
+	switch len(matches) {
+	case 0:
+		if len(ids) > 1 {
+			return "", ambiguousPrefixError(identifier, ids)
+		}
+		// Let the caller report the unknown ID
+		return identifier, nil
+	case 1:
+		return matches[0].ID(), nil
+	}
+
+	n := ui.ChooseNote(matches, identifier)
+	if n == nil {
+		return "", fmt.Errorf("'%s' matches %d notes; use a number or ID to pick one", identifier, len(matches))
+	}
+	return n.ID(), nil
+}
+
+// maxPrefixCandidates is how many IDs an ambiguous prefix error lists
+const maxPrefixCandidates = 10
+
+func ambiguousPrefixError(prefix string, ids []string) error {
+	shown := ids[:min(len(ids), maxPrefixCandidates)]
+	msg := fmt.Sprintf("note ID prefix '%s' is ambiguous; it matches:\n  %s", prefix, strings.Join(shown, "\n  "))
+	if more := len(ids) - len(shown); more > 0 {
+		msg += fmt.Sprintf("\n  and %d more", more)
+	}
+	return errors.New(msg)
+}
+
+// PromptForTags asks for comma-separated tags. On a terminal, known tags
+// whose names occur in content (and are not in current) are suggested, and
+// Tab completes tag names, which keeps the tag vocabulary consistent.
+// It fails with ui.ErrInterrupted when the user presses Ctrl-C.
+func (ctx *CommandContext) PromptForTags(prompt, content string, current []string) ([]string, error) {
+	if !ui.IsInteractive() {
+		return splitTags(ui.PromptForInput(prompt)), nil
+	}
+
+	notes, err := ctx.Storage.GetAllNotes()
+	if err != nil {
+		slog.Debug("no tag completion", "error", err)
+		return splitTags(ui.PromptForInput(prompt)), nil
+	}
+	counts := analysis.TagCounts(notes)
+
+	var suggestions []string
+	for _, tag := range analysis.SuggestTags(counts, content, 5+len(current)) {
+		if !slices.Contains(current, tag) && len(suggestions) < 5 {
+			suggestions = append(suggestions, tag)
+		}
+	}
+	if len(suggestions) > 0 {
+		fmt.Print(ui.Tf("Suggested tags: %s (Tab completes)\n", strings.Join(suggestions, ", ")))
+	}
+
+	input, err := ui.PromptWithCompletion(prompt, func(line string) ([]string, int) {
+		start := strings.LastIndex(line, ",") + 1
+		for start < len(line) && line[start] == ' ' {
+			start++
+		}
+		return analysis.CompleteTag(counts, line[start:], splitTags(line[:start])), start
+	})
+	if err != nil {
+		return nil, err
+	}
+	return splitTags(input), nil
+}
+
+// splitTags parses comma-separated tags, dropping empty entries
+func splitTags(input string) []string {
+	var tags []string
+	for _, tag := range strings.Split(input, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// EncryptionKey returns the passphrase for encrypted notes from MEMO_KEY or
+// the profile's entry in the OS keychain (see `memo key store`), prompting
+// for it when neither has one. With confirm set, a prompted key must be
+// entered twice.
+func (ctx *CommandContext) EncryptionKey(confirm bool) (string, error) {
+	if key := os.Getenv("MEMO_KEY"); key != "" {
+		return key, nil
+	}
+	key, err := keychain.Get(ctx.ProfileName)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, keychain.ErrNotFound) {
+		slog.Debug("keychain unavailable", "error", err)
+	}
+
+	key = ui.PromptForSecret("Enter encryption key: ")
+	if key == "" {
+		return "", fmt.Errorf("encryption key must not be empty")
+	}
+	if confirm && ui.PromptForSecret("Confirm encryption key: ") != key {
+		return "", fmt.Errorf("keys do not match")
+	}
+	return key, nil
+}
+
+// UnlockNote decrypts the content of an encrypted note, asking for the key
+// only when the note actually needs it
+func (ctx *CommandContext) UnlockNote(n *note.Note) error {
+	if !n.Locked() {
+		return nil
+	}
+
+	key, err := ctx.EncryptionKey(false)
+	if err != nil {
+		return err
+	}
+	if err := n.Decrypt(key); err != nil {
+		return fmt.Errorf("cannot decrypt note '%s': %w", n.Metadata.Title, err)
+	}
+	return nil
+}
+
+// ApplyDefaults fills in the author, tags, status and priority n leaves
+// empty with the defaults configured for new notes in notebook
+func (ctx *CommandContext) ApplyDefaults(n *note.Note, notebook string) {
+	d := ctx.Profile.Defaults(notebook)
+	if n.Metadata.Author == "" {
+		n.Metadata.Author = d.Author
+	}
+	if len(n.Metadata.Tags) == 0 {
+		n.Metadata.Tags = slices.Clone(d.Tags)
+	}
+	if n.Metadata.Status == "" {
+		n.Metadata.Status = d.Status
+	}
+	if n.Metadata.Priority == 0 {
+		n.Metadata.Priority = d.Priority
+	}
+	if n.Metadata.Location == nil && d.Location != "" {
+		location := ctx.ResolveLocation(d.Location)
+		n.Metadata.Location = &location
+	}
+}
+
+// ResolveLocation reads a place name or "lat,lon"; names of places in the
+// configuration get their coordinates
+func (ctx *CommandContext) ResolveLocation(s string) note.Location {
+	location := note.ParseLocation(s)
+	if location.Name != "" {
+		if place, ok := ctx.Config.Place(location.Name); ok {
+			location.Lat, location.Lon = place.Lat, place.Lon
+		}
+	}
+	return location
+}
+
+// BeginTransaction opens a store transaction for the running command, so
+// that its changes to several files are rolled back together when it fails
+// or is interrupted. When dryRun, as nothing is written, it returns a nil
+// transaction, whose Commit and Rollback do nothing.
+func (ctx *CommandContext) BeginTransaction(dryRun bool) (*storage.Transaction, error) {
+	if dryRun {
+		return nil, nil
+	}
+	return ctx.Storage.Begin(os.Args[1:])
+}
+
+// RecordAccess adds a note to the access history used by `memo last` and
+// `memo recent`; read notes also join the trail of `memo back`. Failures
+// only produce a warning, and read-only stores and dry runs are left
+// untouched.
+func (ctx *CommandContext) RecordAccess(noteID, action string) {
+	if ctx.Storage.ReadOnly() || ctx.DryRun {
+		return
+	}
+	if err := history.Record(ctx.Storage.StorePath(history.FileName), noteID, action, time.Now()); err != nil {
+		slog.Warn("failed to record note access", "error", err)
+	}
+	if action == "read" {
+		ctx.updateNavigation(func(nav *history.Navigation) { nav.Visit(noteID) })
+	}
+}
+
+// updateNavigation changes the trail of read notes for `memo back` and
+// `memo forward`, warning when it cannot be saved
+func (ctx *CommandContext) updateNavigation(change func(*history.Navigation)) {
+	path := ctx.Storage.StorePath(history.NavigationFileName)
+	nav, err := history.LoadNavigation(path)
+	if err == nil {
+		change(nav)
+		err = nav.Save(path)
+	}
+	if err != nil {
+		slog.Warn("failed to record note navigation", "error", err)
+	}
+}
+
+// RelinkTitle points the [[wikilinks]] naming oldTitle at the new title of
+// renamed in every other note and returns the notes it changed. Links are
+// left alone while another note still goes by oldTitle, since they resolve
+// to that note. With dryRun nothing is saved.
+func (ctx *CommandContext) RelinkTitle(renamed *note.Note, oldTitle string, dryRun bool) ([]ui.LinkUpdate, error) {
+	return ctx.relink(renamed, oldTitle, renamed.Metadata.Title, dryRun)
+}
+
+// RelinkID points the [[wikilinks]] naming the old ID of a renamed note at
+// its new ID, like RelinkTitle. Self-links are changed in renamed, which
+// the caller saves.
+func (ctx *CommandContext) RelinkID(renamed *note.Note, oldID, newID string, dryRun bool) ([]ui.LinkUpdate, error) {
+	return ctx.relink(renamed, oldID, newID, dryRun)
+}
+
+func (ctx *CommandContext) relink(renamed *note.Note, oldTarget, newTarget string, dryRun bool) ([]ui.LinkUpdate, error) {
+	if oldTarget == newTarget {
+		return nil, nil
+	}
+
+	notes, err := ctx.Storage.GetAllNotes()
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range notes {
+		if n.ID() != renamed.ID() && (strings.EqualFold(n.Metadata.Title, oldTarget) || strings.EqualFold(n.ID(), oldTarget)) {
+			slog.Debug("not relinking target still in use", "target", oldTarget, "id", n.ID())
+			return nil, nil
+		}
+	}
+
+	var updates []ui.LinkUpdate
+	for _, n := range notes {
+		// Encrypted content cannot be searched for links
+		if n.Locked() {
+			continue
+		}
+		changed := n.RenameLinks(oldTarget, newTarget)
+		if changed == 0 {
+			continue
+		}
+		if n.ID() == renamed.ID() {
+			// Self-links are saved along with the rename itself
+			renamed.RenameLinks(oldTarget, newTarget)
+		} else if !dryRun {
+			if err := ctx.Storage.SaveNote(n); err != nil {
+				return updates, fmt.Errorf("error updating links in %s: %w", n.ID(), err)
+			}
+		}
+		updates = append(updates, ui.LinkUpdate{Note: n, Links: changed})
+	}
+	return updates, nil
+}
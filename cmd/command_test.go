@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"testing"
+
+	"memo/internal/config"
+	"memo/internal/note"
+	"memo/internal/storage"
+)
+
+// newTestContext returns a command context on a store in a temporary
+// directory holding a note with the given title, and that note's ID
+func newTestContext(t *testing.T, title string) (*CommandContext, string) {
+	t.Helper()
+	fs := storage.NewFileStorageWithConfig(t.TempDir(), storage.DefaultNoteExtension)
+	id, err := fs.CreateNote(note.New(title, "first line", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &CommandContext{Storage: fs, Config: &config.Config{}}, id
+}
+
+// findNote loads a note of the test store, failing the test when it is gone
+func findNote(t *testing.T, ctx *CommandContext, id string) *note.Note {
+	t.Helper()
+	n, err := ctx.Storage.FindNoteByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
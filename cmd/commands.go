@@ -1,22 +1,47 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"memo/internal/config"
+	"memo/internal/logging"
+	"memo/internal/note"
+	"memo/internal/schema"
 	"memo/internal/storage"
+	"memo/internal/trash"
 	"memo/internal/ui"
+	"memo/internal/zipstore"
 )
 
 type App struct {
 	ctx      *CommandContext
 	commands map[string]Command
+	// storeFile is the zip file holding the store, when the profile keeps
+	// it in one
+	storeFile *zipstore.Store
+}
+
+// globalOptions holds the flags accepted before the command name
+type globalOptions struct {
+	profile  string
+	verbose  bool
+	quiet    bool
+	readOnly bool
+	force    bool
+	dryRun   bool
+	// global skips project stores and uses the profile's store
+	global bool
 }
 
 func NewApp() *App {
-	ctx := &CommandContext{
-		Storage: storage.NewFileStorage(),
-	}
+	ctx := &CommandContext{}
 
 	app := &App{
 		ctx:      ctx,
@@ -25,7 +50,7 @@ func NewApp() *App {
 
 	// Register all commands
 	app.registerCommands()
-	
+
 	return app
 }
 
@@ -34,35 +59,372 @@ func (app *App) registerCommands() {
 	app.commands["list"] = NewListCommand(app.ctx)
 	app.commands["read"] = NewReadCommand(app.ctx)
 	app.commands["edit"] = NewEditCommand(app.ctx)
+	app.commands["append"] = NewAppendCommand(app.ctx)
+	app.commands["attach"] = NewAttachCommand(app.ctx)
+	app.commands["audit"] = NewAuditCommand(app.ctx)
+	app.commands["random"] = NewRandomCommand(app.ctx)
+	app.commands["split"] = NewSplitCommand(app.ctx)
+	app.commands["prepend"] = NewPrependCommand(app.ctx)
+	app.commands["inbox"] = NewInboxCommand(app.ctx)
+	app.commands["capture"] = NewCaptureCommand(app.ctx)
+	app.commands["watch"] = NewWatchCommand(app.ctx)
+	app.commands["user"] = NewUserCommand(app.ctx)
+	app.commands["related"] = NewRelatedCommand(app.ctx)
+	app.commands["graph"] = NewGraphCommand(app.ctx)
+	app.commands["copy"] = NewCopyCommand(app.ctx)
+	app.commands["last"] = NewLastCommand(app.ctx)
+	app.commands["recent"] = NewRecentCommand(app.ctx)
+	app.commands["back"] = NewBackCommand(app.ctx)
+	app.commands["forward"] = NewForwardCommand(app.ctx)
+	app.commands["rename"] = NewRenameCommand(app.ctx)
 	app.commands["delete"] = NewDeleteCommand(app.ctx)
+	app.commands["count"] = NewCountCommand(app.ctx)
+	app.commands["count-words"] = NewCountWordsCommand(app.ctx)
+	app.commands["exists"] = NewExistsCommand(app.ctx)
 	app.commands["search"] = NewSearchCommand(app.ctx)
+	app.commands["find"] = NewFindCommand(app.ctx)
+	app.commands["encrypt"] = NewEncryptCommand(app.ctx)
+	app.commands["decrypt"] = NewDecryptCommand(app.ctx)
+	app.commands["key"] = NewKeyCommand(app.ctx)
+	app.commands["grep"] = NewGrepCommand(app.ctx)
+	app.commands["report"] = NewReportCommand(app.ctx)
+	app.commands["clock"] = NewClockCommand(app.ctx)
 	app.commands["stats"] = NewStatsCommand(app.ctx)
+	app.commands["tasks"] = NewTasksCommand(app.ctx)
+	app.commands["tags"] = NewTagsCommand(app.ctx)
+	app.commands["tag"] = NewTagCommand(app.ctx)
+	app.commands["autotag"] = NewAutotagCommand(app.ctx)
+	app.commands["purge-expired"] = NewPurgeExpiredCommand(app.ctx)
+	app.commands["doctor"] = NewDoctorCommand(app.ctx)
+	app.commands["lint"] = NewLintCommand(app.ctx)
+	app.commands["dedupe"] = NewDedupeCommand(app.ctx)
+	app.commands["revisions"] = NewRevisionsCommand(app.ctx)
+	app.commands["rollback"] = NewRollbackCommand(app.ctx)
+	app.commands["history"] = NewHistoryCommand(app.ctx)
+	app.commands["recover"] = NewRecoverCommand(app.ctx)
+	app.commands["export"] = NewExportCommand(app.ctx)
+	app.commands["import"] = NewImportCommand(app.ctx)
+	app.commands["recur"] = NewRecurCommand(app.ctx)
+	app.commands["backup"] = NewBackupCommand(app.ctx)
+	app.commands["sync"] = NewSyncCommand(app.ctx)
+	app.commands["resolve"] = NewResolveCommand(app.ctx)
+	app.commands["notebooks"] = NewNotebooksCommand(app.ctx)
+	app.commands["migrate-store"] = NewMigrateStoreCommand(app.ctx)
+	app.commands["migrate"] = NewMigrateCommand(app.ctx)
+	app.commands["restore-backup"] = NewRestoreBackupCommand(app.ctx)
+	app.commands["serve"] = NewServeCommand(app.ctx)
+	app.commands["daemon"] = NewDaemonCommand(app.ctx)
+	app.commands["share"] = NewShareCommand(app.ctx)
+	app.commands["profiles"] = NewProfilesCommand(app.ctx)
+	app.commands["init"] = NewInitCommand(app.ctx)
+	app.commands["trash"] = NewTrashCommand(app.ctx)
 	app.commands["help"] = NewHelpCommand(app.ctx)
 	app.commands["--help"] = NewHelpCommand(app.ctx)
 	app.commands["-h"] = NewHelpCommand(app.ctx)
 }
 
-func (app *App) Run() {
-	if len(os.Args) < 2 {
-		ui.PrintHelp()
-		return
+// parseGlobalFlags consumes the global flags preceding the command name and
+// returns the remaining arguments
+func parseGlobalFlags(args []string) (globalOptions, []string, error) {
+	opts := globalOptions{profile: os.Getenv("MEMO_PROFILE")}
+
+	for len(args) > 0 {
+		arg := args[0]
+		switch {
+		case arg == "--profile":
+			if len(args) < 2 {
+				return opts, nil, fmt.Errorf("profile name required\nUsage: memo --profile <name> <command>")
+			}
+			opts.profile = args[1]
+			args = args[2:]
+		case strings.HasPrefix(arg, "--profile="):
+			opts.profile = strings.TrimPrefix(arg, "--profile=")
+			args = args[1:]
+		case arg == "--read-only":
+			opts.readOnly = true
+			args = args[1:]
+		case arg == "--force":
+			opts.force = true
+			args = args[1:]
+		case arg == "--global":
+			opts.global = true
+			args = args[1:]
+		case arg == "--dry-run":
+			opts.dryRun = true
+			args = args[1:]
+		case arg == "--verbose" || arg == "-v":
+			opts.verbose = true
+			args = args[1:]
+		case arg == "--quiet" || arg == "-q":
+			opts.quiet = true
+			args = args[1:]
+		default:
+			return opts, args, nil
+		}
 	}
 
-	commandName := os.Args[1]
-	args := []string{}
-	if len(os.Args) > 2 {
-		args = os.Args[2:]
+	return opts, args, nil
+}
+
+// configure loads the configuration and prepares storage for the selected profile
+func (app *App) configure(cfg *config.Config, opts globalOptions) error {
+	profile, err := cfg.Profile(opts.profile)
+	if err != nil {
+		return err
 	}
 
+	app.ctx.Config = cfg
+	app.ctx.Profile = profile
+	app.ctx.ProfileName = cfg.ResolveProfileName(opts.profile)
+
+	// MEMO_DIR and then the nearest project store take precedence over the
+	// configured default profile, but not over a profile chosen with
+	// --profile or MEMO_PROFILE
+	notesDir := profile.NotesDir
+	fromEnv := opts.profile == "" && os.Getenv("MEMO_DIR") != ""
+	project := ""
+	if opts.profile == "" && !fromEnv && !opts.global {
+		if cwd, err := os.Getwd(); err == nil {
+			project, _ = config.FindProjectStore(cwd)
+		}
+	}
+	switch {
+	case project != "":
+		slog.Debug("using project store", "dir", project)
+		notesDir = project
+	case profile.StoreFile != "" && !fromEnv:
+		if profile.NotesDir != "" {
+			return fmt.Errorf("profile '%s' sets both notes_dir and store_file", app.ctx.ProfileName)
+		}
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return fmt.Errorf("no cache directory for store file: %w", err)
+		}
+		if app.storeFile, err = zipstore.Open(profile.StoreFile, zipstore.CacheDir(filepath.Join(base, "memo", "stores"), profile.StoreFile)); err != nil {
+			return err
+		}
+		notesDir = app.storeFile.Dir()
+	case notesDir == "" || fromEnv:
+		notesDir = config.DefaultNotesDir()
+	}
+	app.ctx.Storage = newStorage(cfg, profile, notesDir)
+	app.ctx.Storage.SetReadOnly(opts.readOnly || cfg.ReadOnly || profile.ReadOnly)
+	app.ctx.Storage.SetIgnoreLocks(opts.force)
+	if opts.dryRun {
+		app.ctx.DryRun = true
+		app.ctx.Storage.SetDryRun(ui.DisplayDryRunChange)
+	}
+	ui.SetSizeLimits(sizeLimits(cfg))
+	if !opts.quiet {
+		app.ctx.Storage.SetProgress(ui.ProgressFunc("Loading notes"))
+	}
+
+	slog.Debug("configured storage", "profile", app.ctx.ProfileName, "dir", app.ctx.Storage.NotesDir(), "readOnly", app.ctx.Storage.ReadOnly())
+	return nil
+}
+
+// newStorage opens the store in notesDir with the settings cfg and profile
+// give it
+func newStorage(cfg *config.Config, profile config.Profile, notesDir string) *storage.FileStorage {
+	fs := storage.NewFileStorageWithConfig(notesDir, storage.DefaultNoteExtension)
+	if cfg.MaxRevisions != nil {
+		fs.SetMaxRevisions(*cfg.MaxRevisions)
+	}
+	fs.SetIDPrefixes(profile.IDPrefixes())
+	fs.SetMergeHashtags(profile.MergeHashtags)
+	fs.SetSizeLimits(sizeLimits(cfg))
+	fs.SetConstraints(constraints(cfg, profile))
+	fs.SetTrash(trashPolicy(cfg))
+	if cfg.LintOnSave() {
+		if linter, err := newLinter(cfg, false); err != nil {
+			slog.Warn("not linting notes on save", "error", err)
+		} else {
+			fs.SetLint(lintBeforeSave(fs, linter))
+		}
+	}
+	return fs
+}
+
+// sizeLimits returns the configured note size limits
+func sizeLimits(cfg *config.Config) note.SizeLimits {
+	if l := cfg.NoteLimits; l != nil {
+		return note.SizeLimits{Bytes: l.MaxKB * 1024, Lines: l.MaxLines}
+	}
+	return note.DefaultSizeLimits
+}
+
+// constraints returns the metadata rules of the top of the store, under "",
+// and of every notebook requiring fields of its own
+func constraints(cfg *config.Config, profile config.Profile) map[string]note.Constraints {
+	var base note.Constraints
+	if v := cfg.Validation; v != nil {
+		base = note.Constraints{MinPriority: v.MinPriority, MaxPriority: v.MaxPriority, Statuses: v.Statuses, Required: v.RequiredFields}
+	}
+	rules := map[string]note.Constraints{"": base}
+	for name, nb := range profile.Notebooks {
+		if len(nb.RequiredFields) > 0 {
+			c := base
+			c.Required = append(slices.Clone(base.Required), nb.RequiredFields...)
+			rules[name] = c
+		}
+	}
+	return rules
+}
+
+// trashPolicy returns the configured retention of deleted notes, or nil
+// when the trash is disabled
+func trashPolicy(cfg *config.Config) *trash.Policy {
+	policy := &trash.Policy{MaxAge: trash.DefaultMaxAge}
+	if t := cfg.Trash; t != nil {
+		if t.Disabled {
+			return nil
+		}
+		if t.MaxAgeDays != nil {
+			policy.MaxAge = time.Duration(*t.MaxAgeDays) * 24 * time.Hour
+		}
+		policy.MaxBytes = int64(t.MaxSizeMB * 1024 * 1024)
+	}
+	return policy
+}
+
+// Run executes the command named by the arguments and returns the process
+// exit status: 0 on success, 1 when the command failed and 2 for a usage
+// error. Commands answering a yes/no question return an ExitError instead,
+// and a prompt left with Ctrl-C ends memo with 130, as the signal would.
+func (app *App) Run() int {
+	opts, args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 2
+	}
+
+	if opts.verbose && opts.quiet {
+		fmt.Println("Error: --verbose and --quiet cannot be used together")
+		return 2
+	}
+	logging.Configure(opts.verbose, opts.quiet)
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	ui.SetLocale(ui.DetectLocale(cfg.Language))
+
+	if len(args) < 1 {
+		ui.PrintHelp()
+		return 0
+	}
+
+	commandName := args[0]
+	args = args[1:]
+
 	command, exists := app.commands[commandName]
 	if !exists {
 		fmt.Printf("Unknown command: %s\n", commandName)
 		ui.PrintHelp()
+		return 2
+	}
+
+	if err := app.configure(cfg, opts); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return 1
+	}
+	if commandName != "migrate-store" {
+		warnLegacyStore(app.ctx.Storage.NotesDir())
+	}
+	if commandName != "migrate" {
+		warnSchemaVersion(app.ctx.Storage)
+	}
+	if commandName != "recover" {
+		warnInterruptedTransaction(app.ctx.Storage)
+	}
+	pruneTrash(app.ctx.Storage)
+
+	err = command.Execute(args)
+	if app.storeFile != nil {
+		// Changes are written to the store file even when the command
+		// failed halfway
+		if closeErr := app.storeFile.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	var exit ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exit):
+		return exit.Code
+	case errors.Is(err, ui.ErrInterrupted):
+		return 130
+	}
+	fmt.Printf("Error: %v\n", err)
+	return 1
+}
+
+// ExitError ends a command with a status code and no message, for commands
+// such as `memo exists` that answer through their exit status
+type ExitError struct {
+	Code int
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// warnSchemaVersion points out a store whose notes are in an older front
+// matter layout, or one written by a newer memo
+func warnSchemaVersion(fs *storage.FileStorage) {
+	version, err := fs.SchemaVersion()
+	if err != nil {
+		slog.Warn("cannot read store version", "error", err)
 		return
 	}
+	switch current := schema.Current(); {
+	case version < current:
+		slog.Warn(fmt.Sprintf("notes are in schema version %d; run 'memo migrate' to update them to %d", version, current))
+	case version > current:
+		slog.Warn(fmt.Sprintf("the store has schema version %d, newer than this memo supports (%d)", version, current))
+	}
+}
 
-	err := command.Execute(args)
+// warnInterruptedTransaction points out an operation a memo process left
+// half done, which keeps others from starting until it is recovered
+func warnInterruptedTransaction(fs *storage.FileStorage) {
+	t, err := fs.InterruptedTransaction()
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		slog.Warn("cannot read the transaction journal", "error", err)
+		return
+	}
+	if t != nil {
+		slog.Warn((&storage.TransactionError{Transaction: t, Interrupted: true}).Error())
+	}
+}
+
+// pruneTrash deletes the notes that have been in the trash for too long,
+// so the retention policy holds without anyone emptying the trash
+func pruneTrash(fs *storage.FileStorage) {
+	dropped, err := fs.PruneTrash(time.Now())
+	if err != nil {
+		slog.Warn("could not clean up the trash", "error", err)
+		return
+	}
+	if len(dropped) > 0 {
+		slog.Debug("removed notes from the trash", "count", len(dropped))
+	}
+}
+
+// warnLegacyStore points out a store left in the working directory by
+// older versions, which no longer read it
+func warnLegacyStore(notesDir string) {
+	info, err := os.Stat(config.LegacyNotesDir)
+	if err != nil || !info.IsDir() {
+		return
 	}
-}
\ No newline at end of file
+	legacy, _ := filepath.Abs(config.LegacyNotesDir)
+	current, _ := filepath.Abs(notesDir)
+	if legacy == current || config.IsProjectStore(legacy) {
+		return
+	}
+	slog.Warn(fmt.Sprintf("found notes in %s, which is no longer used; run 'memo migrate-store' to move them into %s", legacy, current))
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+
+	"memo/internal/clipboard"
+)
+
+// CopyCommand puts a note's content on the system clipboard
+type CopyCommand struct {
+	ctx *CommandContext
+}
+
+func NewCopyCommand(ctx *CommandContext) *CopyCommand {
+	return &CopyCommand{ctx: ctx}
+}
+
+func (c *CopyCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--title")
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("note-id or number required\nUsage: memo copy [--title] <note-id|number|title>")
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(p.Positional[0])
+	if err != nil {
+		return err
+	}
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	if err := c.ctx.UnlockNote(n); err != nil {
+		return err
+	}
+
+	text := n.Content
+	if p.Bool("--title") {
+		text = "# " + n.Metadata.Title + "\n\n" + text
+	}
+	if err := clipboard.Write(text); err != nil {
+		return fmt.Errorf("error writing clipboard: %w", err)
+	}
+
+	fmt.Printf("Copied '%s' to the clipboard\n", n.Metadata.Title)
+	c.ctx.RecordAccess(noteID, "read")
+	return nil
+}
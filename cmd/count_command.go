@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"memo/internal/note"
+	"memo/internal/storage"
+)
+
+type CountCommand struct {
+	ctx *CommandContext
+}
+
+func NewCountCommand(ctx *CommandContext) *CountCommand {
+	return &CountCommand{ctx: ctx}
+}
+
+// countFieldFlags are shorthands for --where on common front matter fields
+var countFieldFlags = []string{"--status", "--priority", "--author"}
+
+// Execute prints only the number of matching notes, for shell scripts
+func (c *CountCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--tag=", "--where=", "--notebook=", "--status=", "--priority=", "--author=")
+	if err != nil {
+		return fmt.Errorf("%v\nUsage: memo count [--tag <tag>] [--status <s>] [--priority <n>] [--author <a>] [--notebook <name>] [--where <field>=<value>] [<search terms>]", err)
+	}
+
+	// Progress output would end up in the number a script captures
+	c.ctx.Storage.SetProgress(nil)
+
+	var notes []*note.Note
+	if len(p.Positional) > 0 {
+		notes, err = c.ctx.Storage.SearchNotes(storage.QuoteTerms(p.Positional))
+	} else {
+		notes, err = c.ctx.Storage.GetAllNotes()
+	}
+	if err != nil {
+		return fmt.Errorf("error loading notes: %w", err)
+	}
+
+	if tag := p.Value("--tag"); tag != "" {
+		var tagged []*note.Note
+		for _, n := range notes {
+			if n.HasTag(tag) {
+				tagged = append(tagged, n)
+			}
+		}
+		notes = tagged
+	}
+	if notebook := p.Value("--notebook"); notebook != "" {
+		notes = filterByNotebook(c.ctx.Storage, notes, notebook)
+	}
+	for _, flag := range countFieldFlags {
+		if value := p.Value(flag); value != "" {
+			notes = filterByField(notes, strings.TrimPrefix(flag, "--"), value)
+		}
+	}
+	for _, where := range p.Values("--where") {
+		field, value, _ := strings.Cut(where, "=")
+		field, value = strings.TrimSpace(field), strings.TrimSpace(value)
+		if field == "" {
+			return fmt.Errorf("field name required\nUsage: memo count --where <field>=<value>")
+		}
+		notes = filterByField(notes, field, value)
+	}
+
+	fmt.Println(len(notes))
+	return nil
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"memo/internal/analysis"
+	"memo/internal/ui"
+)
+
+// CountWordsCommand shows how long a note is and how long it takes to read
+type CountWordsCommand struct {
+	ctx *CommandContext
+}
+
+func NewCountWordsCommand(ctx *CommandContext) *CountWordsCommand {
+	return &CountWordsCommand{ctx: ctx}
+}
+
+func (c *CountWordsCommand) Execute(args []string) error {
+	p, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("note-id or number required\nUsage: memo count-words <note-id|number|title>")
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(p.Positional[0])
+	if err != nil {
+		return err
+	}
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	if err := c.ctx.UnlockNote(n); err != nil {
+		return err
+	}
+
+	ui.DisplayTextCounts(n, analysis.CountText(n.Content))
+	return nil
+}
@@ -2,14 +2,32 @@ package cmd
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
+	"memo/internal/clipboard"
 	"memo/internal/note"
+	"memo/internal/storage"
+	"memo/internal/templates"
 	"memo/internal/ui"
 )
 
 type CreateCommand struct {
 	ctx *CommandContext
+	// location is the --location flag, applied by save
+	location string
+	// dates holds the --due, --starts, --duration and --completed flags,
+	// applied by save
+	dates note.Metadata
+}
+
+// dateFlags maps the date flags of create and edit to their fields
+var dateFlags = []struct{ flag, field string }{
+	{"--due", "due"},
+	{"--starts", "starts"},
+	{"--duration", "duration"},
+	{"--completed", "completed_at"},
 }
 
 func NewCreateCommand(ctx *CommandContext) *CreateCommand {
@@ -17,30 +35,175 @@ func NewCreateCommand(ctx *CommandContext) *CreateCommand {
 }
 
 func (c *CreateCommand) Execute(args []string) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	p, err := parseArgs(args, "--template=", "--expires=", "--notebook=", "--location=", "--var=", "--due=", "--starts=", "--duration=", "--completed=", "--encrypt", "--from-clipboard")
+	if err != nil {
+		return err
+	}
+
+	if notebook := p.Value("--notebook"); notebook != "" {
+		if err := storage.ValidateNotebook(notebook); err != nil {
+			return err
+		}
+	}
+
+	var expires time.Time
+	if value := p.Value("--expires"); value != "" {
+		if expires, err = note.ParseExpiry(value, time.Now()); err != nil {
+			return err
+		}
+	}
+	c.location = p.Value("--location")
+
+	// The dates are read before prompting so a mistake costs no typing
+	var dates note.Note
+	for _, d := range dateFlags {
+		if value := p.Value(d.flag); value != "" {
+			if err := dates.SetDateField(d.field, value, time.Now()); err != nil {
+				return err
+			}
+		}
+	}
+	c.dates = dates.Metadata
+
+	vars := make(map[string]string)
+	for _, assignment := range p.Values("--var") {
+		name, value, ok := strings.Cut(assignment, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid variable '%s'\nUsage: memo create --template <name> --var <name>=<value>", assignment)
+		}
+		vars[strings.TrimSpace(name)] = value
+	}
+
+	if name := p.Value("--template"); name != "" {
+		return c.createFromTemplate(name, p.Value("--notebook"), p.Bool("--encrypt"), expires, vars)
+	}
+	if len(vars) > 0 {
+		return fmt.Errorf("--var needs --template")
+	}
+	if p.Bool("--from-clipboard") {
+		return c.createFromClipboard(p.Value("--notebook"), p.Bool("--encrypt"), expires)
+	}
+
 	title := ui.PromptForInput("Enter note title: ")
 	if title == "" {
 		return fmt.Errorf("title is required")
 	}
 
-	content := ui.PromptForInput("Enter note content: ")
+	content := ui.PromptForText("Enter note content (end with a line holding only \".\" or Ctrl-D):")
 
-	tagsInput := ui.PromptForInput("Enter tags (comma-separated, optional): ")
-	var tags []string
-	if tagsInput != "" {
-		for _, tag := range strings.Split(tagsInput, ",") {
-			tags = append(tags, strings.TrimSpace(tag))
-		}
+	tags, err := c.ctx.PromptForTags("Enter tags (comma-separated, optional): ", title+" "+content, nil)
+	if err != nil {
+		return err
 	}
 
-	noteID := c.ctx.Storage.GenerateNoteID()
 	n := note.New(title, content, tags)
-	n.SetFilePath(c.ctx.Storage.GenerateNoteFilePath(noteID))
+	n.Metadata.Expires = expires
+
+	return c.save(n, p.Value("--notebook"), p.Bool("--encrypt"))
+}
+
+// createFromTemplate asks for the template's variables that were not given
+// with --var, offering their defaults
+func (c *CreateCommand) createFromTemplate(name, notebook string, encrypt bool, expires time.Time, vars map[string]string) error {
+	tmpl, err := templates.Load(c.ctx.Storage, name)
+	if err != nil {
+		return err
+	}
+
+	declared, err := tmpl.Variables()
+	if err != nil {
+		return err
+	}
+	for given := range vars {
+		if !slices.ContainsFunc(declared, func(v templates.Variable) bool { return v.Name == given }) {
+			return fmt.Errorf("template '%s' has no variable '%s'", name, given)
+		}
+	}
+	for _, v := range declared {
+		if _, ok := vars[v.Name]; ok {
+			continue
+		}
+		prompt := v.Prompt
+		if prompt == "" {
+			prompt = v.Name
+		}
+		if v.Default != "" {
+			prompt += " [" + v.Default + "]"
+		}
+		if answer := ui.PromptForInput(prompt + ": "); answer != "" {
+			vars[v.Name] = answer
+		}
+	}
+
+	data := templates.NewData(time.Now())
+	data.Vars = vars
+	n, err := tmpl.Instantiate(data)
+	if err != nil {
+		return err
+	}
+	if !expires.IsZero() {
+		n.Metadata.Expires = expires
+	}
+
+	return c.save(n, notebook, encrypt)
+}
 
-	err := c.ctx.Storage.SaveNote(n)
+// createFromClipboard makes a note of the clipboard's text without
+// prompting; like capture, the first line becomes the title
+func (c *CreateCommand) createFromClipboard(notebook string, encrypt bool, expires time.Time) error {
+	text, err := clipboard.Read()
+	if err != nil {
+		return fmt.Errorf("error reading clipboard: %w", err)
+	}
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return fmt.Errorf("the clipboard holds no text")
+	}
+
+	title, content := captureTitle(text)
+	n := note.New(title, content, nil)
+	n.Metadata.Expires = expires
+	return c.save(n, notebook, encrypt)
+}
+
+func (c *CreateCommand) save(n *note.Note, notebook string, encrypt bool) error {
+	if c.location != "" {
+		location := c.ctx.ResolveLocation(c.location)
+		n.Metadata.Location = &location
+	}
+	if !c.dates.Due.IsZero() {
+		n.Metadata.Due = c.dates.Due
+	}
+	if !c.dates.Starts.IsZero() {
+		n.Metadata.Starts = c.dates.Starts
+	}
+	if !c.dates.Duration.IsZero() {
+		n.Metadata.Duration = c.dates.Duration
+	}
+	if !c.dates.CompletedAt.IsZero() {
+		n.Metadata.CompletedAt = c.dates.CompletedAt
+	}
+	c.ctx.ApplyDefaults(n, notebook)
+	if encrypt {
+		key, err := c.ctx.EncryptionKey(true)
+		if err != nil {
+			return err
+		}
+		if err := n.Encrypt(key); err != nil {
+			return err
+		}
+	}
+
+	noteID, err := c.ctx.Storage.CreateNoteIn(n, notebook)
 	if err != nil {
 		return fmt.Errorf("error creating note: %w", err)
 	}
 
 	fmt.Printf("Note created successfully: %s\n", noteID)
+	c.ctx.RecordAccess(noteID, "created")
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"memo/internal/daemon"
+)
+
+// DaemonCommand keeps the store open for editor plugins, which talk to it
+// over a Unix socket instead of starting memo for every request
+type DaemonCommand struct {
+	ctx *CommandContext
+}
+
+func NewDaemonCommand(ctx *CommandContext) *DaemonCommand {
+	return &DaemonCommand{ctx: ctx}
+}
+
+func (c *DaemonCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--socket=")
+	if err != nil {
+		return fmt.Errorf("%v\nUsage: memo daemon [--socket <path>]", err)
+	}
+	path := p.Value("--socket")
+	if path == "" {
+		path = daemon.DefaultSocket()
+	}
+
+	// Requests load notes concurrently; a terminal progress bar makes no
+	// sense there
+	c.ctx.Storage.SetProgress(nil)
+
+	l, err := daemon.Listen(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Serving notes from %s on %s (Ctrl-C to stop)\n", c.ctx.Storage.NotesDir(), path)
+	return daemon.New(c.ctx.Storage).Serve(ctx, l)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import "fmt"
+
+type DecryptCommand struct {
+	ctx *CommandContext
+}
+
+func NewDecryptCommand(ctx *CommandContext) *DecryptCommand {
+	return &DecryptCommand{ctx: ctx}
+}
+
+func (c *DecryptCommand) Execute(args []string) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	if len(args) < 1 {
+		return fmt.Errorf("note-id or number required\nUsage: memo decrypt <note-id|number>")
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(args[0])
+	if err != nil {
+		return err
+	}
+
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	if !n.Metadata.Encrypted {
+		return fmt.Errorf("note '%s' is not encrypted", n.Metadata.Title)
+	}
+
+	if err := c.ctx.UnlockNote(n); err != nil {
+		return err
+	}
+	if err := n.RemoveEncryption(); err != nil {
+		return err
+	}
+
+	if err := c.ctx.Storage.SaveNote(n); err != nil {
+		return fmt.Errorf("error saving note: %w", err)
+	}
+
+	fmt.Println("Note decrypted successfully!")
+	return nil
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"memo/internal/analysis"
+	"memo/internal/note"
+	"memo/internal/ui"
+)
+
+const defaultDedupeThreshold = 0.8
+
+type DedupeCommand struct {
+	ctx *CommandContext
+}
+
+func NewDedupeCommand(ctx *CommandContext) *DedupeCommand {
+	return &DedupeCommand{ctx: ctx}
+}
+
+func (c *DedupeCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--threshold=", "--list")
+	if err != nil {
+		return err
+	}
+
+	threshold := defaultDedupeThreshold
+	if v := p.Value("--threshold"); v != "" {
+		threshold, err = strconv.ParseFloat(v, 64)
+		if err != nil || threshold <= 0 || threshold > 1 {
+			return fmt.Errorf("threshold must be a number between 0 and 1")
+		}
+	}
+
+	notes, err := c.ctx.Storage.GetAllNotes()
+	if err != nil {
+		return fmt.Errorf("error loading notes: %w", err)
+	}
+
+	pairs := analysis.FindDuplicates(notes, threshold)
+	if len(pairs) == 0 {
+		fmt.Println("No duplicate notes found.")
+		return nil
+	}
+
+	fmt.Printf("Found %d duplicate candidate(s).\n", len(pairs))
+	interactive := !p.Bool("--list") && !c.ctx.Storage.ReadOnly()
+
+	removed := make(map[*note.Note]bool)
+	for i, pair := range pairs {
+		if removed[pair.A] || removed[pair.B] {
+			continue
+		}
+
+		fmt.Printf("\nCandidate %d of %d\n", i+1, len(pairs))
+		ui.DisplayDuplicatePair(pair)
+		if !interactive {
+			continue
+		}
+
+		action := strings.ToLower(ui.PromptForInput("[k]eep both, delete [1], delete [2], [m]erge 2 into 1, [q]uit: "))
+		switch action {
+		case "1":
+			err = c.delete(pair.A)
+			removed[pair.A] = true
+		case "2":
+			err = c.delete(pair.B)
+			removed[pair.B] = true
+		case "m":
+			err = c.merge(pair.A, pair.B)
+			removed[pair.B] = true
+		case "q":
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *DedupeCommand) delete(n *note.Note) error {
+	if err := c.ctx.Storage.DeleteNote(n.ID()); err != nil {
+		return fmt.Errorf("error deleting note: %w", err)
+	}
+	fmt.Printf("Deleted %s.\n", n.ID())
+	return nil
+}
+
+// merge folds src into dst: differing content is appended and tags are
+// combined, then src is deleted. Both happen or, if either fails, neither.
+func (c *DedupeCommand) merge(dst, src *note.Note) error {
+	tx, err := c.ctx.BeginTransaction(c.ctx.DryRun)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if analysis.NormalizeContent(dst.Content) != analysis.NormalizeContent(src.Content) {
+		dst.UpdateContent(dst.Content + "\n\n" + src.Content)
+	}
+
+	tags := dst.Metadata.Tags
+	for _, tag := range src.Metadata.Tags {
+		if !dst.HasTag(tag) {
+			tags = append(tags, tag)
+		}
+	}
+	dst.UpdateTags(tags)
+
+	if err := c.ctx.Storage.SaveNote(dst); err != nil {
+		return fmt.Errorf("error saving merged note: %w", err)
+	}
+	if err := c.delete(src); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	fmt.Printf("Merged %s into %s.\n", src.ID(), dst.ID())
+	return nil
+}
@@ -2,9 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"path/filepath"
-	"strconv"
-	"strings"
 
 	"memo/internal/ui"
 )
@@ -18,12 +15,23 @@ func NewDeleteCommand(ctx *CommandContext) *DeleteCommand {
 }
 
 func (c *DeleteCommand) Execute(args []string) error {
-	if len(args) < 1 {
-		return fmt.Errorf("note-id or number required\nUsage: memo delete <note-id|number>")
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
 	}
 
-	identifier := args[0]
-	noteID, err := c.resolveNoteID(identifier)
+	p, err := parseArgs(args, "--force")
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("note-id or number required\nUsage: memo delete [--force] <note-id|number>")
+	}
+	if p.Bool("--force") {
+		c.ctx.Storage.SetIgnoreLocks(true)
+	}
+
+	identifier := p.Positional[0]
+	noteID, err := c.ctx.ResolveNoteID(identifier)
 	if err != nil {
 		return err
 	}
@@ -32,8 +40,15 @@ func (c *DeleteCommand) Execute(args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := c.ctx.Storage.CheckLock(noteID); err != nil {
+		return err
+	}
 
-	prompt := fmt.Sprintf("Are you sure you want to delete note '%s'? (y/N): ", n.Metadata.Title)
+	if c.ctx.DryRun {
+		return c.ctx.Storage.DeleteNote(noteID)
+	}
+
+	prompt := ui.Tf("Are you sure you want to delete note '%s'? (y/N): ", n.Metadata.Title)
 	if !ui.ConfirmAction(prompt) {
 		fmt.Println("Deletion cancelled.")
 		return nil
@@ -44,23 +59,10 @@ func (c *DeleteCommand) Execute(args []string) error {
 		return fmt.Errorf("error deleting note: %w", err)
 	}
 
+	if c.ctx.Storage.TrashPolicy() != nil {
+		fmt.Printf("Note moved to the trash. Restore it with: memo trash restore %s\n", noteID)
+		return nil
+	}
 	fmt.Println("Note deleted successfully!")
 	return nil
 }
-
-func (c *DeleteCommand) resolveNoteID(identifier string) (string, error) {
-	if num, err := strconv.Atoi(identifier); err == nil {
-		if c.ctx.CurrentListing == nil || len(c.ctx.CurrentListing) == 0 {
-			return "", fmt.Errorf("no current note listing. Please run 'memo list' first")
-		}
-
-		if num < 1 || num > len(c.ctx.CurrentListing) {
-			return "", fmt.Errorf("number %d is out of range. Valid range: 1-%d", num, len(c.ctx.CurrentListing))
-		}
-
-		n := c.ctx.CurrentListing[num-1]
-		return strings.TrimSuffix(filepath.Base(n.FilePath), ".note"), nil
-	}
-
-	return identifier, nil
-}
\ No newline at end of file
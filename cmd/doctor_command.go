@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"memo/internal/doctor"
+	"memo/internal/ui"
+)
+
+type DoctorCommand struct {
+	ctx *CommandContext
+}
+
+func NewDoctorCommand(ctx *CommandContext) *DoctorCommand {
+	return &DoctorCommand{ctx: ctx}
+}
+
+func (c *DoctorCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--fix")
+	if err != nil {
+		return err
+	}
+	fix := p.Bool("--fix")
+	if fix {
+		if err := c.ctx.Storage.CheckWritable(); err != nil {
+			return err
+		}
+	}
+
+	report, err := doctor.Check(c.ctx.Storage)
+	if err != nil {
+		return fmt.Errorf("error checking notes: %w", err)
+	}
+	ui.DisplayDoctorReport(report, c.ctx.Storage.NotesDir())
+
+	if !fix || report.Fixable() == 0 {
+		return nil
+	}
+
+	fixed, err := doctor.Fix(c.ctx.Storage, report)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Repaired %d problem(s).\n", fixed)
+	return nil
+}
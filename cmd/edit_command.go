@@ -2,10 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"path/filepath"
-	"strconv"
 	"strings"
+	"time"
 
+	"memo/internal/note"
 	"memo/internal/ui"
 )
 
@@ -18,62 +18,178 @@ func NewEditCommand(ctx *CommandContext) *EditCommand {
 }
 
 func (c *EditCommand) Execute(args []string) error {
-	if len(args) < 1 {
-		return fmt.Errorf("note-id or number required\nUsage: memo edit <note-id|number>")
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	p, err := parseArgs(args, "--set=", "--due=", "--starts=", "--duration=", "--completed=", "--force", "--metadata", "--append")
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("note-id or number required\nUsage: memo edit [--force] [--metadata | --append | --set <field>=<value>... | --due <date>...] <note-id|number|title>")
+	}
+	if p.Bool("--force") {
+		c.ctx.Storage.SetIgnoreLocks(true)
 	}
 
-	identifier := args[0]
-	noteID, err := c.resolveNoteID(identifier)
+	identifier := p.Positional[0]
+	noteID, err := c.ctx.ResolveNoteID(identifier)
 	if err != nil {
 		return err
 	}
 
+	release, err := c.ctx.Storage.AcquireLock(noteID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	n, err := c.ctx.Storage.FindNoteByID(noteID)
 	if err != nil {
 		return err
 	}
 
+	oldTitle := n.Metadata.Title
+
+	// The date flags are shorthands for --set
+	sets := p.Values("--set")
+	for _, d := range dateFlags {
+		if value := p.Value(d.flag); value != "" {
+			sets = append(sets, d.field+"="+value)
+		}
+	}
+
+	// Metadata is never encrypted, so it is edited without unlocking
+	if len(sets) > 0 {
+		err = c.setMetadata(n, sets)
+	} else if p.Bool("--metadata") {
+		err = c.editMetadata(n)
+	} else if p.Bool("--append") {
+		err = c.appendContent(n)
+	} else {
+		err = c.editContent(n)
+	}
+	if err != nil {
+		return err
+	}
+
+	if n.Metadata.Title != oldTitle {
+		updates, err := c.ctx.RelinkTitle(n, oldTitle, false)
+		if err != nil {
+			return err
+		}
+		ui.DisplayLinkUpdates(updates, false)
+	}
+
+	if err := c.ctx.Storage.SaveNote(n); err != nil {
+		return fmt.Errorf("error saving note: %w", err)
+	}
+
+	fmt.Println("Note updated successfully!")
+	c.ctx.RecordAccess(noteID, "edited")
+	return nil
+}
+
+func (c *EditCommand) editContent(n *note.Note) error {
+	if err := c.ctx.UnlockNote(n); err != nil {
+		return err
+	}
+
 	fmt.Printf("Editing note: %s\n", n.Metadata.Title)
 	fmt.Printf("Current content:\n%s\n\n", n.Content)
 
-	newContent := ui.PromptForInput("Enter new content (leave empty to keep current): ")
+	newContent := ui.PromptForText("Enter new content, ending with a line holding only \".\" or Ctrl-D\n(leave empty to keep current):")
 	if newContent != "" {
 		n.UpdateContent(newContent)
 	}
 
 	currentTags := strings.Join(n.Metadata.Tags, ", ")
 	fmt.Printf("Current tags: %s\n", currentTags)
-	newTags := ui.PromptForInput("Enter new tags (comma-separated, leave empty to keep current): ")
-	if newTags != "" {
-		var tags []string
-		for _, tag := range strings.Split(newTags, ",") {
-			tags = append(tags, strings.TrimSpace(tag))
-		}
-		n.UpdateTags(tags)
+	newTags, err := c.ctx.PromptForTags("Enter new tags (comma-separated, leave empty to keep current): ", n.Metadata.Title+" "+n.Content, n.Metadata.Tags)
+	if err != nil {
+		return err
+	}
+	if len(newTags) > 0 {
+		n.UpdateTags(newTags)
 	}
+	return nil
+}
 
-	err = c.ctx.Storage.SaveNote(n)
-	if err != nil {
-		return fmt.Errorf("error saving note: %w", err)
+// appendContent adds lines typed at the prompt to the end of the content
+func (c *EditCommand) appendContent(n *note.Note) error {
+	if err := c.ctx.UnlockNote(n); err != nil {
+		return err
 	}
 
-	fmt.Println("Note updated successfully!")
+	fmt.Printf("Appending to note: %s\n", n.Metadata.Title)
+	text := strings.TrimRight(ui.PromptForText("Enter text to append (end with a line holding only \".\" or Ctrl-D):"), "\n")
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("nothing to append")
+	}
+	n.UpdateContent(joinText(n.Content, text, false))
 	return nil
 }
 
-func (c *EditCommand) resolveNoteID(identifier string) (string, error) {
-	if num, err := strconv.Atoi(identifier); err == nil {
-		if c.ctx.CurrentListing == nil || len(c.ctx.CurrentListing) == 0 {
-			return "", fmt.Errorf("no current note listing. Please run 'memo list' first")
+// setMetadata applies --set field=value assignments, checking all of them
+// before the note is saved
+func (c *EditCommand) setMetadata(n *note.Note, sets []string) error {
+	for _, set := range sets {
+		field, value, ok := strings.Cut(set, "=")
+		if !ok {
+			return fmt.Errorf("invalid assignment '%s'\nUsage: memo edit --set <field>=<value> <note-id|number|title>", set)
 		}
-
-		if num < 1 || num > len(c.ctx.CurrentListing) {
-			return "", fmt.Errorf("number %d is out of range. Valid range: 1-%d", num, len(c.ctx.CurrentListing))
+		if strings.EqualFold(strings.TrimSpace(field), "location") && strings.TrimSpace(value) != "" {
+			// Configured places get their coordinates
+			location := c.ctx.ResolveLocation(value)
+			n.Metadata.Location = &location
+			n.Metadata.Modified = time.Now()
+			continue
+		}
+		if err := n.SetMetadata(field, value); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		n := c.ctx.CurrentListing[num-1]
-		return strings.TrimSuffix(filepath.Base(n.FilePath), ".note"), nil
+// editMetadata asks for each front matter field in turn, repeating the
+// question until the answer is valid, and then for new custom fields
+func (c *EditCommand) editMetadata(n *note.Note) error {
+	fmt.Printf("Editing metadata of: %s\n", n.Metadata.Title)
+	fmt.Println("Press Enter to keep a value, or enter - to clear it.")
+
+	fields := append(append([]string{}, note.EditableFields...), n.FieldNames()...)
+
+	for _, field := range fields {
+		for {
+			answer := ui.PromptForInput(fmt.Sprintf("%s [%s]: ", field, n.MetadataValue(field)))
+			if answer == "" {
+				break
+			}
+			if answer == "-" {
+				answer = ""
+			}
+			if err := n.SetMetadata(field, answer); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			break
+		}
 	}
 
-	return identifier, nil
-}
\ No newline at end of file
+	for {
+		answer := ui.PromptForInput("Add a field (name=value, leave empty to finish): ")
+		if answer == "" {
+			return nil
+		}
+		field, value, ok := strings.Cut(answer, "=")
+		if !ok {
+			fmt.Println("Error: use name=value")
+			continue
+		}
+		if err := n.SetMetadata(field, value); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import "fmt"
+
+type EncryptCommand struct {
+	ctx *CommandContext
+}
+
+func NewEncryptCommand(ctx *CommandContext) *EncryptCommand {
+	return &EncryptCommand{ctx: ctx}
+}
+
+func (c *EncryptCommand) Execute(args []string) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	if len(args) < 1 {
+		return fmt.Errorf("note-id or number required\nUsage: memo encrypt <note-id|number>")
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(args[0])
+	if err != nil {
+		return err
+	}
+
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	if n.Metadata.Encrypted {
+		return fmt.Errorf("note '%s' is already encrypted", n.Metadata.Title)
+	}
+
+	key, err := c.ctx.EncryptionKey(true)
+	if err != nil {
+		return err
+	}
+	if err := n.Encrypt(key); err != nil {
+		return err
+	}
+
+	if err := c.ctx.Storage.SaveNote(n); err != nil {
+		return fmt.Errorf("error saving note: %w", err)
+	}
+
+	fmt.Println("Note encrypted successfully!")
+	return nil
+}
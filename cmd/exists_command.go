@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ExistsCommand struct {
+	ctx *CommandContext
+}
+
+func NewExistsCommand(ctx *CommandContext) *ExistsCommand {
+	return &ExistsCommand{ctx: ctx}
+}
+
+// Execute exits with status 0 when the note exists and 1 when it does not,
+// printing nothing. Unlike other commands it never matches titles fuzzily
+// or asks which note was meant.
+func (c *ExistsCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--title")
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) != 1 {
+		return fmt.Errorf("note ID required\nUsage: memo exists [--title] <note-id|title>")
+	}
+	target := p.Positional[0]
+
+	if !p.Bool("--title") {
+		if c.ctx.Storage.NoteExists(target) {
+			return nil
+		}
+		return ExitError{Code: 1}
+	}
+
+	c.ctx.Storage.SetProgress(nil)
+	notes, err := c.ctx.Storage.GetAllNotes()
+	if err != nil {
+		return fmt.Errorf("error loading notes: %w", err)
+	}
+	for _, n := range notes {
+		if strings.EqualFold(n.Metadata.Title, target) {
+			return nil
+		}
+	}
+	return ExitError{Code: 1}
+}
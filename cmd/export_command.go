@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"memo/internal/backup"
+	"memo/internal/exchange"
+	"memo/internal/note"
+	"memo/internal/storage"
+)
+
+type ExportCommand struct {
+	ctx *CommandContext
+}
+
+func NewExportCommand(ctx *CommandContext) *ExportCommand {
+	return &ExportCommand{ctx: ctx}
+}
+
+func (c *ExportCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--format=", "--batch-size=", "--all")
+	if err != nil {
+		return err
+	}
+	batchSize := storage.DefaultBatchSize
+	if value := p.Value("--batch-size"); value != "" {
+		if batchSize, err = strconv.Atoi(value); err != nil || batchSize < 1 {
+			return fmt.Errorf("--batch-size must be a positive number, not '%s'", value)
+		}
+	}
+	if p.Bool("--all") {
+		return c.exportArchive(p.Positional, p.Value("--format"), batchSize)
+	}
+
+	format := p.Value("--format")
+	if format == "" && len(p.Positional) > 1 && strings.EqualFold(filepath.Ext(p.Positional[1]), ".pdf") {
+		format = "pdf"
+	}
+
+	switch format {
+	case "", "md", "markdown":
+		return c.exportMarkdown(p.Positional)
+	case "pdf":
+		return c.exportPDF(p.Positional)
+	case "ics":
+		return c.exportICS(p.Positional, batchSize)
+	case "todotxt":
+		return c.exportTodoTxt(p.Positional, batchSize)
+	default:
+		return fmt.Errorf("unknown export format '%s' (use md, pdf, ics or todotxt)", format)
+	}
+}
+
+func (c *ExportCommand) exportMarkdown(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("note-id and file required\nUsage: memo export <note-id|number> <file.md|->")
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(args[0])
+	if err != nil {
+		return err
+	}
+
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	if err := c.ctx.UnlockNote(n); err != nil {
+		return err
+	}
+
+	text, err := exchange.ToMarkdown(n)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(args[1], []byte(text), fmt.Sprintf("Note exported to %s\n", args[1]))
+}
+
+// exportPDF writes a note as a printable PDF
+func (c *ExportCommand) exportPDF(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("note-id and file required\nUsage: memo export <note-id|number> <file.pdf|-> --format pdf")
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(args[0])
+	if err != nil {
+		return err
+	}
+
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	if err := c.ctx.UnlockNote(n); err != nil {
+		return err
+	}
+
+	data, err := exchange.ToPDF(n)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(args[1], data, fmt.Sprintf("Note exported to %s\n", args[1]))
+}
+
+// exportICS writes every note with a "due" date to an iCalendar file
+func (c *ExportCommand) exportICS(args []string, batchSize int) error {
+	if len(args) < 1 {
+		return fmt.Errorf("file required\nUsage: memo export --format ics [--batch-size <n>] <file.ics|->")
+	}
+
+	var count int
+	err := streamOutput(args[0], func(w io.Writer) error {
+		ics := exchange.NewICSWriter(w, time.Now())
+		if err := c.ctx.Storage.EachNote(batchSize, ics.Write); err != nil {
+			return err
+		}
+		count = ics.Count()
+		return ics.Close()
+	})
+	if err == nil && args[0] != "-" {
+		fmt.Printf("Exported %d note(s) with due dates to %s\n", count, args[0])
+	}
+	return err
+}
+
+// exportTodoTxt writes the checklist tasks of every note as todo.txt lines
+func (c *ExportCommand) exportTodoTxt(args []string, batchSize int) error {
+	if len(args) < 1 {
+		return fmt.Errorf("file required\nUsage: memo export --format todotxt [--batch-size <n>] <todo.txt|->")
+	}
+
+	var count int
+	err := streamOutput(args[0], func(w io.Writer) error {
+		todo := exchange.NewTodoTxtWriter(w)
+		err := c.ctx.Storage.EachNote(batchSize, todo.Write)
+		count = todo.Count()
+		return err
+	})
+	if err == nil && args[0] != "-" {
+		fmt.Printf("Exported %d task(s) to %s\n", count, args[0])
+	}
+	return err
+}
+
+// exportArchive writes every note as a Markdown file into a zip or tar.gz
+// archive, one batch of notes at a time. Encrypted notes are left out.
+func (c *ExportCommand) exportArchive(args []string, format string, batchSize int) error {
+	if len(args) < 1 {
+		return fmt.Errorf("file required\nUsage: memo export --all [--format md] [--batch-size <n>] <notes.zip|notes.tar.gz|->")
+	}
+	if format != "" && format != "md" && format != "markdown" {
+		return fmt.Errorf("--all exports Markdown files only")
+	}
+	kind := backup.TarGz
+	if strings.EqualFold(filepath.Ext(args[0]), ".zip") {
+		kind = backup.Zip
+	}
+
+	var exported, skipped int
+	err := streamOutput(args[0], func(w io.Writer) error {
+		archive := backup.NewWriter(w, kind)
+		err := c.ctx.Storage.EachNote(batchSize, func(notes []*note.Note) error {
+			for _, n := range notes {
+				if n.Locked() {
+					skipped++
+					continue
+				}
+				text, err := exchange.ToMarkdown(n)
+				if err != nil {
+					return err
+				}
+				if err := archive.Add(path.Join(c.ctx.Storage.NotebookOf(n), n.ID()+".md"), []byte(text), n.Metadata.Modified); err != nil {
+					return err
+				}
+				exported++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return archive.Close()
+	})
+	if err != nil || args[0] == "-" {
+		return err
+	}
+	fmt.Printf("Exported %d note(s) to %s\n", exported, args[0])
+	if skipped > 0 {
+		fmt.Printf("Left out %d encrypted note(s)\n", skipped)
+	}
+	return nil
+}
+
+// streamOutput calls write with a writer for path, or for stdout when path
+// is "-". Files are written under a temporary name first, so a failed
+// export leaves nothing behind.
+func streamOutput(path string, write func(io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeOutput writes data to path, or to stdout when path is "-". The
+// confirmation message is only printed for files.
+func writeOutput(path string, data []byte, message string) error {
+	if path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	fmt.Print(message)
+	return nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"memo/internal/note"
+	"memo/internal/storage"
+	"memo/internal/ui"
+)
+
+// findResults is how many matching notes memo find lists at a time
+const findResults = 10
+
+type FindCommand struct {
+	ctx *CommandContext
+}
+
+func NewFindCommand(ctx *CommandContext) *FindCommand {
+	return &FindCommand{ctx: ctx}
+}
+
+// Execute lets the user search notes interactively: the matches for what
+// has been typed are listed as the query changes, and the one picked is
+// opened. Every note is read once into an in-memory index up front, so the
+// list updates without going back to the disk. Without a terminal it lists
+// the matches for the query given.
+func (c *FindCommand) Execute(args []string) error {
+	p, err := parseArgs(args)
+	if err != nil {
+		return fmt.Errorf("%v\nUsage: memo find [<query>]", err)
+	}
+	query := strings.Join(p.Positional, " ")
+
+	notes, err := c.ctx.Storage.GetAllNotes()
+	if err != nil {
+		return err
+	}
+	finder := storage.NewFinder(notes)
+
+	if !ui.IsInteractive() {
+		matches := finder.Match(query, findResults)
+		c.ctx.SetCurrentListing(matches)
+		for i, n := range matches {
+			fmt.Printf("%2d. %s\n", i+1, findLine(n))
+		}
+		return nil
+	}
+
+	var matches []*note.Note
+	picked, err := ui.PromptLive("Find: ", query, func(query string) []string {
+		matches = finder.Match(query, findResults)
+		lines := make([]string, len(matches))
+		for i, n := range matches {
+			lines[i] = findLine(n)
+		}
+		return lines
+	})
+	if err != nil || picked < 0 {
+		return err
+	}
+	return NewReadCommand(c.ctx).Execute([]string{matches[picked].ID()})
+}
+
+// findLine describes a matching note on one line
+func findLine(n *note.Note) string {
+	line := ui.ListTitle(n) + "  (" + n.ID()
+	if len(n.Metadata.Tags) > 0 {
+		line += ", #" + strings.Join(n.Metadata.Tags, " #")
+	}
+	return line + ")"
+}
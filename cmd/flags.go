@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parsedArgs separates command flags from positional arguments so flags may
+// appear anywhere on the command line
+type parsedArgs struct {
+	Positional []string
+	values     map[string][]string
+	bools      map[string]bool
+}
+
+// parseArgs parses args against the flags a command accepts. A name ending
+// in "=" declares a flag taking a value, given as "--flag value" or
+// "--flag=value"; the others are boolean. Any other flag is an error, so
+// typos do not go unnoticed. An argument with whitespace before any "=",
+// such as "- [ ] buy milk", is never a flag, and a lone "--" ends flag
+// parsing.
+func parseArgs(args []string, flags ...string) (*parsedArgs, error) {
+	return parseFlags(args, false, flags)
+}
+
+// parseArgsWithText is parseArgs for commands whose arguments end in free
+// text, such as the text `memo append` adds: flags end at the first
+// positional argument, and everything from there on is taken as it is,
+// even when it starts with a dash.
+func parseArgsWithText(args []string, flags ...string) (*parsedArgs, error) {
+	return parseFlags(args, true, flags)
+}
+
+func parseFlags(args []string, textAfterPositional bool, flags []string) (*parsedArgs, error) {
+	p := &parsedArgs{
+		values: make(map[string][]string),
+		bools:  make(map[string]bool),
+	}
+
+	takesValue := make(map[string]bool)
+	known := make(map[string]bool)
+	for _, f := range flags {
+		name, value := strings.CutSuffix(f, "=")
+		takesValue[name] = value
+		known[name] = true
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			p.Positional = append(p.Positional, args[i+1:]...)
+			break
+		}
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !strings.HasPrefix(arg, "-") || arg == "-" || strings.ContainsAny(name, " \t\n") {
+			p.Positional = append(p.Positional, arg)
+			if textAfterPositional {
+				p.Positional = append(p.Positional, args[i+1:]...)
+				break
+			}
+			continue
+		}
+
+		if !hasValue && !strings.HasPrefix(name, "--") && len(name) > 2 && takesValue[name[:2]] {
+			// Short flag with an attached value, e.g. -C3
+			name, value, hasValue = name[:2], name[2:], true
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown flag %s", name)
+		}
+		if !takesValue[name] {
+			if hasValue {
+				return nil, fmt.Errorf("flag %s does not take a value", name)
+			}
+			p.bools[name] = true
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("flag %s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		p.values[name] = append(p.values[name], value)
+	}
+
+	return p, nil
+}
+
+// Bool reports whether any of the given boolean flags was present
+func (p *parsedArgs) Bool(names ...string) bool {
+	for _, name := range names {
+		if p.bools[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the last value given for any of the named flags
+func (p *parsedArgs) Value(names ...string) string {
+	var value string
+	for _, name := range names {
+		if vals := p.values[name]; len(vals) > 0 {
+			value = vals[len(vals)-1]
+		}
+	}
+	return value
+}
+
+// Values returns every value given for the named flag
+func (p *parsedArgs) Values(name string) []string {
+	return p.values[name]
+}
+
+// Int returns the value of an integer flag, or def when it is absent
+func (p *parsedArgs) Int(name string, def int) (int, error) {
+	value := p.Value(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %s", name, value)
+	}
+	return n, nil
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	flags := []string{"--tag=", "-C=", "--all", "-a"}
+	tests := []struct {
+		name       string
+		args       []string
+		positional []string
+		tags       []string
+		all        bool
+		context    string
+		wantErr    bool
+	}{
+		{name: "flags anywhere", args: []string{"one", "--all", "two"}, positional: []string{"one", "two"}, all: true},
+		{name: "short alias", args: []string{"-a"}, all: true},
+		{name: "separate value", args: []string{"--tag", "work", "x"}, positional: []string{"x"}, tags: []string{"work"}},
+		{name: "attached value", args: []string{"--tag=work", "--tag=home"}, tags: []string{"work", "home"}},
+		{name: "value with spaces", args: []string{"--tag=to do"}, tags: []string{"to do"}},
+		{name: "short attached value", args: []string{"-C3"}, context: "3"},
+		{name: "lone dash", args: []string{"-"}, positional: []string{"-"}},
+		{name: "double dash", args: []string{"--", "--all", "-x"}, positional: []string{"--all", "-x"}},
+		{name: "text with spaces", args: []string{"- [ ] buy milk"}, positional: []string{"- [ ] buy milk"}},
+		{name: "dash word with spaces", args: []string{"-5 degrees log"}, positional: []string{"-5 degrees log"}},
+		{name: "unknown flag", args: []string{"--alll"}, wantErr: true},
+		{name: "unknown short flag", args: []string{"-x"}, wantErr: true},
+		{name: "missing value", args: []string{"--tag"}, wantErr: true},
+		{name: "bool with value", args: []string{"--all=yes"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseArgs(tt.args, flags...)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseArgs(%q) succeeded, want an error", tt.args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseArgs(%q) = %v", tt.args, err)
+			}
+			if !slices.Equal(p.Positional, tt.positional) {
+				t.Errorf("positional = %q, want %q", p.Positional, tt.positional)
+			}
+			if got := p.Values("--tag"); !slices.Equal(got, tt.tags) {
+				t.Errorf("--tag = %q, want %q", got, tt.tags)
+			}
+			if got := p.Bool("--all", "-a"); got != tt.all {
+				t.Errorf("--all = %v, want %v", got, tt.all)
+			}
+			if got := p.Value("-C"); got != tt.context {
+				t.Errorf("-C = %q, want %q", got, tt.context)
+			}
+		})
+	}
+}
+
+func TestParseArgsWithText(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		positional []string
+		dryRun     bool
+	}{
+		{name: "flags before the text", args: []string{"--dry-run", "note", "-x", "--dry-run"}, positional: []string{"note", "-x", "--dry-run"}, dryRun: true},
+		{name: "unquoted list item", args: []string{"note", "-", "[", "]", "milk"}, positional: []string{"note", "-", "[", "]", "milk"}},
+		{name: "text with spaces first", args: []string{"- [ ] call", "--dry-run"}, positional: []string{"- [ ] call", "--dry-run"}},
+		{name: "double dash", args: []string{"--", "-5", "degrees"}, positional: []string{"-5", "degrees"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := parseArgsWithText(tt.args, "--dry-run")
+			if err != nil {
+				t.Fatalf("parseArgsWithText(%q) = %v", tt.args, err)
+			}
+			if !slices.Equal(p.Positional, tt.positional) {
+				t.Errorf("positional = %q, want %q", p.Positional, tt.positional)
+			}
+			if got := p.Bool("--dry-run"); got != tt.dryRun {
+				t.Errorf("--dry-run = %v, want %v", got, tt.dryRun)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"memo/internal/graph"
+)
+
+const graphUsage = "Usage: memo graph [--format dot|graphml] [--cluster tag|notebook] [--no-tags] [<file>|-]"
+
+// GraphCommand exports the graph of notes, their [[wikilinks]] and tags
+// for visualization
+type GraphCommand struct {
+	ctx *CommandContext
+}
+
+func NewGraphCommand(ctx *CommandContext) *GraphCommand {
+	return &GraphCommand{ctx: ctx}
+}
+
+func (c *GraphCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--format=", "--cluster=", "--no-tags")
+	if err != nil {
+		return fmt.Errorf("%v\n%s", err, graphUsage)
+	}
+	path := "-"
+	if len(p.Positional) > 0 {
+		path = p.Positional[0]
+	}
+
+	format := p.Value("--format")
+	if format == "" {
+		format = "dot"
+		if strings.EqualFold(filepath.Ext(path), ".graphml") {
+			format = "graphml"
+		}
+	}
+	if format != "dot" && format != "graphml" {
+		return fmt.Errorf("unknown graph format '%s' (use dot or graphml)", format)
+	}
+
+	notes, err := c.ctx.Storage.GetAllNotes()
+	if err != nil {
+		return fmt.Errorf("error loading notes: %w", err)
+	}
+	g, err := graph.Build(notes, graph.Options{
+		Cluster:    p.Value("--cluster"),
+		Tags:       !p.Bool("--no-tags"),
+		NotebookOf: c.ctx.Storage.NotebookOf,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = streamOutput(path, func(w io.Writer) error {
+		if format == "graphml" {
+			return g.WriteGraphML(w)
+		}
+		return g.WriteDOT(w)
+	})
+	if err == nil && path != "-" {
+		fmt.Printf("Exported a graph of %d note(s) and tag(s) with %d edge(s) to %s\n", len(g.Nodes), len(g.Edges), path)
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+
+	"memo/internal/ui"
+)
+
+type GrepCommand struct {
+	ctx *CommandContext
+}
+
+func NewGrepCommand(ctx *CommandContext) *GrepCommand {
+	return &GrepCommand{ctx: ctx}
+}
+
+func (c *GrepCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "-A=", "-B=", "-C=", "-F", "-i")
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("pattern required\nUsage: memo grep [-i] [-F] [-A n] [-B n] [-C n] <pattern>")
+	}
+
+	context, err := p.Int("-C", 0)
+	if err != nil {
+		return err
+	}
+	before, err := p.Int("-B", context)
+	if err != nil {
+		return err
+	}
+	after, err := p.Int("-A", context)
+	if err != nil {
+		return err
+	}
+
+	expr := p.Positional[0]
+	if p.Bool("-F") {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if p.Bool("-i") {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	results, err := c.ctx.Storage.GrepNotes(pattern)
+	if err != nil {
+		return fmt.Errorf("error searching notes: %w", err)
+	}
+
+	ui.DisplayGrepResults(results, pattern, before, after)
+	return nil
+}
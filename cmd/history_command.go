@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"memo/internal/audit"
+	"memo/internal/storage"
+	"memo/internal/ui"
+)
+
+const historyUsage = "Usage: memo history (--global | <note-id|number|title>) [--limit <n>] [--revert <number>]"
+
+// defaultHistoryLimit is how many changes memo history shows without --limit
+const defaultHistoryLimit = 20
+
+type HistoryCommand struct {
+	ctx *CommandContext
+}
+
+func NewHistoryCommand(ctx *CommandContext) *HistoryCommand {
+	return &HistoryCommand{ctx: ctx}
+}
+
+// Execute lists the changes recorded in the audit log, of every note with
+// --global or else of one, newest first. A change picked by its number,
+// with --revert or when asked afterwards, is undone.
+func (c *HistoryCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--limit=", "--revert=", "--global")
+	if err != nil {
+		return fmt.Errorf("%v\n%s", err, historyUsage)
+	}
+	global := p.Bool("--global")
+	if global == (len(p.Positional) > 0) {
+		return fmt.Errorf("--global or a note required\n%s", historyUsage)
+	}
+
+	log, err := c.ctx.Storage.AuditLog()
+	if err != nil {
+		return err
+	}
+
+	// indexes holds the positions in log of the listed changes, oldest first
+	var indexes []int
+	scope := "--global"
+	if global {
+		for i := range log {
+			indexes = append(indexes, i)
+		}
+	} else {
+		// Deleted notes cannot be resolved, but their ID still finds them
+		scope = p.Positional[0]
+		id := scope
+		if noteID, err := c.ctx.ResolveNoteID(id); err == nil {
+			id = noteID
+		}
+		indexes = entryIndexes(log, id)
+	}
+
+	limit := defaultHistoryLimit
+	if value := p.Value("--limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			return fmt.Errorf("--limit must be a positive number\n%s", historyUsage)
+		}
+	}
+	if len(indexes) > limit {
+		indexes = indexes[len(indexes)-limit:]
+	}
+
+	number := 0
+	if value := p.Value("--revert"); value != "" {
+		if number, err = strconv.Atoi(value); err != nil || number < 1 || number > len(indexes) {
+			return fmt.Errorf("invalid change number '%s' (use 1-%d)", value, len(indexes))
+		}
+	} else {
+		entries := make([]audit.Entry, len(indexes))
+		for i, index := range indexes {
+			entries[i] = log[index]
+		}
+		ui.DisplayHistory(entries)
+		if len(entries) == 0 || !ui.IsInteractive() || c.ctx.Storage.CheckWritable() != nil {
+			return nil
+		}
+		answer := ui.PromptForInput("\nNumber of a change to revert (Enter to quit): ")
+		if answer == "" {
+			return nil
+		}
+		if number, err = strconv.Atoi(answer); err != nil || number < 1 || number > len(entries) {
+			return fmt.Errorf("invalid change number '%s' (use 1-%d)", answer, len(entries))
+		}
+	}
+
+	return c.revert(log, indexes[len(indexes)-number], scope)
+}
+
+// revert undoes entry i of the log after asking for confirmation
+func (c *HistoryCommand) revert(log []audit.Entry, i int, scope string) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	e := log[i]
+	id := storage.CurrentID(log, i)
+	if later := storage.LaterChanges(log, i); later > 0 && (e.Op == audit.OpEdit || e.Op == audit.OpTag) {
+		fmt.Print(ui.Tf("The note was changed %d more time(s) since; those changes are undone too.\n", later))
+	}
+	if !c.ctx.DryRun && !ui.ConfirmAction(ui.Tf("Revert the %s of %s from %s? (y/N): ", e.Op, id, e.Time.Local().Format("2006-01-02 15:04"))) {
+		fmt.Println("Revert cancelled.")
+		return nil
+	}
+
+	release, err := c.ctx.Storage.AcquireLock(id)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	noteID, err := c.ctx.Storage.RevertChange(log, i)
+	if err != nil {
+		return err
+	}
+	if c.ctx.DryRun {
+		return nil
+	}
+	fmt.Printf("Reverted the %s of %s.\n", e.Op, noteID)
+	if scope != "--global" {
+		scope = noteID
+	}
+	fmt.Printf("Undo the revert with: memo history %s --revert 1\n", scope)
+	return nil
+}
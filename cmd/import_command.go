@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"memo/internal/exchange"
+	"memo/internal/note"
+	"memo/internal/ui"
+)
+
+type ImportCommand struct {
+	ctx *CommandContext
+}
+
+func NewImportCommand(ctx *CommandContext) *ImportCommand {
+	return &ImportCommand{ctx: ctx}
+}
+
+func (c *ImportCommand) Execute(args []string) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	p, err := parseArgs(args, "--format=", "--per-highlight")
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("file required\nUsage: memo import [--format md|csv|json|kindle|todotxt] <file|->")
+	}
+
+	path := p.Positional[0]
+	format := p.Value("--format")
+	if format == "" {
+		// Guess from the extension; stdin and anything else is Markdown
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+		if name := strings.ToLower(filepath.Base(path)); name == "todo.txt" || name == "done.txt" {
+			format = "todotxt"
+		}
+	}
+
+	data, err := readInput(path)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "csv", "json":
+		return c.importTable(path, format, data)
+	case "kindle":
+		return c.createNotes(path, exchange.KindleNotes(exchange.ParseKindle(string(data)), p.Bool("--per-highlight")))
+	case "todotxt":
+		notes, err := exchange.FromTodoTxt(string(data))
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %w", path, err)
+		}
+		return c.createNotes(path, notes)
+	case "md", "markdown", "txt", "":
+	default:
+		if p.Value("--format") != "" {
+			return fmt.Errorf("unknown import format '%s' (use md, csv, json, kindle or todotxt)", format)
+		}
+	}
+
+	fallbackTitle := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if path == "-" {
+		fallbackTitle = "Imported note"
+	}
+
+	n, err := exchange.FromMarkdown(string(data), fallbackTitle)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", path, err)
+	}
+
+	noteID, err := c.ctx.Storage.CreateNote(n)
+	if err != nil {
+		return fmt.Errorf("error importing note: %w", err)
+	}
+	if c.ctx.DryRun {
+		return nil
+	}
+
+	fmt.Printf("Note imported successfully: %s (%s)\n", noteID, n.Metadata.Title)
+	return nil
+}
+
+// importTable creates one note per CSV row or JSON object. The whole file is
+// parsed before anything is written, so a bad row imports nothing.
+func (c *ImportCommand) importTable(path, format string, data []byte) error {
+	var notes []*note.Note
+	var err error
+	if format == "csv" {
+		notes, err = exchange.FromCSV(bytes.NewReader(data))
+	} else {
+		notes, err = exchange.FromJSON(data)
+	}
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return c.createNotes(path, notes)
+}
+
+// createNotes saves notes parsed from path. If one cannot be saved, none
+// are imported.
+func (c *ImportCommand) createNotes(path string, notes []*note.Note) error {
+	if len(notes) == 0 {
+		return fmt.Errorf("no notes found in %s", path)
+	}
+
+	tx, err := c.ctx.BeginTransaction(c.ctx.DryRun)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, n := range notes {
+		if _, err := c.ctx.Storage.CreateNote(n); err != nil {
+			return fmt.Errorf("error importing '%s' (nothing imported): %w", n.Metadata.Title, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if c.ctx.DryRun {
+		fmt.Printf("Would import %d notes from %s\n", len(notes), path)
+		return nil
+	}
+	fmt.Printf("Imported %d notes from %s\n", len(notes), path)
+	return nil
+}
+
+// readInput reads path, or stdin when path is "-"
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(ui.Stdin)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return data, nil
+}
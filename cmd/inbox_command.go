@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+	"memo/internal/ui"
+)
+
+// inboxID is the ID of the quick capture note; it is created on first use
+// and can be read and edited like any other note
+const inboxID = "inbox"
+
+type InboxCommand struct {
+	ctx *CommandContext
+}
+
+func NewInboxCommand(ctx *CommandContext) *InboxCommand {
+	return &InboxCommand{ctx: ctx}
+}
+
+const inboxUsage = `Usage:
+  memo inbox
+  memo inbox add <text|->
+  memo inbox clear`
+
+func (c *InboxCommand) Execute(args []string) error {
+	if len(args) == 0 {
+		return c.show()
+	}
+
+	switch args[0] {
+	case "add":
+		return c.add(args[1:])
+	case "clear":
+		return c.clear(args[1:])
+	default:
+		return fmt.Errorf("unknown subcommand '%s'\n%s", args[0], inboxUsage)
+	}
+}
+
+func (c *InboxCommand) show() error {
+	if !c.ctx.Storage.NoteExists(inboxID) {
+		ui.DisplayInbox(nil)
+		return nil
+	}
+	n, err := c.ctx.Storage.FindNoteByID(inboxID)
+	if err != nil {
+		return err
+	}
+	if err := c.ctx.UnlockNote(n); err != nil {
+		return err
+	}
+	ui.DisplayInbox(n)
+	c.ctx.RecordAccess(inboxID, "read")
+	return nil
+}
+
+// add appends each line of text as a timestamped list item
+func (c *InboxCommand) add(args []string) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	text := strings.Join(args, " ")
+	if text == "" || text == "-" {
+		data, err := readInput("-")
+		if err != nil {
+			return fmt.Errorf("error reading stdin: %w", err)
+		}
+		text = string(data)
+	}
+
+	stamp := time.Now().Format("2006-01-02 15:04")
+	var items []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, fmt.Sprintf("- [%s] %s", stamp, line))
+		}
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("nothing to add\n%s", inboxUsage)
+	}
+
+	n, err := c.load()
+	if err != nil {
+		return err
+	}
+	content := strings.TrimRight(n.Content, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	n.UpdateContent(content + strings.Join(items, "\n"))
+
+	if err := c.ctx.Storage.SaveNoteWithID(n, inboxID); err != nil {
+		return fmt.Errorf("error saving inbox: %w", err)
+	}
+	fmt.Printf("Added %d item(s) to the inbox\n", len(items))
+	c.ctx.RecordAccess(inboxID, "edited")
+	return nil
+}
+
+func (c *InboxCommand) clear(args []string) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+	p, err := parseArgs(args, "--yes", "-y")
+	if err != nil {
+		return err
+	}
+	if !c.ctx.Storage.NoteExists(inboxID) {
+		fmt.Println("The inbox is already empty.")
+		return nil
+	}
+	if !p.Bool("--yes", "-y") && !ui.ConfirmAction(ui.T("Remove all items from the inbox? (y/N): ")) {
+		fmt.Println("Inbox left unchanged.")
+		return nil
+	}
+
+	n, err := c.load()
+	if err != nil {
+		return err
+	}
+	n.UpdateContent("")
+	if err := c.ctx.Storage.SaveNote(n); err != nil {
+		return fmt.Errorf("error saving inbox: %w", err)
+	}
+	fmt.Println("Inbox cleared.")
+	return nil
+}
+
+// load returns the inbox note, or a new empty one when it does not exist yet
+func (c *InboxCommand) load() (*note.Note, error) {
+	if !c.ctx.Storage.NoteExists(inboxID) {
+		return note.New("Inbox", "", []string{"inbox"}), nil
+	}
+	n, err := c.ctx.Storage.FindNoteByID(inboxID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.ctx.UnlockNote(n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
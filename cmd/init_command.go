@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"memo/internal/config"
+)
+
+// InitCommand creates a project store: a .memo-notes directory that memo
+// uses instead of the global store when run in or below its parent
+type InitCommand struct {
+	ctx *CommandContext
+}
+
+func NewInitCommand(ctx *CommandContext) *InitCommand {
+	return &InitCommand{ctx: ctx}
+}
+
+func (c *InitCommand) Execute(args []string) error {
+	p, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) > 1 {
+		return fmt.Errorf("too many arguments\nUsage: memo init [<dir>]")
+	}
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	dir := "."
+	if len(p.Positional) == 1 {
+		dir = p.Positional[0]
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	store := filepath.Join(dir, config.LegacyNotesDir)
+	if config.IsProjectStore(store) {
+		return fmt.Errorf("%s is already a project store", store)
+	}
+
+	_, statErr := os.Stat(store)
+	existing := statErr == nil
+	if c.ctx.DryRun {
+		fmt.Printf("Would create project store %s\n", store)
+		return nil
+	}
+
+	fs := newStorage(c.ctx.Config, c.ctx.Profile, store)
+	if err := fs.EnsureNotesDir(); err != nil {
+		return fmt.Errorf("error creating %s: %w", store, err)
+	}
+	if err := config.MarkProjectStore(store); err != nil {
+		return fmt.Errorf("error creating project store: %w", err)
+	}
+
+	if existing {
+		// A store older versions left here holds notes of this directory
+		// already, which is what a project store is for
+		fmt.Printf("Turned %s into a project store\n", store)
+	} else {
+		fmt.Printf("Created project store %s\n", store)
+	}
+	fmt.Printf("memo now uses it in %s and below; run with --global for your own notes\n", dir)
+	return nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"memo/internal/keychain"
+	"memo/internal/ui"
+)
+
+type KeyCommand struct {
+	ctx *CommandContext
+}
+
+func NewKeyCommand(ctx *CommandContext) *KeyCommand {
+	return &KeyCommand{ctx: ctx}
+}
+
+const keyUsage = `Usage:
+  memo key store
+  memo key forget
+  memo key status`
+
+func (c *KeyCommand) Execute(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("subcommand required\n%s", keyUsage)
+	}
+
+	account := c.ctx.ProfileName
+	switch args[0] {
+	case "store":
+		key := ui.PromptForSecret("Enter encryption key: ")
+		if key == "" {
+			return fmt.Errorf("encryption key must not be empty")
+		}
+		if ui.PromptForSecret("Confirm encryption key: ") != key {
+			return fmt.Errorf("keys do not match")
+		}
+		if err := keychain.Set(account, key); err != nil {
+			return err
+		}
+		fmt.Printf("Encryption key for profile '%s' stored in the keychain\n", account)
+	case "forget":
+		if err := keychain.Delete(account); err != nil {
+			return err
+		}
+		fmt.Printf("Encryption key for profile '%s' removed from the keychain\n", account)
+	case "status":
+		_, err := keychain.Get(account)
+		switch {
+		case err == nil:
+			fmt.Printf("An encryption key for profile '%s' is stored in the keychain\n", account)
+		case errors.Is(err, keychain.ErrNotFound):
+			fmt.Printf("No encryption key for profile '%s' in the keychain\n", account)
+		default:
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown subcommand '%s'\n%s", args[0], keyUsage)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"memo/internal/history"
+)
+
+type LastCommand struct {
+	ctx *CommandContext
+}
+
+func NewLastCommand(ctx *CommandContext) *LastCommand {
+	return &LastCommand{ctx: ctx}
+}
+
+func (c *LastCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--edit")
+	if err != nil {
+		return err
+	}
+
+	entries, err := history.Load(c.ctx.Storage.StorePath(history.FileName))
+	if err != nil {
+		return err
+	}
+
+	// Skip notes that have been deleted since they were last used
+	for _, entry := range history.Recent(entries, 0) {
+		if _, err := c.ctx.Storage.FindNoteByID(entry.NoteID); err != nil {
+			continue
+		}
+		if p.Bool("--edit") {
+			return NewEditCommand(c.ctx).Execute([]string{entry.NoteID})
+		}
+		return NewReadCommand(c.ctx).Execute([]string{entry.NoteID})
+	}
+
+	return fmt.Errorf("no recently used notes")
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"memo/internal/config"
+	"memo/internal/lint"
+	"memo/internal/note"
+	"memo/internal/storage"
+	"memo/internal/ui"
+)
+
+// LintCommand checks notes against the configured lint rules
+type LintCommand struct {
+	ctx *CommandContext
+}
+
+func NewLintCommand(ctx *CommandContext) *LintCommand {
+	return &LintCommand{ctx: ctx}
+}
+
+func (c *LintCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--strict")
+	if err != nil {
+		return fmt.Errorf("%v\nUsage: memo lint [--strict] [<note>...]", err)
+	}
+	linter, err := newLinter(c.ctx.Config, p.Bool("--strict"))
+	if err != nil {
+		return err
+	}
+
+	var notes []*note.Note
+	if len(p.Positional) == 0 {
+		if notes, err = c.ctx.Storage.GetAllNotes(); err != nil {
+			return fmt.Errorf("error loading notes: %w", err)
+		}
+	}
+	for _, identifier := range p.Positional {
+		noteID, err := c.ctx.ResolveNoteID(identifier)
+		if err != nil {
+			return err
+		}
+		n, err := c.ctx.Storage.FindNoteByID(noteID)
+		if err != nil {
+			return err
+		}
+		notes = append(notes, n)
+	}
+
+	exists := linkTargets(c.ctx.Storage)
+	results := make(map[*note.Note][]lint.Issue)
+	errors := 0
+	for _, n := range notes {
+		issues := linter.Check(n, exists)
+		if len(issues) > 0 {
+			results[n] = issues
+		}
+		errors += len(lint.Errors(issues))
+	}
+	ui.DisplayLintResults(notes, results)
+
+	if errors > 0 {
+		return fmt.Errorf("%d lint error(s)", errors)
+	}
+	return nil
+}
+
+// newLinter sets up the lint rules of the configuration
+func newLinter(cfg *config.Config, strict bool) (*lint.Linter, error) {
+	var rules map[string]string
+	if cfg.Lint != nil {
+		rules = cfg.Lint.Rules
+		strict = strict || cfg.Lint.Strict
+	}
+	return lint.New(rules, strict)
+}
+
+// lintBeforeSave warns about the lint issues of a note being saved and
+// refuses to save it when any of them is an error
+func lintBeforeSave(fs *storage.FileStorage, linter *lint.Linter) storage.LintFunc {
+	exists := linkTargets(fs)
+	return func(n *note.Note) error {
+		issues := linter.Check(n, exists)
+		for _, issue := range issues {
+			if issue.Severity == lint.Warn {
+				slog.Warn(fmt.Sprintf("%s: %s (%s)", n.Metadata.Title, issue.Message, issue.Rule))
+			}
+		}
+		errs := lint.Errors(issues)
+		if len(errs) == 0 {
+			return nil
+		}
+		messages := make([]string, len(errs))
+		for i, issue := range errs {
+			messages[i] = fmt.Sprintf("%s (%s)", issue.Message, issue.Rule)
+		}
+		return fmt.Errorf("note not saved: %s", strings.Join(messages, "; "))
+	}
+}
+
+// linkTargets returns a check whether a [[wikilink]] target names a note
+// by ID, former ID or title. Titles are only loaded when an ID does not
+// match.
+func linkTargets(fs *storage.FileStorage) func(target string) bool {
+	var titles map[string]bool
+	return func(target string) bool {
+		if fs.NoteExists(target) {
+			return true
+		}
+		if _, ok := fs.ResolveAlias(target); ok {
+			return true
+		}
+		if titles == nil {
+			titles = make(map[string]bool)
+			notes, _ := fs.GetAllNotes()
+			for _, n := range notes {
+				titles[strings.ToLower(n.Metadata.Title)] = true
+			}
+		}
+		return titles[strings.ToLower(target)]
+	}
+}
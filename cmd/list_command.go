@@ -2,8 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
 
+	"memo/internal/history"
+	"memo/internal/language"
 	"memo/internal/note"
+	"memo/internal/storage"
 	"memo/internal/ui"
 )
 
@@ -16,38 +25,254 @@ func NewListCommand(ctx *CommandContext) *ListCommand {
 }
 
 func (c *ListCommand) Execute(args []string) error {
-	var tagFilter string
-	if len(args) >= 2 && args[0] == "--tag" {
-		tagFilter = args[1]
-	} else if len(args) >= 1 && args[0] == "--tag" {
-		return fmt.Errorf("tag value required\nUsage: memo list --tag <tag>")
+	p, err := parseArgs(args, "--tag=", "--any-tag=", "--not-tag=", "--where=", "--columns=", "--notebook=", "--format=", "--author=", "--template=", "--near=", "--radius=", "--lang=")
+	if err != nil {
+		return fmt.Errorf("%v\nUsage: memo list [--tag <tag>]... [--any-tag <a,b>] [--not-tag <a,b>] [--notebook <name>] [--author <name>] [--near <place> [--radius <km>]] [--lang <code>] [--where <field>=<value>] [--columns <list>] [--format table|compact|oneline] [--template <template>]", err)
+	}
+	radius := defaultNearRadiusKm
+	if value := p.Value("--radius"); value != "" {
+		if radius, err = strconv.ParseFloat(value, 64); err != nil || radius <= 0 {
+			return fmt.Errorf("--radius must be a positive number of kilometers, not '%s'", value)
+		}
+	}
+	allTags := p.Values("--tag")
+	var anyTags, notTags []string
+	for _, value := range p.Values("--any-tag") {
+		anyTags = append(anyTags, splitTags(value)...)
+	}
+	for _, value := range p.Values("--not-tag") {
+		notTags = append(notTags, splitTags(value)...)
+	}
+
+	format := p.Value("--format")
+	if format == "" && p.Value("--columns") != "" {
+		format = "table"
+	}
+	if format != "" && format != "table" && format != "compact" && format != "oneline" {
+		return fmt.Errorf("unknown list format '%s' (use table, compact or oneline)", format)
+	}
+	var tmpl *template.Template
+	if text := p.Value("--template"); text != "" {
+		if tmpl, err = ui.ParseNoteTemplate(text); err != nil {
+			return err
+		}
+	}
+	// One-line and template output is meant for pipes, so it carries
+	// nothing but notes
+	printf := fmt.Printf
+	if format == "oneline" || tmpl != nil {
+		printf = func(string, ...any) (int, error) { return 0, nil }
 	}
 
 	var notes []*note.Note
-	var err error
 
-	if tagFilter != "" {
-		notes, err = c.ctx.Storage.FilterNotesByTag(tagFilter)
+	if len(allTags) == 1 && len(anyTags) == 0 && len(notTags) == 0 {
+		notes, err = c.ctx.Storage.FilterNotesByTag(allTags[0])
 		if err != nil {
 			return fmt.Errorf("error filtering notes by tag: %w", err)
 		}
-		fmt.Printf("Notes with tag '%s':\n", tagFilter)
+		printf("Notes with tag '%s':\n", allTags[0])
+	} else if len(allTags) > 0 || len(anyTags) > 0 || len(notTags) > 0 {
+		notes, err = c.ctx.Storage.GetAllNotes()
+		if err != nil {
+			return fmt.Errorf("error listing notes: %w", err)
+		}
+		notes = filterByTags(notes, allTags, anyTags, notTags)
+		printf("Notes %s:\n", describeTagFilter(allTags, anyTags, notTags))
 	} else {
 		notes, err = c.ctx.Storage.GetAllNotes()
 		if err != nil {
 			return fmt.Errorf("error listing notes: %w", err)
 		}
-		fmt.Println("All notes:")
+		if len(p.Values("--where")) == 0 && p.Value("--notebook") == "" && p.Value("--author") == "" && p.Value("--near") == "" && p.Value("--lang") == "" {
+			printf("All notes:\n")
+		}
+	}
+
+	if notebook := p.Value("--notebook"); notebook != "" {
+		notes = filterByNotebook(c.ctx.Storage, notes, notebook)
+		printf("Notes in notebook '%s':\n", notebook)
+	}
+	if author := p.Value("--author"); author != "" {
+		notes = filterByField(notes, "author", author)
+		printf("Notes by '%s':\n", author)
+	}
+	if near := p.Value("--near"); near != "" {
+		notes = filterNear(notes, c.ctx.ResolveLocation(near), radius)
+		printf("Notes near '%s':\n", near)
+	}
+	if lang := p.Value("--lang"); lang != "" {
+		if !language.Known(lang) {
+			return fmt.Errorf("unknown language '%s' (use %s)", lang, strings.Join(language.Codes(), ", "))
+		}
+		notes = filterByLanguage(notes, lang)
+		printf("Notes in '%s':\n", strings.ToLower(lang))
+	}
+
+	wheres := p.Values("--where")
+	for _, where := range wheres {
+		field, value, _ := strings.Cut(where, "=")
+		field, value = strings.TrimSpace(field), strings.TrimSpace(value)
+		if field == "" {
+			return fmt.Errorf("field name required\nUsage: memo list --where <field>=<value>")
+		}
+		notes = filterByField(notes, field, value)
+	}
+	if len(wheres) > 0 {
+		printf("Notes where %s:\n", strings.Join(wheres, " and "))
 	}
 
 	if len(notes) == 0 {
-		fmt.Println("No notes found.")
+		printf("No notes found.\n")
 		return nil
 	}
 
+	if expired := countExpired(notes, time.Now()); expired > 0 {
+		slog.Warn(fmt.Sprintf("%d note(s) have expired; run 'memo purge-expired' to delete them", expired))
+	}
+
 	// Update current listing for number-based access
 	c.ctx.SetCurrentListing(notes)
-	ui.DisplayNotesWithPagination(notes)
-	
+
+	if tmpl != nil {
+		return ui.DisplayNotesTemplate(notes, tmpl, c.ctx.Storage.NotebookOf)
+	}
+
+	spec := p.Value("--columns")
+	if spec == "" && format != "compact" {
+		spec = defaultListColumns[format]
+	}
+	var columns []ui.Column
+	if spec != "" {
+		if columns, err = c.columns(spec); err != nil {
+			return err
+		}
+	}
+
+	switch format {
+	case "table":
+		ui.DisplayNotesTable(notes, columns)
+	case "compact":
+		ui.DisplayNotesCompact(notes)
+	case "oneline":
+		ui.DisplayNotesOneline(notes, columns)
+	default:
+		ui.DisplayNotesWithPagination(notes)
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// defaultNearRadiusKm is how close to a place `list --near` looks by default
+const defaultNearRadiusKm = 1.0
+
+// defaultListColumns are shown by the list formats when --columns is not given
+var defaultListColumns = map[string]string{
+	"table":   "id,created,tags",
+	"oneline": "tags",
+}
+
+// columns parses the --columns list, adding the "reads" and "edits" columns
+// that count accesses recorded in the history and the "notebook" column
+func (c *ListCommand) columns(spec string) ([]ui.Column, error) {
+	entries, err := history.Load(c.ctx.Storage.StorePath(history.FileName))
+	if err != nil {
+		return nil, err
+	}
+	counter := func(name, action string) ui.Column {
+		counts := history.Counts(entries, action)
+		return ui.Column{Name: name, Value: func(n *note.Note) string {
+			return strconv.Itoa(counts[n.ID()])
+		}}
+	}
+	notebook := ui.Column{Name: "notebook", Value: c.ctx.Storage.NotebookOf}
+	return ui.ParseColumns(spec, counter("reads", "read"), counter("edits", "edited"), notebook), nil
+}
+
+func filterByNotebook(fs *storage.FileStorage, notes []*note.Note, notebook string) []*note.Note {
+	var matched []*note.Note
+	for _, n := range notes {
+		if fs.NotebookOf(n) == notebook {
+			matched = append(matched, n)
+		}
+	}
+	return matched
+}
+
+// filterByTags keeps the notes that have every tag in all, at least one
+// tag in some (when given) and none of the tags in none. Nested tags match
+// their parents as with --tag.
+func filterByTags(notes []*note.Note, all, some, none []string) []*note.Note {
+	var matched []*note.Note
+	for _, n := range notes {
+		if !slices.ContainsFunc(none, n.HasTag) &&
+			!slices.ContainsFunc(all, func(tag string) bool { return !n.HasTag(tag) }) &&
+			(len(some) == 0 || slices.ContainsFunc(some, n.HasTag)) {
+			matched = append(matched, n)
+		}
+	}
+	return matched
+}
+
+// describeTagFilter words a tag filter for the listing header
+func describeTagFilter(all, some, none []string) string {
+	quote := func(tags []string, sep string) string {
+		return "'" + strings.Join(tags, "' "+sep+" '") + "'"
+	}
+	var parts []string
+	if len(all) > 0 {
+		parts = append(parts, "with tags "+quote(all, "and"))
+	}
+	if len(some) > 0 {
+		parts = append(parts, "with any of "+quote(some, "or"))
+	}
+	if len(none) > 0 {
+		parts = append(parts, "without "+quote(none, "or"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// filterNear keeps the notes taken within radiusKm of place, or at a
+// place of the same name when coordinates are missing
+func filterNear(notes []*note.Note, place note.Location, radiusKm float64) []*note.Note {
+	var matched []*note.Note
+	for _, n := range notes {
+		if l := n.Metadata.Location; l != nil && l.Near(place, radiusKm) {
+			matched = append(matched, n)
+		}
+	}
+	return matched
+}
+
+// filterByField keeps the notes whose front matter field matches value; an
+// empty value keeps every note that has the field
+func filterByField(notes []*note.Note, field, value string) []*note.Note {
+	var matched []*note.Note
+	for _, n := range notes {
+		if n.MatchField(field, value) {
+			matched = append(matched, n)
+		}
+	}
+	return matched
+}
+
+// filterByLanguage keeps the notes written in the language with code;
+// notes saved before languages were detected are detected on the fly
+func filterByLanguage(notes []*note.Note, code string) []*note.Note {
+	var matched []*note.Note
+	for _, n := range notes {
+		if strings.EqualFold(n.Language(), code) {
+			matched = append(matched, n)
+		}
+	}
+	return matched
+}
+
+func countExpired(notes []*note.Note, now time.Time) int {
+	count := 0
+	for _, n := range notes {
+		if n.Expired(now) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+
+	"memo/internal/note"
+	"memo/internal/schema"
+)
+
+// MigrateCommand brings the front matter of every note up to the schema
+// version this build of memo writes
+type MigrateCommand struct {
+	ctx *CommandContext
+}
+
+func NewMigrateCommand(ctx *CommandContext) *MigrateCommand {
+	return &MigrateCommand{ctx: ctx}
+}
+
+func (c *MigrateCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--status")
+	if err != nil {
+		return fmt.Errorf("%v\nUsage: memo migrate [--status]", err)
+	}
+
+	version, err := c.ctx.Storage.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	current := schema.Current()
+	if version > current {
+		return fmt.Errorf("the store has schema version %d, but this memo only knows up to %d; upgrade memo", version, current)
+	}
+	pending := schema.Pending(version)
+
+	if p.Bool("--status") {
+		fmt.Printf("Store schema version: %d (current: %d)\n", version, current)
+		for _, m := range pending {
+			fmt.Printf("  pending %d: %s\n", m.Version, m.Description)
+		}
+		return nil
+	}
+
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		fmt.Printf("Store is at schema version %d; nothing to migrate.\n", version)
+		return c.ctx.Storage.SetSchemaVersion(current)
+	}
+
+	// Every note is read before anything is written, so a store with
+	// broken notes is left as it is
+	notes, err := c.loadNotes()
+	if err != nil {
+		return err
+	}
+
+	var changed []*note.Note
+	for _, n := range notes {
+		touched := false
+		for _, m := range pending {
+			ok, err := m.Apply(n)
+			if err != nil {
+				return fmt.Errorf("migration %d failed for %s: %w", m.Version, n.ID(), err)
+			}
+			touched = touched || ok
+		}
+		if touched {
+			changed = append(changed, n)
+		}
+	}
+
+	// The version is only raised once every note is written; migrations
+	// skip notes already in the new layout, so a failed run can be repeated
+	for i, n := range changed {
+		if err := c.ctx.Storage.RepairNote(n); err != nil {
+			return fmt.Errorf("error saving %s (%d of %d migrated): %w", n.ID(), i, len(changed), err)
+		}
+	}
+	if err := c.ctx.Storage.SetSchemaVersion(current); err != nil {
+		return err
+	}
+
+	for _, m := range pending {
+		fmt.Printf("  %d: %s\n", m.Version, m.Description)
+	}
+	if c.ctx.DryRun {
+		fmt.Printf("Would migrate %d note(s) from schema version %d to %d\n", len(changed), version, current)
+		return nil
+	}
+	fmt.Printf("Migrated %d note(s) from schema version %d to %d\n", len(changed), version, current)
+	return nil
+}
+
+// loadNotes parses every note file, failing on the first one that cannot be
+// read
+func (c *MigrateCommand) loadNotes() ([]*note.Note, error) {
+	files, err := c.ctx.Storage.NoteFiles()
+	if err != nil {
+		return nil, err
+	}
+	notes := make([]*note.Note, 0, len(files))
+	for _, file := range files {
+		n, err := c.ctx.Storage.ParseNote(file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot migrate %s: %w (run 'memo doctor' first)", file, err)
+		}
+		notes = append(notes, n)
+	}
+	return notes, nil
+}
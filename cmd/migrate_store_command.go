@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"memo/internal/attachments"
+	"memo/internal/config"
+	"memo/internal/hooks"
+	"memo/internal/templates"
+	"memo/internal/ui"
+)
+
+type MigrateStoreCommand struct {
+	ctx *CommandContext
+}
+
+func NewMigrateStoreCommand(ctx *CommandContext) *MigrateStoreCommand {
+	return &MigrateStoreCommand{ctx: ctx}
+}
+
+func (c *MigrateStoreCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--dry-run", "-n", "--remove")
+	if err != nil {
+		return err
+	}
+	dryRun := p.Bool("--dry-run", "-n") || c.ctx.DryRun
+	remove := p.Bool("--remove")
+	if dryRun && remove {
+		return fmt.Errorf("--dry-run and --remove cannot be used together")
+	}
+
+	sources := p.Positional
+	if len(sources) == 0 {
+		if _, err := os.Stat(config.LegacyNotesDir); err != nil {
+			return fmt.Errorf("no %s directory here to migrate\nUsage: memo migrate-store [--dry-run] [--remove] [<dir>...]", config.LegacyNotesDir)
+		}
+		sources = []string{config.LegacyNotesDir}
+	}
+
+	target := c.ctx.Storage.NotesDir()
+	for _, src := range sources {
+		migrated, err := c.ctx.Storage.MigrateFrom(src, dryRun)
+		if err != nil {
+			return err
+		}
+		var copied []string
+		for _, dir := range []string{templates.DirName, hooks.DirName, attachments.DirName} {
+			files, err := c.ctx.Storage.CopyStoreDir(src, dir, dryRun)
+			if err != nil {
+				return fmt.Errorf("error copying %s: %w", dir, err)
+			}
+			copied = append(copied, files...)
+		}
+
+		ui.DisplayMigration(src, target, migrated, copied, dryRun)
+		if remove {
+			if err := os.RemoveAll(src); err != nil {
+				return fmt.Errorf("error removing %s: %w", src, err)
+			}
+			fmt.Printf("Removed %s\n", src)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"memo/internal/ui"
+)
+
+type NotebooksCommand struct {
+	ctx *CommandContext
+}
+
+func NewNotebooksCommand(ctx *CommandContext) *NotebooksCommand {
+	return &NotebooksCommand{ctx: ctx}
+}
+
+func (c *NotebooksCommand) Execute(args []string) error {
+	notebooks, err := c.ctx.Storage.Notebooks()
+	if err != nil {
+		return err
+	}
+	notes, err := c.ctx.Storage.GetAllNotes()
+	if err != nil {
+		return fmt.Errorf("error listing notes: %w", err)
+	}
+
+	counts := make(map[string]int)
+	for _, n := range notes {
+		counts[c.ctx.Storage.NotebookOf(n)]++
+	}
+	// Configured notebooks are listed before their first note creates them
+	for name := range c.ctx.Profile.Notebooks {
+		if !slices.Contains(notebooks, name) {
+			notebooks = append(notebooks, name)
+		}
+	}
+	sort.Strings(notebooks)
+
+	ui.DisplayNotebooks(notebooks, counts, c.ctx.Profile.IDPrefixes())
+	return nil
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import "memo/internal/ui"
+
+type ProfilesCommand struct {
+	ctx *CommandContext
+}
+
+func NewProfilesCommand(ctx *CommandContext) *ProfilesCommand {
+	return &ProfilesCommand{ctx: ctx}
+}
+
+func (c *ProfilesCommand) Execute(args []string) error {
+	ui.DisplayProfiles(c.ctx.Config, c.ctx.ProfileName)
+	return nil
+}
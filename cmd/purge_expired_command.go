@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"memo/internal/note"
+	"memo/internal/storage"
+	"memo/internal/ui"
+)
+
+type PurgeExpiredCommand struct {
+	ctx *CommandContext
+}
+
+func NewPurgeExpiredCommand(ctx *CommandContext) *PurgeExpiredCommand {
+	return &PurgeExpiredCommand{ctx: ctx}
+}
+
+func (c *PurgeExpiredCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--dry-run", "-n", "--yes", "-y")
+	if err != nil {
+		return err
+	}
+	dryRun := p.Bool("--dry-run", "-n") || c.ctx.DryRun
+	if !dryRun {
+		if err := c.ctx.Storage.CheckWritable(); err != nil {
+			return err
+		}
+	}
+
+	notes, err := c.ctx.Storage.GetAllNotes()
+	if err != nil {
+		return fmt.Errorf("error loading notes: %w", err)
+	}
+
+	now := time.Now()
+	var expired []*note.Note
+	for _, n := range notes {
+		if n.Expired(now) {
+			expired = append(expired, n)
+		}
+	}
+	if len(expired) == 0 {
+		fmt.Println("No expired notes.")
+		return nil
+	}
+
+	fmt.Println("Expired notes:")
+	for _, n := range expired {
+		fmt.Printf("  %s (%s) expired %s\n", n.Metadata.Title, n.ID(), n.Metadata.Expires.Format("2006-01-02 15:04"))
+	}
+	if dryRun {
+		return nil
+	}
+
+	prompt := ui.Tf("Delete %d expired note(s)? (y/N): ", len(expired))
+	if !p.Bool("--yes", "-y") && !ui.ConfirmAction(prompt) {
+		fmt.Println("Purge cancelled.")
+		return nil
+	}
+
+	// The notes are deleted together: all of them or, if memo fails
+	// halfway, none
+	tx, err := c.ctx.BeginTransaction(false)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	deleted := 0
+	for _, n := range expired {
+		if err := c.ctx.Storage.DeleteNote(n.ID()); err != nil {
+			var locked *storage.LockedError
+			if errors.As(err, &locked) {
+				slog.Warn("skipping locked note", "id", n.ID())
+				continue
+			}
+			return fmt.Errorf("error deleting note: %w", err)
+		}
+		deleted++
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted %d expired note(s).\n", deleted)
+	return nil
+}
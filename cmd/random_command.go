@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+	"memo/internal/ui"
+)
+
+// ArchivedStatus marks notes that `memo random` leaves alone; a tag of the
+// same name does too
+const ArchivedStatus = "archived"
+
+type RandomCommand struct {
+	ctx *CommandContext
+}
+
+func NewRandomCommand(ctx *CommandContext) *RandomCommand {
+	return &RandomCommand{ctx: ctx}
+}
+
+// Execute shows a random note, or several, to resurface old knowledge.
+// Archived and expired notes are never picked.
+func (c *RandomCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--tag=", "--min-age=", "--count=")
+	if err != nil {
+		return fmt.Errorf("%v\nUsage: memo random [--tag <tag>] [--min-age <age>] [--count <n>]", err)
+	}
+
+	count := 1
+	if value := p.Value("--count"); value != "" {
+		if count, err = strconv.Atoi(value); err != nil || count < 1 {
+			return fmt.Errorf("--count must be a positive number\nUsage: memo random [--count <n>]")
+		}
+	}
+	now := time.Now()
+	var cutoff time.Time
+	if value := p.Value("--min-age"); value != "" {
+		age, err := parseAge(value)
+		if err != nil {
+			return err
+		}
+		cutoff = now.Add(-age)
+	}
+
+	notes, err := c.ctx.Storage.GetAllNotes()
+	if err != nil {
+		return fmt.Errorf("error loading notes: %w", err)
+	}
+
+	tag := p.Value("--tag")
+	var candidates []*note.Note
+	for _, n := range notes {
+		switch {
+		case strings.EqualFold(n.Metadata.Status, ArchivedStatus) || n.HasTag(ArchivedStatus):
+		case n.Expired(now):
+		case tag != "" && !n.HasTag(tag):
+		case !cutoff.IsZero() && n.Metadata.Created.After(cutoff):
+		default:
+			candidates = append(candidates, n)
+		}
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no notes to pick from")
+	}
+
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if len(candidates) > count {
+		candidates = candidates[:count]
+	}
+
+	if count == 1 {
+		n := candidates[0]
+		if err := c.ctx.UnlockNote(n); err != nil {
+			return err
+		}
+		ui.DisplayNote(n)
+		c.ctx.RecordAccess(n.ID(), "read")
+		return nil
+	}
+
+	c.ctx.SetCurrentListing(candidates)
+	ui.DisplayRandomNotes(candidates)
+	return nil
+}
+
+// parseAge parses an age such as 30d, 2w, 6m or 1y; months count as 30
+// days and years as 365
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if len(value) > 1 {
+		days := map[byte]int{'d': 1, 'w': 7, 'm': 30, 'y': 365}[value[len(value)-1]]
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && days > 0 && n > 0 {
+			return time.Duration(n*days) * 24 * time.Hour, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid age '%s' (use a number of days, weeks, months or years like 30d, 2w, 6m, 1y)", value)
+}
@@ -2,10 +2,9 @@ package cmd
 
 import (
 	"fmt"
-	"path/filepath"
-	"strconv"
-	"strings"
+	"text/template"
 
+	"memo/internal/render"
 	"memo/internal/ui"
 )
 
@@ -18,12 +17,21 @@ func NewReadCommand(ctx *CommandContext) *ReadCommand {
 }
 
 func (c *ReadCommand) Execute(args []string) error {
-	if len(args) < 1 {
-		return fmt.Errorf("note-id or number required\nUsage: memo read <note-id|number>")
+	p, err := parseArgs(args, "--template=", "--plain", "--sentences", "--related")
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("note-id or number required\nUsage: memo read <note-id|number> [--related | --plain [--sentences] | --template <template>]")
+	}
+	var tmpl *template.Template
+	if text := p.Value("--template"); text != "" {
+		if tmpl, err = ui.ParseNoteTemplate(text); err != nil {
+			return err
+		}
 	}
 
-	identifier := args[0]
-	noteID, err := c.resolveNoteID(identifier)
+	noteID, err := c.ctx.ResolveNoteID(p.Positional[0])
 	if err != nil {
 		return err
 	}
@@ -33,23 +41,29 @@ func (c *ReadCommand) Execute(args []string) error {
 		return err
 	}
 
-	ui.DisplayNote(n)
-	return nil
-}
+	if err := c.ctx.UnlockNote(n); err != nil {
+		return err
+	}
 
-func (c *ReadCommand) resolveNoteID(identifier string) (string, error) {
-	if num, err := strconv.Atoi(identifier); err == nil {
-		if c.ctx.CurrentListing == nil || len(c.ctx.CurrentListing) == 0 {
-			return "", fmt.Errorf("no current note listing. Please run 'memo list' first")
+	switch {
+	case tmpl != nil:
+		if err := ui.DisplayNoteTemplate(n, tmpl, c.ctx.Storage.NotebookOf(n)); err != nil {
+			return err
 		}
-
-		if num < 1 || num > len(c.ctx.CurrentListing) {
-			return "", fmt.Errorf("number %d is out of range. Valid range: 1-%d", num, len(c.ctx.CurrentListing))
+	case p.Bool("--plain", "--sentences"):
+		// Just the words, for piping into say or another speech tool; the
+		// title is read as a heading
+		fmt.Print(render.ToPlain("# "+n.Metadata.Title+"\n\n"+n.Content, p.Bool("--sentences")))
+	default:
+		ui.DisplayNote(n)
+		if p.Bool("--related") {
+			related, err := relatedTo(c.ctx, n, readRelated)
+			if err != nil {
+				return err
+			}
+			ui.DisplayRelatedSection(related)
 		}
-
-		n := c.ctx.CurrentListing[num-1]
-		return strings.TrimSuffix(filepath.Base(n.FilePath), ".note"), nil
 	}
-
-	return identifier, nil
-}
\ No newline at end of file
+	c.ctx.RecordAccess(noteID, "read")
+	return nil
+}
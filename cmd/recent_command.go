@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"memo/internal/history"
+	"memo/internal/note"
+	"memo/internal/ui"
+)
+
+const defaultRecentCount = 10
+
+type RecentCommand struct {
+	ctx *CommandContext
+}
+
+func NewRecentCommand(ctx *CommandContext) *RecentCommand {
+	return &RecentCommand{ctx: ctx}
+}
+
+func (c *RecentCommand) Execute(args []string) error {
+	limit := defaultRecentCount
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid count '%s'\nUsage: memo recent [n]", args[0])
+		}
+		limit = n
+	}
+
+	entries, err := history.Load(c.ctx.Storage.StorePath(history.FileName))
+	if err != nil {
+		return err
+	}
+
+	var shown []history.Entry
+	var notes []*note.Note
+	for _, entry := range history.Recent(entries, 0) {
+		n, err := c.ctx.Storage.FindNoteByID(entry.NoteID)
+		if err != nil {
+			continue
+		}
+		shown = append(shown, entry)
+		notes = append(notes, n)
+		if len(notes) == limit {
+			break
+		}
+	}
+
+	c.ctx.SetCurrentListing(notes)
+	ui.DisplayRecent(shown, notes)
+	return nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"memo/internal/ui"
+)
+
+const recoverUsage = "Usage: memo recover [--rollback | --complete]"
+
+type RecoverCommand struct {
+	ctx *CommandContext
+}
+
+func NewRecoverCommand(ctx *CommandContext) *RecoverCommand {
+	return &RecoverCommand{ctx: ctx}
+}
+
+// Execute deals with an operation on several files that a memo process
+// left half done when it crashed or was killed. --rollback undoes what it
+// had changed; --complete undoes it and runs the command again. Without
+// either, the user is asked.
+func (c *RecoverCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--rollback", "--complete")
+	if err != nil {
+		return fmt.Errorf("%v\n%s", err, recoverUsage)
+	}
+	rollback, complete := p.Bool("--rollback"), p.Bool("--complete")
+	if rollback && complete {
+		return fmt.Errorf("--rollback and --complete cannot be used together\n%s", recoverUsage)
+	}
+
+	t, err := c.ctx.Storage.InterruptedTransaction()
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		fmt.Println("No interrupted operation to recover.")
+		return nil
+	}
+
+	fmt.Printf("'%s' was interrupted on %s after changing %d file(s):\n", t.CommandLine(), t.Started.Local().Format("2006-01-02 15:04"), len(t.Files))
+	for _, e := range t.Files {
+		fmt.Printf("  %s\n", e.Path)
+	}
+	if c.ctx.DryRun {
+		return nil
+	}
+
+	if !rollback && !complete {
+		if !ui.IsInteractive() {
+			fmt.Println("Run 'memo recover --rollback' to undo these changes, or 'memo recover --complete' to undo them and run the command again.")
+			return nil
+		}
+		switch strings.ToLower(ui.PromptForInput("[r]oll back, [c]omplete, [q]uit: ")) {
+		case "r":
+			rollback = true
+		case "c":
+			complete = true
+		default:
+			return nil
+		}
+	}
+
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+	if err := t.Rollback(); err != nil {
+		return err
+	}
+	fmt.Printf("Rolled back '%s'.\n", t.CommandLine())
+	if !complete {
+		return nil
+	}
+
+	fmt.Printf("Running '%s' again.\n", t.CommandLine())
+	return rerun(t.Command)
+}
+
+// rerun runs memo again with args on the same terminal and passes on its
+// exit status
+func rerun(args []string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot run memo again: %w", err)
+	}
+	cmd := exec.Command(self, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return ExitError{Code: exitErr.ExitCode()}
+	}
+	return err
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"memo/internal/recur"
+	"memo/internal/templates"
+	"memo/internal/ui"
+)
+
+type RecurCommand struct {
+	ctx *CommandContext
+}
+
+func NewRecurCommand(ctx *CommandContext) *RecurCommand {
+	return &RecurCommand{ctx: ctx}
+}
+
+const recurUsage = `Usage:
+  memo recur add "<name>" --every <schedule> --template <template>
+  memo recur list
+  memo recur remove "<name>"
+  memo recur run`
+
+func (c *RecurCommand) Execute(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("subcommand required\n%s", recurUsage)
+	}
+
+	switch args[0] {
+	case "add":
+		return c.add(args[1:])
+	case "list":
+		return c.list()
+	case "remove":
+		return c.remove(args[1:])
+	case "run":
+		return c.run(time.Now())
+	default:
+		return fmt.Errorf("unknown subcommand '%s'\n%s", args[0], recurUsage)
+	}
+}
+
+func (c *RecurCommand) rulesPath() string {
+	return c.ctx.Storage.StorePath(recur.FileName)
+}
+
+func (c *RecurCommand) add(args []string) error {
+	p, err := parseArgs(args, "--every=", "--template=")
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 1 || p.Value("--every") == "" || p.Value("--template") == "" {
+		return fmt.Errorf("name, --every and --template are required\n%s", recurUsage)
+	}
+
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	name := strings.Join(p.Positional, " ")
+	if err := recur.ValidateSchedule(p.Value("--every")); err != nil {
+		return err
+	}
+	if _, err := templates.Load(c.ctx.Storage, p.Value("--template")); err != nil {
+		return err
+	}
+
+	rules, err := recur.Load(c.rulesPath())
+	if err != nil {
+		return err
+	}
+	for _, r := range rules {
+		if strings.EqualFold(r.Name, name) {
+			return fmt.Errorf("recurring note '%s' already exists", name)
+		}
+	}
+
+	rules = append(rules, recur.Rule{
+		Name:     name,
+		Every:    strings.ToLower(p.Value("--every")),
+		Template: p.Value("--template"),
+		Added:    time.Now(),
+	})
+
+	if err := c.ctx.Storage.EnsureNotesDir(); err != nil {
+		return err
+	}
+	if err := recur.Save(c.rulesPath(), rules); err != nil {
+		return err
+	}
+
+	fmt.Printf("Recurring note '%s' added (every %s).\n", name, p.Value("--every"))
+	return nil
+}
+
+func (c *RecurCommand) list() error {
+	rules, err := recur.Load(c.rulesPath())
+	if err != nil {
+		return err
+	}
+
+	ui.DisplayRecurRules(rules)
+	return nil
+}
+
+func (c *RecurCommand) remove(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("name required\n%s", recurUsage)
+	}
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+	name := strings.Join(args, " ")
+
+	rules, err := recur.Load(c.rulesPath())
+	if err != nil {
+		return err
+	}
+
+	for i, r := range rules {
+		if strings.EqualFold(r.Name, name) {
+			rules = append(rules[:i], rules[i+1:]...)
+			if err := recur.Save(c.rulesPath(), rules); err != nil {
+				return err
+			}
+			fmt.Printf("Recurring note '%s' removed.\n", r.Name)
+			return nil
+		}
+	}
+	return fmt.Errorf("recurring note '%s' not found", name)
+}
+
+// run creates the notes that are due. It is safe to call repeatedly, e.g.
+// from cron: each rule fires at most once per scheduled day.
+func (c *RecurCommand) run(now time.Time) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	rules, err := recur.Load(c.rulesPath())
+	if err != nil {
+		return err
+	}
+
+	created := 0
+	for i, r := range rules {
+		day, due := r.DueOccurrence(now)
+		if !due {
+			continue
+		}
+
+		tmpl, err := templates.Load(c.ctx.Storage, r.Template)
+		if err != nil {
+			return fmt.Errorf("recurring note '%s': %w", r.Name, err)
+		}
+		n, err := tmpl.Instantiate(templates.NewData(day))
+		if err != nil {
+			return fmt.Errorf("recurring note '%s': %w", r.Name, err)
+		}
+
+		noteID, err := c.ctx.Storage.CreateNote(n)
+		if err != nil {
+			return fmt.Errorf("recurring note '%s': %w", r.Name, err)
+		}
+		fmt.Printf("Created %s: %s\n", noteID, n.Metadata.Title)
+
+		rules[i].LastRun = now
+		if err := recur.Save(c.rulesPath(), rules); err != nil {
+			return err
+		}
+		created++
+	}
+
+	if created == 0 {
+		fmt.Println("No recurring notes due.")
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+
+	"memo/internal/analysis"
+	"memo/internal/note"
+	"memo/internal/ui"
+)
+
+// readRelated is how many related notes `memo read --related` shows
+const readRelated = 3
+
+// RelatedCommand suggests notes similar to a note by content and tags
+type RelatedCommand struct {
+	ctx *CommandContext
+}
+
+func NewRelatedCommand(ctx *CommandContext) *RelatedCommand {
+	return &RelatedCommand{ctx: ctx}
+}
+
+func (c *RelatedCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--top=")
+	if err != nil {
+		return fmt.Errorf("%v\nUsage: memo related [--top <n>] <note-id|number|title>", err)
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("note-id or number required\nUsage: memo related [--top <n>] <note-id|number|title>")
+	}
+	top, err := p.Int("--top", analysis.DefaultRelated)
+	if err != nil || top < 1 {
+		return fmt.Errorf("--top must be a positive number")
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(p.Positional[0])
+	if err != nil {
+		return err
+	}
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+
+	related, err := relatedTo(c.ctx, n, top)
+	if err != nil {
+		return err
+	}
+
+	listing := make([]*note.Note, len(related))
+	for i, r := range related {
+		listing[i] = r.Note
+	}
+	c.ctx.SetCurrentListing(listing)
+	ui.DisplayRelated(n, related)
+	return nil
+}
+
+// relatedTo ranks the other notes in the store by similarity to n
+func relatedTo(ctx *CommandContext, n *note.Note, top int) ([]analysis.RelatedNote, error) {
+	notes, err := ctx.Storage.GetAllNotes()
+	if err != nil {
+		return nil, fmt.Errorf("error loading notes: %w", err)
+	}
+	return analysis.FindRelated(n, notes, top), nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"memo/internal/storage"
+	"memo/internal/ui"
+)
+
+type RenameCommand struct {
+	ctx *CommandContext
+}
+
+func NewRenameCommand(ctx *CommandContext) *RenameCommand {
+	return &RenameCommand{ctx: ctx}
+}
+
+func (c *RenameCommand) Execute(args []string) error {
+	p, err := parseArgsWithText(args, "--id", "--dry-run", "-n", "--no-links")
+	if err != nil {
+		return err
+	}
+	if p.Bool("--id") {
+		if len(p.Positional) != 2 {
+			return fmt.Errorf("note and new ID required\nUsage: memo rename --id [--dry-run] [--no-links] <note-id|number|title> <new-id>")
+		}
+	} else if len(p.Positional) < 2 {
+		return fmt.Errorf("note and new title required\nUsage: memo rename [--dry-run] [--no-links] <note-id|number|title> <new title>")
+	}
+	dryRun := p.Bool("--dry-run", "-n") || c.ctx.DryRun
+	if !dryRun {
+		if err := c.ctx.Storage.CheckWritable(); err != nil {
+			return err
+		}
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(p.Positional[0])
+	if err != nil {
+		return err
+	}
+	if p.Bool("--id") {
+		return c.renameID(noteID, p.Positional[1], dryRun, !p.Bool("--no-links"))
+	}
+	newTitle := strings.TrimSpace(strings.Join(p.Positional[1:], " "))
+
+	release, err := c.ctx.Storage.AcquireLock(noteID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	tx, err := c.ctx.BeginTransaction(dryRun)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	oldTitle := n.Metadata.Title
+	if err := n.SetMetadata("title", newTitle); err != nil {
+		return err
+	}
+
+	if !p.Bool("--no-links") {
+		updates, err := c.ctx.RelinkTitle(n, oldTitle, dryRun)
+		if err != nil {
+			return err
+		}
+		ui.DisplayLinkUpdates(updates, dryRun)
+	}
+	if dryRun {
+		fmt.Printf("Would rename '%s' to '%s'\n", oldTitle, newTitle)
+		return nil
+	}
+
+	if err := c.ctx.Storage.SaveNote(n); err != nil {
+		return fmt.Errorf("error saving note: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	fmt.Printf("Renamed '%s' to '%s'\n", oldTitle, newTitle)
+	c.ctx.RecordAccess(noteID, "edited")
+	return nil
+}
+
+// renameID gives a note a new ID. The note's file, revisions and attachment
+// references move along, links naming the old ID are updated, and the old
+// ID stays usable as an alias.
+func (c *RenameCommand) renameID(oldID, newID string, dryRun, relink bool) error {
+	newID = strings.TrimSpace(newID)
+	if err := storage.ValidateName(newID); err != nil {
+		return fmt.Errorf("invalid note ID: %w", err)
+	}
+
+	release, err := c.ctx.Storage.AcquireLock(oldID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	tx, err := c.ctx.BeginTransaction(dryRun)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	n, err := c.ctx.Storage.FindNoteByID(oldID)
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		if err := c.ctx.Storage.RenameNoteFile(oldID, newID); err != nil {
+			return err
+		}
+		n.SetFilePath(c.ctx.Storage.GenerateNoteFilePath(newID))
+	}
+
+	if relink {
+		updates, err := c.ctx.RelinkID(n, oldID, newID, dryRun)
+		if err != nil {
+			return err
+		}
+		ui.DisplayLinkUpdates(updates, dryRun)
+		for _, u := range updates {
+			if u.Note.ID() == n.ID() && !dryRun {
+				if err := c.ctx.Storage.SaveNote(n); err != nil {
+					return fmt.Errorf("error saving note: %w", err)
+				}
+			}
+		}
+	}
+	if dryRun {
+		fmt.Printf("Would rename %s to %s\n", oldID, newID)
+		return nil
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Renamed %s to %s ('%s' still finds the note)\n", oldID, newID, oldID)
+	c.ctx.RecordAccess(newID, "edited")
+	return nil
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import "testing"
+
+func TestRenameTitleStartingWithDash(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		title string
+	}{
+		{name: "quoted", args: []string{"-5 degrees log"}, title: "-5 degrees log"},
+		{name: "unquoted", args: []string{"-5", "degrees", "log"}, title: "-5 degrees log"},
+		{name: "flag-like words", args: []string{"--no-links", "primer"}, title: "--no-links primer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, id := newTestContext(t, "Weather")
+			if err := NewRenameCommand(ctx).Execute(append([]string{id}, tt.args...)); err != nil {
+				t.Fatalf("Execute = %v", err)
+			}
+			if got := findNote(t, ctx, id).Metadata.Title; got != tt.title {
+				t.Errorf("title = %q, want %q", got, tt.title)
+			}
+		})
+	}
+
+	ctx, id := newTestContext(t, "Weather")
+	if err := NewRenameCommand(ctx).Execute([]string{"--", id, "-5 degrees log"}); err != nil {
+		t.Fatalf("Execute after -- = %v", err)
+	}
+	if got := findNote(t, ctx, id).Metadata.Title; got != "-5 degrees log" {
+		t.Errorf("title after -- = %q, want %q", got, "-5 degrees log")
+	}
+}
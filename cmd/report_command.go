@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+	"memo/internal/stats"
+)
+
+const reportUsage = "Usage: memo report [--week|--month|--since YYYY-MM-DD [--until YYYY-MM-DD]] [--previous] [--tag <tag>] [--as-note]"
+
+type ReportCommand struct {
+	ctx *CommandContext
+}
+
+func NewReportCommand(ctx *CommandContext) *ReportCommand {
+	return &ReportCommand{ctx: ctx}
+}
+
+func (c *ReportCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--since=", "--until=", "--tag=", "--as-note", "--week", "--month", "--previous")
+	if err != nil {
+		return err
+	}
+
+	title, period, err := reportPeriod(p, reportUsage)
+	if err != nil {
+		return err
+	}
+	asNote := p.Bool("--as-note")
+	if asNote {
+		if err := c.ctx.Storage.CheckWritable(); err != nil {
+			return err
+		}
+	}
+
+	notes, err := c.ctx.Storage.GetAllNotes()
+	if err != nil {
+		return fmt.Errorf("error loading notes: %w", err)
+	}
+	if tag := p.Value("--tag"); tag != "" {
+		var tagged []*note.Note
+		for _, n := range notes {
+			if n.HasTag(tag) {
+				tagged = append(tagged, n)
+			}
+		}
+		notes = tagged
+	}
+
+	report := stats.NewReport(title, notes, period)
+	markdown := report.Markdown()
+	if !asNote {
+		fmt.Print(markdown)
+		return nil
+	}
+
+	// The note's title replaces the report's heading
+	_, body, _ := strings.Cut(markdown, "\n")
+	n := note.New(fmt.Sprintf("%s %s", title, period), strings.TrimSpace(body), []string{"review"})
+	n.Metadata.Author = c.ctx.Profile.Author
+	n.SetField(stats.ReportField, "report")
+	noteID, err := c.ctx.Storage.CreateNote(n)
+	if err != nil {
+		return fmt.Errorf("error creating note: %w", err)
+	}
+	fmt.Printf("Report saved as note: %s\n", noteID)
+	c.ctx.RecordAccess(noteID, "created")
+	return nil
+}
+
+// reportPeriod picks the period and title from --week, --month or
+// --since/--until; --previous steps back one period
+func reportPeriod(p *parsedArgs, usage string) (string, stats.Period, error) {
+	var title string
+	var period stats.Period
+	switch {
+	case p.Bool("--week") && p.Bool("--month"):
+		return "", period, fmt.Errorf("--week and --month cannot be used together\n%s", usage)
+	case p.Bool("--month"):
+		title, period = "Monthly review", stats.Month(time.Now())
+	case p.Value("--since") != "":
+		var err error
+		if period, err = stats.ParsePeriod(p.Value("--since"), p.Value("--until")); err != nil {
+			return "", period, err
+		}
+		if period.Until.IsZero() {
+			// An open end is taken to be today
+			y, m, d := time.Now().Date()
+			period.Until = time.Date(y, m, d+1, 0, 0, 0, 0, time.Local)
+		}
+		title = "Review"
+	default:
+		title, period = "Weekly review", stats.Week(time.Now())
+	}
+
+	if p.Bool("--previous") {
+		period = period.Previous()
+	}
+	return title, period, nil
+}
@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"memo/internal/index"
+	"memo/internal/merge"
+	"memo/internal/notesync"
+	"memo/internal/storage"
+	"memo/internal/ui"
+)
+
+// ResolveCommand settles a note `memo sync` skipped because it changed
+// both in the store and in the sync target
+type ResolveCommand struct {
+	ctx *CommandContext
+}
+
+func NewResolveCommand(ctx *CommandContext) *ResolveCommand {
+	return &ResolveCommand{ctx: ctx}
+}
+
+const resolveUsage = "Usage: memo resolve [--target <dir>] [--keep local|remote|both] <note-id>"
+
+func (c *ResolveCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--target=", "--keep=")
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("note-id required\n%s", resolveUsage)
+	}
+	keep := p.Value("--keep")
+	if keep != "" && keep != "local" && keep != "remote" && keep != "both" {
+		return fmt.Errorf("--keep must be 'local', 'remote' or 'both'")
+	}
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(p.Positional[0])
+	if err != nil {
+		return err
+	}
+	release, err := c.ctx.Storage.AcquireLock(noteID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	statePath := c.ctx.Storage.StorePath(notesync.StateFileName)
+	state, err := notesync.LoadState(statePath)
+	if err != nil {
+		return err
+	}
+	dir, err := c.findConflict(noteID, state, p.Value("--target"))
+	if err != nil {
+		return err
+	}
+
+	ext := c.ctx.Storage.NoteExtension()
+	localPath := c.ctx.Storage.GenerateNoteFilePath(noteID)
+	remotePath := filepath.Join(dir, noteID+ext)
+	local, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+	remote, err := os.ReadFile(remotePath)
+	if err != nil {
+		return err
+	}
+	base, hasBase := notesync.LoadBase(c.ctx.Storage.StorePath(notesync.BaseDirName), state[dir][noteID])
+
+	chunks := merge.Merge(merge.Split(string(base)), merge.Split(string(local)), merge.Split(string(remote)))
+	settleModified(chunks)
+
+	fmt.Printf("Note %s changed both here (local) and in %s (remote)\n", noteID, dir)
+	if !hasBase {
+		fmt.Println("The version of the last sync is unknown, so every difference is a conflict.")
+	}
+	encrypted := isEncrypted(local) || isEncrypted(remote)
+	if encrypted {
+		fmt.Println("The note is encrypted; its versions cannot be compared or merged.")
+	} else {
+		ui.DisplayMerge(chunks)
+	}
+
+	if keep == "" {
+		keep, err = c.choose(encrypted)
+		if err != nil || keep == "" {
+			return err
+		}
+	}
+
+	var result []byte
+	switch keep {
+	case "local", "both":
+		result = local
+	case "remote":
+		result = remote
+	case "merge":
+		merged, err := c.merge(chunks)
+		if err != nil {
+			return err
+		}
+		result = []byte(merge.Join(merged))
+		if _, err := storage.ParseNoteContent(string(result)); err != nil {
+			return fmt.Errorf("the merged note is not valid, nothing was changed: %w", err)
+		}
+	}
+
+	if c.ctx.DryRun {
+		ui.DisplayDryRunChange("resolve", noteID)
+		return nil
+	}
+
+	if keep == "both" {
+		copied, err := storage.ParseNoteContent(string(remote))
+		if err != nil {
+			return fmt.Errorf("error reading the remote version: %w", err)
+		}
+		copied.Metadata.Title += " (remote copy)"
+		copyID, err := c.ctx.Storage.CreateNote(copied)
+		if err != nil {
+			return fmt.Errorf("error saving the remote version: %w", err)
+		}
+		fmt.Printf("Kept the remote version as %s\n", copyID)
+	}
+
+	now := time.Now()
+	if string(result) != string(local) {
+		if err := c.ctx.Storage.WriteNoteFile(noteID, result, now); err != nil {
+			return err
+		}
+	}
+	if err := notesync.WriteFile(remotePath, result, now); err != nil {
+		return fmt.Errorf("error writing %s: %w", remotePath, err)
+	}
+
+	// Both sides now hold the same version, which becomes the new base
+	entry, err := index.HashFile(remotePath)
+	if err != nil {
+		return err
+	}
+	if state[dir] == nil {
+		state[dir] = make(index.Index)
+	}
+	state[dir][noteID] = entry
+	if err := notesync.SaveState(statePath, state); err != nil {
+		return err
+	}
+	if err := notesync.SaveBases(c.ctx.Storage.StorePath(notesync.BaseDirName), dir, ext, state[dir], state); err != nil {
+		return err
+	}
+
+	fmt.Printf("Resolved the conflict of %s\n", noteID)
+	return nil
+}
+
+// findConflict returns the sync target in which noteID conflicts with the
+// store, which target must name when there are several
+func (c *ResolveCommand) findConflict(noteID string, state notesync.State, target string) (string, error) {
+	if target != "" {
+		dir, err := filepath.Abs(target)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := state[dir]; !ok {
+			return "", fmt.Errorf("'%s' has never been synced", dir)
+		}
+		if !c.conflicts(noteID, dir, state[dir]) {
+			return "", fmt.Errorf("note %s does not conflict with '%s'", noteID, dir)
+		}
+		return dir, nil
+	}
+
+	var dirs []string
+	for dir, base := range state {
+		if c.conflicts(noteID, dir, base) {
+			dirs = append(dirs, dir)
+		}
+	}
+	switch len(dirs) {
+	case 0:
+		return "", fmt.Errorf("note %s has no sync conflict", noteID)
+	case 1:
+		return dirs[0], nil
+	}
+	sort.Strings(dirs)
+	return "", fmt.Errorf("note %s conflicts with several sync targets; pick one with --target:\n  %s", noteID, strings.Join(dirs, "\n  "))
+}
+
+// conflicts reports whether both the store and dir changed a note since
+// the last sync, the way notesync.Plan decides it
+func (c *ResolveCommand) conflicts(noteID, dir string, base index.Index) bool {
+	local, err := index.HashFile(c.ctx.Storage.GenerateNoteFilePath(noteID))
+	if err != nil {
+		return false
+	}
+	remote, err := index.HashFile(filepath.Join(dir, noteID+c.ctx.Storage.NoteExtension()))
+	if err != nil {
+		return false
+	}
+	last, synced := base[noteID]
+	if local.Hash == remote.Hash {
+		return false
+	}
+	return !synced || local.Hash != last.Hash && remote.Hash != last.Hash
+}
+
+// choose asks how to resolve the conflict; "" means the user gave up
+func (c *ResolveCommand) choose(encrypted bool) (string, error) {
+	for {
+		var answer string
+		if encrypted {
+			answer = ui.PromptForInput("Keep [l]ocal, [r]emote or [b]oth as separate notes, or [q]uit? ")
+		} else {
+			answer = ui.PromptForInput("[m]erge, keep [l]ocal, [r]emote or [b]oth as separate notes, or [q]uit? ")
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "m", "merge":
+			if !encrypted {
+				return "merge", nil
+			}
+		case "l", "local":
+			return "local", nil
+		case "r", "remote":
+			return "remote", nil
+		case "b", "both":
+			return "both", nil
+		case "q", "quit", "":
+			fmt.Println("Nothing was changed.")
+			return "", nil
+		}
+	}
+}
+
+// merge takes the lines both sides agree on and asks which side wins each
+// conflict
+func (c *ResolveCommand) merge(chunks []merge.Chunk) ([]string, error) {
+	var lines []string
+	conflict := 0
+	for _, chunk := range chunks {
+		if chunk.Source != merge.Conflict {
+			lines = append(lines, chunk.Lines()...)
+			continue
+		}
+		conflict++
+		for answered := false; !answered; {
+			answer := ui.PromptForInput(ui.Tf("Conflict %d: take [l]ocal, [r]emote or [b]oth (local first)? ", conflict))
+			answered = true
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "l", "local":
+				lines = append(lines, chunk.Local...)
+			case "r", "remote":
+				lines = append(lines, chunk.Remote...)
+			case "b", "both":
+				lines = append(lines, chunk.Local...)
+				lines = append(lines, chunk.Remote...)
+			case "q", "quit":
+				return nil, fmt.Errorf("merge cancelled, nothing was changed")
+			default:
+				answered = false
+			}
+		}
+	}
+	return lines, nil
+}
+
+// settleModified resolves conflicts in nothing but the modification time
+// of the front matter, which any two edits cause, by taking the later one
+func settleModified(chunks []merge.Chunk) {
+	modified := func(lines []string) (time.Time, bool) {
+		if len(lines) != 1 || !strings.HasPrefix(lines[0], "modified: ") {
+			return time.Time{}, false
+		}
+		t, err := time.Parse(time.RFC3339Nano, strings.TrimPrefix(lines[0], "modified: "))
+		return t, err == nil
+	}
+	for i, chunk := range chunks {
+		if chunk.Source != merge.Conflict {
+			continue
+		}
+		local, okLocal := modified(chunk.Local)
+		remote, okRemote := modified(chunk.Remote)
+		if !okLocal || !okRemote {
+			continue
+		}
+		chunks[i].Source = merge.Local
+		if remote.After(local) {
+			chunks[i].Source = merge.Remote
+		}
+	}
+}
+
+// isEncrypted reports whether note file data has encrypted content
+func isEncrypted(data []byte) bool {
+	n, err := storage.ParseNoteContent(string(data))
+	return err == nil && n.Metadata.Encrypted
+}
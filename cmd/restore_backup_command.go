@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"memo/internal/backup"
+	"memo/internal/config"
+	"memo/internal/ui"
+)
+
+type RestoreBackupCommand struct {
+	ctx *CommandContext
+}
+
+func NewRestoreBackupCommand(ctx *CommandContext) *RestoreBackupCommand {
+	return &RestoreBackupCommand{ctx: ctx}
+}
+
+func (c *RestoreBackupCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--dry-run", "-n", "--yes", "-y", "--with-config")
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("backup file required\nUsage: memo restore-backup [--with-config] [--dry-run] [--yes] <archive>")
+	}
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	archive := p.Positional[0]
+	contents, err := backup.Verify(archive)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backup verified: %d files", contents.Notes)
+	if contents.HasConfig {
+		fmt.Print(" and the configuration")
+	}
+	fmt.Println()
+
+	if p.Bool("--dry-run", "-n") || c.ctx.DryRun {
+		return nil
+	}
+
+	notesDir := c.ctx.Storage.NotesDir()
+	if !p.Bool("--yes", "-y") && !ui.ConfirmAction(ui.Tf("Replace the notes in %s with this backup? (y/N): ", notesDir)) {
+		fmt.Println("Restore cancelled.")
+		return nil
+	}
+
+	restoreConfig := p.Bool("--with-config")
+	previous, err := contents.Restore(notesDir, config.Path(), restoreConfig, time.Now())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Notes restored to %s\n", notesDir)
+	if previous != "" {
+		fmt.Printf("The previous notes were moved to %s\n", previous)
+	}
+	if restoreConfig && contents.HasConfig {
+		fmt.Printf("Configuration restored to %s\n", config.Path())
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"memo/internal/ui"
+)
+
+type RevisionsCommand struct {
+	ctx *CommandContext
+}
+
+func NewRevisionsCommand(ctx *CommandContext) *RevisionsCommand {
+	return &RevisionsCommand{ctx: ctx}
+}
+
+func (c *RevisionsCommand) Execute(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("note-id or number required\nUsage: memo revisions <note-id|number>")
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(args[0])
+	if err != nil {
+		return err
+	}
+
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+
+	revisions, err := c.ctx.Storage.ListRevisions(noteID)
+	if err != nil {
+		return fmt.Errorf("error listing revisions: %w", err)
+	}
+
+	ui.DisplayRevisions(n, revisions)
+	return nil
+}
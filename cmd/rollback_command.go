@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"memo/internal/ui"
+)
+
+type RollbackCommand struct {
+	ctx *CommandContext
+}
+
+func NewRollbackCommand(ctx *CommandContext) *RollbackCommand {
+	return &RollbackCommand{ctx: ctx}
+}
+
+func (c *RollbackCommand) Execute(args []string) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	if len(args) < 2 {
+		return fmt.Errorf("note-id and revision required\nUsage: memo rollback <note-id|number> <revision>")
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(args[0])
+	if err != nil {
+		return err
+	}
+
+	rev, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid revision '%s'", args[1])
+	}
+
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+
+	prompt := ui.Tf("Roll back note '%s' to revision %d? (y/N): ", n.Metadata.Title, rev)
+	if !ui.ConfirmAction(prompt) {
+		fmt.Println("Rollback cancelled.")
+		return nil
+	}
+
+	if _, err := c.ctx.Storage.RollbackNote(noteID, rev); err != nil {
+		return err
+	}
+
+	fmt.Printf("Note rolled back to revision %d.\n", rev)
+	return nil
+}
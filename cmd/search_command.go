@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 
+	"memo/internal/storage"
 	"memo/internal/ui"
 )
 
@@ -15,16 +18,120 @@ func NewSearchCommand(ctx *CommandContext) *SearchCommand {
 }
 
 func (c *SearchCommand) Execute(args []string) error {
-	if len(args) < 1 {
-		return fmt.Errorf("search query required\nUsage: memo search <query>")
+	p, err := parseArgs(args, "--save=", "--saved=", "--delete-saved=", "--limit=", "--context=", "--list-saved", "--case-sensitive", "-s", "--word", "-w", "--any", "--or", "--all", "--and", "--stem", "--no-stem")
+	if err != nil {
+		return err
+	}
+
+	saved := c.ctx.Config.SavedSearches
+	if p.Bool("--list-saved") {
+		ui.DisplaySavedSearches(saved)
+		return nil
+	}
+	if name := p.Value("--delete-saved"); name != "" {
+		if _, ok := saved[name]; !ok {
+			return fmt.Errorf("no saved search named '%s'", name)
+		}
+		delete(saved, name)
+		if err := c.ctx.Config.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Saved search '%s' deleted\n", name)
+		return nil
+	}
+
+	var query string
+	if name := p.Value("--saved"); name != "" {
+		q, ok := saved[name]
+		if !ok {
+			return fmt.Errorf("no saved search named '%s' (see memo search --list-saved)", name)
+		}
+		query = q
+	} else {
+		if len(p.Positional) < 1 {
+			return fmt.Errorf("search query required\nUsage: memo search [--any|--all] [--case-sensitive] [--word] [--stem] [--limit <n>] [--context <n>] [--save <name>] <terms...>")
+		}
+		query = storage.QuoteTerms(p.Positional)
 	}
 
-	query := args[0]
-	notes, err := c.ctx.Storage.SearchNotes(query)
+	if name := p.Value("--save"); name != "" {
+		if c.ctx.Config.SavedSearches == nil {
+			c.ctx.Config.SavedSearches = make(map[string]string)
+		}
+		c.ctx.Config.SavedSearches[name] = query
+		if err := c.ctx.Config.Save(); err != nil {
+			return err
+		}
+		fmt.Printf("Search saved as '%s'\n", name)
+	}
+
+	opts := storage.SearchOptions{
+		CaseSensitive: p.Bool("--case-sensitive", "-s"),
+		WholeWord:     p.Bool("--word", "-w"),
+	}
+	switch mode := c.ctx.Config.SearchMode; {
+	case p.Bool("--any", "--or") && p.Bool("--all", "--and"):
+		return fmt.Errorf("--any and --all cannot be used together")
+	case p.Bool("--any", "--or"):
+		opts.MatchAny = true
+	case p.Bool("--all", "--and"):
+	case mode == "any":
+		opts.MatchAny = true
+	case mode != "" && mode != "all":
+		return fmt.Errorf("unknown search_mode '%s' in config (use all or any)", mode)
+	}
+	// Stems ignore case and word boundaries, so asking for either turns off
+	// stemming from the config
+	exact := opts.CaseSensitive || opts.WholeWord
+	switch {
+	case p.Bool("--stem") && p.Bool("--no-stem"):
+		return fmt.Errorf("--stem and --no-stem cannot be used together")
+	case p.Bool("--stem") && exact:
+		return fmt.Errorf("--stem cannot be used with --case-sensitive or --word")
+	case p.Bool("--stem"):
+		opts.Stem = true
+	case p.Bool("--no-stem"):
+	default:
+		opts.Stem = c.ctx.Config.SearchStemming && !exact
+	}
+	length, err := p.Int("--context", ui.DefaultSnippetLength)
+	if err != nil || length < 1 {
+		return fmt.Errorf("--context must be a positive number")
+	}
+	if v := p.Value("--limit"); v != "" {
+		if opts.Limit, err = strconv.Atoi(v); err != nil || opts.Limit < 1 {
+			return fmt.Errorf("--limit must be a positive number")
+		}
+	}
+	if opts.Limit > 0 {
+		return c.stream(query, opts, length)
+	}
+	notes, err := c.ctx.Storage.SearchNotesWithOptions(query, opts)
 	if err != nil {
 		return fmt.Errorf("error searching notes: %w", err)
 	}
 
-	ui.DisplaySearchResults(notes, query)
+	ui.DisplaySearchResults(notes, query, opts.TermsPattern(query), length)
 	return nil
-}
\ No newline at end of file
+}
+
+// stream shows each note as soon as it is found. With a limit the matches
+// are the first ones found rather than the first in file order anyway, so
+// there is nothing to sort and no reason to wait for the last one.
+func (c *SearchCommand) stream(query string, opts storage.SearchOptions, length int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notes, err := c.ctx.Storage.SearchStream(ctx, query, opts)
+	if err != nil {
+		return fmt.Errorf("error searching notes: %w", err)
+	}
+
+	pattern := opts.TermsPattern(query)
+	count := 0
+	for n := range notes {
+		ui.DisplaySearchResult(n, pattern, length)
+		count++
+	}
+	ui.DisplaySearchCount(count, query)
+	return nil
+}
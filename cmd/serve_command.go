@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"memo/api"
+	"memo/internal/accounts"
+	"memo/internal/grpcapi"
+	"memo/internal/server"
+	"memo/internal/storage"
+)
+
+const defaultServeAddr = "localhost:8080"
+
+type ServeCommand struct {
+	ctx *CommandContext
+}
+
+func NewServeCommand(ctx *CommandContext) *ServeCommand {
+	return &ServeCommand{ctx: ctx}
+}
+
+func (c *ServeCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--addr=", "--grpc-addr=", "--openapi", "--web", "--webdav")
+	if err != nil {
+		return err
+	}
+
+	if p.Bool("--openapi") {
+		_, err := os.Stdout.Write(api.OpenAPI)
+		return err
+	}
+
+	addr := p.Value("--addr")
+	if addr == "" {
+		addr = defaultServeAddr
+	}
+
+	// Requests load notes concurrently; a terminal progress bar makes no
+	// sense there
+	c.ctx.Storage.SetProgress(nil)
+
+	users, err := c.ctx.Config.Users()
+	if err != nil {
+		return err
+	}
+	stores, err := c.userStores(users)
+	if err != nil {
+		return err
+	}
+
+	srv := server.New(c.ctx.Storage, server.Options{Web: p.Bool("--web"), WebDAV: p.Bool("--webdav"), Users: users, Stores: stores})
+	errs := make(chan error, 2)
+
+	if grpcAddr := p.Value("--grpc-addr"); grpcAddr != "" {
+		if len(users) > 0 {
+			return fmt.Errorf("the gRPC service does not support user accounts; remove --grpc-addr or the users of %s", c.ctx.Config.UsersFile())
+		}
+		go func() {
+			errs <- grpcapi.New(c.ctx.Storage).ListenAndServe(grpcAddr)
+		}()
+		fmt.Printf("Serving gRPC (memo.v1.Notes, see api/memo/v1/memo.proto) on %s\n", grpcAddr)
+	}
+
+	go func() {
+		errs <- srv.ListenAndServe(addr)
+	}()
+	fmt.Printf("Serving notes from %s on http://%s\n", c.ctx.Storage.NotesDir(), addr)
+	fmt.Printf("API description at http://%s/api/openapi.yaml\n", addr)
+	if p.Bool("--web") {
+		fmt.Println("Web UI enabled. Use --addr 0.0.0.0:8080 to allow access from other devices.")
+	}
+	if p.Bool("--webdav") {
+		fmt.Printf("WebDAV enabled at http://%s/dav/\n", addr)
+	}
+	if len(users) > 0 {
+		fmt.Printf("Authentication required for %d user(s). Credentials travel unencrypted; put the server behind HTTPS when others can reach it.\n", len(users))
+	}
+
+	return <-errs
+}
+
+// userStores opens the store of each profile a user works in, so requests
+// by that user never touch the shared store
+func (c *ServeCommand) userStores(users []accounts.User) (map[string]*storage.FileStorage, error) {
+	stores := make(map[string]*storage.FileStorage)
+	for _, u := range users {
+		if u.Shared() || stores[u.Profile] != nil {
+			continue
+		}
+		profile, err := c.ctx.Config.Profile(u.Profile)
+		if err != nil {
+			return nil, fmt.Errorf("user '%s': %w", u.Name, err)
+		}
+		if profile.NotesDir == "" {
+			return nil, fmt.Errorf("user '%s': profile '%s' has no notes_dir", u.Name, u.Profile)
+		}
+		fs := newStorage(c.ctx.Config, profile, profile.NotesDir)
+		fs.SetReadOnly(c.ctx.Storage.ReadOnly() || profile.ReadOnly)
+		stores[u.Profile] = fs
+	}
+	return stores, nil
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"memo/internal/server"
+)
+
+const defaultShareAddr = ":0"
+
+type ShareCommand struct {
+	ctx *CommandContext
+}
+
+func NewShareCommand(ctx *CommandContext) *ShareCommand {
+	return &ShareCommand{ctx: ctx}
+}
+
+func (c *ShareCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--addr=", "--for=", "--views=")
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("note-id or number required\nUsage: memo share [--addr host:port] [--for duration] [--views n] <note-id|number>")
+	}
+
+	var lifetime time.Duration
+	if value := p.Value("--for"); value != "" {
+		if lifetime, err = time.ParseDuration(value); err != nil || lifetime <= 0 {
+			return fmt.Errorf("invalid duration for --for: %s", value)
+		}
+	}
+	views, err := p.Int("--views", 0)
+	if err != nil || views < 0 {
+		return fmt.Errorf("invalid value for --views: %s", p.Value("--views"))
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(p.Positional[0])
+	if err != nil {
+		return err
+	}
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	if err := c.ctx.UnlockNote(n); err != nil {
+		return err
+	}
+
+	share, err := server.NewShare(n, views)
+	if err != nil {
+		return fmt.Errorf("error creating share link: %w", err)
+	}
+
+	addr := p.Value("--addr")
+	if addr == "" {
+		addr = defaultShareAddr
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{Handler: share, ReadHeaderTimeout: 10 * time.Second}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(listener)
+	}()
+
+	fmt.Printf("Sharing '%s' at:\n", n.Metadata.Title)
+	for _, url := range shareURLs(listener.Addr().(*net.TCPAddr), share.Path()) {
+		fmt.Printf("  %s\n", url)
+	}
+	switch {
+	case views > 0 && lifetime > 0:
+		fmt.Printf("The link stops working after %d view(s) or %s.\n", views, lifetime)
+	case views > 0:
+		fmt.Printf("The link stops working after %d view(s).\n", views)
+	case lifetime > 0:
+		fmt.Printf("The link stops working after %s.\n", lifetime)
+	default:
+		fmt.Println("Press Ctrl+C to stop sharing.")
+	}
+
+	var expired <-chan time.Time
+	if lifetime > 0 {
+		expired = time.After(lifetime)
+	}
+
+	select {
+	case err := <-errs:
+		return err
+	case <-share.Done():
+	case <-expired:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	fmt.Printf("Stopped sharing after %d view(s).\n", share.Views())
+	return nil
+}
+
+// shareURLs lists the URLs under which a listener can be reached; for a
+// wildcard address that is every non-loopback IPv4 address of this machine
+func shareURLs(addr *net.TCPAddr, path string) []string {
+	if !addr.IP.IsUnspecified() {
+		return []string{fmt.Sprintf("http://%s%s", addr, path)}
+	}
+
+	var urls []string
+	if ifaces, err := net.InterfaceAddrs(); err == nil {
+		for _, ia := range ifaces {
+			ipnet, ok := ia.(*net.IPNet)
+			if !ok || ipnet.IP.IsLoopback() || ipnet.IP.To4() == nil {
+				continue
+			}
+			urls = append(urls, fmt.Sprintf("http://%s%s", net.JoinHostPort(ipnet.IP.String(), fmt.Sprint(addr.Port)), path))
+		}
+	}
+	return append(urls, fmt.Sprintf("http://localhost:%d%s", addr.Port, path))
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"memo/internal/note"
+)
+
+// SplitCommand breaks a large note up by headings into child notes, leaving
+// the note itself with its introduction and links to the children
+type SplitCommand struct {
+	ctx *CommandContext
+}
+
+func NewSplitCommand(ctx *CommandContext) *SplitCommand {
+	return &SplitCommand{ctx: ctx}
+}
+
+func (c *SplitCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--level=", "--dry-run")
+	if err != nil {
+		return fmt.Errorf("%v\nUsage: memo split [--level <1-6>] [--dry-run] <note>", err)
+	}
+	if len(p.Positional) != 1 {
+		return fmt.Errorf("note-id or number required\nUsage: memo split [--level <1-6>] [--dry-run] <note>")
+	}
+	dryRun := p.Bool("--dry-run") || c.ctx.DryRun
+	if !dryRun {
+		if err := c.ctx.Storage.CheckWritable(); err != nil {
+			return err
+		}
+	}
+
+	level := 0
+	if value := p.Value("--level"); value != "" {
+		if level, err = strconv.Atoi(value); err != nil || level < 1 || level > 6 {
+			return fmt.Errorf("--level must be a heading level from 1 to 6")
+		}
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(p.Positional[0])
+	if err != nil {
+		return err
+	}
+	parent, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+	if parent.Metadata.Encrypted {
+		return fmt.Errorf("cannot split encrypted note '%s'; decrypt it first", parent.Metadata.Title)
+	}
+
+	intro, sections, err := parent.Sections(level)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("Would split '%s' into %d note(s):\n", parent.Metadata.Title, len(sections))
+		for i, s := range sections {
+			fmt.Printf("  %s (%d bytes)\n", sectionTitle(s, i), len(s.Content))
+		}
+		return nil
+	}
+
+	tx, err := c.ctx.BeginTransaction(false)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	notebook := c.ctx.Storage.NotebookOf(parent)
+	links := make([]string, 0, len(sections))
+	for i, s := range sections {
+		title := sectionTitle(s, i)
+		child := note.New(title, s.Content, parent.Metadata.Tags)
+		child.Metadata.Author = parent.Metadata.Author
+		child.SetField("parent", noteID)
+		childID, err := c.ctx.Storage.CreateNoteIn(child, notebook)
+		if err != nil {
+			return fmt.Errorf("error creating note for '%s': %w", title, err)
+		}
+		links = append(links, fmt.Sprintf("- [[%s|%s]]", childID, title))
+		fmt.Printf("Created %s: %s\n", childID, title)
+	}
+
+	content := strings.Join(links, "\n")
+	if intro != "" {
+		content = intro + "\n\n" + content
+	}
+	parent.UpdateContent(content)
+	if err := c.ctx.Storage.SaveNote(parent); err != nil {
+		return fmt.Errorf("error saving note: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Split '%s' into %d note(s); it now links to them.\n", parent.Metadata.Title, len(sections))
+	c.ctx.RecordAccess(noteID, "edited")
+	return nil
+}
+
+// sectionTitle is the heading of a section, or a numbered placeholder for
+// an empty heading
+func sectionTitle(s note.Section, i int) string {
+	if title := strings.TrimSpace(s.Heading); title != "" {
+		return title
+	}
+	return fmt.Sprintf("Part %d", i+1)
+}
@@ -2,7 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strconv"
+	"time"
 
+	"memo/internal/note"
+	"memo/internal/stats"
 	"memo/internal/ui"
 )
 
@@ -15,11 +20,219 @@ func NewStatsCommand(ctx *CommandContext) *StatsCommand {
 }
 
 func (c *StatsCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--format=", "--since=", "--until=", "--tag=", "--top=", "--weeks=", "--months=", "--compare", "--authors", "--words", "--tags-over-time", "--dashboard")
+	if err != nil {
+		return err
+	}
+
+	period, err := stats.ParsePeriod(p.Value("--since"), p.Value("--until"))
+	if err != nil {
+		return err
+	}
+
 	notes, err := c.ctx.Storage.GetAllNotes()
 	if err != nil {
 		return fmt.Errorf("error loading notes: %w", err)
 	}
 
-	ui.DisplayStats(notes)
+	if p.Bool("--compare") {
+		return c.compare(notes, period, p.Value("--format"))
+	}
+	if p.Bool("--authors") {
+		return c.authors(period, period.Filter(notes), p.Value("--top"), p.Value("--format"))
+	}
+	if p.Bool("--words") {
+		return c.words(period.Filter(notes), p.Value("--tag"), p.Value("--top"), p.Value("--format"))
+	}
+	if p.Bool("--tags-over-time") {
+		if !period.IsZero() {
+			return fmt.Errorf("--tags-over-time takes --months instead of --since and --until\n%s", tagTrendsUsage)
+		}
+		return c.tagTrends(notes, p.Value("--months"), p.Value("--top"), p.Value("--format"))
+	}
+	if p.Bool("--dashboard") {
+		return c.dashboard(period.Filter(notes), p.Value("--weeks"), p.Value("--top"), p.Value("--format"))
+	}
+
+	s := stats.Compute(period.Filter(notes))
+	if !period.IsZero() {
+		s.Period = &period
+	}
+
+	switch format := p.Value("--format"); format {
+	case "", "text":
+		ui.DisplayStats(s)
+	case "json":
+		return s.WriteJSON(os.Stdout)
+	case "csv":
+		return s.WriteCSV(os.Stdout)
+	default:
+		return fmt.Errorf("unknown format '%s' (use text, json or csv)", format)
+	}
+	return nil
+}
+
+// compare shows how the period grew against the one of equal length before
+// it; an open end is taken to be today
+func (c *StatsCommand) compare(notes []*note.Note, period stats.Period, format string) error {
+	if period.Since.IsZero() {
+		return fmt.Errorf("--compare needs a --since date\nUsage: memo stats --since YYYY-MM-DD [--until YYYY-MM-DD] --compare")
+	}
+	if period.Until.IsZero() {
+		y, m, d := time.Now().Date()
+		period.Until = time.Date(y, m, d+1, 0, 0, 0, 0, time.Local)
+	}
+
+	cmp := stats.Compare(notes, period)
+
+	switch format {
+	case "", "text":
+		ui.DisplayStatsComparison(cmp)
+	case "json":
+		return cmp.WriteJSON(os.Stdout)
+	case "csv":
+		return cmp.WriteCSV(os.Stdout)
+	default:
+		return fmt.Errorf("unknown format '%s' (use text, json or csv)", format)
+	}
+	return nil
+}
+
+// authors breaks the notes down by author, with each author's most used
+// tags
+func (c *StatsCommand) authors(period stats.Period, notes []*note.Note, top, format string) error {
+	limit := stats.DefaultAuthorTags
+	if top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil || n < 1 {
+			return fmt.Errorf("--top must be a positive number\nUsage: memo stats --authors [--top <n>]")
+		}
+		limit = n
+	}
+
+	ab := stats.Authors(notes, limit)
+	if !period.IsZero() {
+		ab.Period = &period
+	}
+
+	switch format {
+	case "", "text":
+		ui.DisplayAuthorStats(ab)
+	case "json":
+		return ab.WriteJSON(os.Stdout)
+	case "csv":
+		return ab.WriteCSV(os.Stdout)
+	default:
+		return fmt.Errorf("unknown format '%s' (use text, json or csv)", format)
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// dashboard charts note creation week by week and shows the most used tags
+// and the largest notes
+func (c *StatsCommand) dashboard(notes []*note.Note, weeks, top, format string) error {
+	const usage = "Usage: memo stats --dashboard [--weeks <n>] [--top <n>] [--format text|json]"
+	weekCount := stats.DefaultDashboardWeeks
+	if weeks != "" {
+		n, err := strconv.Atoi(weeks)
+		if err != nil || n < 1 {
+			return fmt.Errorf("--weeks must be a positive number\n%s", usage)
+		}
+		weekCount = n
+	}
+	limit := stats.DefaultDashboardTop
+	if top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil || n < 1 {
+			return fmt.Errorf("--top must be a positive number\n%s", usage)
+		}
+		limit = n
+	}
+
+	d := stats.NewDashboard(notes, weekCount, limit, time.Now())
+
+	switch format {
+	case "", "text":
+		ui.DisplayDashboard(d)
+	case "json":
+		return d.WriteJSON(os.Stdout)
+	default:
+		return fmt.Errorf("unknown format '%s' (use text or json)", format)
+	}
+	return nil
+}
+
+const tagTrendsUsage = "Usage: memo stats --tags-over-time [--months <n>] [--top <n>] [--format text|table|json|csv]"
+
+// tagTrends charts the use of the most used tags month by month and names
+// the tags that appeared or faded recently
+func (c *StatsCommand) tagTrends(notes []*note.Note, months, top, format string) error {
+	monthCount := stats.DefaultTrendMonths
+	if months != "" {
+		n, err := strconv.Atoi(months)
+		if err != nil || n < 2 {
+			return fmt.Errorf("--months must be a number of at least 2\n%s", tagTrendsUsage)
+		}
+		monthCount = n
+	}
+	limit := stats.DefaultTrendTop
+	if top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil || n < 1 {
+			return fmt.Errorf("--top must be a positive number\n%s", tagTrendsUsage)
+		}
+		limit = n
+	}
+
+	t := stats.NewTagTrends(notes, monthCount, limit, time.Now())
+
+	switch format {
+	case "", "text":
+		ui.DisplayTagTrends(t, false)
+	case "table":
+		ui.DisplayTagTrends(t, true)
+	case "json":
+		return t.WriteJSON(os.Stdout)
+	case "csv":
+		return t.WriteCSV(os.Stdout)
+	default:
+		return fmt.Errorf("unknown format '%s' (use text, table, json or csv)", format)
+	}
+	return nil
+}
+
+// words shows the most frequent terms, optionally only in notes carrying tag
+func (c *StatsCommand) words(notes []*note.Note, tag, top, format string) error {
+	limit := stats.DefaultTopWords
+	if top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil || n < 1 {
+			return fmt.Errorf("--top must be a positive number\nUsage: memo stats --words [--tag <tag>] [--top <n>]")
+		}
+		limit = n
+	}
+
+	if tag != "" {
+		var tagged []*note.Note
+		for _, n := range notes {
+			if n.HasTag(tag) {
+				tagged = append(tagged, n)
+			}
+		}
+		notes = tagged
+	}
+
+	wf := stats.Words(notes, limit)
+
+	switch format {
+	case "", "text":
+		ui.DisplayWordFrequency(wf, tag)
+	case "json":
+		return wf.WriteJSON(os.Stdout)
+	case "csv":
+		return wf.WriteCSV(os.Stdout)
+	default:
+		return fmt.Errorf("unknown format '%s' (use text, json or csv)", format)
+	}
+	return nil
+}
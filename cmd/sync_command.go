@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"memo/internal/notesync"
+	"memo/internal/ui"
+)
+
+type SyncCommand struct {
+	ctx *CommandContext
+}
+
+func NewSyncCommand(ctx *CommandContext) *SyncCommand {
+	return &SyncCommand{ctx: ctx}
+}
+
+func (c *SyncCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--prefer=", "--dry-run", "-n")
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("directory required\nUsage: memo sync [--dry-run] [--prefer local|remote] <dir>")
+	}
+	prefer := p.Value("--prefer")
+	if prefer != "" && prefer != "local" && prefer != "remote" {
+		return fmt.Errorf("--prefer must be 'local' or 'remote'")
+	}
+	dryRun := p.Bool("--dry-run", "-n") || c.ctx.DryRun
+	if !dryRun {
+		if err := c.ctx.Storage.CheckWritable(); err != nil {
+			return err
+		}
+	}
+
+	dir, err := filepath.Abs(p.Positional[0])
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return fmt.Errorf("'%s' is not a directory", dir)
+	}
+
+	statePath := c.ctx.Storage.StorePath(notesync.StateFileName)
+	state, err := notesync.LoadState(statePath)
+	if err != nil {
+		return err
+	}
+	base := state[dir]
+	ext := c.ctx.Storage.NoteExtension()
+
+	local, err := c.ctx.Storage.CurrentIndex()
+	if err != nil {
+		return err
+	}
+	remote, err := notesync.Scan(dir, ext, base)
+	if err != nil {
+		return err
+	}
+
+	steps := notesync.Plan(local, remote, base)
+	for i, step := range steps {
+		if step.Action != notesync.Conflict {
+			continue
+		}
+		switch prefer {
+		case "local":
+			steps[i].Action = notesync.Upload
+		case "remote":
+			steps[i].Action = notesync.Download
+		}
+	}
+
+	ui.DisplaySyncPlan(steps, dryRun)
+	if dryRun {
+		return nil
+	}
+
+	var conflicts []string
+	for _, step := range steps {
+		if step.Action == notesync.Conflict {
+			conflicts = append(conflicts, step.ID)
+			continue
+		}
+		if err := c.apply(step, dir, ext); err != nil {
+			return fmt.Errorf("error syncing %s: %w", step.ID, err)
+		}
+		// A copy may get the same size and timestamp as the file it replaced;
+		// make sure it is hashed again
+		delete(remote, step.ID)
+	}
+
+	// The target now matches the store, except for skipped conflicts, which
+	// keep their old base so the next sync reports them again
+	synced, err := notesync.Scan(dir, ext, remote)
+	if err != nil {
+		return err
+	}
+	for _, id := range conflicts {
+		if entry, ok := base[id]; ok {
+			synced[id] = entry
+		} else {
+			delete(synced, id)
+		}
+	}
+	state[dir] = synced
+	if err := notesync.SaveState(statePath, state); err != nil {
+		return err
+	}
+	if err := notesync.SaveBases(c.ctx.Storage.StorePath(notesync.BaseDirName), dir, ext, synced, state); err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+
+	fmt.Printf("Synced %d note(s) with %s\n", len(steps)-len(conflicts), dir)
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%d note(s) changed on both sides were skipped; run memo resolve <note-id> or rerun with --prefer local or --prefer remote", len(conflicts))
+	}
+	return nil
+}
+
+func (c *SyncCommand) apply(step notesync.Step, dir, ext string) error {
+	remotePath := filepath.Join(dir, step.ID+ext)
+
+	switch step.Action {
+	case notesync.Upload:
+		return notesync.CopyFile(c.ctx.Storage.GenerateNoteFilePath(step.ID), remotePath)
+	case notesync.Download:
+		data, err := os.ReadFile(remotePath)
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(remotePath)
+		if err != nil {
+			return err
+		}
+		return c.ctx.Storage.WriteNoteFile(step.ID, data, info.ModTime())
+	case notesync.DeleteRemote:
+		return os.Remove(remotePath)
+	case notesync.DeleteLocal:
+		return c.ctx.Storage.DeleteNote(step.ID)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+const tagUsage = "Usage: memo tag add|remove <note-id|number|title> <tag>..."
+
+// TagCommand adds or removes single tags on a note, keeping its other tags,
+// without an interactive edit session
+type TagCommand struct {
+	ctx *CommandContext
+}
+
+func NewTagCommand(ctx *CommandContext) *TagCommand {
+	return &TagCommand{ctx: ctx}
+}
+
+func (c *TagCommand) Execute(args []string) error {
+	p, err := parseArgs(args)
+	if err != nil {
+		return err
+	}
+	if len(p.Positional) < 3 {
+		return fmt.Errorf("action, note and tag required\n%s", tagUsage)
+	}
+
+	action := p.Positional[0]
+	if action != "add" && action != "remove" && action != "rm" {
+		return fmt.Errorf("unknown tag action '%s'\n%s", action, tagUsage)
+	}
+	var tags []string
+	for _, arg := range p.Positional[2:] {
+		tags = append(tags, splitTags(arg)...)
+	}
+	if len(tags) == 0 {
+		return fmt.Errorf("tag required\n%s", tagUsage)
+	}
+
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+	noteID, err := c.ctx.ResolveNoteID(p.Positional[1])
+	if err != nil {
+		return err
+	}
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+
+	var changed, unchanged []string
+	for _, tag := range tags {
+		if action == "add" && n.AddTag(tag) || action != "add" && n.RemoveTag(tag) {
+			changed = append(changed, tag)
+		} else {
+			unchanged = append(unchanged, tag)
+		}
+	}
+
+	if len(unchanged) > 0 {
+		if action == "add" {
+			fmt.Printf("Note %s already has: %s\n", noteID, strings.Join(unchanged, ", "))
+		} else {
+			fmt.Printf("Note %s does not have: %s\n", noteID, strings.Join(unchanged, ", "))
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if err := c.ctx.Storage.SaveNote(n); err != nil {
+		return fmt.Errorf("error saving note: %w", err)
+	}
+	fmt.Printf("Tags of %s: %s\n", noteID, strings.Join(n.Metadata.Tags, ", "))
+	c.ctx.RecordAccess(noteID, "edited")
+	return nil
+}
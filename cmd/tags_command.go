@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"memo/internal/note"
+	"memo/internal/ui"
+)
+
+type TagsCommand struct {
+	ctx *CommandContext
+}
+
+func NewTagsCommand(ctx *CommandContext) *TagsCommand {
+	return &TagsCommand{ctx: ctx}
+}
+
+func (c *TagsCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--merge-hashtags", "--tree")
+	if err != nil {
+		return err
+	}
+
+	notes, err := c.ctx.Storage.GetAllNotes()
+	if err != nil {
+		return fmt.Errorf("error loading notes: %w", err)
+	}
+
+	if p.Bool("--merge-hashtags") {
+		return c.mergeHashtags(notes)
+	}
+
+	if p.Bool("--tree") {
+		ui.DisplayTagTree(notes)
+	} else {
+		ui.DisplayTags(notes)
+	}
+	return nil
+}
+
+// mergeHashtags adds the #hashtags in each note's content to its tags, for
+// notes written before merge_hashtags was enabled
+func (c *TagsCommand) mergeHashtags(notes []*note.Note) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	updated := 0
+	for _, n := range notes {
+		if !n.MergeHashtags() {
+			continue
+		}
+		if err := c.ctx.Storage.SaveNote(n); err != nil {
+			return fmt.Errorf("error saving note %s: %w", n.ID(), err)
+		}
+		fmt.Printf("%s: %s\n", n.ID(), strings.Join(n.Metadata.Tags, ", "))
+		updated++
+	}
+	if c.ctx.DryRun {
+		fmt.Printf("Would add hashtags as tags to %d note(s)\n", updated)
+		return nil
+	}
+	fmt.Printf("Added hashtags as tags to %d note(s)\n", updated)
+	return nil
+}
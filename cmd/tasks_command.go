@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"memo/internal/ui"
+)
+
+type TasksCommand struct {
+	ctx *CommandContext
+}
+
+func NewTasksCommand(ctx *CommandContext) *TasksCommand {
+	return &TasksCommand{ctx: ctx}
+}
+
+func (c *TasksCommand) Execute(args []string) error {
+	if len(args) > 0 && (args[0] == "done" || args[0] == "undo") {
+		return c.setDone(args[1:], args[0] == "done")
+	}
+
+	p, err := parseArgs(args, "--all")
+	if err != nil {
+		return err
+	}
+
+	notes, err := c.ctx.Storage.GetAllNotes()
+	if err != nil {
+		return fmt.Errorf("error loading notes: %w", err)
+	}
+
+	ui.DisplayTasks(notes, p.Bool("--all"))
+	return nil
+}
+
+func (c *TasksCommand) setDone(args []string, done bool) error {
+	if err := c.ctx.Storage.CheckWritable(); err != nil {
+		return err
+	}
+
+	if len(args) < 2 {
+		return fmt.Errorf("note-id and task number required\nUsage: memo tasks done <note-id|number> <task>")
+	}
+
+	noteID, err := c.ctx.ResolveNoteID(args[0])
+	if err != nil {
+		return err
+	}
+
+	number, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid task number '%s'", args[1])
+	}
+
+	n, err := c.ctx.Storage.FindNoteByID(noteID)
+	if err != nil {
+		return err
+	}
+
+	if err := n.SetTaskDone(number, done); err != nil {
+		return err
+	}
+
+	if err := c.ctx.Storage.SaveNote(n); err != nil {
+		return fmt.Errorf("error saving note: %w", err)
+	}
+
+	task := n.Tasks()[number-1]
+	if done {
+		fmt.Printf("Completed: %s\n", task.Text)
+	} else {
+		fmt.Printf("Reopened: %s\n", task.Text)
+	}
+	return nil
+}
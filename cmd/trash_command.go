@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+
+	"memo/internal/ui"
+)
+
+// TrashCommand shows and manages the notes `memo delete` moved to the trash
+type TrashCommand struct {
+	ctx *CommandContext
+}
+
+func NewTrashCommand(ctx *CommandContext) *TrashCommand {
+	return &TrashCommand{ctx: ctx}
+}
+
+const trashUsage = `Usage:
+  memo trash status
+  memo trash list
+  memo trash restore <note-id>
+  memo trash empty`
+
+func (c *TrashCommand) Execute(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("subcommand required\n%s", trashUsage)
+	}
+
+	fs := c.ctx.Storage
+	switch args[0] {
+	case "status":
+		entries, err := fs.TrashEntries()
+		if err != nil {
+			return err
+		}
+		ui.DisplayTrashStatus(entries, fs.TrashPolicy())
+	case "list":
+		entries, err := fs.TrashEntries()
+		if err != nil {
+			return err
+		}
+		ui.DisplayTrash(entries, fs.TrashPolicy())
+	case "restore":
+		if len(args) < 2 {
+			return fmt.Errorf("note-id required\nUsage: memo trash restore <note-id>")
+		}
+		n, err := fs.RestoreNote(args[1])
+		if err != nil {
+			return err
+		}
+		if !c.ctx.DryRun {
+			fmt.Printf("Restored '%s' as %s\n", n.Metadata.Title, n.ID())
+		}
+	case "empty":
+		entries, err := fs.TrashEntries()
+		if err != nil || len(entries) == 0 {
+			if err == nil {
+				fmt.Println("The trash is empty.")
+			}
+			return err
+		}
+		if !c.ctx.DryRun && !ui.ConfirmAction(ui.Tf("Delete the %d note(s) in the trash for good? (y/N): ", len(entries))) {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+		removed, err := fs.EmptyTrash()
+		if err != nil {
+			return err
+		}
+		if !c.ctx.DryRun {
+			fmt.Printf("Deleted %d note(s) for good\n", len(removed))
+		}
+	default:
+		return fmt.Errorf("unknown subcommand '%s'\n%s", args[0], trashUsage)
+	}
+	return nil
+}
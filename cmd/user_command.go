@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"memo/internal/accounts"
+	"memo/internal/config"
+	"memo/internal/ui"
+)
+
+// UserCommand manages the accounts of `memo serve` in the users file
+type UserCommand struct {
+	ctx *CommandContext
+}
+
+func NewUserCommand(ctx *CommandContext) *UserCommand {
+	return &UserCommand{ctx: ctx}
+}
+
+const userUsage = `Usage:
+  memo user add <name> [--store <profile>] [--admin]
+  memo user passwd <name>
+  memo user token <name>
+  memo user remove <name>
+  memo user list`
+
+func (c *UserCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--store=", "--admin")
+	if err != nil {
+		return fmt.Errorf("%v\n%s", err, userUsage)
+	}
+	if len(p.Positional) < 1 {
+		return fmt.Errorf("subcommand required\n%s", userUsage)
+	}
+
+	path := c.ctx.Config.UsersFile()
+	users, err := accounts.Load(path)
+	if err != nil {
+		return err
+	}
+
+	action := p.Positional[0]
+	if action == "list" {
+		c.list(users)
+		return nil
+	}
+	if len(p.Positional) < 2 {
+		return fmt.Errorf("user name required\n%s", userUsage)
+	}
+	name := p.Positional[1]
+
+	switch action {
+	case "add":
+		if err := accounts.ValidateName(name); err != nil {
+			return err
+		}
+		if accounts.Find(users, name) != nil || c.inConfig(name) {
+			return fmt.Errorf("user '%s' already exists", name)
+		}
+		u := accounts.User{Name: name, Profile: p.Value("--store"), Admin: p.Bool("--admin")}
+		if u.Profile != "" {
+			if _, err := c.ctx.Config.Profile(u.Profile); err != nil {
+				return err
+			}
+		}
+		if err := setPassword(&u); err != nil {
+			return err
+		}
+		users = append(users, u)
+		fmt.Printf("User '%s' added to %s\n", name, path)
+	case "passwd", "token", "remove", "rm":
+		u := accounts.Find(users, name)
+		if u == nil {
+			if c.inConfig(name) {
+				return fmt.Errorf("user '%s' is defined in %s; change it there", name, config.Path())
+			}
+			return fmt.Errorf("unknown user '%s'", name)
+		}
+		switch action {
+		case "passwd":
+			if err := setPassword(u); err != nil {
+				return err
+			}
+			fmt.Printf("Password of '%s' changed\n", u.Name)
+		case "token":
+			token, err := u.NewToken()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("API token for '%s' (shown only once):\n%s\n", u.Name, token)
+			fmt.Println("Send it as 'Authorization: Bearer <token>' or as the password of basic authentication.")
+		default:
+			users = slices.DeleteFunc(users, func(other accounts.User) bool { return other.Name == u.Name })
+			fmt.Printf("User '%s' removed\n", name)
+		}
+	default:
+		return fmt.Errorf("unknown subcommand '%s'\n%s", action, userUsage)
+	}
+
+	return accounts.Save(path, users)
+}
+
+// inConfig reports whether the configuration file itself defines a user
+// called name
+func (c *UserCommand) inConfig(name string) bool {
+	return c.ctx.Config.Server != nil && accounts.Find(c.ctx.Config.Server.Users, name) != nil
+}
+
+func (c *UserCommand) list(users []accounts.User) {
+	all, err := c.ctx.Config.Users()
+	if err == nil {
+		users = all
+	}
+	if len(users) == 0 {
+		fmt.Println("No users; memo serve accepts requests without authentication.")
+		return
+	}
+	for _, u := range users {
+		var details []string
+		if u.Shared() {
+			details = append(details, "shared store")
+		} else {
+			details = append(details, "store of profile '"+u.Profile+"'")
+		}
+		if u.Admin {
+			details = append(details, "admin")
+		}
+		if len(u.Tokens) > 0 {
+			details = append(details, fmt.Sprintf("%d token(s)", len(u.Tokens)))
+		}
+		fmt.Printf("%s (%s)\n", u.Name, strings.Join(details, ", "))
+	}
+}
+
+// setPassword prompts for a new password, twice
+func setPassword(u *accounts.User) error {
+	fmt.Printf("Setting the password of '%s'\n", u.Name)
+	password := ui.PromptForSecret("Enter password: ")
+	if password == "" {
+		return fmt.Errorf("password must not be empty")
+	}
+	if ui.PromptForSecret("Confirm password: ") != password {
+		return fmt.Errorf("passwords do not match")
+	}
+	return u.SetPassword(password)
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"memo/internal/backup"
+	"memo/internal/config"
+	"memo/internal/index"
+)
+
+const (
+	defaultWatchPoll        = 2 * time.Second
+	defaultSnapshotInterval = 24 * time.Hour
+	defaultSnapshotKeep     = 7
+	// snapshotRetry is how soon an unchanged store is checked again once a
+	// snapshot is due
+	snapshotRetry = time.Minute
+)
+
+const watchUsage = "Usage: memo watch [--poll <duration>] [--snapshot-interval <duration>] [--keep <n>] [--dir <dir>] [--format tar.gz|zip] [--no-snapshots]"
+
+// snapshotSettings say when and where `memo watch` backs up the store
+type snapshotSettings struct {
+	interval time.Duration
+	keep     int
+	dir      string
+	format   backup.Format
+}
+
+// WatchCommand runs until interrupted, reporting note files that change and
+// backing up the store at a regular interval
+type WatchCommand struct {
+	ctx *CommandContext
+	// nextSnapshot is when the store is next checked for a snapshot
+	nextSnapshot time.Time
+}
+
+func NewWatchCommand(ctx *CommandContext) *WatchCommand {
+	return &WatchCommand{ctx: ctx}
+}
+
+func (c *WatchCommand) Execute(args []string) error {
+	p, err := parseArgs(args, "--poll=", "--snapshot-interval=", "--keep=", "--dir=", "--format=", "--no-snapshots")
+	if err != nil {
+		return fmt.Errorf("%v\n%s", err, watchUsage)
+	}
+
+	poll := defaultWatchPoll
+	if value := p.Value("--poll"); value != "" {
+		if poll, err = time.ParseDuration(value); err != nil || poll <= 0 {
+			return fmt.Errorf("invalid --poll duration '%s'\n%s", value, watchUsage)
+		}
+	}
+	snapshots, err := c.snapshotSettings(p)
+	if err != nil {
+		return err
+	}
+
+	// Progress output makes no sense for a long-running process
+	c.ctx.Storage.SetProgress(nil)
+
+	known, err := c.ctx.Storage.CurrentIndex()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Watching %s for changes (press Ctrl-C to stop)\n", c.ctx.Storage.NotesDir())
+	if snapshots.interval > 0 {
+		fmt.Printf("Snapshots every %s into %s, keeping the newest %d\n", snapshots.interval, snapshots.dir, snapshots.keep)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		if snapshots.interval > 0 {
+			if err := c.snapshot(snapshots, time.Now()); err != nil {
+				slog.Warn("snapshot failed", "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("Stopped watching.")
+			return nil
+		case <-ticker.C:
+		}
+
+		if err := c.reportChanges(known); err != nil {
+			slog.Warn("could not check for changes", "error", err)
+		}
+	}
+}
+
+// snapshotSettings combines the snapshots section of the configuration with
+// the command line flags
+func (c *WatchCommand) snapshotSettings(p *parsedArgs) (snapshotSettings, error) {
+	cfg := config.Snapshots{}
+	if c.ctx.Config != nil && c.ctx.Config.Snapshots != nil {
+		cfg = *c.ctx.Config.Snapshots
+	}
+	for flag, value := range map[string]*string{"--snapshot-interval": &cfg.Interval, "--dir": &cfg.Dir, "--format": &cfg.Format} {
+		if v := p.Value(flag); v != "" {
+			*value = v
+		}
+	}
+	if v := p.Value("--keep"); v != "" {
+		keep, err := strconv.Atoi(v)
+		if err != nil || keep < 1 {
+			return snapshotSettings{}, fmt.Errorf("--keep must be a positive number\n%s", watchUsage)
+		}
+		cfg.Keep = keep
+	}
+
+	s := snapshotSettings{interval: defaultSnapshotInterval, keep: cfg.Keep, dir: config.ExpandHome(cfg.Dir)}
+	if p.Bool("--no-snapshots") || cfg.Interval == "0" {
+		s.interval = 0
+	} else if cfg.Interval != "" {
+		interval, err := time.ParseDuration(cfg.Interval)
+		if err != nil || interval < 0 {
+			return snapshotSettings{}, fmt.Errorf("invalid snapshot interval '%s' (use a duration like 6h or 24h)", cfg.Interval)
+		}
+		s.interval = interval
+	}
+	if s.keep == 0 {
+		s.keep = defaultSnapshotKeep
+	}
+	if s.dir == "" {
+		s.dir = defaultBackupDir(c.ctx.Storage.NotesDir())
+	}
+	format, err := backup.ParseFormat(cfg.Format)
+	if err != nil {
+		return snapshotSettings{}, err
+	}
+	s.format = format
+	return s, nil
+}
+
+// snapshot backs up the store once the interval since the newest backup has
+// passed, unless nothing changed since then, and deletes old backups
+func (c *WatchCommand) snapshot(s snapshotSettings, now time.Time) error {
+	if now.Before(c.nextSnapshot) {
+		return nil
+	}
+
+	backups, err := backup.List(s.dir)
+	if err != nil {
+		return err
+	}
+	if len(backups) > 0 {
+		last := backups[0].Created
+		if due := last.Add(s.interval); now.Before(due) {
+			c.nextSnapshot = due
+			return nil
+		}
+		changed, err := backup.ChangedSince(c.ctx.Storage.NotesDir(), last)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			c.nextSnapshot = now.Add(min(snapshotRetry, s.interval))
+			return nil
+		}
+	}
+
+	archive, count, err := backup.Create(c.ctx.Storage.NotesDir(), config.Path(), s.dir, s.format, now)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s  snapshot %s (%d files)\n", now.Format("15:04:05"), filepath.Base(archive), count)
+	c.nextSnapshot = now.Add(s.interval)
+
+	removed, err := backup.Rotate(s.dir, s.keep)
+	for _, path := range removed {
+		fmt.Printf("%s  removed old snapshot %s\n", now.Format("15:04:05"), filepath.Base(path))
+	}
+	return err
+}
+
+// reportChanges prints the note files created, modified or deleted since
+// the last check and updates known to match
+func (c *WatchCommand) reportChanges(known index.Index) error {
+	files, err := c.ctx.Storage.NoteFiles()
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]string, len(files))
+	for _, file := range files {
+		byID[strings.TrimSuffix(filepath.Base(file), c.ctx.Storage.NoteExtension())] = file
+	}
+
+	changes, err := known.Diff(byID)
+	if err != nil {
+		return err
+	}
+	stamp := time.Now().Format("15:04:05")
+	for _, change := range changes {
+		switch change.Status {
+		case index.Missing:
+			delete(known, change.ID)
+			fmt.Printf("%s  deleted  %s\n", stamp, change.ID)
+		case index.Touched:
+			known[change.ID] = change.Entry
+		default:
+			known[change.ID] = change.Entry
+			fmt.Printf("%s  %-8s %s\n", stamp, change.Status, change.ID)
+		}
+	}
+	return nil
+}
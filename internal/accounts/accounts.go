@@ -0,0 +1,209 @@
+// Package accounts manages the user accounts of `memo serve`: hashed
+// passwords and API tokens, and the store each user works in.
+package accounts
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the users file kept next to the configuration file
+const FileName = "users.yaml"
+
+const (
+	// passwordScheme prefixes password hashes, followed by the iteration
+	// count, salt and key
+	passwordScheme = "pbkdf2-sha256"
+	saltSize       = 16
+	keySize        = 32
+	iterations     = 600000
+	// TokenPrefix starts every API token, which makes tokens easy to spot
+	// in scripts and logs
+	TokenPrefix = "memo_"
+)
+
+// User is an account of the HTTP server
+type User struct {
+	Name string `yaml:"name"`
+	// Password is the hash of the user's password
+	Password string `yaml:"password,omitempty"`
+	// Tokens are the SHA-256 hashes of the user's API tokens
+	Tokens []string `yaml:"tokens,omitempty"`
+	// Profile gives the user the notes store of a profile of their own.
+	// Users without one share the server's store, where they may only
+	// change the notes they are the author of.
+	Profile string `yaml:"profile,omitempty"`
+	// Admin users may change every note in the shared store
+	Admin bool `yaml:"admin,omitempty"`
+}
+
+// Shared reports whether the user works in the server's shared store
+func (u *User) Shared() bool {
+	return u.Profile == ""
+}
+
+// Owns reports whether the user may change a note by author: admins and
+// users with a store of their own may change any note, others only the
+// notes without an author or authored by them
+func (u *User) Owns(author string) bool {
+	return u.Admin || !u.Shared() || author == "" || strings.EqualFold(author, u.Name)
+}
+
+// SetPassword replaces the user's password
+func (u *User) SetPassword(password string) error {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("error generating salt: %w", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, keySize)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+	enc := base64.RawStdEncoding
+	u.Password = strings.Join([]string{passwordScheme, strconv.Itoa(iterations), enc.EncodeToString(salt), enc.EncodeToString(key)}, "$")
+	return nil
+}
+
+// CheckPassword reports whether password is the user's password
+func (u *User) CheckPassword(password string) bool {
+	parts := strings.Split(u.Password, "$")
+	if len(parts) != 4 || parts[0] != passwordScheme {
+		return false
+	}
+	rounds, err := strconv.Atoi(parts[1])
+	if err != nil || rounds < 1 {
+		return false
+	}
+	enc := base64.RawStdEncoding
+	salt, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := enc.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, rounds, len(want))
+	return err == nil && subtle.ConstantTimeCompare(key, want) == 1
+}
+
+// NewToken adds a random API token to the user and returns it. Only its
+// hash is kept, so the token cannot be shown again.
+func (u *User) NewToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("error generating token: %w", err)
+	}
+	token := TokenPrefix + hex.EncodeToString(buf)
+	u.Tokens = append(u.Tokens, hashToken(token))
+	return token, nil
+}
+
+// CheckToken reports whether token is one of the user's API tokens
+func (u *User) CheckToken(token string) bool {
+	hash := hashToken(token)
+	for _, t := range u.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(hash)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Find returns the user called name, ignoring case, or nil
+func Find(users []User, name string) *User {
+	for i := range users {
+		if strings.EqualFold(users[i].Name, name) {
+			return &users[i]
+		}
+	}
+	return nil
+}
+
+// ByToken returns the user owning the API token, or nil
+func ByToken(users []User, token string) *User {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return nil
+	}
+	for i := range users {
+		if users[i].CheckToken(token) {
+			return &users[i]
+		}
+	}
+	return nil
+}
+
+// Authenticate returns the user called name if secret is their password or
+// one of their API tokens, or nil
+func Authenticate(users []User, name, secret string) *User {
+	u := Find(users, name)
+	if u == nil {
+		return nil
+	}
+	if strings.HasPrefix(secret, TokenPrefix) && u.CheckToken(secret) || u.CheckPassword(secret) {
+		return u
+	}
+	return nil
+}
+
+// ValidateName checks that name can be used as a user name: HTTP basic
+// authentication does not allow colons, and spaces invite confusion
+func ValidateName(name string) error {
+	if name == "" || strings.IndexFunc(name, func(r rune) bool { return r == ':' || unicode.IsSpace(r) || unicode.IsControl(r) }) >= 0 {
+		return fmt.Errorf("invalid user name '%s'", name)
+	}
+	return nil
+}
+
+// Load reads a users file; a missing file yields no users
+func Load(path string) ([]User, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading users: %w", err)
+	}
+
+	var file struct {
+		Users []User `yaml:"users"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing users file %s: %w", path, err)
+	}
+	return file.Users, nil
+}
+
+// Save writes the users file, readable only by its owner
+func Save(path string, users []User) error {
+	data, err := yaml.Marshal(struct {
+		Users []User `yaml:"users"`
+	}{users})
+	if err != nil {
+		return fmt.Errorf("error marshaling users: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating users directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("error writing users: %w", err)
+	}
+	return nil
+}
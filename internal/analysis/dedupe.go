@@ -0,0 +1,99 @@
+package analysis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"memo/internal/note"
+)
+
+// shingleSize is the number of consecutive words compared for near-duplicates
+const shingleSize = 3
+
+// DuplicatePair is two notes whose content is identical or similar
+type DuplicatePair struct {
+	A, B       *note.Note
+	Similarity float64 // 1.0 for identical content
+	Identical  bool
+}
+
+// NormalizeContent lowercases text and collapses whitespace so formatting
+// differences do not hide duplicates
+func NormalizeContent(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+// ContentHash returns the SHA-256 of the normalized content
+func ContentHash(text string) string {
+	sum := sha256.Sum256([]byte(NormalizeContent(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+func shingles(text string) map[string]bool {
+	words := strings.Fields(NormalizeContent(text))
+	set := make(map[string]bool)
+	if len(words) < shingleSize {
+		if len(words) > 0 {
+			set[strings.Join(words, " ")] = true
+		}
+		return set
+	}
+	for i := 0; i+shingleSize <= len(words); i++ {
+		set[strings.Join(words[i:i+shingleSize], " ")] = true
+	}
+	return set
+}
+
+// Jaccard returns the Jaccard similarity of two sets
+func Jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	intersection := 0
+	for k := range a {
+		if b[k] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+// FindDuplicates returns pairs of notes whose content is identical (by
+// normalized hash) or whose word-shingle similarity is at least threshold.
+// Encrypted and empty notes are skipped. Pairs are ordered most similar first.
+func FindDuplicates(notes []*note.Note, threshold float64) []DuplicatePair {
+	type entry struct {
+		n      *note.Note
+		hash   string
+		tokens map[string]bool
+	}
+
+	var entries []entry
+	for _, n := range notes {
+		if n.Locked() || strings.TrimSpace(n.Content) == "" {
+			continue
+		}
+		entries = append(entries, entry{n: n, hash: ContentHash(n.Content), tokens: shingles(n.Content)})
+	}
+
+	var pairs []DuplicatePair
+	for i := 0; i < len(entries); i++ {
+		for j := i + 1; j < len(entries); j++ {
+			a, b := entries[i], entries[j]
+			if a.hash == b.hash {
+				pairs = append(pairs, DuplicatePair{A: a.n, B: b.n, Similarity: 1, Identical: true})
+				continue
+			}
+			if sim := Jaccard(a.tokens, b.tokens); sim >= threshold {
+				pairs = append(pairs, DuplicatePair{A: a.n, B: b.n, Similarity: sim})
+			}
+		}
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].Similarity > pairs[j].Similarity
+	})
+	return pairs
+}
@@ -0,0 +1,152 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"memo/internal/note"
+	"memo/internal/stats"
+)
+
+// DefaultRelated is how many notes `memo related` shows by default
+const DefaultRelated = 5
+
+const (
+	// contentWeight and tagWeight combine content and tag similarity
+	contentWeight = 0.7
+	tagWeight     = 0.3
+	// minRelated leaves out notes that only share a stray word
+	minRelated = 0.05
+	// sharedWords is how many common terms explain a match
+	sharedWords = 3
+)
+
+// RelatedNote is a note similar to another one, with what they share
+type RelatedNote struct {
+	Note *note.Note
+	// Score is between 0 and 1
+	Score      float64
+	SharedTags []string
+	// SharedWords are the terms contributing most to the similarity
+	SharedWords []string
+}
+
+// termVector maps terms to TF-IDF weights
+type termVector map[string]float64
+
+func (v termVector) norm() float64 {
+	sum := 0.0
+	for _, w := range v {
+		sum += w * w
+	}
+	return math.Sqrt(sum)
+}
+
+// FindRelated ranks the other notes by similarity to target: the cosine of
+// their TF-IDF weighted terms (title and content) combined with the share
+// of tags they have in common. At most top notes are returned, most similar
+// first. Encrypted content is not compared, only titles and tags.
+func FindRelated(target *note.Note, notes []*note.Note, top int) []RelatedNote {
+	counts := make(map[*note.Note]map[string]int, len(notes))
+	docs := make(map[string]int)
+	for _, n := range notes {
+		counts[n] = termCounts(n)
+		for t := range counts[n] {
+			docs[t]++
+		}
+	}
+
+	vector := func(tf map[string]int) termVector {
+		v := make(termVector, len(tf))
+		for t, c := range tf {
+			idf := math.Log(float64(1+len(notes))/float64(1+docs[t])) + 1
+			v[t] = (1 + math.Log(float64(c))) * idf
+		}
+		return v
+	}
+
+	tv := vector(termCounts(target))
+	tnorm := tv.norm()
+	var related []RelatedNote
+	for _, n := range notes {
+		if n.FilePath == target.FilePath {
+			continue
+		}
+		r := RelatedNote{Note: n}
+
+		var content float64
+		if v := vector(counts[n]); tnorm > 0 && len(v) > 0 {
+			type shared struct {
+				term   string
+				weight float64
+			}
+			var common []shared
+			dot := 0.0
+			for t, w := range tv {
+				if other, ok := v[t]; ok {
+					dot += w * other
+					common = append(common, shared{t, w * other})
+				}
+			}
+			content = dot / (tnorm * v.norm())
+			sort.Slice(common, func(i, j int) bool {
+				if common[i].weight != common[j].weight {
+					return common[i].weight > common[j].weight
+				}
+				return common[i].term < common[j].term
+			})
+			for i := 0; i < len(common) && i < sharedWords; i++ {
+				r.SharedWords = append(r.SharedWords, common[i].term)
+			}
+		}
+
+		r.SharedTags = sharedTags(target.Metadata.Tags, n.Metadata.Tags)
+		var tags float64
+		if union := len(target.Metadata.Tags) + len(n.Metadata.Tags) - len(r.SharedTags); union > 0 {
+			tags = float64(len(r.SharedTags)) / float64(union)
+		}
+
+		r.Score = contentWeight*content + tagWeight*tags
+		if r.Score >= minRelated {
+			related = append(related, r)
+		}
+	}
+
+	sort.SliceStable(related, func(i, j int) bool {
+		return related[i].Score > related[j].Score
+	})
+	if top > 0 && len(related) > top {
+		related = related[:top]
+	}
+	return related
+}
+
+// termCounts counts the terms in the title and content of n
+func termCounts(n *note.Note) map[string]int {
+	text := n.Metadata.Title
+	if !n.Locked() {
+		text += " " + n.Content
+	}
+	tf := make(map[string]int)
+	for _, t := range stats.Terms(text) {
+		tf[t]++
+	}
+	return tf
+}
+
+// sharedTags returns the tags of a that b has as well, ignoring case
+func sharedTags(a, b []string) []string {
+	have := make(map[string]bool, len(b))
+	for _, tag := range b {
+		have[strings.ToLower(tag)] = true
+	}
+	var shared []string
+	for _, tag := range a {
+		if have[strings.ToLower(tag)] {
+			shared = append(shared, tag)
+			delete(have, strings.ToLower(tag))
+		}
+	}
+	return shared
+}
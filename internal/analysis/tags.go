@@ -0,0 +1,70 @@
+package analysis
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"memo/internal/note"
+)
+
+// TagCounts returns how many notes carry each tag
+func TagCounts(notes []*note.Note) map[string]int {
+	counts := make(map[string]int)
+	for _, n := range notes {
+		for _, tag := range n.Metadata.Tags {
+			counts[tag]++
+		}
+	}
+	return counts
+}
+
+// CompleteTag returns the known tags starting with prefix (ignoring case),
+// most used first, leaving out tags already chosen
+func CompleteTag(counts map[string]int, prefix string, chosen []string) []string {
+	skip := make(map[string]bool, len(chosen))
+	for _, tag := range chosen {
+		skip[strings.ToLower(tag)] = true
+	}
+
+	var matches []string
+	for tag := range counts {
+		if !skip[strings.ToLower(tag)] && strings.HasPrefix(strings.ToLower(tag), strings.ToLower(prefix)) {
+			matches = append(matches, tag)
+		}
+	}
+	sortByCount(matches, counts)
+	return matches
+}
+
+// SuggestTags returns up to limit known tags whose name, or the last part
+// of a nested tag, occurs as a word in content, most used first
+func SuggestTags(counts map[string]int, content string, limit int) []string {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(content), func(r rune) bool {
+		return !(r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r > 127)
+	}) {
+		words[w] = true
+	}
+
+	var suggestions []string
+	for tag := range counts {
+		if words[strings.ToLower(tag)] || words[strings.ToLower(path.Base(tag))] {
+			suggestions = append(suggestions, tag)
+		}
+	}
+	sortByCount(suggestions, counts)
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+func sortByCount(tags []string, counts map[string]int) {
+	sort.Slice(tags, func(i, j int) bool {
+		if counts[tags[i]] != counts[tags[j]] {
+			return counts[tags[i]] > counts[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+}
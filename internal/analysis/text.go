@@ -0,0 +1,60 @@
+package analysis
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WordsPerMinute is the reading speed behind reading time estimates
+const WordsPerMinute = 200
+
+// TextCounts measures the length of a text
+type TextCounts struct {
+	Words      int `json:"words"`
+	Characters int `json:"characters"`
+	// Letters counts the characters that are not white space
+	Letters    int `json:"characters_no_spaces"`
+	Lines      int `json:"lines"`
+	Paragraphs int `json:"paragraphs"`
+}
+
+// CountText counts the words, characters, lines and paragraphs of text.
+// Words are separated by white space; paragraphs by blank lines.
+func CountText(text string) TextCounts {
+	c := TextCounts{
+		Words:      len(strings.Fields(text)),
+		Characters: utf8.RuneCountInString(text),
+	}
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			c.Letters++
+		}
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return c
+	}
+	inParagraph := false
+	for _, line := range strings.Split(text, "\n") {
+		c.Lines++
+		blank := strings.TrimSpace(line) == ""
+		if !blank && !inParagraph {
+			c.Paragraphs++
+		}
+		inParagraph = !blank
+	}
+	return c
+}
+
+// ReadingTime estimates how long the text takes to read at WordsPerMinute,
+// rounded up to whole minutes; any text takes at least a minute
+func (c TextCounts) ReadingTime() time.Duration {
+	if c.Words == 0 {
+		return 0
+	}
+	minutes := (c.Words + WordsPerMinute - 1) / WordsPerMinute
+	return time.Duration(minutes) * time.Minute
+}
@@ -0,0 +1,78 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"memo/internal/note"
+)
+
+// MatchTitles finds the notes whose title best matches query, ignoring
+// case. Exact matches win over prefix matches, which win over fuzzy ones
+// (the query appearing inside the title, or a title within a few typos of
+// it); only the best tier that matches anything is returned.
+func MatchTitles(notes []*note.Note, query string) []*note.Note {
+	q := NormalizeContent(query)
+	if q == "" {
+		return nil
+	}
+
+	var exact, prefix []*note.Note
+	type scored struct {
+		n    *note.Note
+		dist int
+	}
+	var fuzzy []scored
+
+	maxDist := len([]rune(q)) / 4
+	for _, n := range notes {
+		title := NormalizeContent(n.Metadata.Title)
+		switch {
+		case title == q:
+			exact = append(exact, n)
+		case strings.HasPrefix(title, q):
+			prefix = append(prefix, n)
+		case strings.Contains(title, q):
+			fuzzy = append(fuzzy, scored{n, len(title) - len(q)})
+		default:
+			if d := levenshtein(title, q); d <= maxDist {
+				fuzzy = append(fuzzy, scored{n, d})
+			}
+		}
+	}
+
+	if len(exact) > 0 {
+		return exact
+	}
+	if len(prefix) > 0 {
+		return prefix
+	}
+	sort.SliceStable(fuzzy, func(i, j int) bool { return fuzzy[i].dist < fuzzy[j].dist })
+	matches := make([]*note.Note, 0, len(fuzzy))
+	for _, s := range fuzzy {
+		matches = append(matches, s.n)
+	}
+	return matches
+}
+
+// levenshtein returns the edit distance between a and b in runes
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,186 @@
+// Package attachments keeps files attached to notes under their content
+// hash, so a file attached to many notes is stored once, and records which
+// notes refer to each stored file.
+package attachments
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DirName is the directory inside the notes store holding attachments
+	DirName = ".attachments"
+	// RefsFileName is the file inside DirName recording the references
+	RefsFileName = "refs.yaml"
+)
+
+var (
+	// namePattern matches the file names of stored attachments
+	namePattern = regexp.MustCompile(`^[0-9a-f]{64}(\.[a-z0-9]+)?$`)
+	// linkPattern finds references to stored attachments in note text
+	linkPattern = regexp.MustCompile(regexp.QuoteMeta(DirName) + `/([0-9a-f]{64}(?:\.[a-z0-9]+)?)`)
+)
+
+// Blob is a stored attachment and what refers to it
+type Blob struct {
+	Name string
+	Path string
+	Size int64
+	// Notes are the IDs of the notes referring to the attachment
+	Notes []string
+	// Revisions is the number of stored revisions referring to it
+	Revisions int
+}
+
+// Unreferenced reports whether nothing refers to the attachment any more
+func (b Blob) Unreferenced() bool {
+	return len(b.Notes) == 0 && b.Revisions == 0
+}
+
+// Refs maps note IDs to the names of the attachments they refer to
+type Refs map[string][]string
+
+// LoadRefs reads the references recorded in dir; a missing file yields
+// empty references
+func LoadRefs(dir string) (Refs, error) {
+	refs := make(Refs)
+	data, err := os.ReadFile(filepath.Join(dir, RefsFileName))
+	if os.IsNotExist(err) {
+		return refs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading attachment references: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &refs); err != nil {
+		return nil, fmt.Errorf("error parsing attachment references: %w", err)
+	}
+	return refs, nil
+}
+
+// SaveRefs writes the references into dir
+func SaveRefs(dir string, refs Refs) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating attachments directory: %w", err)
+	}
+	data, err := yaml.Marshal(refs)
+	if err != nil {
+		return fmt.Errorf("error marshaling attachment references: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, RefsFileName), data, 0644)
+}
+
+// Scan returns the sorted names of the stored attachments text refers to
+func Scan(text string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range linkPattern.FindAllStringSubmatch(text, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Link returns the path notes use to refer to a stored attachment,
+// relative to the notes store
+func Link(name string) string {
+	return DirName + "/" + name
+}
+
+// Store copies the file at src into dir, named after the SHA-256 hash of
+// its content and keeping its extension. When an identical file is already
+// stored it is reused and nothing is copied.
+func Store(dir, src string) (name string, reused bool, err error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return "", false, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", false, fmt.Errorf("error reading %s: %w", src, err)
+	}
+	name = hex.EncodeToString(h.Sum(nil)) + extension(src)
+
+	target := filepath.Join(dir, name)
+	if _, err := os.Stat(target); err == nil {
+		return name, true, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", false, fmt.Errorf("error creating attachments directory: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", false, err
+	}
+	// Write under a temporary name so an interrupted copy never leaves a
+	// truncated file behind the hash
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return "", false, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, f); err != nil {
+		tmp.Close()
+		return "", false, fmt.Errorf("error copying %s: %w", src, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", false, err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", false, err
+	}
+	return name, false, nil
+}
+
+// List returns the attachments stored in dir, without references
+func List(dir string) ([]Blob, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var blobs []Blob
+	for _, e := range entries {
+		if e.IsDir() || !namePattern.MatchString(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, Blob{Name: e.Name(), Path: filepath.Join(dir, e.Name()), Size: info.Size()})
+	}
+	return blobs, nil
+}
+
+// extension returns the lower-case extension of path when it is a plain
+// alphanumeric one
+func extension(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, r := range strings.TrimPrefix(ext, ".") {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return ""
+		}
+	}
+	if ext == "." {
+		return ""
+	}
+	return ext
+}
@@ -0,0 +1,206 @@
+// Package audit keeps an append-only log of the changes made to notes, one
+// JSON object per line, for stores that are shared or need a record of who
+// changed what.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+)
+
+// FileName is the file inside the notes store holding the audit log
+const FileName = ".audit.log"
+
+// Operations recorded in the log
+const (
+	OpCreate = "create"
+	OpEdit   = "edit"
+	OpTag    = "tag"
+	OpDelete = "delete"
+	OpRename = "rename"
+	OpRepair = "repair"
+	// OpRollback records a note put back as it was when an operation on
+	// several files failed or was recovered with memo recover
+	OpRollback = "rollback"
+)
+
+// Metadata is the front matter of a note at the time of an operation
+type Metadata struct {
+	Title     string            `json:"title"`
+	Tags      []string          `json:"tags,omitempty"`
+	Author    string            `json:"author,omitempty"`
+	Status    string            `json:"status,omitempty"`
+	Priority  int               `json:"priority,omitempty"`
+	Encrypted bool              `json:"encrypted,omitempty"`
+	Expires   string            `json:"expires,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// Entry is one line of the audit log
+type Entry struct {
+	Time time.Time `json:"time"`
+	Op   string    `json:"op"`
+	ID   string    `json:"id"`
+	// NewID is the ID a renamed note got
+	NewID string    `json:"new_id,omitempty"`
+	User  string    `json:"user,omitempty"`
+	Old   *Metadata `json:"old,omitempty"`
+	New   *Metadata `json:"new,omitempty"`
+	// ContentChanged is set when the text of the note changed; the content
+	// of encrypted notes is not compared
+	ContentChanged bool `json:"content_changed,omitempty"`
+}
+
+// Change is one front matter field that differs between Old and New
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// MetadataOf returns the front matter of n to record, or nil for no note
+func MetadataOf(n *note.Note) *Metadata {
+	if n == nil {
+		return nil
+	}
+	m := &Metadata{
+		Title:     n.Metadata.Title,
+		Tags:      slices.Clone(n.Metadata.Tags),
+		Author:    n.Metadata.Author,
+		Status:    n.Metadata.Status,
+		Priority:  n.Metadata.Priority,
+		Encrypted: n.Metadata.Encrypted,
+	}
+	if !n.Metadata.Expires.IsZero() {
+		m.Expires = n.Metadata.Expires.Format(time.RFC3339)
+	}
+	// The typed date fields are recorded with the custom ones, where "due"
+	// was kept before it became a built-in field
+	for _, name := range append(slices.Clone(note.DateFields), n.FieldNames()...) {
+		values, ok := n.Field(name)
+		if !ok {
+			continue
+		}
+		if m.Fields == nil {
+			m.Fields = make(map[string]string)
+		}
+		m.Fields[name] = strings.Join(values, ", ")
+	}
+	return m
+}
+
+// Changes lists the fields that differ between the old and new front
+// matter of the entry
+func (e Entry) Changes() []Change {
+	var before, after Metadata
+	if e.Old != nil {
+		before = *e.Old
+	}
+	if e.New != nil {
+		after = *e.New
+	}
+
+	var changes []Change
+	add := func(field, a, b string) {
+		if a != b {
+			changes = append(changes, Change{Field: field, Old: a, New: b})
+		}
+	}
+	add("title", before.Title, after.Title)
+	add("tags", strings.Join(before.Tags, ", "), strings.Join(after.Tags, ", "))
+	add("author", before.Author, after.Author)
+	add("status", before.Status, after.Status)
+	add("priority", priority(before.Priority), priority(after.Priority))
+	add("encrypted", strconv.FormatBool(before.Encrypted), strconv.FormatBool(after.Encrypted))
+	add("expires", before.Expires, after.Expires)
+
+	var names []string
+	for name := range before.Fields {
+		names = append(names, name)
+	}
+	for name := range after.Fields {
+		if _, ok := before.Fields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		add(name, before.Fields[name], after.Fields[name])
+	}
+	return changes
+}
+
+func priority(p int) string {
+	if p == 0 {
+		return ""
+	}
+	return strconv.Itoa(p)
+}
+
+// Append adds an entry to the log at path
+func Append(path string, e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("error marshaling audit entry: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening audit log: %w", err)
+	}
+	// One write per line keeps concurrent appends from interleaving
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// Load reads the log at path, oldest entry first; a missing log yields no
+// entries
+func Load(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("error parsing audit log line %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// User returns the name of the user making changes, taken from the
+// environment
+func User() string {
+	for _, name := range []string{"USER", "USERNAME", "LOGNAME"} {
+		if user := os.Getenv(name); user != "" {
+			return user
+		}
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+// Package autotag infers tags for notes from rules pairing regular
+// expressions with the tags given to notes they match.
+package autotag
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"memo/internal/note"
+)
+
+// FileName is the file inside the notes store holding the tagging rules
+const FileName = ".autotag.yaml"
+
+// Rule gives Tags to notes in which Pattern matches. Patterns ignore case
+// unless they start with (?-i). In limits the match to the "title" or the
+// "content"; both are searched when it is empty.
+type Rule struct {
+	Pattern string   `yaml:"pattern"`
+	Tags    []string `yaml:"tags"`
+	In      string   `yaml:"in,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// Load reads and compiles the rules file; a missing file yields no rules
+func Load(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading tagging rules: %w", err)
+	}
+
+	var rules []Rule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("error parsing tagging rules: %w", err)
+	}
+	for i := range rules {
+		r := &rules[i]
+		switch r.In {
+		case "", "title", "content":
+		default:
+			return nil, fmt.Errorf("tagging rule %d: unknown field '%s' (use title or content)", i+1, r.In)
+		}
+		if len(r.Tags) == 0 {
+			return nil, fmt.Errorf("tagging rule %d: no tags given", i+1)
+		}
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return nil, fmt.Errorf("tagging rule %d: %w", i+1, err)
+		}
+		r.re = regexp.MustCompile("(?i)" + r.Pattern)
+	}
+	return rules, nil
+}
+
+// Matches reports whether the rule applies to n. The content of a locked
+// note cannot be searched.
+func (r Rule) Matches(n *note.Note) bool {
+	if r.In != "content" && r.re.MatchString(n.Metadata.Title) {
+		return true
+	}
+	return r.In != "title" && !n.Locked() && r.re.MatchString(n.Content)
+}
+
+// Missing returns the tags the rules give n that it does not have yet, in
+// the order of the rules
+func Missing(rules []Rule, n *note.Note) []string {
+	var missing []string
+	for _, r := range rules {
+		if !r.Matches(n) {
+			continue
+		}
+		for _, tag := range r.Tags {
+			if !hasTag(n.Metadata.Tags, tag) && !hasTag(missing, tag) {
+				missing = append(missing, tag)
+			}
+		}
+	}
+	return missing
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package backup
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+func newArchiveWriter(w io.Writer, format Format) archiveWriter {
+	if format == Zip {
+		return newZipWriter(w)
+	}
+	return newTarGzWriter(w)
+}
+
+// Writer adds files to a tar.gz or zip archive as they come, for archives
+// too large to collect in memory first
+type Writer struct {
+	aw archiveWriter
+}
+
+// NewWriter starts an archive in format on w
+func NewWriter(w io.Writer, format Format) *Writer {
+	return &Writer{aw: newArchiveWriter(w, format)}
+}
+
+// Add writes a regular file into the archive
+func (w *Writer) Add(name string, data []byte, modTime time.Time) error {
+	return w.aw.add(file{name: name, data: data, mode: 0644, modTime: modTime})
+}
+
+// Close finishes the archive; it does not close the underlying writer
+func (w *Writer) Close() error {
+	return w.aw.Close()
+}
+
+type tarGzWriter struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func newTarGzWriter(w io.Writer) *tarGzWriter {
+	gz := gzip.NewWriter(w)
+	return &tarGzWriter{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+func (w *tarGzWriter) add(f file) error {
+	hdr := &tar.Header{
+		Name:    f.name,
+		Mode:    int64(f.mode),
+		Size:    int64(len(f.data)),
+		ModTime: f.modTime,
+	}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(f.data)
+	return err
+}
+
+func (w *tarGzWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.gz.Close()
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func newZipWriter(w io.Writer) *zipWriter {
+	return &zipWriter{zw: zip.NewWriter(w)}
+}
+
+func (w *zipWriter) add(f file) error {
+	hdr := &zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: f.modTime}
+	hdr.SetMode(f.mode)
+	out, err := w.zw.CreateHeader(hdr)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(f.data)
+	return err
+}
+
+func (w *zipWriter) Close() error {
+	return w.zw.Close()
+}
+
+// readArchive loads every file of a tar.gz or zip archive, telling the two
+// apart by their magic bytes
+func readArchive(archive string) ([]file, error) {
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		return nil, fmt.Errorf("error reading backup: %w", err)
+	}
+
+	switch {
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		return readTarGz(data)
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return readZip(data)
+	}
+	return nil, fmt.Errorf("%s is not a tar.gz or zip archive", archive)
+}
+
+func readTarGz(data []byte) ([]file, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error reading backup: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	var files []file
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading backup: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("error reading backup: %w", err)
+		}
+		files = append(files, file{name: hdr.Name, data: content, mode: hdr.FileInfo().Mode().Perm(), modTime: hdr.ModTime})
+	}
+}
+
+func readZip(data []byte) ([]file, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading backup: %w", err)
+	}
+
+	var files []file
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, fmt.Errorf("error reading backup: %w", err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading backup: %w", err)
+		}
+		files = append(files, file{name: zf.Name, data: content, mode: zf.Mode().Perm(), modTime: zf.Modified})
+	}
+	return files, nil
+}
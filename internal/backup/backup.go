@@ -0,0 +1,283 @@
+// Package backup writes the whole notes store, including revisions,
+// templates and other auxiliary files, plus the configuration file into a
+// single archive, and restores it after verifying every file's checksum.
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// Prefix starts the name of every backup archive
+	Prefix = "memo-backup-"
+	// ManifestName is the archive entry listing the checksum of every file
+	ManifestName = "MANIFEST"
+	// ConfigName is the archive entry holding the configuration file
+	ConfigName = "config.yaml"
+	// notesPrefix is the archive directory holding the notes store
+	notesPrefix = "notes/"
+
+	timeLayout = "20060102-150405"
+)
+
+// skipDirs are store directories that only hold transient state
+var skipDirs = map[string]bool{".locks": true}
+
+// Format is an archive format
+type Format string
+
+const (
+	TarGz Format = "tar.gz"
+	Zip   Format = "zip"
+)
+
+// ParseFormat accepts "tar.gz" (or "tgz") and "zip"
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "tar.gz", "tgz":
+		return TarGz, nil
+	case "zip":
+		return Zip, nil
+	}
+	return "", fmt.Errorf("unknown backup format '%s' (use tar.gz or zip)", s)
+}
+
+// file is one entry of an archive
+type file struct {
+	name    string
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// archiveWriter adds files to an archive
+type archiveWriter interface {
+	add(f file) error
+	Close() error
+}
+
+// Info describes a backup archive found in a backup directory
+type Info struct {
+	Path    string
+	Created time.Time
+	Size    int64
+
+	modTime time.Time
+}
+
+// Create writes a backup of notesDir and, when it exists, the configuration
+// file at configPath into dir. It returns the archive path and the number
+// of files it holds.
+func Create(notesDir, configPath, dir string, format Format, now time.Time) (string, int, error) {
+	files, err := collect(notesDir, configPath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", 0, fmt.Errorf("error creating backup directory: %w", err)
+	}
+	archive := filepath.Join(dir, Prefix+now.Format(timeLayout)+"."+string(format))
+	tmp := archive + ".tmp"
+
+	out, err := os.Create(tmp)
+	if err != nil {
+		return "", 0, fmt.Errorf("error creating backup: %w", err)
+	}
+	err = writeArchive(out, format, files, now)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, archive)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", 0, fmt.Errorf("error writing backup: %w", err)
+	}
+	return archive, len(files), nil
+}
+
+// errChanged stops the walk in ChangedSince at the first change
+var errChanged = errors.New("changed")
+
+// ChangedSince reports whether anything in notesDir was modified after t.
+// Directories count too, since deleting a file changes its directory.
+func ChangedSince(notesDir string, t time.Time) (bool, error) {
+	err := filepath.WalkDir(notesDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && skipDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(t) {
+			return errChanged
+		}
+		return nil
+	})
+	if errors.Is(err, errChanged) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error reading notes: %w", err)
+	}
+	return false, nil
+}
+
+// collect reads every file of the store and the config file
+func collect(notesDir, configPath string) ([]file, error) {
+	var files []file
+	err := filepath.WalkDir(notesDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(notesDir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, file{name: notesPrefix + filepath.ToSlash(rel), data: data, mode: info.Mode().Perm(), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading notes: %w", err)
+	}
+
+	if data, err := os.ReadFile(configPath); err == nil {
+		info, _ := os.Stat(configPath)
+		files = append(files, file{name: ConfigName, data: data, mode: 0644, modTime: info.ModTime()})
+	}
+	return files, nil
+}
+
+func writeArchive(w io.Writer, format Format, files []file, now time.Time) error {
+	aw := newArchiveWriter(w, format)
+	for _, f := range files {
+		if err := aw.add(f); err != nil {
+			return err
+		}
+	}
+	if err := aw.add(file{name: ManifestName, data: manifest(files), mode: 0644, modTime: now}); err != nil {
+		return err
+	}
+	return aw.Close()
+}
+
+// manifest lists "sha256  name" for every file, like sha256sum
+func manifest(files []file) []byte {
+	var b strings.Builder
+	for _, f := range files {
+		sum := sha256.Sum256(f.data)
+		fmt.Fprintf(&b, "%s  %s\n", hex.EncodeToString(sum[:]), f.name)
+	}
+	return []byte(b.String())
+}
+
+// List returns the backups in dir, newest first
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading backup directory: %w", err)
+	}
+
+	var backups []Info
+	for _, e := range entries {
+		created, ok := parseName(e.Name())
+		if !ok || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Info{Path: filepath.Join(dir, e.Name()), Created: created, Size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		// Names only have second resolution
+		if !backups[i].Created.Equal(backups[j].Created) {
+			return backups[i].Created.After(backups[j].Created)
+		}
+		return backups[i].modTime.After(backups[j].modTime)
+	})
+	return backups, nil
+}
+
+// parseName extracts the creation time from a backup file name
+func parseName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, Prefix) {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimPrefix(name, Prefix)
+	switch {
+	case strings.HasSuffix(stamp, "."+string(TarGz)):
+		stamp = strings.TrimSuffix(stamp, "."+string(TarGz))
+	case strings.HasSuffix(stamp, "."+string(Zip)):
+		stamp = strings.TrimSuffix(stamp, "."+string(Zip))
+	default:
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(timeLayout, stamp, time.Local)
+	return t, err == nil
+}
+
+// Rotate deletes all but the newest keep backups in dir and returns the
+// removed paths
+func Rotate(dir string, keep int) ([]string, error) {
+	backups, err := List(dir)
+	if err != nil || len(backups) <= keep {
+		return nil, err
+	}
+
+	var removed []string
+	for _, b := range backups[keep:] {
+		if err := os.Remove(b.Path); err != nil {
+			return removed, fmt.Errorf("error removing old backup: %w", err)
+		}
+		removed = append(removed, b.Path)
+	}
+	return removed, nil
+}
+
+// cleanName rejects archive entries that would escape the restore directory
+func cleanName(name string) (string, error) {
+	clean := path.Clean(name)
+	if clean != name || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("unsafe path '%s' in backup", name)
+	}
+	return clean, nil
+}
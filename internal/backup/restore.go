@@ -0,0 +1,142 @@
+package backup
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Contents summarizes a verified backup
+type Contents struct {
+	Notes     int  // note store files, including auxiliary ones
+	HasConfig bool // whether the configuration file was backed up
+
+	files []file
+}
+
+// Verify reads archive and checks every file against the manifest. Missing,
+// extra or altered files make the backup invalid.
+func Verify(archive string) (*Contents, error) {
+	files, err := readArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]file, len(files))
+	var sums []byte
+	for _, f := range files {
+		if f.name == ManifestName {
+			sums = f.data
+			continue
+		}
+		if _, err := cleanName(f.name); err != nil {
+			return nil, err
+		}
+		byName[f.name] = f
+	}
+	if sums == nil {
+		return nil, fmt.Errorf("backup has no %s; it was not made by memo backup", ManifestName)
+	}
+
+	listed := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(string(sums)))
+	for scanner.Scan() {
+		want, name, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok {
+			return nil, fmt.Errorf("malformed %s line: %q", ManifestName, scanner.Text())
+		}
+		f, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("backup is missing %s", name)
+		}
+		sum := sha256.Sum256(f.data)
+		if hex.EncodeToString(sum[:]) != want {
+			return nil, fmt.Errorf("checksum mismatch for %s; the backup is corrupt", name)
+		}
+		listed[name] = true
+	}
+	for name := range byName {
+		if !listed[name] {
+			return nil, fmt.Errorf("backup contains %s, which is not in the %s", name, ManifestName)
+		}
+	}
+
+	c := &Contents{files: files}
+	for _, f := range files {
+		switch {
+		case f.name == ConfigName:
+			c.HasConfig = true
+		case strings.HasPrefix(f.name, notesPrefix):
+			c.Notes++
+		}
+	}
+	return c, nil
+}
+
+// Restore replaces notesDir with the notes in a verified backup. The
+// current directory is kept next to it under a ".pre-restore-<time>" suffix
+// and its path returned, or "" when there was none. With restoreConfig set
+// the configuration file is replaced the same way.
+func (c *Contents) Restore(notesDir, configPath string, restoreConfig bool, now time.Time) (string, error) {
+	suffix := ".pre-restore-" + now.Format(timeLayout)
+	staging := notesDir + ".restoring"
+	if err := os.RemoveAll(staging); err != nil {
+		return "", err
+	}
+
+	for _, f := range c.files {
+		if !strings.HasPrefix(f.name, notesPrefix) {
+			continue
+		}
+		target := filepath.Join(staging, filepath.FromSlash(strings.TrimPrefix(f.name, notesPrefix)))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			os.RemoveAll(staging)
+			return "", fmt.Errorf("error restoring: %w", err)
+		}
+		if err := os.WriteFile(target, f.data, f.mode|0600); err != nil {
+			os.RemoveAll(staging)
+			return "", fmt.Errorf("error restoring: %w", err)
+		}
+		os.Chtimes(target, f.modTime, f.modTime)
+	}
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return "", err
+	}
+
+	previous := ""
+	if _, err := os.Stat(notesDir); err == nil {
+		previous = notesDir + suffix
+		if err := os.Rename(notesDir, previous); err != nil {
+			os.RemoveAll(staging)
+			return "", fmt.Errorf("error moving current notes aside: %w", err)
+		}
+	}
+	if err := os.Rename(staging, notesDir); err != nil {
+		return previous, fmt.Errorf("error restoring: %w", err)
+	}
+
+	if restoreConfig && c.HasConfig {
+		for _, f := range c.files {
+			if f.name != ConfigName {
+				continue
+			}
+			if _, err := os.Stat(configPath); err == nil {
+				if err := os.Rename(configPath, configPath+suffix); err != nil {
+					return previous, fmt.Errorf("error moving current config aside: %w", err)
+				}
+			}
+			if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+				return previous, err
+			}
+			if err := os.WriteFile(configPath, f.data, 0644); err != nil {
+				return previous, fmt.Errorf("error restoring config: %w", err)
+			}
+		}
+	}
+	return previous, nil
+}
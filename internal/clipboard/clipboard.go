@@ -0,0 +1,45 @@
+// Package clipboard reads and writes the system clipboard through the
+// platform's command-line tools: pbcopy and pbpaste on macOS, PowerShell on
+// Windows and wl-clipboard, xclip or xsel elsewhere.
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// ErrUnsupported is returned when no clipboard tool is available, for
+// example on a server without a desktop session
+var ErrUnsupported = errors.New("no supported clipboard available")
+
+// Read returns the text on the clipboard
+func Read() (string, error) {
+	return read()
+}
+
+// Write replaces the clipboard's content with text
+func Write(text string) error {
+	return write(text)
+}
+
+// run executes a clipboard tool with stdin as its input and returns its
+// output. ErrUnsupported is returned when the tool is not installed.
+func run(stdin string, name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", ErrUnsupported
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
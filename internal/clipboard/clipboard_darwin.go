@@ -0,0 +1,10 @@
+package clipboard
+
+func read() (string, error) {
+	return run("", "pbpaste")
+}
+
+func write(text string) error {
+	_, err := run(text, "pbcopy")
+	return err
+}
@@ -0,0 +1,53 @@
+//go:build !darwin && !windows
+
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// tool is a pair of commands for pasting and copying
+type tool struct {
+	paste []string
+	copy  []string
+}
+
+var errNoTool = fmt.Errorf("%w (install wl-clipboard, xclip or xsel)", ErrUnsupported)
+
+// tools returns the clipboard tools to try, Wayland's first when a Wayland
+// session is running
+func tools() []tool {
+	x11 := []tool{
+		{paste: []string{"xclip", "-selection", "clipboard", "-o"}, copy: []string{"xclip", "-selection", "clipboard"}},
+		{paste: []string{"xsel", "--clipboard", "--output"}, copy: []string{"xsel", "--clipboard", "--input"}},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") == "" {
+		return x11
+	}
+	wayland := tool{paste: []string{"wl-paste", "--no-newline"}, copy: []string{"wl-copy"}}
+	return append([]tool{wayland}, x11...)
+}
+
+func read() (string, error) {
+	for _, t := range tools() {
+		out, err := run("", t.paste[0], t.paste[1:]...)
+		if errors.Is(err, ErrUnsupported) {
+			continue
+		}
+		return out, err
+	}
+	return "", errNoTool
+}
+
+func write(text string) error {
+	for _, t := range tools() {
+		_, err := run(text, t.copy[0], t.copy[1:]...)
+		if errors.Is(err, ErrUnsupported) {
+			continue
+		}
+		return err
+	}
+	return errNoTool
+}
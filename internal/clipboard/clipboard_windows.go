@@ -0,0 +1,18 @@
+package clipboard
+
+import "strings"
+
+// PowerShell reads the text from stdin itself; piping it through $input
+// would split it into lines
+const setClipboard = "[Console]::InputEncoding = [Text.Encoding]::UTF8; Set-Clipboard -Value ([Console]::In.ReadToEnd())"
+
+func read() (string, error) {
+	out, err := run("", "powershell.exe", "-NoProfile", "-NonInteractive", "-Command",
+		"[Console]::OutputEncoding = [Text.Encoding]::UTF8; Get-Clipboard -Raw")
+	return strings.ReplaceAll(out, "\r\n", "\n"), err
+}
+
+func write(text string) error {
+	_, err := run(text, "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", setClipboard)
+	return err
+}
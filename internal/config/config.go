@@ -0,0 +1,366 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"memo/internal/accounts"
+)
+
+const (
+	DefaultProfileName = "default"
+	configFileName     = "config.yaml"
+	// LegacyNotesDir is where older versions kept notes, relative to the
+	// working directory
+	LegacyNotesDir = ".memo-notes"
+)
+
+// Profile holds the settings that apply to one named notes store
+type Profile struct {
+	NotesDir string `yaml:"notes_dir,omitempty"`
+	// StoreFile keeps the whole store in one zip file instead of NotesDir;
+	// commands work on a copy in the user's cache directory
+	StoreFile       string   `yaml:"store_file,omitempty"`
+	Author          string   `yaml:"author,omitempty"`
+	DefaultTags     []string `yaml:"default_tags,omitempty"`
+	DefaultStatus   string   `yaml:"default_status,omitempty"`
+	DefaultPriority int      `yaml:"default_priority,omitempty"`
+	// DefaultLocation is a place name or "lat,lon" given to new notes
+	DefaultLocation string `yaml:"default_location,omitempty"`
+	ReadOnly        bool   `yaml:"read_only,omitempty"`
+	// MergeHashtags adds #hashtags written in a note's content to its tags
+	// whenever the note is saved
+	MergeHashtags bool `yaml:"merge_hashtags,omitempty"`
+	// Notebooks holds settings for subdirectories of the notes directory
+	Notebooks map[string]Notebook `yaml:"notebooks,omitempty"`
+}
+
+// Notebook holds the settings of one notebook
+type Notebook struct {
+	// IDPrefix starts the IDs of new notes, e.g. "work-{year}-{month}-";
+	// a sequence number completes the ID
+	IDPrefix string `yaml:"id_prefix,omitempty"`
+	// Author, DefaultTags, DefaultStatus, DefaultPriority and
+	// DefaultLocation override the profile's defaults for notes created in
+	// the notebook
+	Author          string   `yaml:"author,omitempty"`
+	DefaultTags     []string `yaml:"default_tags,omitempty"`
+	DefaultStatus   string   `yaml:"default_status,omitempty"`
+	DefaultPriority int      `yaml:"default_priority,omitempty"`
+	DefaultLocation string   `yaml:"default_location,omitempty"`
+	// RequiredFields must be filled in for notes in the notebook to be
+	// saved, in addition to those the validation rules require everywhere
+	RequiredFields []string `yaml:"required_fields,omitempty"`
+}
+
+// NoteDefaults is the metadata given to new notes unless they set their own
+type NoteDefaults struct {
+	Author   string
+	Tags     []string
+	Status   string
+	Priority int
+	Location string
+}
+
+// Defaults returns the metadata for new notes in notebook ("" for the top
+// of the notes directory): the notebook's settings, where set, override
+// the profile's
+func (p Profile) Defaults(notebook string) NoteDefaults {
+	d := NoteDefaults{Author: p.Author, Tags: p.DefaultTags, Status: p.DefaultStatus, Priority: p.DefaultPriority, Location: p.DefaultLocation}
+	nb, ok := p.Notebooks[notebook]
+	if notebook == "" || !ok {
+		return d
+	}
+	if nb.Author != "" {
+		d.Author = nb.Author
+	}
+	if len(nb.DefaultTags) > 0 {
+		d.Tags = nb.DefaultTags
+	}
+	if nb.DefaultStatus != "" {
+		d.Status = nb.DefaultStatus
+	}
+	if nb.DefaultPriority != 0 {
+		d.Priority = nb.DefaultPriority
+	}
+	if nb.DefaultLocation != "" {
+		d.Location = nb.DefaultLocation
+	}
+	return d
+}
+
+// IDPrefixes returns the configured ID prefix of each notebook
+func (p Profile) IDPrefixes() map[string]string {
+	prefixes := make(map[string]string, len(p.Notebooks))
+	for name, nb := range p.Notebooks {
+		if nb.IDPrefix != "" {
+			prefixes[name] = nb.IDPrefix
+		}
+	}
+	return prefixes
+}
+
+// Config is the on-disk configuration file
+type Config struct {
+	DefaultProfile string             `yaml:"default_profile,omitempty"`
+	Profiles       map[string]Profile `yaml:"profiles,omitempty"`
+	MaxRevisions   *int               `yaml:"max_revisions,omitempty"`
+	ReadOnly       bool               `yaml:"read_only,omitempty"`
+	// Language selects the language of messages, e.g. "de"; LANG is used
+	// when it is empty
+	Language string `yaml:"language,omitempty"`
+	// SavedSearches maps names to queries for `memo search --saved`
+	SavedSearches map[string]string `yaml:"saved_searches,omitempty"`
+	// SearchMode is "all" (the default) to find notes containing every
+	// search term, or "any" for notes containing at least one
+	SearchMode string `yaml:"search_mode,omitempty"`
+	// SearchStemming makes `memo search` match words by their stems, as
+	// with --stem, unless --no-stem is given
+	SearchStemming bool `yaml:"search_stemming,omitempty"`
+	// NoteLimits are soft size limits: larger notes are flagged by
+	// `memo doctor` and shortened in listings
+	NoteLimits *NoteLimits `yaml:"note_limits,omitempty"`
+	// Snapshots configures the backups `memo watch` makes while it runs
+	Snapshots *Snapshots `yaml:"snapshots,omitempty"`
+	// Lint configures the rules of `memo lint`, which are also checked
+	// whenever a note is saved
+	Lint *Lint `yaml:"lint,omitempty"`
+	// Validation restricts the metadata of notes; saving a note that breaks
+	// a rule fails
+	Validation *Validation `yaml:"validation,omitempty"`
+	// Trash sets how long deleted notes are kept for `memo trash restore`
+	Trash *Trash `yaml:"trash,omitempty"`
+	// Server configures the accounts of `memo serve`
+	Server *Server `yaml:"server,omitempty"`
+	// Places names coordinates for `--location` and `memo list --near`
+	Places map[string]Place `yaml:"places,omitempty"`
+
+	path string
+}
+
+// Server configures `memo serve`. When it has users, every request must
+// authenticate as one of them.
+type Server struct {
+	// Users are accounts written into the configuration by hand
+	Users []accounts.User `yaml:"users,omitempty"`
+	// UsersFile holds the accounts managed with `memo user`; it defaults
+	// to users.yaml next to the configuration file
+	UsersFile string `yaml:"users_file,omitempty"`
+}
+
+// UsersFile returns the location of the users file managed by `memo user`
+func (c *Config) UsersFile() string {
+	if c.Server != nil && c.Server.UsersFile != "" {
+		return ExpandHome(c.Server.UsersFile)
+	}
+	return filepath.Join(filepath.Dir(Path()), accounts.FileName)
+}
+
+// Users returns the accounts of `memo serve`: those in the configuration
+// followed by those in the users file
+func (c *Config) Users() ([]accounts.User, error) {
+	users, err := accounts.Load(c.UsersFile())
+	if err != nil {
+		return nil, err
+	}
+	if c.Server != nil {
+		users = append(slices.Clone(c.Server.Users), users...)
+	}
+	return users, nil
+}
+
+// Place is a named location
+type Place struct {
+	Lat float64 `yaml:"lat"`
+	Lon float64 `yaml:"lon"`
+}
+
+// Place looks up a named place, ignoring case
+func (c *Config) Place(name string) (Place, bool) {
+	for key, place := range c.Places {
+		if strings.EqualFold(key, name) {
+			return place, true
+		}
+	}
+	return Place{}, false
+}
+
+// Validation holds the metadata rules every saved note must meet
+type Validation struct {
+	// MinPriority and MaxPriority bound priorities; notes without a
+	// priority are not checked
+	MinPriority int `yaml:"min_priority,omitempty"`
+	MaxPriority int `yaml:"max_priority,omitempty"`
+	// Statuses lists the allowed values of status
+	Statuses []string `yaml:"statuses,omitempty"`
+	// RequiredFields must be filled in, e.g. [author, status]
+	RequiredFields []string `yaml:"required_fields,omitempty"`
+}
+
+// Lint sets up content linting
+type Lint struct {
+	// Rules maps rule names to "off", "warn" or "error"; other rules warn
+	Rules map[string]string `yaml:"rules,omitempty"`
+	// Strict treats every warning as an error
+	Strict bool `yaml:"strict,omitempty"`
+	// OnSave also checks notes as they are saved; errors stop the save
+	OnSave bool `yaml:"on_save,omitempty"`
+}
+
+// LintOnSave reports whether notes are linted when they are saved, which
+// is off unless lint.on_save is set
+func (c *Config) LintOnSave() bool {
+	return c.Lint != nil && c.Lint.OnSave
+}
+
+// Trash limits what the trash keeps. Deleted notes go to the trash unless
+// it is disabled, and leave it for good when it holds them longer than
+// MaxAgeDays (30 unless set; 0 keeps them) or when it grows beyond
+// MaxSizeMB, oldest first.
+type Trash struct {
+	Disabled   bool    `yaml:"disabled,omitempty"`
+	MaxAgeDays *int    `yaml:"max_age_days,omitempty"`
+	MaxSizeMB  float64 `yaml:"max_size_mb,omitempty"`
+}
+
+// Snapshots configures periodic backups; empty values use the defaults of
+// `memo watch`
+type Snapshots struct {
+	// Interval between snapshots, such as "24h"; "0" turns them off
+	Interval string `yaml:"interval,omitempty"`
+	// Keep is the number of snapshots kept; older ones are deleted
+	Keep   int    `yaml:"keep,omitempty"`
+	Dir    string `yaml:"dir,omitempty"`
+	Format string `yaml:"format,omitempty"`
+}
+
+// NoteLimits sets when a note counts as large; zero disables a limit
+type NoteLimits struct {
+	MaxKB    int `yaml:"max_kb"`
+	MaxLines int `yaml:"max_lines"`
+}
+
+// Path returns the location of the configuration file, honoring MEMO_CONFIG
+// and XDG_CONFIG_HOME
+func Path() string {
+	if p := os.Getenv("MEMO_CONFIG"); p != "" {
+		return p
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "memo", configFileName)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return configFileName
+	}
+	return filepath.Join(home, ".config", "memo", configFileName)
+}
+
+// DefaultNotesDir returns the store used when no profile sets notes_dir:
+// MEMO_DIR, $XDG_DATA_HOME/memo or ~/.memo-notes, so notes end up in one
+// place whatever the working directory
+func DefaultNotesDir() string {
+	if dir := os.Getenv("MEMO_DIR"); dir != "" {
+		return ExpandHome(dir)
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "memo")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return LegacyNotesDir
+	}
+	return filepath.Join(home, LegacyNotesDir)
+}
+
+// Load reads the configuration file. A missing file yields an empty config.
+func Load() (*Config, error) {
+	path := Path()
+	cfg := &Config{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("error parsing config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the configuration back to the file it was loaded from
+func (c *Config) Save() error {
+	if c.path == "" {
+		c.path = Path()
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("error marshaling config: %w", err)
+	}
+	return os.WriteFile(c.path, data, 0644)
+}
+
+// ResolveProfileName maps an empty profile name to the configured default
+func (c *Config) ResolveProfileName(name string) string {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		name = DefaultProfileName
+	}
+	return name
+}
+
+// Profile looks up a profile by name. An empty name selects the configured
+// default profile; the implicit "default" profile always exists.
+func (c *Config) Profile(name string) (Profile, error) {
+	name = c.ResolveProfileName(name)
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		if name == DefaultProfileName {
+			return Profile{}, nil
+		}
+		return Profile{}, fmt.Errorf("unknown profile '%s'", name)
+	}
+
+	p.NotesDir = ExpandHome(p.NotesDir)
+	p.StoreFile = ExpandHome(p.StoreFile)
+	return p, nil
+}
+
+// ProfileNames returns the configured profile names in sorted order
+func (c *Config) ProfileNames() []string {
+	var names []string
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ExpandHome replaces a leading "~" with the user's home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
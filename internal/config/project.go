@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ProjectMarker is the file `memo init` leaves in the .memo-notes directory
+// of a project store. It tells project stores apart from the stores older
+// versions left in whatever directory memo was run from.
+const ProjectMarker = ".project"
+
+// IsProjectStore reports whether dir is a notes directory set up by
+// `memo init`
+func IsProjectStore(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, ProjectMarker))
+	return err == nil && !info.IsDir()
+}
+
+// FindProjectStore looks for a project store in dir and then in each of its
+// parents, the way git finds a repository, and returns the nearest one
+func FindProjectStore(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if store := filepath.Join(dir, LegacyNotesDir); IsProjectStore(store) {
+			return store, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// MarkProjectStore turns the notes directory dir into a project store
+func MarkProjectStore(dir string) error {
+	text := "# memo uses this store from the directory above it and everything below\n"
+	return os.WriteFile(filepath.Join(dir, ProjectMarker), []byte(text), 0644)
+}
@@ -0,0 +1,161 @@
+// Package daemon answers requests from editor plugins over a Unix socket.
+// The protocol is one JSON object per line in each direction:
+//
+//	{"id": 1, "method": "get", "params": {"id": "note_1700000000"}}
+//	{"id": 1, "result": {"id": "note_1700000000", "title": "...", ...}}
+//
+// A failed request gets an "error" string instead of a result. Requests on
+// one connection are answered in order.
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"memo/internal/storage"
+)
+
+// maxLine bounds a request line; a note's content travels within it
+const maxLine = 16 << 20
+
+// request is one call from a client
+type request struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params params          `json:"params"`
+}
+
+// response answers the request with the same ID
+type response struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result any             `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Daemon serves one notes store on a Unix socket
+type Daemon struct {
+	storage *storage.FileStorage
+}
+
+func New(fs *storage.FileStorage) *Daemon {
+	return &Daemon{storage: fs}
+}
+
+// DefaultSocket returns the socket path used when none is given: memo.sock
+// in XDG_RUNTIME_DIR, or a per-user file in the temporary directory
+func DefaultSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "memo.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("memo-%d.sock", os.Getuid()))
+}
+
+// Listen creates the socket at path, readable only by the current user. A
+// socket left behind by a daemon that is no longer running is replaced.
+func Listen(path string) (net.Listener, error) {
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("a daemon is already listening on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("error removing stale socket: %w", err)
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Serve answers connections on l until ctx is done, then closes l and waits
+// for open connections to finish their current request
+func (d *Daemon) Serve(ctx context.Context, l net.Listener) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	conns := make(map[net.Conn]bool)
+
+	go func() {
+		<-ctx.Done()
+		l.Close()
+		mu.Lock()
+		for conn := range conns {
+			// Unblocks the reader; a request being answered still completes
+			conn.SetReadDeadline(time.Now())
+		}
+		mu.Unlock()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				wg.Wait()
+				return nil
+			}
+			return err
+		}
+		mu.Lock()
+		conns[conn] = true
+		mu.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.handle(conn)
+			mu.Lock()
+			delete(conns, conn)
+			mu.Unlock()
+		}()
+	}
+}
+
+// handle answers the requests of one connection until it is closed
+func (d *Daemon) handle(conn net.Conn) {
+	defer conn.Close()
+	slog.Debug("daemon client connected")
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), maxLine)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		start := time.Now()
+		var req request
+		var resp response
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp.Error = "invalid request: " + err.Error()
+		} else {
+			resp.ID = req.ID
+			result, err := d.call(req.Method, req.Params)
+			if err != nil {
+				resp.Error = err.Error()
+			} else {
+				resp.Result = result
+			}
+		}
+		if err := enc.Encode(resp); err != nil {
+			slog.Debug("daemon client went away", "error", err)
+			return
+		}
+		slog.Debug("daemon request", "method", req.Method, "duration", time.Since(start))
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+		slog.Warn("daemon connection failed", "error", err)
+	}
+}
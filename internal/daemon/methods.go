@@ -0,0 +1,199 @@
+package daemon
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+)
+
+// params holds the arguments of every method; each uses the ones it needs.
+// Note fields that are absent are left unchanged by "update".
+type params struct {
+	ID       string    `json:"id"`
+	Tag      string    `json:"tag"`
+	Query    string    `json:"query"`
+	Title    *string   `json:"title"`
+	Content  *string   `json:"content"`
+	Tags     *[]string `json:"tags"`
+	Author   *string   `json:"author"`
+	Status   *string   `json:"status"`
+	Priority *int      `json:"priority"`
+}
+
+// noteResult is a note as returned to clients; lists leave out the content
+type noteResult struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Created   time.Time `json:"created"`
+	Modified  time.Time `json:"modified"`
+	Tags      []string  `json:"tags"`
+	Author    string    `json:"author,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Priority  int       `json:"priority,omitempty"`
+	Encrypted bool      `json:"encrypted,omitempty"`
+	Path      string    `json:"path"`
+	Content   *string   `json:"content,omitempty"`
+}
+
+// tagResult is a tag and the number of notes carrying it
+type tagResult struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Methods lists the methods clients may call
+var Methods = []string{"ping", "list", "search", "get", "create", "update", "delete", "tags"}
+
+// call runs one method
+func (d *Daemon) call(method string, p params) (any, error) {
+	fs := d.storage
+	switch method {
+	case "ping":
+		return "pong", nil
+	case "list":
+		var notes []*note.Note
+		var err error
+		if p.Tag != "" {
+			notes, err = fs.FilterNotesByTag(p.Tag)
+		} else {
+			notes, err = fs.GetAllNotes()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return toResults(notes), nil
+	case "search":
+		if p.Query == "" {
+			return nil, fmt.Errorf("query is required")
+		}
+		notes, err := fs.SearchNotes(p.Query)
+		if err != nil {
+			return nil, err
+		}
+		return toResults(notes), nil
+	case "get":
+		n, err := d.find(p.ID)
+		if err != nil {
+			return nil, err
+		}
+		return toResult(n, true), nil
+	case "create":
+		if p.Title == nil || strings.TrimSpace(*p.Title) == "" {
+			return nil, fmt.Errorf("title is required")
+		}
+		n := note.New("", "", nil)
+		p.apply(n)
+		if _, err := fs.CreateNote(n); err != nil {
+			return nil, err
+		}
+		return toResult(n, true), nil
+	case "update":
+		n, err := d.find(p.ID)
+		if err != nil {
+			return nil, err
+		}
+		if p.Content != nil && n.Locked() {
+			return nil, fmt.Errorf("content of encrypted notes cannot be changed through the daemon")
+		}
+		if p.Title != nil && strings.TrimSpace(*p.Title) == "" {
+			return nil, fmt.Errorf("title must not be empty")
+		}
+		p.apply(n)
+		if err := fs.SaveNote(n); err != nil {
+			return nil, err
+		}
+		return toResult(n, true), nil
+	case "delete":
+		if _, err := d.find(p.ID); err != nil {
+			return nil, err
+		}
+		if err := fs.DeleteNote(p.ID); err != nil {
+			return nil, err
+		}
+		return true, nil
+	case "tags":
+		notes, err := fs.GetAllNotes()
+		if err != nil {
+			return nil, err
+		}
+		return countTags(notes), nil
+	}
+	return nil, fmt.Errorf("unknown method '%s' (use %s)", method, strings.Join(Methods, ", "))
+}
+
+func (d *Daemon) find(id string) (*note.Note, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	return d.storage.FindNoteByID(id)
+}
+
+func (p params) apply(n *note.Note) {
+	if p.Title != nil {
+		n.Metadata.Title = strings.TrimSpace(*p.Title)
+	}
+	if p.Content != nil {
+		n.UpdateContent(*p.Content)
+	}
+	if p.Tags != nil {
+		n.UpdateTags(*p.Tags)
+	}
+	if p.Author != nil {
+		n.Metadata.Author = *p.Author
+	}
+	if p.Status != nil {
+		n.Metadata.Status = *p.Status
+	}
+	if p.Priority != nil {
+		n.Metadata.Priority = *p.Priority
+	}
+}
+
+func toResult(n *note.Note, withContent bool) noteResult {
+	tags := n.Metadata.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	r := noteResult{
+		ID:        n.ID(),
+		Title:     n.Metadata.Title,
+		Created:   n.Metadata.Created,
+		Modified:  n.Metadata.Modified,
+		Tags:      tags,
+		Author:    n.Metadata.Author,
+		Status:    n.Metadata.Status,
+		Priority:  n.Metadata.Priority,
+		Encrypted: n.Metadata.Encrypted,
+		Path:      n.FilePath,
+	}
+	if withContent && !n.Locked() {
+		r.Content = &n.Content
+	}
+	return r
+}
+
+func toResults(notes []*note.Note) []noteResult {
+	results := make([]noteResult, 0, len(notes))
+	for _, n := range notes {
+		results = append(results, toResult(n, false))
+	}
+	return results
+}
+
+func countTags(notes []*note.Note) []tagResult {
+	counts := make(map[string]int)
+	for _, n := range notes {
+		for _, tag := range n.Metadata.Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]tagResult, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, tagResult{Name: name, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags
+}
@@ -0,0 +1,320 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"memo/internal/index"
+	"memo/internal/note"
+	"memo/internal/storage"
+)
+
+// Issue kinds reported by Check
+const (
+	KindParseError       = "parse-error"
+	KindMissingField     = "missing-field"
+	KindBrokenAttachment = "broken-attachment"
+	KindDanglingLink     = "dangling-link"
+	KindDuplicateID      = "duplicate-id"
+	KindExternalChange   = "external-change"
+	KindUnusedAttachment = "unused-attachment"
+	KindLargeNote        = "large-note"
+)
+
+// Issue is one problem found in the store
+type Issue struct {
+	Kind    string
+	Path    string
+	Message string
+	Fixable bool
+
+	fix func() error
+}
+
+// Report is the result of checking a store
+type Report struct {
+	NotesChecked int
+	Issues       []Issue
+}
+
+// Fixable returns the number of issues that Fix can repair
+func (r *Report) Fixable() int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Fixable {
+			count++
+		}
+	}
+	return count
+}
+
+// checker inspects the store and appends issues to the report
+type checker struct {
+	fs     *storage.FileStorage
+	report *Report
+	notes  []*note.Note
+}
+
+// Check validates every note file in the store
+func Check(fs *storage.FileStorage) (*Report, error) {
+	files, err := fs.NoteFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &checker{fs: fs, report: &Report{NotesChecked: len(files)}}
+	for _, file := range files {
+		c.checkFile(file)
+	}
+	c.checkDuplicateIDs(files)
+	c.checkLinks()
+	if err := c.checkIndex(); err != nil {
+		return nil, err
+	}
+	if err := c.checkAttachments(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(c.report.Issues, func(i, j int) bool {
+		return c.report.Issues[i].Path < c.report.Issues[j].Path
+	})
+	return c.report, nil
+}
+
+func (c *checker) add(issue Issue) {
+	issue.Fixable = issue.fix != nil
+	c.report.Issues = append(c.report.Issues, issue)
+}
+
+func (c *checker) checkFile(path string) {
+	n, err := c.fs.ParseNote(path)
+	if err != nil {
+		issue := Issue{Kind: KindParseError, Path: path, Message: err.Error()}
+		if data, readErr := os.ReadFile(path); readErr == nil && !strings.HasPrefix(string(data), "---\n") {
+			issue.Message += "; --fix adds front matter"
+			issue.fix = func() error { return c.addFrontMatter(path, string(data)) }
+		}
+		c.add(issue)
+		return
+	}
+	c.notes = append(c.notes, n)
+
+	var missing []string
+	if strings.TrimSpace(n.Metadata.Title) == "" {
+		missing = append(missing, "title")
+	}
+	if n.Metadata.Created.IsZero() {
+		missing = append(missing, "created")
+	}
+	if n.Metadata.Modified.IsZero() {
+		missing = append(missing, "modified")
+	}
+	if len(missing) > 0 {
+		c.add(Issue{
+			Kind:    KindMissingField,
+			Path:    path,
+			Message: "missing required field(s): " + strings.Join(missing, ", "),
+			fix:     func() error { return c.fillRequiredFields(n) },
+		})
+	}
+
+	if limits := c.fs.SizeLimits(); limits.Exceeds(n) {
+		bytes, lines := n.Size()
+		var over []string
+		if limits.Bytes > 0 && bytes > limits.Bytes {
+			over = append(over, fmt.Sprintf("%d bytes (limit %d)", bytes, limits.Bytes))
+		}
+		if limits.Lines > 0 && lines > limits.Lines {
+			over = append(over, fmt.Sprintf("%d lines (limit %d)", lines, limits.Lines))
+		}
+		c.add(Issue{
+			Kind:    KindLargeNote,
+			Path:    path,
+			Message: fmt.Sprintf("note has %s; 'memo split %s' breaks it up", strings.Join(over, " and "), n.ID()),
+		})
+	}
+
+	for _, ref := range n.LocalReferences() {
+		target := ref
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(c.fs.NotesDir(), ref)
+		}
+		if _, err := os.Stat(target); os.IsNotExist(err) {
+			c.add(Issue{Kind: KindBrokenAttachment, Path: path, Message: fmt.Sprintf("referenced file not found: %s", ref)})
+		}
+	}
+}
+
+// checkDuplicateIDs reports IDs that differ only by case; they collide on
+// case-insensitive file systems
+func (c *checker) checkDuplicateIDs(files []string) {
+	seen := make(map[string]string)
+	for _, path := range files {
+		id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		key := strings.ToLower(id)
+		if first, ok := seen[key]; ok {
+			dup := path
+			c.add(Issue{
+				Kind:    KindDuplicateID,
+				Path:    path,
+				Message: fmt.Sprintf("ID collides with %s on case-insensitive file systems", filepath.Base(first)),
+				fix:     func() error { return c.renameDuplicate(dup, seen) },
+			})
+			continue
+		}
+		seen[key] = path
+	}
+}
+
+func (c *checker) checkLinks() {
+	targets := make(map[string]bool)
+	for _, n := range c.notes {
+		targets[strings.ToLower(n.ID())] = true
+		targets[strings.ToLower(n.Metadata.Title)] = true
+	}
+
+	for _, n := range c.notes {
+		for _, link := range n.WikiLinks() {
+			if !targets[strings.ToLower(link)] {
+				c.add(Issue{Kind: KindDanglingLink, Path: n.FilePath, Message: fmt.Sprintf("link target not found: [[%s]]", link)})
+			}
+		}
+	}
+}
+
+// externalMessages describe how a note file differs from the content index
+var externalMessages = map[index.Status]string{
+	index.Touched:  "modification time changed outside memo, content unchanged",
+	index.Modified: "modified outside memo",
+	index.Added:    "created outside memo",
+	index.Missing:  "deleted outside memo",
+}
+
+// checkIndex reports note files changed since memo last wrote them; fixing
+// accepts the changes into the index
+func (c *checker) checkIndex() error {
+	changes, exists, err := c.fs.IndexChanges()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		c.add(Issue{
+			Kind:    KindExternalChange,
+			Path:    c.fs.StorePath(index.FileName),
+			Message: "no content index yet, so outside changes cannot be detected",
+			fix:     func() error { return c.fs.RefreshIndex() },
+		})
+		return nil
+	}
+
+	for _, change := range changes {
+		path := change.Path
+		if path == "" {
+			path = c.fs.GenerateNoteFilePath(change.ID)
+		}
+		id := change.ID
+		c.add(Issue{
+			Kind:    KindExternalChange,
+			Path:    path,
+			Message: externalMessages[change.Status],
+			fix:     func() error { return c.fs.RefreshIndex(id) },
+		})
+	}
+	return nil
+}
+
+// checkAttachments reports stored attachments no note or revision refers to
+// any more; fixing deletes them
+func (c *checker) checkAttachments() error {
+	blobs, err := c.fs.Attachments()
+	if err != nil {
+		return err
+	}
+	for _, b := range blobs {
+		if !b.Unreferenced() {
+			continue
+		}
+		name := b.Name
+		c.add(Issue{
+			Kind:    KindUnusedAttachment,
+			Path:    b.Path,
+			Message: fmt.Sprintf("attachment not referenced by any note (%d bytes)", b.Size),
+			fix:     func() error { return c.fs.RemoveAttachment(name) },
+		})
+	}
+	return nil
+}
+
+// Fix repairs every fixable issue and returns the number repaired
+func Fix(fs *storage.FileStorage, report *Report) (int, error) {
+	if err := fs.CheckWritable(); err != nil {
+		return 0, err
+	}
+
+	fixed := 0
+	for _, issue := range report.Issues {
+		if issue.fix == nil {
+			continue
+		}
+		if err := issue.fix(); err != nil {
+			return fixed, fmt.Errorf("error fixing %s: %w", issue.Path, err)
+		}
+		fixed++
+	}
+	return fixed, nil
+}
+
+func (c *checker) addFrontMatter(path, content string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	title := id
+	body := strings.TrimSpace(content)
+	if first, rest, _ := strings.Cut(body, "\n"); strings.HasPrefix(first, "# ") {
+		title = strings.TrimSpace(strings.TrimPrefix(first, "# "))
+		body = strings.TrimSpace(rest)
+	}
+
+	n := note.New(title, body, nil)
+	n.Metadata.Created = info.ModTime()
+	n.Metadata.Modified = info.ModTime()
+	n.SetFilePath(path)
+	return c.fs.RepairNote(n)
+}
+
+func (c *checker) fillRequiredFields(n *note.Note) error {
+	info, err := os.Stat(n.FilePath)
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(n.Metadata.Title) == "" {
+		n.Metadata.Title = n.ID()
+	}
+	if n.Metadata.Created.IsZero() {
+		n.Metadata.Created = info.ModTime()
+	}
+	if n.Metadata.Modified.IsZero() {
+		n.Metadata.Modified = info.ModTime()
+	}
+	return c.fs.RepairNote(n)
+}
+
+func (c *checker) renameDuplicate(path string, taken map[string]string) error {
+	id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	newID := id
+	for i := 2; ; i++ {
+		newID = fmt.Sprintf("%s_%d", id, i)
+		if _, ok := taken[strings.ToLower(newID)]; !ok {
+			break
+		}
+	}
+	taken[strings.ToLower(newID)] = path
+	return c.fs.RenameNoteFile(id, newID)
+}
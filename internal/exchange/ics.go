@@ -0,0 +1,122 @@
+package exchange
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+)
+
+// icsLineLimit is the maximum length of a content line in octets (RFC 5545)
+const icsLineLimit = 75
+
+// ToICS renders the notes that have a "due" date as an iCalendar file with
+// one event, and a reminder, per note. Notes without a due date are skipped;
+// the number of exported events is returned alongside the calendar.
+func ToICS(notes []*note.Note, now time.Time) (string, int) {
+	var b strings.Builder
+	w := NewICSWriter(&b, now)
+	w.Write(notes)
+	w.Close()
+	return b.String(), w.Count()
+}
+
+// ICSWriter writes the calendar ToICS renders to w one batch of notes at a
+// time, so a store of any size can be exported
+type ICSWriter struct {
+	w     *bufio.Writer
+	now   time.Time
+	count int
+}
+
+// NewICSWriter starts a calendar on w
+func NewICSWriter(w io.Writer, now time.Time) *ICSWriter {
+	e := &ICSWriter{w: bufio.NewWriter(w), now: now}
+	writeICSLine(e.w, "BEGIN:VCALENDAR")
+	writeICSLine(e.w, "VERSION:2.0")
+	writeICSLine(e.w, "PRODID:-//memo//notes//EN")
+	writeICSLine(e.w, "CALSCALE:GREGORIAN")
+	return e
+}
+
+// Write adds an event for every note with a due date
+func (e *ICSWriter) Write(notes []*note.Note) error {
+	for _, n := range notes {
+		due, allDay, ok := n.Due()
+		if !ok {
+			continue
+		}
+		e.count++
+
+		b := e.w
+		writeICSLine(b, "BEGIN:VEVENT")
+		writeICSLine(b, "UID:"+escapeICS(n.ID())+"@memo")
+		writeICSLine(b, "DTSTAMP:"+e.now.UTC().Format("20060102T150405Z"))
+		if allDay {
+			writeICSLine(b, "DTSTART;VALUE=DATE:"+due.Format("20060102"))
+			writeICSLine(b, "DTEND;VALUE=DATE:"+due.AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			writeICSLine(b, "DTSTART:"+due.UTC().Format("20060102T150405Z"))
+		}
+		writeICSLine(b, "SUMMARY:"+escapeICS(n.Metadata.Title))
+		if !n.Locked() && strings.TrimSpace(n.Content) != "" {
+			writeICSLine(b, "DESCRIPTION:"+escapeICS(n.Content))
+		}
+		if len(n.Metadata.Tags) > 0 {
+			tags := make([]string, len(n.Metadata.Tags))
+			for i, tag := range n.Metadata.Tags {
+				tags[i] = escapeICS(tag)
+			}
+			writeICSLine(b, "CATEGORIES:"+strings.Join(tags, ","))
+		}
+		writeICSLine(b, "LAST-MODIFIED:"+n.Metadata.Modified.UTC().Format("20060102T150405Z"))
+		writeICSLine(b, "BEGIN:VALARM")
+		writeICSLine(b, "ACTION:DISPLAY")
+		writeICSLine(b, "DESCRIPTION:"+escapeICS(n.Metadata.Title))
+		writeICSLine(b, "TRIGGER:PT0S")
+		writeICSLine(b, "END:VALARM")
+		writeICSLine(b, "END:VEVENT")
+	}
+	return e.w.Flush()
+}
+
+// Close ends the calendar
+func (e *ICSWriter) Close() error {
+	writeICSLine(e.w, "END:VCALENDAR")
+	return e.w.Flush()
+}
+
+// Count returns the number of events written
+func (e *ICSWriter) Count() int {
+	return e.count
+}
+
+// escapeICS escapes a TEXT value
+func escapeICS(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// writeICSLine writes a content line, folding it at icsLineLimit octets
+// without splitting UTF-8 sequences
+func writeICSLine(b *bufio.Writer, line string) {
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		fmt.Fprintf(b, "%s\r\n ", line[:cut])
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = icsLineLimit - 1
+	}
+	b.WriteString(line + "\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
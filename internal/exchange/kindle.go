@@ -0,0 +1,157 @@
+package exchange
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"memo/internal/note"
+)
+
+// kindleSeparator ends every entry of a Kindle "My Clippings.txt" file
+const kindleSeparator = "=========="
+
+// kindleAdded is the date format of English Kindle clippings
+const kindleAdded = "Monday, January 2, 2006 3:04:05 PM"
+
+var (
+	// kindleTitle splits "Title (Author)"; titles may contain parentheses
+	// themselves, so the author is the last parenthesized group
+	kindleTitle = regexp.MustCompile(`^(.*?)\s*\(([^()]*)\)$`)
+	kindleMeta  = regexp.MustCompile(`^- Your (\w+)(.*?)(?:\|\s*Added on (.*))?$`)
+	kindleWhere = regexp.MustCompile(`(?i)(page|location)\s+([\w-]+)`)
+)
+
+// Clipping is one highlight or note from a Kindle clippings file
+type Clipping struct {
+	Book   string
+	Author string
+	Kind   string // "Highlight" or "Note"
+	Where  string // e.g. "page 12, location 120-125"
+	Added  time.Time
+	Text   string
+}
+
+// ParseKindle reads the entries of a Kindle "My Clippings.txt" file.
+// Bookmarks and entries without text are skipped.
+func ParseKindle(text string) []Clipping {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var clippings []Clipping
+	for _, entry := range strings.Split(text, kindleSeparator) {
+		lines := strings.Split(strings.Trim(entry, "\n\ufeff "), "\n")
+		if len(lines) < 3 {
+			continue
+		}
+
+		c := Clipping{Book: strings.TrimSpace(strings.TrimPrefix(lines[0], "\ufeff"))}
+		if m := kindleTitle.FindStringSubmatch(c.Book); m != nil {
+			c.Book, c.Author = m[1], strings.TrimSpace(m[2])
+		}
+
+		if m := kindleMeta.FindStringSubmatch(strings.TrimSpace(lines[1])); m != nil {
+			c.Kind = m[1]
+			var where []string
+			for _, w := range kindleWhere.FindAllStringSubmatch(m[2], -1) {
+				where = append(where, strings.ToLower(w[1])+" "+w[2])
+			}
+			c.Where = strings.Join(where, ", ")
+			if added, err := time.ParseInLocation(kindleAdded, strings.TrimSpace(m[3]), time.Local); err == nil {
+				c.Added = added
+			}
+		}
+
+		c.Text = strings.TrimSpace(strings.Join(lines[2:], "\n"))
+		if c.Kind == "Bookmark" || c.Text == "" {
+			continue
+		}
+		clippings = append(clippings, c)
+	}
+	return clippings
+}
+
+// KindleNotes turns clippings into notes, one per book or, with
+// perHighlight, one per clipping. Notes are tagged "kindle", "book/<title>"
+// and "author/<name>".
+func KindleNotes(clippings []Clipping, perHighlight bool) []*note.Note {
+	if perHighlight {
+		notes := make([]*note.Note, 0, len(clippings))
+		for _, c := range clippings {
+			title := c.Book
+			if c.Where != "" {
+				title += " (" + c.Where + ")"
+			}
+			notes = append(notes, kindleNote(title, quoteClipping(c, false), c, c.Added))
+		}
+		return notes
+	}
+
+	var books []string
+	byBook := make(map[string][]Clipping)
+	for _, c := range clippings {
+		key := c.Book + "\x00" + c.Author
+		if _, ok := byBook[key]; !ok {
+			books = append(books, key)
+		}
+		byBook[key] = append(byBook[key], c)
+	}
+
+	notes := make([]*note.Note, 0, len(books))
+	for _, key := range books {
+		group := byBook[key]
+		var parts []string
+		first := group[0].Added
+		for _, c := range group {
+			parts = append(parts, quoteClipping(c, true))
+			if !c.Added.IsZero() && (first.IsZero() || c.Added.Before(first)) {
+				first = c.Added
+			}
+		}
+		notes = append(notes, kindleNote(group[0].Book, strings.Join(parts, "\n\n"), group[0], first))
+	}
+	return notes
+}
+
+func kindleNote(title, content string, c Clipping, created time.Time) *note.Note {
+	tags := []string{"kindle", "book/" + tagSlug(c.Book)}
+	if c.Author != "" {
+		tags = append(tags, "author/"+tagSlug(c.Author))
+	}
+	n := note.New(title, content, tags)
+	n.Metadata.Author = c.Author
+	if !created.IsZero() {
+		n.Metadata.Created = created
+		n.Metadata.Modified = created
+	}
+	return n
+}
+
+// quoteClipping renders a highlight as a block quote and a Kindle note as
+// plain text, optionally followed by where it was made
+func quoteClipping(c Clipping, withWhere bool) string {
+	text := c.Text
+	if c.Kind != "Note" {
+		text = "> " + strings.ReplaceAll(text, "\n", "\n> ")
+	}
+	if withWhere && c.Where != "" {
+		text += fmt.Sprintf("\n\n— %s", c.Where)
+	}
+	return text
+}
+
+// tagSlug makes a tag component from free text: lowercase words of letters
+// and digits joined by hyphens, so no "/" accidentally nests tags
+func tagSlug(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		b.WriteString(word)
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package exchange
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+	"memo/internal/storage"
+)
+
+// ToMarkdown renders a note as a standalone Markdown document with YAML
+// front matter. The note must not be locked; the exported copy is plain text.
+func ToMarkdown(n *note.Note) (string, error) {
+	if n.Locked() {
+		return "", fmt.Errorf("note '%s' is encrypted", n.Metadata.Title)
+	}
+
+	exported := &note.Note{Metadata: n.Metadata, Content: n.Content}
+	exported.Metadata.Encrypted = false
+
+	text, err := exported.Format()
+	if err != nil {
+		return "", err
+	}
+	return text + "\n", nil
+}
+
+// FromMarkdown parses a Markdown document. Front matter is optional: without
+// it the title is taken from a leading "# " heading, or fallbackTitle.
+func FromMarkdown(text, fallbackTitle string) (*note.Note, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	if strings.HasPrefix(text, "---\n") {
+		n, err := storage.ParseNoteContent(text)
+		if err != nil {
+			return nil, err
+		}
+		if n.Metadata.Title == "" {
+			n.Metadata.Title = fallbackTitle
+		}
+		fillTimestamps(n)
+		return n, nil
+	}
+
+	title := fallbackTitle
+	content := strings.TrimSpace(text)
+	if first, rest, _ := strings.Cut(content, "\n"); strings.HasPrefix(first, "# ") {
+		title = strings.TrimSpace(strings.TrimPrefix(first, "# "))
+		content = strings.TrimSpace(rest)
+	}
+
+	return note.New(title, content, nil), nil
+}
+
+func fillTimestamps(n *note.Note) {
+	now := time.Now()
+	if n.Metadata.Created.IsZero() {
+		n.Metadata.Created = now
+	}
+	if n.Metadata.Modified.IsZero() {
+		n.Metadata.Modified = n.Metadata.Created
+	}
+}
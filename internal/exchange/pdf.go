@@ -0,0 +1,68 @@
+package exchange
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"memo/internal/attachments"
+	"memo/internal/note"
+	"memo/internal/render"
+)
+
+// ToPDF renders a note as a printable PDF: the title and front matter at the
+// top, then the content, then the attachments the note refers to. The note
+// must not be locked.
+func ToPDF(n *note.Note) ([]byte, error) {
+	if n.Locked() {
+		return nil, fmt.Errorf("note '%s' is encrypted", n.Metadata.Title)
+	}
+
+	m := n.Metadata
+	metadata := []string{
+		"Created: " + m.Created.Format("2006-01-02 15:04"),
+		"Modified: " + m.Modified.Format("2006-01-02 15:04"),
+	}
+	if len(m.Tags) > 0 {
+		metadata = append(metadata, "Tags: "+strings.Join(m.Tags, ", "))
+	}
+	if m.Author != "" {
+		metadata = append(metadata, "Author: "+m.Author)
+	}
+	if m.Status != "" {
+		metadata = append(metadata, "Status: "+m.Status)
+	}
+	if m.Priority != 0 {
+		metadata = append(metadata, "Priority: "+strconv.Itoa(m.Priority))
+	}
+	if m.Location != nil {
+		metadata = append(metadata, "Location: "+m.Location.String())
+	}
+	if !m.Expires.IsZero() {
+		metadata = append(metadata, "Expires: "+m.Expires.Format("2006-01-02 15:04"))
+	}
+
+	var attached []string
+	for _, name := range attachments.Scan(n.Content) {
+		attached = append(attached, attachmentLabel(n.Content, name))
+	}
+
+	return render.ToPDF(render.PDFDocument{
+		Title:       m.Title,
+		Metadata:    metadata,
+		Markdown:    n.Content,
+		Attachments: attached,
+	}), nil
+}
+
+// attachmentLabel names a stored attachment after the text of the first
+// link to it, which is usually the original file name, followed by the
+// start of its hash
+func attachmentLabel(content, name string) string {
+	link := regexp.MustCompile(`\[([^\]]+)\]\(` + regexp.QuoteMeta(attachments.Link(name)) + `\)`)
+	if m := link.FindStringSubmatch(content); m != nil && m[1] != name {
+		return m[1] + " (" + name[:12] + "…)"
+	}
+	return name
+}
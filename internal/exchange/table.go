@@ -0,0 +1,221 @@
+package exchange
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+)
+
+// columnAliases maps the header names found in spreadsheets and task manager
+// exports to note fields. Other columns become custom front matter fields.
+var columnAliases = map[string]string{
+	"title":        "title",
+	"name":         "title",
+	"subject":      "title",
+	"content":      "content",
+	"body":         "content",
+	"text":         "content",
+	"notes":        "content",
+	"tags":         "tags",
+	"labels":       "tags",
+	"created":      "created",
+	"created_at":   "created",
+	"date":         "created",
+	"modified":     "modified",
+	"updated":      "modified",
+	"updated_at":   "modified",
+	"author":       "author",
+	"status":       "status",
+	"priority":     "priority",
+	"due":          "due",
+	"due_date":     "due",
+	"deadline":     "due",
+	"start":        "starts",
+	"starts":       "starts",
+	"start_date":   "starts",
+	"duration":     "duration",
+	"completed":    "completed_at",
+	"completed_at": "completed_at",
+	"expires":      "expires",
+	"location":     "location",
+}
+
+// timeLayouts are tried in order when parsing created and modified columns
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"01/02/2006",
+}
+
+// FromCSV reads one note per row. The header row names the columns: title,
+// content, tags (separated by commas or semicolons) and created are
+// recognized along with common aliases, and any other column is kept as a
+// custom field. Errors name the offending line.
+func FromCSV(r io.Reader) ([]*note.Note, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %w", err)
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	var notes []*note.Note
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(record) {
+				row[name] = record[i]
+			}
+		}
+		n, err := fromRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		notes = append(notes, n)
+	}
+	return notes, nil
+}
+
+// FromJSON reads an array of objects using the same keys as FromCSV. Tags
+// may be given as an array or as a separated string.
+func FromJSON(data []byte) ([]*note.Note, error) {
+	var records []map[string]any
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of objects: %w", err)
+	}
+
+	notes := make([]*note.Note, 0, len(records))
+	for i, record := range records {
+		row := make(map[string]string, len(record))
+		for key, value := range record {
+			switch v := value.(type) {
+			case nil:
+			case string:
+				row[key] = v
+			case []any:
+				parts := make([]string, 0, len(v))
+				for _, item := range v {
+					parts = append(parts, fmt.Sprint(item))
+				}
+				row[key] = strings.Join(parts, ",")
+			default:
+				row[key] = fmt.Sprint(v)
+			}
+		}
+		n, err := fromRow(row)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+		notes = append(notes, n)
+	}
+	return notes, nil
+}
+
+// fromRow builds a note from column values keyed by header name
+func fromRow(row map[string]string) (*note.Note, error) {
+	n := note.New("", "", nil)
+	modified := false
+	for column, value := range row {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		field, known := columnAliases[strings.ToLower(strings.TrimSpace(column))]
+		switch {
+		case !known:
+			if err := n.SetField(strings.TrimSpace(column), value); err != nil {
+				return nil, err
+			}
+		case field == "title":
+			n.Metadata.Title = value
+		case field == "content":
+			n.Content = value
+		case field == "tags":
+			n.Metadata.Tags = splitTags(value)
+		case field == "created", field == "modified":
+			t, err := parseTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s date '%s'", column, value)
+			}
+			if field == "created" {
+				n.Metadata.Created = t
+			} else {
+				n.Metadata.Modified = t
+				modified = true
+			}
+		case field == "author":
+			n.Metadata.Author = value
+		case field == "status":
+			n.Metadata.Status = value
+		case field == "priority":
+			p, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid priority '%s'", value)
+			}
+			n.Metadata.Priority = p
+		case field == "expires":
+			t, err := note.ParseExpiry(value, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			n.Metadata.Expires = t
+		case field == "location":
+			location := note.ParseLocation(value)
+			n.Metadata.Location = &location
+		case slices.Contains(note.DateFields, field):
+			if err := n.SetDateField(field, value, time.Now()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if n.Metadata.Title == "" {
+		return nil, fmt.Errorf("title is empty")
+	}
+	if !modified {
+		n.Metadata.Modified = n.Metadata.Created
+	}
+	return n, nil
+}
+
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time '%s'", value)
+}
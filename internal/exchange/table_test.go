@@ -0,0 +1,32 @@
+package exchange
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromCSVBuiltInColumns(t *testing.T) {
+	notes, err := FromCSV(strings.NewReader("title,expires,location,project\nTrip,2030-05-01,Lisbon,travel\n"))
+	if err != nil {
+		t.Fatalf("FromCSV = %v", err)
+	}
+	n := notes[0]
+	if got := n.Metadata.Expires.Format("2006-01-02"); got != "2030-05-01" {
+		t.Errorf("expires = %s, want 2030-05-01", got)
+	}
+	if n.Metadata.Location == nil || n.Metadata.Location.Name != "Lisbon" {
+		t.Errorf("location = %v, want Lisbon", n.Metadata.Location)
+	}
+	if values, _ := n.Field("project"); len(values) != 1 || values[0] != "travel" {
+		t.Errorf("project = %q, want travel", values)
+	}
+	if _, err := n.Format(); err != nil {
+		t.Errorf("Format = %v", err)
+	}
+
+	for _, column := range []string{"encrypted", "clock", "Clock"} {
+		if _, err := FromCSV(strings.NewReader("title," + column + "\nTrip,x\n")); err == nil {
+			t.Errorf("FromCSV with a %s column succeeded, want an error", column)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package exchange
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+)
+
+// todo.txt dates and priorities (http://todotxt.org). Note priority 1 is
+// (A), 2 is (B) and so on up to 26; higher numbers have no todo.txt
+// equivalent and are left out.
+var (
+	todoDatePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	todoPriorityPattern = regexp.MustCompile(`^\(([A-Z])\)$`)
+	todoKeyValuePattern = regexp.MustCompile(`^([^\s:]+):(\S+)$`)
+)
+
+// ToTodoTxt renders every checklist task of the notes as a todo.txt line.
+// The note supplies what todo.txt tracks per task: its priority, creation
+// date, due date and tags, which become +projects, or @contexts when they
+// start with "@". Done tasks use the note's completed_at field as their
+// completion date, or else its modification time. The number of exported
+// tasks is returned alongside.
+func ToTodoTxt(notes []*note.Note) (string, int) {
+	var b strings.Builder
+	w := NewTodoTxtWriter(&b)
+	w.Write(notes)
+	return b.String(), w.Count()
+}
+
+// TodoTxtWriter writes the lines ToTodoTxt renders to w one batch of notes
+// at a time
+type TodoTxtWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+// NewTodoTxtWriter writes todo.txt lines to w
+func NewTodoTxtWriter(w io.Writer) *TodoTxtWriter {
+	return &TodoTxtWriter{w: bufio.NewWriter(w)}
+}
+
+// Write adds a line for every checklist task of the notes
+func (e *TodoTxtWriter) Write(notes []*note.Note) error {
+	for _, n := range notes {
+		if n.Locked() {
+			continue
+		}
+		for _, task := range n.Tasks() {
+			e.w.WriteString(todoLine(n, task))
+			e.w.WriteByte('\n')
+			e.count++
+		}
+	}
+	return e.w.Flush()
+}
+
+// Count returns the number of tasks written
+func (e *TodoTxtWriter) Count() int {
+	return e.count
+}
+
+func todoLine(n *note.Note, task note.Task) string {
+	var parts []string
+	if task.Done {
+		completed := n.Metadata.Modified.Format("2006-01-02")
+		if n.Metadata.CompletedAt.Valid() {
+			completed = n.Metadata.CompletedAt.Time.Format("2006-01-02")
+		} else if values, ok := n.Field("completed"); ok && todoDatePattern.MatchString(values[0]) {
+			completed = values[0]
+		}
+		parts = append(parts, "x", completed)
+	} else if p := n.Metadata.Priority; p >= 1 && p <= 26 {
+		parts = append(parts, fmt.Sprintf("(%c)", 'A'+p-1))
+	}
+	if !n.Metadata.Created.IsZero() {
+		parts = append(parts, n.Metadata.Created.Format("2006-01-02"))
+	}
+	parts = append(parts, task.Text)
+
+	for _, tag := range n.Metadata.Tags {
+		tag = strings.ReplaceAll(tag, " ", "_")
+		if strings.HasPrefix(tag, "@") {
+			parts = append(parts, tag)
+		} else {
+			parts = append(parts, "+"+tag)
+		}
+	}
+	if due, _, ok := n.Due(); ok {
+		parts = append(parts, "due:"+due.Format("2006-01-02"))
+	}
+	return strings.Join(parts, " ")
+}
+
+// FromTodoTxt creates a note per todo.txt line, holding the task as a
+// checklist item. The priority, dates, +projects and @contexts map back as
+// ToTodoTxt writes them; completed tasks get the status "done" and a
+// completed_at date, due: sets the due date, and other key:value pairs
+// become custom fields.
+func FromTodoTxt(text string) ([]*note.Note, error) {
+	var notes []*note.Note
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n, err := fromTodoLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		notes = append(notes, n)
+	}
+	return notes, nil
+}
+
+func fromTodoLine(line string) (*note.Note, error) {
+	fields := strings.Fields(line)
+	n := note.New("", "", nil)
+	done := false
+
+	if len(fields) > 0 && fields[0] == "x" {
+		done = true
+		n.Metadata.Status = "done"
+		fields = fields[1:]
+	}
+	if len(fields) > 0 {
+		if m := todoPriorityPattern.FindStringSubmatch(fields[0]); m != nil {
+			n.Metadata.Priority = int(m[1][0]-'A') + 1
+			fields = fields[1:]
+		}
+	}
+
+	// A done task may carry a completion date before the creation date; a
+	// single date on a done task is its completion date
+	var dates []time.Time
+	for len(fields) > 0 && len(dates) < 2 && todoDatePattern.MatchString(fields[0]) {
+		t, err := time.ParseInLocation("2006-01-02", fields[0], time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date '%s'", fields[0])
+		}
+		dates = append(dates, t)
+		fields = fields[1:]
+	}
+	if len(dates) > 0 {
+		n.Metadata.Created = dates[len(dates)-1]
+		n.Metadata.Modified = dates[len(dates)-1]
+		if done {
+			n.Metadata.CompletedAt = note.Moment{Time: dates[0], AllDay: true}
+		}
+	}
+
+	var words []string
+	for _, field := range fields {
+		switch {
+		case len(field) > 1 && field[0] == '+':
+			n.Metadata.Tags = append(n.Metadata.Tags, field[1:])
+		case len(field) > 1 && field[0] == '@':
+			n.Metadata.Tags = append(n.Metadata.Tags, field)
+		case todoKeyValuePattern.MatchString(field) && !strings.Contains(field, "://"):
+			m := todoKeyValuePattern.FindStringSubmatch(field)
+			if m[1] == "due" {
+				if err := n.SetDateField("due", m[2], time.Now()); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err := n.SetField(m[1], m[2]); err != nil {
+				return nil, err
+			}
+		default:
+			words = append(words, field)
+		}
+	}
+
+	text := strings.Join(words, " ")
+	if text == "" {
+		return nil, fmt.Errorf("task has no text")
+	}
+	n.Metadata.Title = text
+	mark := " "
+	if done {
+		mark = "x"
+	}
+	n.Content = fmt.Sprintf("- [%s] %s", mark, text)
+	return n, nil
+}
@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteDOT writes the graph in Graphviz's DOT language, with each group in
+// a cluster of its own. Links are arrows, tags dashed lines.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	b.WriteString("digraph memo {\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+
+	for i, group := range g.groups() {
+		b.WriteString("  subgraph cluster_" + strconv.Itoa(i) + " {\n")
+		b.WriteString("    label=" + dotQuote(group) + ";\n")
+		for _, n := range g.Nodes {
+			if n.Group == group {
+				b.WriteString("    " + dotNode(n) + "\n")
+			}
+		}
+		b.WriteString("  }\n")
+	}
+	for _, n := range g.Nodes {
+		if n.Group == "" {
+			b.WriteString("  " + dotNode(n) + "\n")
+		}
+	}
+
+	for _, e := range g.Edges {
+		line := "  " + dotQuote(e.From) + " -> " + dotQuote(e.To)
+		if e.Kind == KindTag {
+			line += " [style=dashed, arrowhead=none]"
+		}
+		b.WriteString(line + ";\n")
+	}
+	b.WriteString("}\n")
+	return b.Flush()
+}
+
+func dotNode(n Node) string {
+	attrs := []string{"label=" + dotQuote(n.Label)}
+	if n.Kind == KindTag {
+		attrs = append(attrs, "shape=ellipse", "style=\"\"")
+	}
+	if n.Color != "" {
+		attrs = append(attrs, "color="+dotQuote(n.Color))
+	}
+	return dotQuote(n.ID) + " [" + strings.Join(attrs, ", ") + "];"
+}
+
+// dotQuote makes s a quoted DOT ID
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
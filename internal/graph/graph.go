@@ -0,0 +1,141 @@
+// Package graph builds the graph of notes linked by [[wikilinks]] and
+// sharing tags, and writes it for Graphviz (DOT) or Gephi (GraphML).
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"memo/internal/note"
+)
+
+// Node kinds and edge kinds
+const (
+	KindNote = "note"
+	KindTag  = "tag"
+	KindLink = "link"
+)
+
+// Ways of grouping the notes of a graph
+const (
+	ClusterTag      = "tag"
+	ClusterNotebook = "notebook"
+)
+
+// Node is a note or a tag
+type Node struct {
+	ID    string
+	Label string
+	Kind  string
+	// Group is the cluster the node is drawn in, or "" for none
+	Group string
+	// Color is the note's color field
+	Color string
+}
+
+// Edge links a note to a note it links to or to one of its tags
+type Edge struct {
+	From string
+	To   string
+	Kind string
+}
+
+// Graph is the notes and tags of a store and the edges between them
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+}
+
+// Options control what Build puts into the graph
+type Options struct {
+	// Cluster groups the notes by ClusterTag, their first tag, or by
+	// ClusterNotebook. Clustering by tag leaves the tag nodes out.
+	Cluster string
+	// Tags adds a node for every tag, with an edge from each note having it
+	Tags bool
+	// NotebookOf returns the notebook of a note for ClusterNotebook
+	NotebookOf func(*note.Note) string
+}
+
+// Build makes the graph of notes. Links are resolved like everywhere else:
+// to the note whose ID or title they name, ignoring case; links to missing
+// notes are left out.
+func Build(notes []*note.Note, opts Options) (*Graph, error) {
+	switch opts.Cluster {
+	case "", ClusterTag, ClusterNotebook:
+	default:
+		return nil, fmt.Errorf("unknown cluster '%s' (use %s or %s)", opts.Cluster, ClusterTag, ClusterNotebook)
+	}
+	withTags := opts.Tags && opts.Cluster != ClusterTag
+
+	targets := make(map[string]string)
+	for _, n := range notes {
+		targets[strings.ToLower(n.Metadata.Title)] = n.ID()
+	}
+	// IDs win over titles that happen to match them
+	for _, n := range notes {
+		targets[strings.ToLower(n.ID())] = n.ID()
+	}
+
+	g := &Graph{}
+	tags := make(map[string]string)
+	for _, n := range notes {
+		node := Node{ID: n.ID(), Label: n.Metadata.Title, Kind: KindNote, Color: n.Color()}
+		switch opts.Cluster {
+		case ClusterTag:
+			if len(n.Metadata.Tags) > 0 {
+				node.Group = n.Metadata.Tags[0]
+			}
+		case ClusterNotebook:
+			if opts.NotebookOf != nil {
+				node.Group = opts.NotebookOf(n)
+			}
+		}
+		g.Nodes = append(g.Nodes, node)
+
+		linked := make(map[string]bool)
+		for _, link := range n.WikiLinks() {
+			to, ok := targets[strings.ToLower(link)]
+			if !ok || to == n.ID() || linked[to] {
+				continue
+			}
+			linked[to] = true
+			g.Edges = append(g.Edges, Edge{From: n.ID(), To: to, Kind: KindLink})
+		}
+
+		if !withTags {
+			continue
+		}
+		for _, tag := range n.Metadata.Tags {
+			id := "#" + strings.ToLower(tag)
+			if _, ok := tags[id]; !ok {
+				tags[id] = tag
+			}
+			g.Edges = append(g.Edges, Edge{From: n.ID(), To: id, Kind: KindTag})
+		}
+	}
+
+	ids := make([]string, 0, len(tags))
+	for id := range tags {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		g.Nodes = append(g.Nodes, Node{ID: id, Label: "#" + tags[id], Kind: KindTag})
+	}
+	return g, nil
+}
+
+// groups returns the names of the clusters in the order first seen
+func (g *Graph) groups() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, n := range g.Nodes {
+		if n.Group != "" && !seen[n.Group] {
+			seen[n.Group] = true
+			names = append(names, n.Group)
+		}
+	}
+	return names
+}
@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// WriteGraphML writes the graph as GraphML, which Gephi and yEd open. The
+// label, kind and group of nodes and the kind of edges are attributes,
+// so a tool can color or partition the graph by them.
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	b.WriteString(xml.Header)
+	b.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	b.WriteString(`  <key id="label" for="node" attr.name="label" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="kind" for="node" attr.name="kind" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="group" for="node" attr.name="group" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="color" for="node" attr.name="color" attr.type="string"/>` + "\n")
+	b.WriteString(`  <key id="edgekind" for="edge" attr.name="kind" attr.type="string"/>` + "\n")
+	b.WriteString(`  <graph id="memo" edgedefault="directed">` + "\n")
+
+	for _, n := range g.Nodes {
+		b.WriteString(`    <node id="` + xmlEscape(n.ID) + `">` + "\n")
+		writeData(b, "label", n.Label)
+		writeData(b, "kind", n.Kind)
+		writeData(b, "group", n.Group)
+		writeData(b, "color", n.Color)
+		b.WriteString("    </node>\n")
+	}
+	for _, e := range g.Edges {
+		b.WriteString(`    <edge source="` + xmlEscape(e.From) + `" target="` + xmlEscape(e.To) + `">` + "\n")
+		writeData(b, "edgekind", e.Kind)
+		b.WriteString("    </edge>\n")
+	}
+
+	b.WriteString("  </graph>\n</graphml>\n")
+	return b.Flush()
+}
+
+// writeData writes an attribute value unless it is empty
+func writeData(b *bufio.Writer, key, value string) {
+	if value != "" {
+		b.WriteString(`      <data key="` + key + `">` + xmlEscape(value) + "</data>\n")
+	}
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
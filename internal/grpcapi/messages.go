@@ -0,0 +1,131 @@
+package grpcapi
+
+import (
+	"time"
+
+	"memo/internal/note"
+)
+
+// The message types mirror api/memo/v1/memo.proto. Field numbers must be
+// kept in sync with that file.
+
+func encodeNote(n *note.Note, withContent bool) []byte {
+	var e encoder
+	e.string(1, n.ID())
+	e.string(2, n.Metadata.Title)
+	e.string(3, formatTime(n.Metadata.Created))
+	e.string(4, formatTime(n.Metadata.Modified))
+	e.repeatedString(5, n.Metadata.Tags)
+	e.string(6, n.Metadata.Author)
+	e.string(7, n.Metadata.Status)
+	e.int32(8, int32(n.Metadata.Priority))
+	e.bool(9, n.Metadata.Encrypted)
+	if withContent && !n.Locked() {
+		e.string(10, n.Content)
+	}
+	return e.buf
+}
+
+func encodeNoteList(notes []*note.Note) []byte {
+	var e encoder
+	for _, n := range notes {
+		e.message(1, encodeNote(n, false))
+	}
+	return e.buf
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// singleString decodes requests consisting of one string field numbered 1
+// (ListNotesRequest, GetNoteRequest, DeleteNoteRequest, SearchNotesRequest)
+func singleString(b []byte) (string, error) {
+	fields, err := decodeFields(b)
+	if err != nil {
+		return "", err
+	}
+	var s string
+	for _, f := range fields {
+		if f.number == 1 && f.wireType == wireBytes {
+			s = f.string()
+		}
+	}
+	return s, nil
+}
+
+type createNoteRequest struct {
+	title, content, author, status string
+	tags                           []string
+	priority                       int32
+}
+
+func decodeCreateNoteRequest(b []byte) (createNoteRequest, error) {
+	var req createNoteRequest
+	fields, err := decodeFields(b)
+	if err != nil {
+		return req, err
+	}
+	for _, f := range fields {
+		switch f.number {
+		case 1:
+			req.title = f.string()
+		case 2:
+			req.content = f.string()
+		case 3:
+			req.tags = append(req.tags, f.string())
+		case 4:
+			req.author = f.string()
+		case 5:
+			req.status = f.string()
+		case 6:
+			req.priority = f.int32()
+		}
+	}
+	return req, nil
+}
+
+type updateNoteRequest struct {
+	id                             string
+	title, content, author, status *string
+	tags                           []string
+	replaceTags                    bool
+	priority                       *int32
+}
+
+func decodeUpdateNoteRequest(b []byte) (updateNoteRequest, error) {
+	var req updateNoteRequest
+	fields, err := decodeFields(b)
+	if err != nil {
+		return req, err
+	}
+	for _, f := range fields {
+		switch f.number {
+		case 1:
+			req.id = f.string()
+		case 2:
+			s := f.string()
+			req.title = &s
+		case 3:
+			s := f.string()
+			req.content = &s
+		case 4:
+			req.tags = append(req.tags, f.string())
+		case 5:
+			req.replaceTags = f.bool()
+		case 6:
+			s := f.string()
+			req.author = &s
+		case 7:
+			s := f.string()
+			req.status = &s
+		case 8:
+			p := f.int32()
+			req.priority = &p
+		}
+	}
+	return req, nil
+}
@@ -0,0 +1,315 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+	"memo/internal/storage"
+)
+
+const (
+	servicePath = "/memo.v1.Notes/"
+
+	// maxMessageSize bounds request messages, matching gRPC's default
+	maxMessageSize = 4 << 20
+)
+
+// gRPC status codes
+const (
+	codeOK                 = 0
+	codeInvalidArgument    = 3
+	codeNotFound           = 5
+	codePermissionDenied   = 7
+	codeFailedPrecondition = 9
+	codeAborted            = 10
+	codeUnimplemented      = 12
+	codeInternal           = 13
+)
+
+// statusError carries a gRPC status code
+type statusError struct {
+	code    int
+	message string
+}
+
+func (e *statusError) Error() string { return e.message }
+
+func statusf(code int, format string, args ...any) error {
+	return &statusError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// Server implements the memo.v1.Notes gRPC service defined in
+// api/memo/v1/memo.proto on top of net/http's HTTP/2 support
+type Server struct {
+	storage *storage.FileStorage
+}
+
+func New(fs *storage.FileStorage) *Server {
+	return &Server{storage: fs}
+}
+
+// ListenAndServe serves gRPC over unencrypted HTTP/2 on addr
+func (s *Server) ListenAndServe(addr string) error {
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		Protocols:         &protocols,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
+
+// sendFunc writes one response message to the stream
+type sendFunc func(msg []byte) error
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "gRPC requests only", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Add("Trailer", "Grpc-Status")
+	w.Header().Add("Trailer", "Grpc-Message")
+
+	method := strings.TrimPrefix(r.URL.Path, servicePath)
+	start := time.Now()
+	err := s.dispatch(r.Context(), method, r.Body, func(msg []byte) error {
+		if err := writeFrame(w, msg); err != nil {
+			return err
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		return nil
+	})
+
+	code, message := codeOK, ""
+	if err != nil {
+		code, message = codeInternal, err.Error()
+		var se *statusError
+		if errors.As(err, &se) {
+			code = se.code
+		}
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	if message != "" {
+		w.Header().Set("Grpc-Message", url.PathEscape(message))
+	}
+
+	slog.Debug("grpc request", "method", method, "status", code, "duration", time.Since(start))
+}
+
+func (s *Server) dispatch(ctx context.Context, method string, body io.Reader, send sendFunc) error {
+	req, err := readFrame(body)
+	if err != nil {
+		return err
+	}
+
+	switch method {
+	case "ListNotes":
+		return s.listNotes(req, send)
+	case "GetNote":
+		return s.getNote(req, send)
+	case "CreateNote":
+		return s.createNote(req, send)
+	case "UpdateNote":
+		return s.updateNote(req, send)
+	case "DeleteNote":
+		return s.deleteNote(req, send)
+	case "SearchNotes":
+		return s.searchNotes(ctx, req, send)
+	}
+	return statusf(codeUnimplemented, "unknown method %s", method)
+}
+
+// readFrame reads one length-prefixed gRPC message
+func readFrame(r io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, statusf(codeInvalidArgument, "error reading request: %v", err)
+	}
+	if header[0] != 0 {
+		return nil, statusf(codeUnimplemented, "compressed messages are not supported")
+	}
+
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > maxMessageSize {
+		return nil, statusf(codeInvalidArgument, "request message too large")
+	}
+
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, statusf(codeInvalidArgument, "error reading request: %v", err)
+	}
+	return msg, nil
+}
+
+func writeFrame(w io.Writer, msg []byte) error {
+	var header [5]byte
+	binary.BigEndian.PutUint32(header[1:], uint32(len(msg)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(msg)
+	return err
+}
+
+// storageError maps storage failures to gRPC status codes
+func storageError(err error) error {
+	var locked *storage.LockedError
+	switch {
+	case errors.Is(err, storage.ErrReadOnly):
+		return statusf(codePermissionDenied, "%v", err)
+	case errors.As(err, &locked):
+		return statusf(codeAborted, "%v", err)
+	default:
+		return statusf(codeInternal, "%v", err)
+	}
+}
+
+func (s *Server) listNotes(req []byte, send sendFunc) error {
+	tag, err := singleString(req)
+	if err != nil {
+		return statusf(codeInvalidArgument, "%v", err)
+	}
+
+	var notes []*note.Note
+	if tag != "" {
+		notes, err = s.storage.FilterNotesByTag(tag)
+	} else {
+		notes, err = s.storage.GetAllNotes()
+	}
+	if err != nil {
+		return storageError(err)
+	}
+	return send(encodeNoteList(notes))
+}
+
+func (s *Server) getNote(req []byte, send sendFunc) error {
+	id, err := singleString(req)
+	if err != nil {
+		return statusf(codeInvalidArgument, "%v", err)
+	}
+
+	n, err := s.storage.FindNoteByID(id)
+	if err != nil {
+		return statusf(codeNotFound, "%v", err)
+	}
+	return send(encodeNote(n, true))
+}
+
+func (s *Server) createNote(req []byte, send sendFunc) error {
+	in, err := decodeCreateNoteRequest(req)
+	if err != nil {
+		return statusf(codeInvalidArgument, "%v", err)
+	}
+	if strings.TrimSpace(in.title) == "" {
+		return statusf(codeInvalidArgument, "title is required")
+	}
+
+	n := note.New(strings.TrimSpace(in.title), in.content, in.tags)
+	n.Metadata.Author = in.author
+	n.Metadata.Status = in.status
+	n.Metadata.Priority = int(in.priority)
+
+	if _, err := s.storage.CreateNote(n); err != nil {
+		return storageError(err)
+	}
+	return send(encodeNote(n, true))
+}
+
+func (s *Server) updateNote(req []byte, send sendFunc) error {
+	in, err := decodeUpdateNoteRequest(req)
+	if err != nil {
+		return statusf(codeInvalidArgument, "%v", err)
+	}
+
+	n, err := s.storage.FindNoteByID(in.id)
+	if err != nil {
+		return statusf(codeNotFound, "%v", err)
+	}
+	if in.content != nil && n.Locked() {
+		return statusf(codeFailedPrecondition, "content of encrypted notes cannot be changed remotely")
+	}
+
+	if in.title != nil {
+		if strings.TrimSpace(*in.title) == "" {
+			return statusf(codeInvalidArgument, "title must not be empty")
+		}
+		n.Metadata.Title = strings.TrimSpace(*in.title)
+	}
+	if in.content != nil {
+		n.UpdateContent(*in.content)
+	}
+	if in.replaceTags {
+		n.UpdateTags(in.tags)
+	}
+	if in.author != nil {
+		n.Metadata.Author = *in.author
+	}
+	if in.status != nil {
+		n.Metadata.Status = *in.status
+	}
+	if in.priority != nil {
+		n.Metadata.Priority = int(*in.priority)
+	}
+
+	if err := s.storage.SaveNote(n); err != nil {
+		return storageError(err)
+	}
+	return send(encodeNote(n, true))
+}
+
+func (s *Server) deleteNote(req []byte, send sendFunc) error {
+	id, err := singleString(req)
+	if err != nil {
+		return statusf(codeInvalidArgument, "%v", err)
+	}
+
+	if _, err := s.storage.FindNoteByID(id); err != nil {
+		return statusf(codeNotFound, "%v", err)
+	}
+	if err := s.storage.DeleteNote(id); err != nil {
+		return storageError(err)
+	}
+	return send(nil)
+}
+
+// searchNotes sends each matching note as soon as a search worker finds
+// it; the search stops when the client goes away
+func (s *Server) searchNotes(ctx context.Context, req []byte, send sendFunc) error {
+	query, err := singleString(req)
+	if err != nil {
+		return statusf(codeInvalidArgument, "%v", err)
+	}
+	if query == "" {
+		return statusf(codeInvalidArgument, "query is required")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	notes, err := s.storage.SearchStream(ctx, query, storage.SearchOptions{})
+	if err != nil {
+		return storageError(err)
+	}
+	for n := range notes {
+		if err := send(encodeNote(n, false)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
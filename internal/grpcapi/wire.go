@@ -0,0 +1,120 @@
+package grpcapi
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Protocol buffer wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("truncated protobuf message")
+
+// encoder appends protobuf fields to a buffer. Zero values are omitted, as
+// proto3 does for fields without explicit presence.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) tag(field, wireType int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field)<<3|uint64(wireType))
+}
+
+func (e *encoder) bytes(field int, b []byte) {
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) string(field int, s string) {
+	if s != "" {
+		e.bytes(field, []byte(s))
+	}
+}
+
+func (e *encoder) repeatedString(field int, values []string) {
+	for _, s := range values {
+		e.bytes(field, []byte(s))
+	}
+}
+
+func (e *encoder) int32(field int, v int32) {
+	if v != 0 {
+		e.tag(field, wireVarint)
+		// Negative int32 values are sign-extended to 64 bits
+		e.buf = binary.AppendUvarint(e.buf, uint64(int64(v)))
+	}
+}
+
+func (e *encoder) bool(field int, v bool) {
+	if v {
+		e.tag(field, wireVarint)
+		e.buf = append(e.buf, 1)
+	}
+}
+
+func (e *encoder) message(field int, m []byte) {
+	e.bytes(field, m)
+}
+
+// field is one decoded protobuf field
+type field struct {
+	number   int
+	wireType int
+	varint   uint64
+	data     []byte
+}
+
+func (f field) string() string { return string(f.data) }
+func (f field) int32() int32   { return int32(f.varint) }
+func (f field) bool() bool     { return f.varint != 0 }
+
+// decodeFields splits a protobuf message into its fields
+func decodeFields(b []byte) ([]field, error) {
+	var fields []field
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errTruncated
+		}
+		b = b[n:]
+
+		f := field{number: int(key >> 3), wireType: int(key & 7)}
+		switch f.wireType {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return nil, errTruncated
+			}
+			f.varint = v
+			b = b[n:]
+		case wireBytes:
+			length, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < length {
+				return nil, errTruncated
+			}
+			f.data = b[n : n+int(length)]
+			b = b[n+int(length):]
+		case wireFixed64:
+			if len(b) < 8 {
+				return nil, errTruncated
+			}
+			b = b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return nil, errTruncated
+			}
+			b = b[4:]
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d", f.wireType)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
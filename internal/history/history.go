@@ -0,0 +1,93 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the file inside the notes store recording note accesses
+const FileName = ".history.yaml"
+
+// MaxEntries bounds the history file; older entries are dropped
+const MaxEntries = 200
+
+// Entry records one access to a note
+type Entry struct {
+	NoteID string    `yaml:"id"`
+	Action string    `yaml:"action"`
+	At     time.Time `yaml:"at"`
+}
+
+// Load reads the history file, oldest entry first; a missing file yields no
+// entries
+func Load(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading history: %w", err)
+	}
+
+	var entries []Entry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing history: %w", err)
+	}
+	return entries, nil
+}
+
+// Save writes the history file
+func Save(path string, entries []Entry) error {
+	data, err := yaml.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("error marshaling history: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Record appends an access to the history file
+func Record(path, noteID, action string, at time.Time) error {
+	entries, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	entries = append(entries, Entry{NoteID: noteID, Action: action, At: at})
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+	return Save(path, entries)
+}
+
+// Recent returns the latest entry for each of the last limit distinct
+// notes, most recent first. A limit of 0 returns every note.
+func Recent(entries []Entry, limit int) []Entry {
+	var recent []Entry
+	seen := make(map[string]bool)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if seen[entries[i].NoteID] {
+			continue
+		}
+		seen[entries[i].NoteID] = true
+		recent = append(recent, entries[i])
+		if limit > 0 && len(recent) == limit {
+			break
+		}
+	}
+	return recent
+}
+
+// Counts returns how often each note appears with action in entries. The
+// history is bounded by MaxEntries, so these are counts of recent accesses.
+func Counts(entries []Entry, action string) map[string]int {
+	counts := make(map[string]int)
+	for _, e := range entries {
+		if e.Action == action {
+			counts[e.NoteID]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,77 @@
+package history
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NavigationFileName is the file inside the notes store holding the trail
+// of read notes `memo back` and `memo forward` move along
+const NavigationFileName = ".navigation.yaml"
+
+// MaxTrail bounds the navigation trail; the oldest notes are dropped
+const MaxTrail = 50
+
+// Navigation is the trail of notes read one after another, which works
+// like the history of a web browser: going back and then reading another
+// note drops the notes that were ahead
+type Navigation struct {
+	Trail []string `yaml:"trail"`
+	// Current is the position of the note last shown in Trail
+	Current int `yaml:"current"`
+}
+
+// LoadNavigation reads the navigation trail; a missing file yields an
+// empty one
+func LoadNavigation(path string) (*Navigation, error) {
+	nav := &Navigation{Current: -1}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nav, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading navigation: %w", err)
+	}
+	if err := yaml.Unmarshal(data, nav); err != nil {
+		return nil, fmt.Errorf("error parsing navigation: %w", err)
+	}
+	if nav.Current < 0 || nav.Current >= len(nav.Trail) {
+		nav.Current = len(nav.Trail) - 1
+	}
+	return nav, nil
+}
+
+// Save writes the navigation trail
+func (n *Navigation) Save(path string) error {
+	data, err := yaml.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("error marshaling navigation: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Visit makes noteID the current note, dropping the notes ahead of the
+// current one unless noteID is already current
+func (n *Navigation) Visit(noteID string) {
+	if len(n.Trail) > 0 && n.Trail[n.Current] == noteID {
+		return
+	}
+	n.Trail = append(n.Trail[:n.Current+1], noteID)
+	if len(n.Trail) > MaxTrail {
+		n.Trail = n.Trail[len(n.Trail)-MaxTrail:]
+	}
+	n.Current = len(n.Trail) - 1
+}
+
+// Step moves one note back (-1) or forward (1) and returns the note there,
+// or false at either end of the trail
+func (n *Navigation) Step(direction int) (string, bool) {
+	next := n.Current + direction
+	if next < 0 || next >= len(n.Trail) {
+		return "", false
+	}
+	n.Current = next
+	return n.Trail[next], true
+}
@@ -0,0 +1,86 @@
+package hooks
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"memo/internal/note"
+)
+
+// DirName is the directory inside the notes store holding hook executables
+const DirName = ".hooks"
+
+// Hook names. A pre- hook that exits with a non-zero status cancels the
+// operation; a failing post- hook is only reported.
+const (
+	PreCreate  = "pre-create"
+	PostCreate = "post-create"
+	PreSave    = "pre-save"
+	PostSave   = "post-save"
+	PreDelete  = "pre-delete"
+	PostDelete = "post-delete"
+)
+
+// Find returns the path of the executable for hook in dir, or "" when there
+// is none. On Windows a file with an executable extension such as
+// "post-create.bat" also counts.
+func Find(dir, hook string) string {
+	path := filepath.Join(dir, hook)
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		if runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+			slog.Warn("hook is not executable; skipping", "path", path)
+			return ""
+		}
+		return path
+	}
+
+	if runtime.GOOS == "windows" {
+		for _, ext := range strings.Split(strings.ToLower(os.Getenv("PATHEXT")), ";") {
+			if ext == "" {
+				continue
+			}
+			if _, err := os.Stat(path + ext); err == nil {
+				return path + ext
+			}
+		}
+	}
+	return ""
+}
+
+// Run executes the hook at path, as returned by Find, for n. The note's
+// metadata is passed in MEMO_* environment variables and its file content
+// on stdin; the hook runs in the notes directory with its output sent to
+// stderr.
+func Run(path, hook string, n *note.Note, content string) error {
+	// The hook runs inside the notes directory, so relative paths would break
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	notesDir := filepath.Dir(filepath.Dir(path))
+	cmd := exec.Command(path)
+	cmd.Dir = notesDir
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"MEMO_HOOK="+hook,
+		"MEMO_NOTES_DIR="+notesDir,
+		"MEMO_NOTE_ID="+n.ID(),
+		"MEMO_NOTE_PATH="+n.FilePath,
+		"MEMO_NOTE_TITLE="+n.Metadata.Title,
+		"MEMO_NOTE_TAGS="+strings.Join(n.Metadata.Tags, ","),
+		"MEMO_NOTE_STATUS="+n.Metadata.Status,
+	)
+
+	slog.Debug("running hook", "hook", hook, "path", path, "note", n.ID())
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s hook failed: %w", hook, err)
+	}
+	return nil
+}
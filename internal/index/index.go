@@ -0,0 +1,151 @@
+// Package index records a content hash for every note file so changes made
+// outside memo can be told apart from files that were merely touched.
+package index
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the file inside the notes store holding the index
+const FileName = ".index.yaml"
+
+// Entry is what the index knows about one note file
+type Entry struct {
+	Hash    string    `yaml:"sha256"`
+	Size    int64     `yaml:"size"`
+	ModTime time.Time `yaml:"modified"`
+}
+
+// Index maps note IDs to entries
+type Index map[string]Entry
+
+// Load reads the index; a missing file yields nil and no error, which Exists
+// distinguishes from an empty index
+func Load(path string) (Index, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading index: %w", err)
+	}
+
+	ix := make(Index)
+	if err := yaml.Unmarshal(data, &ix); err != nil {
+		return nil, fmt.Errorf("error parsing index: %w", err)
+	}
+	return ix, nil
+}
+
+// Save writes the index
+func Save(path string, ix Index) error {
+	data, err := yaml.Marshal(ix)
+	if err != nil {
+		return fmt.Errorf("error marshaling index: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// HashFile computes the entry for the file at path
+func HashFile(path string) (Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return Entry{}, err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return Entry{}, err
+	}
+	return Entry{Hash: hex.EncodeToString(h.Sum(nil)), Size: info.Size(), ModTime: info.ModTime().UTC()}, nil
+}
+
+// Status classifies a file relative to its index entry
+type Status int
+
+const (
+	Unchanged Status = iota
+	// Touched files have a new modification time but the same content
+	Touched
+	Modified
+	Added
+	Missing
+)
+
+func (s Status) String() string {
+	return [...]string{"unchanged", "touched", "modified", "added", "missing"}[s]
+}
+
+// Current returns the entry for the file at path and how it differs from
+// known. The file is only hashed when its size or modification time changed.
+func Current(known Entry, indexed bool, path string) (Entry, Status, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		if indexed {
+			return Entry{}, Missing, nil
+		}
+		return Entry{}, Unchanged, err
+	}
+	if err != nil {
+		return Entry{}, Unchanged, err
+	}
+	if indexed && info.Size() == known.Size && info.ModTime().UTC().Equal(known.ModTime) {
+		return known, Unchanged, nil
+	}
+
+	entry, err := HashFile(path)
+	if err != nil {
+		return Entry{}, Unchanged, err
+	}
+	switch {
+	case !indexed:
+		return entry, Added, nil
+	case entry.Hash == known.Hash:
+		return entry, Touched, nil
+	}
+	return entry, Modified, nil
+}
+
+// Change is a note file that differs from the index
+type Change struct {
+	ID     string
+	Path   string
+	Status Status
+	Entry  Entry // the file's current entry; zero when Missing
+}
+
+// Diff compares the note files, given as ID to path, with the index and
+// returns every file that is not Unchanged, sorted by ID
+func (ix Index) Diff(files map[string]string) ([]Change, error) {
+	var changes []Change
+	for id, path := range files {
+		known, indexed := ix[id]
+		entry, status, err := Current(known, indexed, path)
+		if err != nil {
+			return nil, fmt.Errorf("error checking %s: %w", path, err)
+		}
+		if status != Unchanged {
+			changes = append(changes, Change{ID: id, Path: path, Status: status, Entry: entry})
+		}
+	}
+	for id := range ix {
+		if _, ok := files[id]; !ok {
+			changes = append(changes, Change{ID: id, Status: Missing})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ID < changes[j].ID })
+	return changes, nil
+}
@@ -0,0 +1,50 @@
+// Package keychain stores secrets in the operating system's credential
+// store: the macOS Keychain, the Windows Credential Manager or the Secret
+// Service (GNOME Keyring, KWallet) through secret-tool on Linux and BSD.
+package keychain
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// Service is the name memo's secrets are stored under
+const Service = "memo"
+
+var (
+	// ErrNotFound is returned by Get when no secret is stored for the account
+	ErrNotFound = errors.New("no secret stored in the keychain")
+	// ErrUnsupported is returned when the platform's credential store cannot
+	// be reached, for example when secret-tool is not installed
+	ErrUnsupported = errors.New("no supported keychain available")
+)
+
+// Get returns the secret stored for account
+func Get(account string) (string, error) {
+	return get(account)
+}
+
+// Set stores secret for account, replacing any previous one
+func Set(account, secret string) error {
+	return set(account, secret)
+}
+
+// Delete removes the secret stored for account
+func Delete(account string) error {
+	return remove(account)
+}
+
+// run executes a credential helper, returning its trimmed output.
+// ErrUnsupported is returned when the helper is not installed.
+func run(stdin string, name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", ErrUnsupported
+	}
+	cmd := exec.Command(name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	out, err := cmd.Output()
+	return strings.TrimRight(string(out), "\n"), err
+}
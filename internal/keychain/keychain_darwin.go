@@ -0,0 +1,67 @@
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// security exits with 44 when an item does not exist
+const errSecItemNotFound = 44
+
+func get(account string) (string, error) {
+	secret, err := run("", "security", "find-generic-password", "-s", Service, "-a", account, "-w")
+	if exitCode(err) == errSecItemNotFound {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading from keychain: %w", err)
+	}
+	return secret, nil
+}
+
+func set(account, secret string) error {
+	if strings.ContainsAny(secret, "\r\n") {
+		return fmt.Errorf("error writing to keychain: the secret must be a single line")
+	}
+	// The command goes to security -i through stdin, keeping the secret off
+	// the command line and out of the process list
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -l %s -w %s\n",
+		quote(Service), quote(account), quote("memo encryption key"), quote(secret))
+	if _, err := run(command, "security", "-i"); err != nil {
+		return fmt.Errorf("error writing to keychain: %w", err)
+	}
+
+	// security -i does not fail when one of its commands does, so the
+	// secret is read back to make sure it was stored
+	if stored, err := get(account); err != nil || stored != secret {
+		return fmt.Errorf("error writing to keychain: security did not store the secret")
+	}
+	return nil
+}
+
+func remove(account string) error {
+	_, err := run("", "security", "delete-generic-password", "-s", Service, "-a", account)
+	if exitCode(err) == errSecItemNotFound {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting from keychain: %w", err)
+	}
+	return nil
+}
+
+// quote wraps s in double quotes for the command line security -i reads,
+// escaping backslashes and quotes
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return 0
+}
@@ -0,0 +1,47 @@
+//go:build !darwin && !windows
+
+package keychain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// secret-tool (from libsecret) talks to the Secret Service of the desktop
+// session. lookup prints nothing and exits non-zero for a missing item.
+
+var errNoSecretTool = fmt.Errorf("%w (secret-tool from libsecret is not installed)", ErrUnsupported)
+
+func get(account string) (string, error) {
+	secret, err := run("", "secret-tool", "lookup", "service", Service, "account", account)
+	if errors.Is(err, ErrUnsupported) {
+		return "", errNoSecretTool
+	}
+	if err != nil || secret == "" {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func set(account, secret string) error {
+	// The secret goes through stdin, never the command line
+	_, err := run(secret, "secret-tool", "store", "--label=memo encryption key ("+account+")", "service", Service, "account", account)
+	if errors.Is(err, ErrUnsupported) {
+		return errNoSecretTool
+	}
+	if err != nil {
+		return fmt.Errorf("error writing to keychain: %w", err)
+	}
+	return nil
+}
+
+func remove(account string) error {
+	if _, err := get(account); err != nil {
+		return err
+	}
+	_, err := run("", "secret-tool", "clear", "service", Service, "account", account)
+	if err != nil {
+		return fmt.Errorf("error deleting from keychain: %w", err)
+	}
+	return nil
+}
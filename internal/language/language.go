@@ -0,0 +1,99 @@
+// Package language tells which language a text is written in by counting
+// the most common words of each language it knows.
+package language
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// minWords is the number of words a text needs before its language is
+	// guessed at all
+	minWords = 8
+	// minHits is how many common words of the winning language it needs
+	minHits = 3
+)
+
+// commonWords are frequent function words of each language, by ISO 639-1
+// code. Words shared between languages count for each of them.
+var commonWords = map[string]map[string]bool{
+	"en": wordSet(`the of and to in is that it for was on are as with be at by this have from
+		or an but not you they we his her she he which there their were been has had will would
+		what all if can do so no about out up them then its into than`),
+	"de": wordSet(`der die das und ist nicht ein eine zu den von mit sich des auf für im dem
+		auch es an als wie ich er sie wir ihr aber oder wenn nur noch nach bei aus um so war hat
+		werden wird sind dass kann`),
+	"fr": wordSet(`le la les de des et est un une du en que qui dans pour pas sur au avec ce
+		il elle nous vous ils sont ne se plus par mais ou son sa ses aux cette été être avoir fait
+		comme tout`),
+	"es": wordSet(`el la los las de del y que en un una es por con para no se al lo como más
+		pero sus su le ha me si sin sobre este esta ya también fue son está hay muy`),
+	"it": wordSet(`il lo la gli le di del della e che è un una per con non sono si da dei nel
+		alla anche come più ma ha questo questa sul ci ne se molto essere`),
+	"nl": wordSet(`de het een en van is dat in op te niet met voor zijn er aan ook als maar om
+		bij dit die wordt door naar nog wel heeft hebben kan worden was geen zo`),
+	"pt": wordSet(`o a os as de do da dos das e que em um uma é para com não por se no na mais
+		mas como ao seu sua também foi são está ele ela isso muito`),
+}
+
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		set[w] = true
+	}
+	return set
+}
+
+// Codes returns the codes of the languages Detect knows, sorted
+func Codes() []string {
+	codes := make([]string, 0, len(commonWords))
+	for code := range commonWords {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Known reports whether code is one of Codes, ignoring case
+func Known(code string) bool {
+	_, ok := commonWords[strings.ToLower(code)]
+	return ok
+}
+
+// Detect returns the code of the language text is most likely written in,
+// or "" when the text is too short or no language clearly wins
+func Detect(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+	if len(words) < minWords {
+		return ""
+	}
+
+	hits := make(map[string]int, len(commonWords))
+	for _, w := range words {
+		for code, common := range commonWords {
+			if common[w] {
+				hits[code]++
+			}
+		}
+	}
+
+	best, runnerUp := "", 0
+	for _, code := range Codes() {
+		switch {
+		case best == "" || hits[code] > hits[best]:
+			if best != "" {
+				runnerUp = hits[best]
+			}
+			best = code
+		case hits[code] > runnerUp:
+			runnerUp = hits[code]
+		}
+	}
+	// A tie or a narrow lead is more likely noise than a language
+	if hits[best] < minHits || hits[best]*4 < runnerUp*5 {
+		return ""
+	}
+	return best
+}
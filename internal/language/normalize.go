@@ -0,0 +1,165 @@
+package language
+
+import (
+	"strings"
+	"unicode"
+)
+
+// plainForms maps letters with diacritics to the letters they are written
+// as without them, for the Latin alphabets of the languages Detect knows
+var plainForms = func() map[rune]string {
+	forms := make(map[rune]string)
+	for plain, accented := range map[string]string{
+		"a": "àáâãäåāăą", "c": "çćĉċč", "d": "ďđ", "e": "èéêëēĕėęě", "g": "ĝğġģ",
+		"h": "ĥħ", "i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķ", "l": "ĺļľŀł", "n": "ñńņňŉ",
+		"o": "òóôõöøōŏő", "r": "ŕŗř", "s": "śŝşš", "t": "ţťŧ", "u": "ùúûüũūŭůűų",
+		"w": "ŵ", "y": "ýÿŷ", "z": "źżž", "ss": "ß", "ae": "æ", "oe": "œ",
+	} {
+		for _, r := range accented {
+			forms[r] = plain
+		}
+	}
+	return forms
+}()
+
+// Fold lowercases s and writes its letters without diacritics, so "Café"
+// and "cafe" compare equal
+func Fold(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range strings.ToLower(s) {
+		if plain, ok := plainForms[r]; ok {
+			b.WriteString(plain)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Words splits text into folded words
+func Words(text string) []string {
+	return strings.FieldsFunc(Fold(text), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+}
+
+// Stopwords are the lowercase words of each language, by code, that are
+// so common they say nothing about a text's topic. English and German have
+// full lists; the other languages have the common words Detect counts.
+var Stopwords = func() map[string]map[string]bool {
+	sets := map[string]map[string]bool{
+		"en": wordSet(`a about above after again against all also am an and any are as at be because
+			been before being below between both but by can could did do does doing down
+			during each even every few for from further get got had has have having he her
+			here hers herself him himself his how however i if in into is it its itself
+			just like made make many may me might more most much must my myself need new
+			no nor not now of off on once one only or other our ours ourselves out over own
+			per same she should since so some still such than that the their theirs them
+			themselves then there these they this those through thus to too under until up
+			upon us use used using very was we well were what when where whether which
+			while who whom why will with within without would yet you your yours yourself
+			yourselves`),
+		"de": wordSet(`aber alle allem allen aller alles als also am an ander andere anderem anderen
+			anderer anderes auch auf aus bei bin bis bist da damit dann das dass dem den
+			denn der des dich die dies diese diesem diesen dieser dieses dir doch dort du
+			durch ein eine einem einen einer eines einig einige er es etwas euch euer für
+			gegen gewesen hab habe haben hat hatte hatten hier hin hinter ich ihm ihn ihnen
+			ihr ihre im in indem ins ist jede jedem jeden jeder jedes jene jetzt kann kein
+			keine können man manche mein meine mich mir mit muss musste nach nicht nichts
+			noch nun nur ob oder ohne schon sehr sein seine sich sie sind so solche soll
+			sollte sondern sonst über um und uns unser unter viel vom von vor war waren
+			warst was weg weil weiter welche wenn werde werden wie wieder will wir wird
+			wirst wo wollen wollte würde würden zu zum zur zwar zwischen`),
+	}
+	for code, words := range commonWords {
+		if sets[code] == nil {
+			sets[code] = make(map[string]bool, len(words))
+		}
+		for w := range words {
+			sets[code][w] = true
+		}
+	}
+	return sets
+}()
+
+// stopwords are Stopwords folded like the words of Words
+var stopwords = func() map[string]map[string]bool {
+	sets := make(map[string]map[string]bool, len(Stopwords))
+	for code, words := range Stopwords {
+		set := make(map[string]bool, len(words))
+		for w := range words {
+			set[Fold(w)] = true
+		}
+		sets[code] = set
+	}
+	return sets
+}()
+
+// Stopword reports whether the folded word is so common in the language
+// with code that it says nothing about a text
+func Stopword(word, code string) bool {
+	return stopwords[code][word]
+}
+
+// suffixes are the endings the light stemmers of the languages other than
+// English remove, longest first and folded like the words they apply to
+var suffixes = map[string][]string{
+	"de": {"ungen", "heiten", "keiten", "ung", "heit", "keit", "ern", "em", "en", "er", "es", "e", "s"},
+	"fr": {"issements", "issement", "ements", "ement", "ations", "ation", "euses", "euse", "eux", "ees", "es", "ee", "e", "s", "x"},
+	"es": {"aciones", "amientos", "amiento", "acion", "mente", "iendo", "ando", "ados", "adas", "idos", "idas", "ado", "ada", "ido", "ida", "es", "os", "as", "s", "o", "a", "e"},
+	"it": {"azioni", "azione", "amente", "mente", "ando", "endo", "ati", "ate", "ato", "ata", "iti", "ite", "ito", "ita", "i", "e", "o", "a"},
+	"nl": {"heden", "ingen", "heid", "lijk", "ing", "en", "e", "s"},
+	"pt": {"acoes", "acao", "mente", "ando", "endo", "ados", "adas", "idos", "idas", "ado", "ada", "ido", "ida", "es", "os", "as", "s", "o", "a", "e"},
+}
+
+// minStem is the shortest stem the English stemmer leaves; the others
+// leave longer ones, as their endings are more ambiguous
+const minStem = 3
+
+// Stem reduces a folded word to its stem in the language with code, so
+// that "running" and "runs" both become "run". The stemmers are light:
+// they remove common inflections, not every derivation. Words of unknown
+// languages are stemmed as English.
+func Stem(word, code string) string {
+	endings, ok := suffixes[code]
+	if !ok {
+		return stemEnglish(word)
+	}
+	for _, suffix := range endings {
+		if stem, ok := strings.CutSuffix(word, suffix); ok && len(stem) > minStem {
+			return stem
+		}
+	}
+	return word
+}
+
+// stemEnglish removes plural, -ing, -ed and -ly endings, undoubling the
+// consonant before them, and a final e, after the first steps of Porter's
+// algorithm
+func stemEnglish(w string) string {
+	if len(w) <= minStem {
+		return w
+	}
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		w = w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "sses"):
+		w = w[:len(w)-2]
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"), strings.HasSuffix(w, "is"):
+	case strings.HasSuffix(w, "s"):
+		w = w[:len(w)-1]
+	}
+
+	for _, suffix := range []string{"ingly", "edly", "ing", "ed", "ly"} {
+		if stem, ok := strings.CutSuffix(w, suffix); ok && len(stem) >= minStem && strings.ContainsAny(stem, "aeiouy") {
+			w = stem
+			if n := len(w); w[n-1] == w[n-2] && !strings.ContainsRune("aeiouylsz", rune(w[n-1])) {
+				w = w[:n-1]
+			}
+			break
+		}
+	}
+	if strings.HasSuffix(w, "e") && len(w) > minStem {
+		w = w[:len(w)-1]
+	}
+	return w
+}
@@ -0,0 +1,147 @@
+// Package lint checks notes against style rules such as "every note has a
+// tag". Each rule can be turned off, reported as a warning or treated as an
+// error.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"memo/internal/note"
+)
+
+// Rule names
+const (
+	MissingTitle       = "missing-title"
+	Untagged           = "untagged"
+	TrailingWhitespace = "trailing-whitespace"
+	BrokenLinks        = "broken-links"
+	EmptyContent       = "empty-content"
+)
+
+// Rules lists every rule in the order they are checked
+var Rules = []string{MissingTitle, EmptyContent, Untagged, TrailingWhitespace, BrokenLinks}
+
+// Severities of a rule
+const (
+	Off   = "off"
+	Warn  = "warn"
+	Error = "error"
+)
+
+// Issue is one rule a note breaks
+type Issue struct {
+	Rule     string
+	Severity string
+	Message  string
+}
+
+// Linter checks notes with configured severities
+type Linter struct {
+	severity map[string]string
+}
+
+// New returns a linter applying severities, a map of rule names to "off",
+// "warn" or "error". Rules not mentioned warn; strict turns every warning
+// into an error.
+func New(severities map[string]string, strict bool) (*Linter, error) {
+	l := &Linter{severity: make(map[string]string, len(Rules))}
+	for _, rule := range Rules {
+		l.severity[rule] = Warn
+	}
+	for rule, severity := range severities {
+		if _, ok := l.severity[rule]; !ok {
+			return nil, fmt.Errorf("unknown lint rule '%s' (use %s)", rule, strings.Join(Rules, ", "))
+		}
+		switch severity {
+		case Off, Warn, Error:
+			l.severity[rule] = severity
+		default:
+			return nil, fmt.Errorf("lint rule %s: severity must be off, warn or error, not '%s'", rule, severity)
+		}
+	}
+	if strict {
+		for rule, severity := range l.severity {
+			if severity == Warn {
+				l.severity[rule] = Error
+			}
+		}
+	}
+	return l, nil
+}
+
+// Check returns the issues of n. linkExists tells whether a [[wikilink]]
+// target names a note; without it links are not checked. The content of
+// encrypted notes is not checked.
+func (l *Linter) Check(n *note.Note, linkExists func(target string) bool) []Issue {
+	var issues []Issue
+	add := func(rule, format string, args ...any) {
+		if severity := l.severity[rule]; severity != Off {
+			issues = append(issues, Issue{Rule: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+		}
+	}
+
+	if strings.TrimSpace(n.Metadata.Title) == "" {
+		add(MissingTitle, "the note has no title")
+	}
+	if len(n.Metadata.Tags) == 0 {
+		add(Untagged, "the note has no tags")
+	}
+	if n.Locked() {
+		return issues
+	}
+
+	if strings.TrimSpace(n.Content) == "" {
+		add(EmptyContent, "the note has no content")
+	}
+	if lines := trailingWhitespace(n.Content); len(lines) > 0 {
+		add(TrailingWhitespace, "trailing whitespace on content line(s) %s", joinInts(lines))
+	}
+	if linkExists != nil {
+		var broken []string
+		for _, link := range n.WikiLinks() {
+			if !linkExists(link) && !strings.EqualFold(link, n.ID()) && !strings.EqualFold(link, n.Metadata.Title) {
+				broken = append(broken, "[["+link+"]]")
+			}
+		}
+		if len(broken) > 0 {
+			add(BrokenLinks, "link target(s) not found: %s", strings.Join(broken, ", "))
+		}
+	}
+	return issues
+}
+
+// Errors returns the issues with error severity
+func Errors(issues []Issue) []Issue {
+	var errs []Issue
+	for _, issue := range issues {
+		if issue.Severity == Error {
+			errs = append(errs, issue)
+		}
+	}
+	return errs
+}
+
+// trailingWhitespace returns the numbers of content lines ending in spaces
+// or tabs. Markdown's two-space line break is allowed.
+func trailingWhitespace(content string) []int {
+	var lines []int
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimRight(line, " \t")
+		if trimmed == line || trimmed != "" && line == trimmed+"  " {
+			continue
+		}
+		lines = append(lines, i+1)
+	}
+	return lines
+}
+
+func joinInts(values []int) string {
+	sort.Ints(values)
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
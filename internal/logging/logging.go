@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+var level = new(slog.LevelVar)
+
+func init() {
+	level.Set(slog.LevelInfo)
+	slog.SetDefault(slog.New(NewHandler(os.Stderr, level)))
+}
+
+// Configure sets the global log level from the --verbose and --quiet flags.
+// Verbose enables debug output; quiet suppresses everything but errors.
+func Configure(verbose, quiet bool) {
+	switch {
+	case verbose:
+		level.Set(slog.LevelDebug)
+	case quiet:
+		level.Set(slog.LevelError)
+	default:
+		level.Set(slog.LevelInfo)
+	}
+}
+
+// Quiet reports whether non-essential output should be suppressed
+func Quiet() bool {
+	return level.Level() > slog.LevelInfo
+}
+
+// Handler is a slog.Handler producing compact, human-readable lines:
+//
+//	Warning: failed to parse note path=.memo-notes/x.note error="..."
+type Handler struct {
+	mu    *sync.Mutex
+	out   io.Writer
+	level slog.Leveler
+	attrs []slog.Attr
+}
+
+func NewHandler(out io.Writer, level slog.Leveler) *Handler {
+	return &Handler{mu: &sync.Mutex{}, out: out, level: level}
+}
+
+func (h *Handler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(levelPrefix(r.Level))
+	b.WriteString(r.Message)
+
+	writeAttr := func(a slog.Attr) bool {
+		fmt.Fprintf(&b, " %s=%s", a.Key, quoteValue(a.Value.String()))
+		return true
+	}
+	for _, a := range h.attrs {
+		writeAttr(a)
+	}
+	r.Attrs(writeAttr)
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.out, b.String())
+	return err
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &clone
+}
+
+// WithGroup is not used by memo; groups are flattened
+func (h *Handler) WithGroup(_ string) slog.Handler {
+	return h
+}
+
+func levelPrefix(l slog.Level) string {
+	switch {
+	case l >= slog.LevelError:
+		return "Error: "
+	case l >= slog.LevelWarn:
+		return "Warning: "
+	case l >= slog.LevelInfo:
+		return ""
+	default:
+		return "Debug: "
+	}
+}
+
+func quoteValue(v string) string {
+	if strings.ContainsAny(v, " \t\"=") {
+		return fmt.Sprintf("%q", v)
+	}
+	return v
+}
@@ -0,0 +1,188 @@
+// Package merge combines two versions of a text that were edited
+// independently from a common base, line by line, the way diff3 does.
+package merge
+
+import (
+	"slices"
+	"strings"
+)
+
+// Source says where the lines of a chunk come from
+type Source int
+
+const (
+	// Unchanged lines are the same in all three versions
+	Unchanged Source = iota
+	// Local lines were only changed locally
+	Local
+	// Remote lines were only changed remotely
+	Remote
+	// Both means both sides made the same change
+	Both
+	// Conflict means both sides changed the lines differently
+	Conflict
+)
+
+// Chunk is a stretch of the merge with the lines of each version
+type Chunk struct {
+	Source Source
+	Base   []string
+	Local  []string
+	Remote []string
+}
+
+// Lines returns the merged lines of a chunk that is not a conflict
+func (c Chunk) Lines() []string {
+	switch c.Source {
+	case Local, Both:
+		return c.Local
+	case Remote:
+		return c.Remote
+	}
+	return c.Base
+}
+
+// Changed reports whether the chunk differs from the base
+func (c Chunk) Changed() bool {
+	return c.Source != Unchanged
+}
+
+// Split breaks text into lines; a final newline does not start another one
+func Split(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Join turns lines back into text ending in a newline
+func Join(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Merge lines up the local and remote versions against their base and
+// returns the chunks between the lines all three have in common
+func Merge(base, local, remote []string) []Chunk {
+	toLocal := match(base, local)
+	toRemote := match(base, remote)
+
+	var chunks []Chunk
+	add := func(c Chunk) {
+		if len(c.Base)+len(c.Local)+len(c.Remote) == 0 {
+			return
+		}
+		if c.Source == Unchanged && len(chunks) > 0 && chunks[len(chunks)-1].Source == Unchanged {
+			last := &chunks[len(chunks)-1]
+			last.Base = append(slices.Clip(last.Base), c.Base...)
+			last.Local, last.Remote = last.Base, last.Base
+			return
+		}
+		chunks = append(chunks, c)
+	}
+
+	i, l, r := 0, 0, 0
+	for i < len(base) {
+		// Lines kept by both sides at the current position are stable
+		if toLocal[i] == l && toRemote[i] == r {
+			add(Chunk{Source: Unchanged, Base: base[i : i+1], Local: local[l : l+1], Remote: remote[r : r+1]})
+			i, l, r = i+1, l+1, r+1
+			continue
+		}
+		// Otherwise everything up to the next line both sides kept changed
+		next := i
+		for next < len(base) && (toLocal[next] < 0 || toRemote[next] < 0) {
+			next++
+		}
+		endLocal, endRemote := len(local), len(remote)
+		if next < len(base) {
+			endLocal, endRemote = toLocal[next], toRemote[next]
+		}
+		add(classify(base[i:next], local[l:endLocal], remote[r:endRemote]))
+		i, l, r = next, endLocal, endRemote
+	}
+	add(classify(nil, local[l:], remote[r:]))
+	return chunks
+}
+
+// Conflicts counts the conflicting chunks
+func Conflicts(chunks []Chunk) int {
+	count := 0
+	for _, c := range chunks {
+		if c.Source == Conflict {
+			count++
+		}
+	}
+	return count
+}
+
+func classify(base, local, remote []string) Chunk {
+	c := Chunk{Base: base, Local: local, Remote: remote}
+	switch {
+	case slices.Equal(local, remote):
+		c.Source = Both
+		if slices.Equal(local, base) {
+			c.Source = Unchanged
+		}
+	case slices.Equal(local, base):
+		c.Source = Remote
+	case slices.Equal(remote, base):
+		c.Source = Local
+	default:
+		c.Source = Conflict
+	}
+	return c
+}
+
+// match pairs the lines of a with a longest common subsequence of b and
+// returns, for every line of a, the index of its partner in b or -1
+func match(a, b []string) []int {
+	pairs := make([]int, len(a))
+	for i := range pairs {
+		pairs[i] = -1
+	}
+
+	// A common start and end need no table, which keeps the usual case of
+	// a few edits in a long note cheap
+	start := 0
+	for start < len(a) && start < len(b) && a[start] == b[start] {
+		pairs[start] = start
+		start++
+	}
+	endA, endB := len(a), len(b)
+	for endA > start && endB > start && a[endA-1] == b[endB-1] {
+		endA, endB = endA-1, endB-1
+		pairs[endA] = endB
+	}
+
+	n, m := endA-start, endB-start
+	if n == 0 || m == 0 {
+		return pairs
+	}
+	// lengths[i*(m+1)+j] is the length of the longest common subsequence
+	// of the middle parts of a from i and b from j
+	lengths := make([]int32, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[start+i] == b[start+j] {
+				lengths[i*(m+1)+j] = lengths[(i+1)*(m+1)+j+1] + 1
+			} else {
+				lengths[i*(m+1)+j] = max(lengths[(i+1)*(m+1)+j], lengths[i*(m+1)+j+1])
+			}
+		}
+	}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[start+i] == b[start+j]:
+			pairs[start+i] = start + j
+			i, j = i+1, j+1
+		case lengths[(i+1)*(m+1)+j] >= lengths[i*(m+1)+j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
@@ -0,0 +1,40 @@
+package note
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ColorNames are the named colors a note's color field may hold; a hex
+// value like #ff8800 is accepted too
+var ColorNames = []string{"red", "green", "yellow", "blue", "magenta", "cyan", "white", "gray"}
+
+var hexColor = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// ValidColor reports whether color is one of ColorNames or a hex value,
+// ignoring case
+func ValidColor(color string) bool {
+	color = strings.ToLower(color)
+	return slices.Contains(ColorNames, color) || hexColor.MatchString(color)
+}
+
+// Color returns the lowercased color the note is listed in, or "" when its
+// color field is unset or not a color
+func (n *Note) Color() string {
+	values, _ := n.Field("color")
+	if len(values) != 1 || !ValidColor(values[0]) {
+		return ""
+	}
+	return strings.ToLower(values[0])
+}
+
+// Icon returns the icon, usually an emoji, shown before the note's title in
+// listings, or ""
+func (n *Note) Icon() string {
+	values, _ := n.Field("icon")
+	if len(values) != 1 {
+		return ""
+	}
+	return strings.TrimSpace(values[0])
+}
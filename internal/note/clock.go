@@ -0,0 +1,73 @@
+package note
+
+import (
+	"errors"
+	"time"
+)
+
+// TimeEntry is a stretch of time spent on a note; a zero End means the
+// clock is still running
+type TimeEntry struct {
+	Start time.Time `yaml:"start"`
+	End   time.Time `yaml:"end,omitempty"`
+}
+
+var (
+	// ErrClockRunning is returned by ClockIn when the note's clock is
+	// already running
+	ErrClockRunning = errors.New("the clock is already running")
+	// ErrClockStopped is returned by ClockOut when the note's clock is not
+	// running
+	ErrClockStopped = errors.New("the clock is not running")
+)
+
+// Running reports whether the note's clock is running
+func (n *Note) Running() bool {
+	entries := n.Metadata.Clock
+	return len(entries) > 0 && entries[len(entries)-1].End.IsZero()
+}
+
+// ClockIn starts a time entry at now
+func (n *Note) ClockIn(now time.Time) error {
+	if n.Running() {
+		return ErrClockRunning
+	}
+	n.Metadata.Clock = append(n.Metadata.Clock, TimeEntry{Start: now.Truncate(time.Second)})
+	return nil
+}
+
+// ClockOut ends the running time entry at now and returns its length
+func (n *Note) ClockOut(now time.Time) (time.Duration, error) {
+	if !n.Running() {
+		return 0, ErrClockStopped
+	}
+	e := &n.Metadata.Clock[len(n.Metadata.Clock)-1]
+	e.End = now.Truncate(time.Second)
+	if e.End.Before(e.Start) {
+		e.End = e.Start
+	}
+	return e.End.Sub(e.Start), nil
+}
+
+// TimeSpent adds up the time entries between since and until, cutting off
+// the parts outside; zero bounds are open. A running entry counts up to
+// now.
+func (n *Note) TimeSpent(since, until, now time.Time) time.Duration {
+	var total time.Duration
+	for _, e := range n.Metadata.Clock {
+		start, end := e.Start, e.End
+		if end.IsZero() {
+			end = now
+		}
+		if !since.IsZero() && start.Before(since) {
+			start = since
+		}
+		if !until.IsZero() && end.After(until) {
+			end = until
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
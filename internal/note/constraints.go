@@ -0,0 +1,67 @@
+package note
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Constraints restrict the front matter of notes. Zero values allow
+// anything.
+type Constraints struct {
+	// MinPriority and MaxPriority bound the priority of notes that have one
+	MinPriority int
+	MaxPriority int
+	// Statuses lists the allowed values of status, ignoring case
+	Statuses []string
+	// Required names fields that must not be empty
+	Required []string
+}
+
+// ConstraintError lists every way a note breaks its constraints
+type ConstraintError struct {
+	Title    string
+	Problems []string
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("note '%s' does not meet the metadata rules: %s", e.Title, strings.Join(e.Problems, "; "))
+}
+
+// Check returns a *ConstraintError when the front matter of n breaks the
+// constraints, and nil otherwise
+func (c Constraints) Check(n *Note) error {
+	var problems []string
+	for _, name := range c.Required {
+		if strings.TrimSpace(n.MetadataValue(name)) == "" {
+			problems = append(problems, fmt.Sprintf("field '%s' is required", name))
+		}
+	}
+
+	p := n.Metadata.Priority
+	if p != 0 && (c.MinPriority > 0 && p < c.MinPriority || c.MaxPriority > 0 && p > c.MaxPriority) {
+		problems = append(problems, fmt.Sprintf("priority %d is not %s", p, c.priorityRange()))
+	}
+
+	if status := n.Metadata.Status; status != "" && len(c.Statuses) > 0 {
+		allowed := slices.ContainsFunc(c.Statuses, func(s string) bool { return strings.EqualFold(s, status) })
+		if !allowed {
+			problems = append(problems, fmt.Sprintf("status '%s' is not one of %s", status, strings.Join(c.Statuses, ", ")))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ConstraintError{Title: n.Metadata.Title, Problems: problems}
+	}
+	return nil
+}
+
+func (c Constraints) priorityRange() string {
+	switch {
+	case c.MinPriority > 0 && c.MaxPriority > 0:
+		return fmt.Sprintf("between %d and %d", c.MinPriority, c.MaxPriority)
+	case c.MinPriority > 0:
+		return fmt.Sprintf("at least %d", c.MinPriority)
+	}
+	return fmt.Sprintf("at most %d", c.MaxPriority)
+}
@@ -0,0 +1,398 @@
+package note
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DateFields are the front matter fields holding a Moment or a Duration
+var DateFields = []string{"due", "starts", "duration", "completed_at"}
+
+// SetDateField parses value into one of DateFields, clearing it when value
+// is empty. Unlike SetMetadata it leaves the modification time alone.
+func (n *Note) SetDateField(name, value string, now time.Time) error {
+	value = strings.TrimSpace(value)
+	if name == "duration" {
+		var d time.Duration
+		if value != "" {
+			var err error
+			if d, err = ParseDuration(value); err != nil {
+				return err
+			}
+		}
+		n.Metadata.Duration = Duration{Duration: d}
+		return nil
+	}
+
+	var m Moment
+	if value != "" {
+		var err error
+		if m, err = ParseMoment(value, now); err != nil {
+			return err
+		}
+	}
+	switch name {
+	case "due":
+		n.Metadata.Due = m
+	case "starts":
+		n.Metadata.Starts = m
+	case "completed_at":
+		n.Metadata.CompletedAt = m
+	default:
+		return fmt.Errorf("'%s' is not a date field", name)
+	}
+	return nil
+}
+
+// Moment is a date, or a time of day on a date, in front matter. Values
+// that could not be read are kept as written so saving does not lose them.
+type Moment struct {
+	Time time.Time
+	// AllDay is set for a plain date without a time
+	AllDay bool
+
+	raw string
+}
+
+// IsZero reports whether the moment is unset
+func (m Moment) IsZero() bool {
+	return m.Time.IsZero() && m.raw == ""
+}
+
+// Valid reports whether the moment is set to a time that could be read
+func (m Moment) Valid() bool {
+	return !m.Time.IsZero()
+}
+
+// String formats the moment the way it is written to front matter: a plain
+// date for a whole day, RFC 3339 otherwise
+func (m Moment) String() string {
+	switch {
+	case m.raw != "":
+		return m.raw
+	case m.Time.IsZero():
+		return ""
+	case m.AllDay:
+		return m.Time.Format("2006-01-02")
+	}
+	return m.Time.Format(time.RFC3339)
+}
+
+func (m Moment) MarshalYAML() (any, error) {
+	return m.String(), nil
+}
+
+func (m *Moment) UnmarshalYAML(value *yaml.Node) error {
+	*m = Moment{}
+	if value.Value == "" {
+		return nil
+	}
+	if parsed, ok := parseMomentLayout(value.Value); ok {
+		*m = parsed
+	} else {
+		m.raw = value.Value
+	}
+	return nil
+}
+
+// momentLayouts are the exact formats a moment may be written in; the
+// first one is a plain date
+var momentLayouts = []string{"2006-01-02", time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02 15:04:05"}
+
+func parseMomentLayout(value string) (Moment, bool) {
+	for i, layout := range momentLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return Moment{Time: t, AllDay: i == 0}, true
+		}
+	}
+	return Moment{}, false
+}
+
+var (
+	clockPattern    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+	inPattern       = regexp.MustCompile(`^in (\d+|an?) (minute|hour|day|week|month|year)s?$`)
+	dayMonthPattern = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)? ([a-z]+)\.?,?(?: (\d{4}))?$`)
+	monthDayPattern = regexp.MustCompile(`^([a-z]+)\.? (\d{1,2})(?:st|nd|rd|th)?,?(?: (\d{4}))?$`)
+)
+
+// ParseMoment reads a date or time the way people write them: exact dates
+// like 2006-01-02 or 2006-01-02 15:04, "today", "tomorrow 5pm", "friday",
+// "next friday at 9:30", "march 3", "3 mar 2027 noon" or "in 2 weeks".
+// A weekday means the next one from today, including today; "next" skips
+// today. Dates without a year lie in the coming twelve months.
+func ParseMoment(value string, now time.Time) (Moment, error) {
+	value = strings.TrimSpace(value)
+	if m, ok := parseMomentLayout(value); ok {
+		return m, nil
+	}
+
+	text := strings.Join(strings.Fields(strings.ToLower(value)), " ")
+	invalid := fmt.Errorf("cannot read the date '%s' (use e.g. 2006-01-02, 2006-01-02 15:04, tomorrow 5pm or next friday)", value)
+
+	if m := inPattern.FindStringSubmatch(text); m != nil {
+		count := 1
+		if m[1] != "a" && m[1] != "an" {
+			count, _ = strconv.Atoi(m[1])
+		}
+		switch m[2] {
+		case "minute":
+			return Moment{Time: now.Add(time.Duration(count) * time.Minute)}, nil
+		case "hour":
+			return Moment{Time: now.Add(time.Duration(count) * time.Hour)}, nil
+		case "day":
+			return Moment{Time: startOfDay(now).AddDate(0, 0, count), AllDay: true}, nil
+		case "week":
+			return Moment{Time: startOfDay(now).AddDate(0, 0, 7*count), AllDay: true}, nil
+		case "month":
+			return Moment{Time: startOfDay(now).AddDate(0, count, 0), AllDay: true}, nil
+		}
+		return Moment{Time: startOfDay(now).AddDate(count, 0, 0), AllDay: true}, nil
+	}
+
+	// Split off a time of day: after "at", or the last one or two words
+	dayText, clockText := text, ""
+	if before, after, found := strings.Cut(text, " at "); found {
+		dayText, clockText = before, after
+	} else if _, _, ok := parseClock(text); ok {
+		dayText, clockText = "", text
+	} else {
+		words := strings.Fields(text)
+		for n := min(2, len(words)-1); n >= 1; n-- {
+			if _, _, ok := parseClock(strings.Join(words[len(words)-n:], " ")); ok {
+				dayText, clockText = strings.Join(words[:len(words)-n], " "), strings.Join(words[len(words)-n:], " ")
+				break
+			}
+		}
+	}
+
+	day := startOfDay(now)
+	if dayText != "" {
+		var ok bool
+		if day, ok = parseDay(dayText, now); !ok {
+			return Moment{}, invalid
+		}
+	}
+	if clockText == "" {
+		return Moment{Time: day, AllDay: true}, nil
+	}
+	hour, minute, ok := parseClock(clockText)
+	if !ok {
+		return Moment{}, invalid
+	}
+	return Moment{Time: time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.Local)}, nil
+}
+
+// parseDay reads the date part of ParseMoment's input
+func parseDay(text string, now time.Time) (time.Time, bool) {
+	today := startOfDay(now)
+	switch text {
+	case "today", "tonight":
+		return today, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true
+	case "next week":
+		return today.AddDate(0, 0, 7), true
+	case "next month":
+		return today.AddDate(0, 1, 0), true
+	case "next year":
+		return today.AddDate(1, 0, 0), true
+	}
+	if t, err := time.ParseInLocation("2006-01-02", text, time.Local); err == nil {
+		return t, true
+	}
+
+	next := false
+	if rest, found := strings.CutPrefix(text, "next "); found {
+		text, next = rest, true
+	} else {
+		text = strings.TrimPrefix(text, "this ")
+	}
+	if weekday, ok := parseWeekday(text); ok {
+		days := (int(weekday) - int(today.Weekday()) + 7) % 7
+		if next && days == 0 {
+			days = 7
+		}
+		return today.AddDate(0, 0, days), true
+	}
+	if next {
+		return time.Time{}, false
+	}
+
+	var monthText, dayText, yearText string
+	if m := monthDayPattern.FindStringSubmatch(text); m != nil {
+		monthText, dayText, yearText = m[1], m[2], m[3]
+	} else if m := dayMonthPattern.FindStringSubmatch(text); m != nil {
+		dayText, monthText, yearText = m[1], m[2], m[3]
+	} else {
+		return time.Time{}, false
+	}
+	month, ok := parseMonth(monthText)
+	if !ok {
+		return time.Time{}, false
+	}
+	dayOfMonth, _ := strconv.Atoi(dayText)
+	year := today.Year()
+	if yearText != "" {
+		year, _ = strconv.Atoi(yearText)
+	}
+	t := time.Date(year, month, dayOfMonth, 0, 0, 0, 0, time.Local)
+	if t.Day() != dayOfMonth {
+		return time.Time{}, false
+	}
+	if yearText == "" && t.Before(today) {
+		t = t.AddDate(1, 0, 0)
+	}
+	return t, true
+}
+
+// parseClock reads a time of day such as 5pm, 5:30 pm, 17:00 or noon
+func parseClock(text string) (hour, minute int, ok bool) {
+	switch text {
+	case "noon", "midday":
+		return 12, 0, true
+	case "midnight":
+		return 0, 0, true
+	}
+	m := clockPattern.FindStringSubmatch(text)
+	// A bare number is a day of the month, not an hour
+	if m == nil || m[2] == "" && m[3] == "" {
+		return 0, 0, false
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+func parseWeekday(text string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if text == name || text == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+func parseMonth(text string) (time.Month, bool) {
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if text == name || len(text) >= 3 && strings.HasPrefix(name, text) {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// Duration is a length of time in front matter, such as the duration of
+// an event. Values that could not be read are kept as written.
+type Duration struct {
+	time.Duration
+
+	raw string
+}
+
+// IsZero reports whether the duration is unset
+func (d Duration) IsZero() bool {
+	return d.Duration == 0 && d.raw == ""
+}
+
+// String formats the duration compactly, in days when it is a whole
+// number of them: 2d, 1h30m, 45m
+func (d Duration) String() string {
+	switch {
+	case d.raw != "":
+		return d.raw
+	case d.Duration == 0:
+		return ""
+	case d.Duration%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d.Duration/(24*time.Hour))
+	}
+	s := d.Duration.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+func (d Duration) MarshalYAML() (any, error) {
+	return d.String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	*d = Duration{}
+	if value.Value == "" {
+		return nil
+	}
+	if parsed, err := ParseDuration(value.Value); err == nil {
+		d.Duration = parsed
+	} else {
+		d.raw = value.Value
+	}
+	return nil
+}
+
+var durationPartPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*([a-z]+)`)
+
+// durationUnits maps the units ParseDuration accepts to their length
+var durationUnits = map[string]time.Duration{
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// ParseDuration reads a positive length of time such as 90m, 1h30m, 2d,
+// 1.5 hours or "1 hour and 15 minutes"
+func ParseDuration(value string) (time.Duration, error) {
+	text := strings.ToLower(strings.TrimSpace(value))
+	if d, err := time.ParseDuration(text); err == nil && d > 0 {
+		return d, nil
+	}
+
+	var total time.Duration
+	rest := text
+	for _, m := range durationPartPattern.FindAllStringSubmatch(text, -1) {
+		unit, ok := durationUnits[m[2]]
+		if !ok {
+			return 0, fmt.Errorf("invalid duration '%s': unknown unit '%s'", value, m[2])
+		}
+		count, _ := strconv.ParseFloat(m[1], 64)
+		total += time.Duration(count * float64(unit))
+		rest = strings.Replace(rest, m[0], "", 1)
+	}
+	rest = strings.NewReplacer(",", "", "and", "").Replace(rest)
+	if total <= 0 || strings.TrimSpace(rest) != "" {
+		return 0, fmt.Errorf("invalid duration '%s' (use e.g. 45m, 1h30m, 2d or 1.5 hours)", value)
+	}
+	return total, nil
+}
@@ -0,0 +1,10 @@
+package note
+
+import "time"
+
+// Due returns the note's due date. allDay is set when the field holds a
+// date without a time.
+func (n *Note) Due() (due time.Time, allDay bool, ok bool) {
+	d := n.Metadata.Due
+	return d.Time, d.AllDay, d.Valid()
+}
@@ -0,0 +1,63 @@
+package note
+
+import (
+	"fmt"
+
+	"memo/internal/vault"
+)
+
+// Locked reports whether the note content is encrypted and has not been
+// decrypted in this session
+func (n *Note) Locked() bool {
+	return n.Metadata.Encrypted && n.key == ""
+}
+
+// Encrypt marks the note as sensitive; its content is encrypted with
+// passphrase whenever the note is written. Title and tags stay in plain text.
+func (n *Note) Encrypt(passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("encryption key must not be empty")
+	}
+	if n.Locked() {
+		return fmt.Errorf("note is already encrypted")
+	}
+	n.Metadata.Encrypted = true
+	n.key = passphrase
+	return nil
+}
+
+// Decrypt replaces encrypted content with its plain text in memory. The note
+// stays encrypted on disk when saved again.
+func (n *Note) Decrypt(passphrase string) error {
+	if !n.Locked() {
+		return nil
+	}
+
+	plaintext, err := vault.Decrypt(n.Content, passphrase)
+	if err != nil {
+		return err
+	}
+
+	n.Content = plaintext
+	n.key = passphrase
+	return nil
+}
+
+// RemoveEncryption stores the note content in plain text from now on.
+// The note must be decrypted first.
+func (n *Note) RemoveEncryption() error {
+	if n.Locked() {
+		return fmt.Errorf("note must be decrypted first")
+	}
+	n.Metadata.Encrypted = false
+	n.key = ""
+	return nil
+}
+
+// fileBody returns the content as it should be written to disk
+func (n *Note) fileBody() (string, error) {
+	if !n.Metadata.Encrypted || n.key == "" {
+		return n.Content, nil
+	}
+	return vault.Encrypt(n.Content, n.key)
+}
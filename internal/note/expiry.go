@@ -0,0 +1,41 @@
+package note
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expired reports whether the note has an expiry time at or before now
+func (n *Note) Expired(now time.Time) bool {
+	return !n.Metadata.Expires.IsZero() && !n.Metadata.Expires.After(now)
+}
+
+// ParseExpiry parses an expiry given as a date (2006-01-02), an RFC 3339
+// timestamp, a duration from now such as 12h, 7d or 2w, or anything
+// ParseMoment understands, like "next friday"
+func ParseExpiry(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if len(value) > 1 {
+		unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[value[len(value)-1]]
+		if count, err := strconv.Atoi(value[:len(value)-1]); err == nil && unit > 0 && count > 0 {
+			return now.Add(time.Duration(count) * unit), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(d), nil
+	}
+	if m, err := ParseMoment(value, now); err == nil {
+		return m.Time, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid expiry '%s' (use a date like 2006-01-02 or next friday, or a duration like 12h, 7d, 2w)", value)
+}
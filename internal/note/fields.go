@@ -0,0 +1,192 @@
+package note
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldNames returns the names of the note's custom fields in sorted order
+func (n *Note) FieldNames() []string {
+	names := make([]string, 0, len(n.Metadata.Fields))
+	for name := range n.Metadata.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Field returns the values of a front matter field as strings. Built-in
+// fields are looked up by their YAML key; list values yield one entry each.
+func (n *Note) Field(name string) ([]string, bool) {
+	switch strings.ToLower(name) {
+	case "title":
+		return []string{n.Metadata.Title}, true
+	case "tags":
+		return n.Metadata.Tags, len(n.Metadata.Tags) > 0
+	case "author":
+		return []string{n.Metadata.Author}, n.Metadata.Author != ""
+	case "status":
+		return []string{n.Metadata.Status}, n.Metadata.Status != ""
+	case "priority":
+		return []string{strconv.Itoa(n.Metadata.Priority)}, n.Metadata.Priority > 0
+	case "created":
+		return []string{n.Metadata.Created.Format(time.RFC3339)}, !n.Metadata.Created.IsZero()
+	case "modified":
+		return []string{n.Metadata.Modified.Format(time.RFC3339)}, !n.Metadata.Modified.IsZero()
+	case "location":
+		l := n.Metadata.Location
+		if l == nil {
+			return nil, false
+		}
+		var values []string
+		if l.Name != "" {
+			values = append(values, l.Name)
+		}
+		if l.HasCoordinates() {
+			values = append(values, l.coordinates())
+		}
+		return values, true
+	case "due":
+		return []string{n.Metadata.Due.String()}, !n.Metadata.Due.IsZero()
+	case "starts":
+		return []string{n.Metadata.Starts.String()}, !n.Metadata.Starts.IsZero()
+	case "duration":
+		return []string{n.Metadata.Duration.String()}, !n.Metadata.Duration.IsZero()
+	case "completed_at":
+		return []string{n.Metadata.CompletedAt.String()}, !n.Metadata.CompletedAt.IsZero()
+	}
+
+	for key, node := range n.Metadata.Fields {
+		if strings.EqualFold(key, name) {
+			return FieldValues(&node), true
+		}
+	}
+	return nil, false
+}
+
+// SetField sets a custom field to a plain string value. Names of built-in
+// fields are refused; those are set through Metadata or SetMetadata.
+func (n *Note) SetField(name, value string) error {
+	if IsMetadataField(name) {
+		return fmt.Errorf("'%s' is a built-in field, not a custom one", name)
+	}
+	if n.Metadata.Fields == nil {
+		n.Metadata.Fields = make(map[string]yaml.Node)
+	}
+	var node yaml.Node
+	node.SetString(value)
+	n.Metadata.Fields[name] = node
+	return nil
+}
+
+// FieldMap decodes the custom fields into plain Go values
+func (n *Note) FieldMap() map[string]any {
+	if len(n.Metadata.Fields) == 0 {
+		return nil
+	}
+	fields := make(map[string]any, len(n.Metadata.Fields))
+	for name, node := range n.Metadata.Fields {
+		var value any
+		if err := node.Decode(&value); err != nil {
+			value = node.Value
+		}
+		fields[name] = value
+	}
+	return fields
+}
+
+// FieldValues flattens a field's YAML node into its scalar values exactly as
+// written in the file
+func FieldValues(node *yaml.Node) []string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return []string{node.Value}
+	case yaml.AliasNode:
+		return FieldValues(node.Alias)
+	}
+
+	var values []string
+	for i, child := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			continue
+		}
+		values = append(values, FieldValues(child)...)
+	}
+	return values
+}
+
+// MatchField reports whether the note has field name and, when value is
+// not empty, whether any of its values equals value (case-insensitive)
+func (n *Note) MatchField(name, value string) bool {
+	values, ok := n.Field(name)
+	if !ok {
+		return false
+	}
+	if value == "" {
+		return true
+	}
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareField reports whether any value of field name satisfies op (one of
+// =, <, <=, >, >=) against value, or for != that none equals it. Numbers
+// compare numerically, anything else as case-insensitive text, which orders
+// ISO dates correctly.
+func (n *Note) CompareField(name, op, value string) bool {
+	if op == "!=" {
+		return !n.CompareField(name, "=", value)
+	}
+	values, ok := n.Field(name)
+	if !ok {
+		return false
+	}
+	for _, v := range values {
+		if compareOp(compareValues(v, value), op) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareValues(a, b string) int {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func compareOp(cmp int, op string) bool {
+	switch op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package note
+
+import (
+	"strings"
+
+	"memo/internal/language"
+)
+
+// LanguageField is the front matter field holding the language of a note,
+// as an ISO 639-1 code such as "en" or "de"
+const LanguageField = "language"
+
+// Language returns the language of the note: its language field, or else
+// the language detected in its title and content, or ""
+func (n *Note) Language() string {
+	if values, _ := n.Field(LanguageField); len(values) == 1 && values[0] != "" {
+		return strings.ToLower(values[0])
+	}
+	if n.Locked() {
+		return ""
+	}
+	return language.Detect(n.Metadata.Title + "\n" + n.Content)
+}
+
+// DetectLanguage fills in the language field from the content when it is
+// not set, and reports whether it did. A language set by hand is kept;
+// clearing the field has it detected again.
+func (n *Note) DetectLanguage() bool {
+	if values, _ := n.Field(LanguageField); len(values) > 0 || n.Locked() {
+		return false
+	}
+	code := language.Detect(n.Metadata.Title + "\n" + n.Content)
+	if code == "" {
+		return false
+	}
+	n.SetField(LanguageField, code)
+	return true
+}
@@ -0,0 +1,51 @@
+package note
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	wikiLinkPattern = regexp.MustCompile(`\[\[([^\]|]+)(?:\|[^\]]*)?\]\]`)
+	localRefPattern = regexp.MustCompile(`!?\[[^\]]*\]\(([^)\s]+)\)`)
+)
+
+// WikiLinks returns the targets of [[wikilinks]] in the content. A link may
+// name a note ID or title and carry an alias: [[target|shown text]].
+func (n *Note) WikiLinks() []string {
+	var links []string
+	for _, m := range wikiLinkPattern.FindAllStringSubmatch(n.Content, -1) {
+		links = append(links, strings.TrimSpace(m[1]))
+	}
+	return links
+}
+
+// LocalReferences returns the targets of Markdown links and images that
+// point at local files rather than URLs or anchors
+func (n *Note) LocalReferences() []string {
+	var refs []string
+	for _, m := range localRefPattern.FindAllStringSubmatch(n.Content, -1) {
+		target := m[1]
+		if strings.Contains(target, "://") || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "mailto:") {
+			continue
+		}
+		refs = append(refs, target)
+	}
+	return refs
+}
+
+// RenameLinks rewrites the [[wikilinks]] whose target is oldTarget (ignoring
+// case) to point at newTarget, keeping any alias, and returns how many links
+// were changed
+func (n *Note) RenameLinks(oldTarget, newTarget string) int {
+	changed := 0
+	n.Content = wikiLinkPattern.ReplaceAllStringFunc(n.Content, func(link string) string {
+		m := wikiLinkPattern.FindStringSubmatch(link)
+		if !strings.EqualFold(strings.TrimSpace(m[1]), oldTarget) {
+			return link
+		}
+		changed++
+		return "[[" + newTarget + link[2+len(m[1]):]
+	})
+	return changed
+}
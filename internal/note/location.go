@@ -0,0 +1,101 @@
+package note
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// earthRadiusKm is the mean radius of the earth used for distances
+const earthRadiusKm = 6371.0
+
+// Location is where a note was taken: a named place, coordinates, or both.
+// In front matter it is written as just the name or "lat,lon" when it has
+// only one of them.
+type Location struct {
+	Name string  `yaml:"name,omitempty"`
+	Lat  float64 `yaml:"lat,omitempty"`
+	Lon  float64 `yaml:"lon,omitempty"`
+}
+
+// ParseLocation reads "lat,lon" as coordinates and anything else as the
+// name of a place
+func ParseLocation(s string) Location {
+	s = strings.TrimSpace(s)
+	if lat, lon, ok := strings.Cut(s, ","); ok {
+		la, errLat := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+		lo, errLon := strconv.ParseFloat(strings.TrimSpace(lon), 64)
+		if errLat == nil && errLon == nil && math.Abs(la) <= 90 && math.Abs(lo) <= 180 {
+			return Location{Lat: la, Lon: lo}
+		}
+	}
+	return Location{Name: s}
+}
+
+// HasCoordinates reports whether the location has a latitude and longitude
+func (l Location) HasCoordinates() bool {
+	return l.Lat != 0 || l.Lon != 0
+}
+
+// String returns the name and coordinates that are set
+func (l Location) String() string {
+	switch {
+	case !l.HasCoordinates():
+		return l.Name
+	case l.Name == "":
+		return l.coordinates()
+	}
+	return fmt.Sprintf("%s (%s)", l.Name, l.coordinates())
+}
+
+// coordinates formats the latitude and longitude the way ParseLocation
+// reads them
+func (l Location) coordinates() string {
+	return strconv.FormatFloat(l.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(l.Lon, 'f', -1, 64)
+}
+
+// DistanceKm returns the great-circle distance between two locations with
+// coordinates
+func (l Location) DistanceKm(other Location) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(other.Lat - l.Lat)
+	dLon := rad(other.Lon - l.Lon)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(l.Lat))*math.Cos(rad(other.Lat))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Near reports whether l is within radiusKm of place. Without coordinates
+// on both sides the names are compared instead, ignoring case.
+func (l Location) Near(place Location, radiusKm float64) bool {
+	if l.HasCoordinates() && place.HasCoordinates() {
+		return l.DistanceKm(place) <= radiusKm
+	}
+	return l.Name != "" && strings.EqualFold(l.Name, place.Name)
+}
+
+// MarshalYAML writes a location with only a name or only coordinates as a
+// single value
+func (l Location) MarshalYAML() (any, error) {
+	switch {
+	case !l.HasCoordinates():
+		return l.Name, nil
+	case l.Name == "":
+		return l.coordinates(), nil
+	}
+	type plain Location
+	return plain(l), nil
+}
+
+// UnmarshalYAML accepts both a single value and a mapping
+func (l *Location) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = ParseLocation(value.Value)
+		return nil
+	}
+	type plain Location
+	return value.Decode((*plain)(l))
+}
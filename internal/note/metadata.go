@@ -0,0 +1,135 @@
+package note
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EditableFields are the built-in front matter fields SetMetadata accepts,
+// in the order `memo edit --metadata` asks for them
+var EditableFields = []string{"title", "status", "priority", "due", "starts", "duration", "completed_at", "expires", "author", "tags", "location"}
+
+// readOnlyFields are maintained by memo itself
+var readOnlyFields = map[string]bool{"created": true, "modified": true, "encrypted": true, "clock": true}
+
+// IsMetadataField reports whether name, in any case, is the key of a
+// built-in front matter field rather than a custom one
+func IsMetadataField(name string) bool {
+	key := strings.ToLower(name)
+	return readOnlyFields[key] || slices.Contains(EditableFields, key)
+}
+
+// MetadataValue returns a front matter field formatted for editing, with
+// list values joined by commas
+func (n *Note) MetadataValue(name string) string {
+	switch strings.ToLower(name) {
+	case "expires":
+		if n.Metadata.Expires.IsZero() {
+			return ""
+		}
+		return n.Metadata.Expires.Format(time.RFC3339)
+	case "priority":
+		if n.Metadata.Priority == 0 {
+			return ""
+		}
+	}
+	values, _ := n.Field(name)
+	return strings.Join(values, ", ")
+}
+
+// SetMetadata validates value and stores it in a front matter field. An
+// empty value clears the field, except for the title, which is required.
+// Names other than the built-in fields set custom fields; color is one
+// that is checked.
+func (n *Note) SetMetadata(name, value string) error {
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	key := strings.ToLower(name)
+	if key == "" {
+		return fmt.Errorf("field name required")
+	}
+	if readOnlyFields[key] {
+		return fmt.Errorf("field '%s' cannot be changed", key)
+	}
+
+	switch key {
+	case "title":
+		if value == "" {
+			return fmt.Errorf("title must not be empty")
+		}
+		n.Metadata.Title = value
+	case "status":
+		n.Metadata.Status = value
+	case "author":
+		n.Metadata.Author = value
+	case "priority":
+		priority := 0
+		if value != "" {
+			var err error
+			if priority, err = strconv.Atoi(value); err != nil || priority < 0 {
+				return fmt.Errorf("priority must be a whole number of at least 0, not '%s'", value)
+			}
+		}
+		n.Metadata.Priority = priority
+	case "tags":
+		var tags []string
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		n.Metadata.Tags = tags
+	case "location":
+		n.Metadata.Location = nil
+		if value != "" {
+			location := ParseLocation(value)
+			n.Metadata.Location = &location
+		}
+	case "expires":
+		var expires time.Time
+		if value != "" {
+			var err error
+			if expires, err = ParseExpiry(value, time.Now()); err != nil {
+				return err
+			}
+		}
+		n.Metadata.Expires = expires
+	case "due", "starts", "duration", "completed_at":
+		if err := n.SetDateField(key, value, time.Now()); err != nil {
+			return err
+		}
+	case "color":
+		// Kept with the custom fields, where it lived before memo used it
+		if value != "" && !ValidColor(value) {
+			return fmt.Errorf("unknown color '%s' (use %s or a hex value like #ff8800)", value, strings.Join(ColorNames, ", "))
+		}
+		if err := n.setCustomField(name, strings.ToLower(value)); err != nil {
+			return err
+		}
+	default:
+		if err := n.setCustomField(name, value); err != nil {
+			return err
+		}
+	}
+
+	n.Metadata.Modified = time.Now()
+	return nil
+}
+
+// setCustomField sets or, for an empty value, removes a custom field,
+// keeping the spelling of an existing key that differs only by case
+func (n *Note) setCustomField(name, value string) error {
+	for key := range n.Metadata.Fields {
+		if strings.EqualFold(key, name) {
+			name = key
+			break
+		}
+	}
+	if value == "" {
+		delete(n.Metadata.Fields, name)
+		return nil
+	}
+	return n.SetField(name, value)
+}
@@ -3,25 +3,46 @@ package note
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Metadata struct {
-	Title    string    `yaml:"title"`
-	Created  time.Time `yaml:"created"`
-	Modified time.Time `yaml:"modified"`
-	Tags     []string  `yaml:"tags,omitempty"`
-	Author   string    `yaml:"author,omitempty"`
-	Status   string    `yaml:"status,omitempty"`
-	Priority int       `yaml:"priority,omitempty"`
+	Title     string    `yaml:"title"`
+	Created   time.Time `yaml:"created"`
+	Modified  time.Time `yaml:"modified"`
+	Tags      []string  `yaml:"tags,omitempty"`
+	Author    string    `yaml:"author,omitempty"`
+	Status    string    `yaml:"status,omitempty"`
+	Priority  int       `yaml:"priority,omitempty"`
+	Encrypted bool      `yaml:"encrypted,omitempty"`
+	Expires   time.Time `yaml:"expires,omitempty"`
+	Location  *Location `yaml:"location,omitempty"`
+
+	// Due, Starts and CompletedAt hold dates of tasks and events, and
+	// Duration how long an event lasts
+	Due         Moment   `yaml:"due,omitempty"`
+	Starts      Moment   `yaml:"starts,omitempty"`
+	Duration    Duration `yaml:"duration,omitempty"`
+	CompletedAt Moment   `yaml:"completed_at,omitempty"`
+
+	// Clock holds the time spent on the note, tracked by `memo clock`
+	Clock []TimeEntry `yaml:"clock,omitempty"`
+
+	// Fields holds user-defined front matter keys so they survive a save
+	Fields map[string]yaml.Node `yaml:",inline"`
 }
 
 type Note struct {
 	Metadata Metadata
 	Content  string
 	FilePath string
+
+	// key is the passphrase of an encrypted note once it has been unlocked
+	key string
 }
 
 func New(title, content string, tags []string) *Note {
@@ -41,6 +62,12 @@ func (n *Note) SetFilePath(path string) {
 	n.FilePath = path
 }
 
+// ID returns the note identifier derived from its file name
+func (n *Note) ID() string {
+	base := filepath.Base(n.FilePath)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
 func (n *Note) UpdateContent(content string) {
 	n.Content = content
 	n.Metadata.Modified = time.Now()
@@ -53,13 +80,23 @@ func (n *Note) UpdateTags(tags []string) {
 
 func (n *Note) ToFileContent() (string, error) {
 	n.Metadata.Modified = time.Now()
+	return n.Format()
+}
 
+// Format renders the note as YAML front matter followed by its content,
+// without touching the modification time
+func (n *Note) Format() (string, error) {
 	yamlData, err := yaml.Marshal(&n.Metadata)
 	if err != nil {
 		return "", fmt.Errorf("error marshaling metadata: %w", err)
 	}
 
-	return fmt.Sprintf("---\n%s---\n\n%s", string(yamlData), n.Content), nil
+	body, err := n.fileBody()
+	if err != nil {
+		return "", fmt.Errorf("error encrypting content: %w", err)
+	}
+
+	return fmt.Sprintf("---\n%s---\n\n%s", string(yamlData), body), nil
 }
 
 func (n *Note) Save() error {
@@ -69,4 +106,4 @@ func (n *Note) Save() error {
 	}
 
 	return os.WriteFile(n.FilePath, []byte(content), 0644)
-}
\ No newline at end of file
+}
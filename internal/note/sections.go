@@ -0,0 +1,80 @@
+package note
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var sectionHeadingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// Section is the part of a note's content below one heading
+type Section struct {
+	Heading string
+	Content string
+}
+
+// Sections splits the content at the headings of the given level, ignoring
+// headings inside code blocks. The text before the first such heading is
+// returned as intro; deeper headings stay inside their section. A level of
+// 0 picks the highest level that occurs at least twice.
+func (n *Note) Sections(level int) (intro string, sections []Section, err error) {
+	lines := strings.Split(n.Content, "\n")
+	levels := headingLevels(lines)
+	if level == 0 {
+		for l := 1; l <= 6; l++ {
+			if levels[l] >= 2 {
+				level = l
+				break
+			}
+		}
+		if level == 0 {
+			return "", nil, fmt.Errorf("note has no headings to split at")
+		}
+	}
+	if levels[level] == 0 {
+		return "", nil, fmt.Errorf("note has no level %d headings", level)
+	}
+
+	var current []string
+	flush := func() {
+		text := strings.Trim(strings.Join(current, "\n"), "\n")
+		if sections == nil {
+			intro = text
+		} else {
+			sections[len(sections)-1].Content = text
+		}
+		current = nil
+	}
+
+	fenced := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+		}
+		if m := sectionHeadingPattern.FindStringSubmatch(line); !fenced && m != nil && len(m[1]) == level {
+			flush()
+			sections = append(sections, Section{Heading: m[2]})
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+	return intro, sections, nil
+}
+
+// headingLevels counts the headings of each level outside code blocks
+func headingLevels(lines []string) map[int]int {
+	levels := make(map[int]int)
+	fenced := false
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+			continue
+		}
+		if m := sectionHeadingPattern.FindStringSubmatch(line); !fenced && m != nil {
+			levels[len(m[1])]++
+		}
+	}
+	return levels
+}
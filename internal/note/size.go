@@ -0,0 +1,28 @@
+package note
+
+import "strings"
+
+// SizeLimits are soft limits on the content of a note. Larger notes still
+// work but are slow to edit and search, so they are flagged. A zero limit
+// is not checked.
+type SizeLimits struct {
+	Bytes int
+	Lines int
+}
+
+// DefaultSizeLimits apply when the configuration sets none
+var DefaultSizeLimits = SizeLimits{Bytes: 256 * 1024, Lines: 5000}
+
+// Size returns the length of the content in bytes and lines
+func (n *Note) Size() (bytes, lines int) {
+	if n.Content == "" {
+		return 0, 0
+	}
+	return len(n.Content), strings.Count(strings.TrimRight(n.Content, "\n"), "\n") + 1
+}
+
+// Exceeds reports whether the content of n is over either limit
+func (l SizeLimits) Exceeds(n *Note) bool {
+	bytes, lines := n.Size()
+	return (l.Bytes > 0 && bytes > l.Bytes) || (l.Lines > 0 && lines > l.Lines)
+}
@@ -0,0 +1,108 @@
+package note
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// TagSeparator separates the levels of a hierarchical tag like "project/alpha"
+const TagSeparator = "/"
+
+// TagMatches reports whether tag equals filter or is nested below it,
+// ignoring case: the filter "project" matches "project" and "project/alpha".
+func TagMatches(tag, filter string) bool {
+	tag = strings.ToLower(tag)
+	filter = strings.ToLower(strings.TrimSuffix(filter, TagSeparator))
+	return tag == filter || strings.HasPrefix(tag, filter+TagSeparator)
+}
+
+// HasTag reports whether any of the note's tags matches filter
+func (n *Note) HasTag(filter string) bool {
+	for _, tag := range n.Metadata.Tags {
+		if TagMatches(tag, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTag adds tag unless the note already has it (ignoring case) and
+// reports whether it was added
+func (n *Note) AddTag(tag string) bool {
+	for _, have := range n.Metadata.Tags {
+		if strings.EqualFold(have, tag) {
+			return false
+		}
+	}
+	n.UpdateTags(append(n.Metadata.Tags, tag))
+	return true
+}
+
+// RemoveTag removes tag (ignoring case, but not the tags nested below it)
+// and reports whether the note had it
+func (n *Note) RemoveTag(tag string) bool {
+	tags := slices.DeleteFunc(slices.Clone(n.Metadata.Tags), func(have string) bool {
+		return strings.EqualFold(have, tag)
+	})
+	if len(tags) == len(n.Metadata.Tags) {
+		return false
+	}
+	n.UpdateTags(tags)
+	return true
+}
+
+var (
+	hashtagPattern    = regexp.MustCompile(`(?:^|[\s(\[{,;])#(\pL[\pL\pN_/-]*)`)
+	inlineCodePattern = regexp.MustCompile("`[^`]*`")
+)
+
+// Hashtags returns the #hashtags written in the content, in order of first
+// appearance and without duplicates (ignoring case). A hashtag must start
+// with a letter and may be nested like #project/alpha; text in code blocks,
+// inline code, URLs and Markdown headings is not searched.
+func (n *Note) Hashtags() []string {
+	if n.Locked() {
+		return nil
+	}
+
+	var tags []string
+	seen := make(map[string]bool)
+	inFence := false
+	for _, line := range strings.Split(n.Content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		line = inlineCodePattern.ReplaceAllString(line, "")
+		for _, m := range hashtagPattern.FindAllStringSubmatch(line, -1) {
+			tag := strings.TrimRight(m[1], "/-")
+			if !seen[strings.ToLower(tag)] {
+				seen[strings.ToLower(tag)] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// MergeHashtags adds the content's hashtags that are missing from the tags
+// and reports whether any were added
+func (n *Note) MergeHashtags() bool {
+	have := make(map[string]bool, len(n.Metadata.Tags))
+	for _, tag := range n.Metadata.Tags {
+		have[strings.ToLower(tag)] = true
+	}
+
+	added := false
+	for _, tag := range n.Hashtags() {
+		if !have[strings.ToLower(tag)] {
+			n.Metadata.Tags = append(n.Metadata.Tags, tag)
+			added = true
+		}
+	}
+	return added
+}
@@ -0,0 +1,55 @@
+package note
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// taskPattern matches Markdown checklist items such as "- [ ] buy milk"
+var taskPattern = regexp.MustCompile(`^(\s*[-*+]\s+\[)([ xX])(\]\s*)(.*)$`)
+
+// Task is a checklist item found in note content
+type Task struct {
+	Number int // 1-based position among the note's tasks
+	Line   int // 0-based line index in the content
+	Text   string
+	Done   bool
+}
+
+// Tasks returns all checklist items in the note content
+func (n *Note) Tasks() []Task {
+	var tasks []Task
+	for i, line := range strings.Split(n.Content, "\n") {
+		m := taskPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		tasks = append(tasks, Task{
+			Number: len(tasks) + 1,
+			Line:   i,
+			Text:   strings.TrimSpace(m[4]),
+			Done:   m[2] != " ",
+		})
+	}
+	return tasks
+}
+
+// SetTaskDone marks the numbered task as done or open
+func (n *Note) SetTaskDone(number int, done bool) error {
+	tasks := n.Tasks()
+	if number < 1 || number > len(tasks) {
+		return fmt.Errorf("task %d not found. Valid range: 1-%d", number, len(tasks))
+	}
+
+	mark := " "
+	if done {
+		mark = "x"
+	}
+
+	lines := strings.Split(n.Content, "\n")
+	line := tasks[number-1].Line
+	lines[line] = taskPattern.ReplaceAllString(lines[line], "${1}"+mark+"${3}${4}")
+	n.UpdateContent(strings.Join(lines, "\n"))
+	return nil
+}
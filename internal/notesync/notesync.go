@@ -0,0 +1,221 @@
+// Package notesync keeps the notes store and another directory, such as a
+// folder synchronized by a cloud drive, in step. Content hashes decide what
+// changed since the last sync, so files that were only touched are never
+// copied.
+package notesync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"memo/internal/index"
+)
+
+// StateFileName is the file inside the notes store remembering, for every
+// sync target, the files it held after the last sync
+const StateFileName = ".sync.yaml"
+
+// BaseDirName is the directory inside the notes store keeping the content
+// of synced notes as of the last sync, for `memo resolve`
+const BaseDirName = ".sync-base"
+
+// State maps absolute target directories to their entries at the last sync
+type State map[string]index.Index
+
+// LoadState reads the sync state; a missing file yields an empty state
+func LoadState(path string) (State, error) {
+	state := make(State)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading sync state: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("error parsing sync state: %w", err)
+	}
+	return state, nil
+}
+
+// SaveState writes the sync state
+func SaveState(path string, state State) error {
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("error marshaling sync state: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Scan returns the entries of the note files in dir, reusing the hashes in
+// previous for files whose size and modification time did not change
+func Scan(dir, ext string, previous index.Index) (index.Index, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+	if err != nil {
+		return nil, err
+	}
+	entries := make(index.Index, len(files))
+	for _, file := range files {
+		id := strings.TrimSuffix(filepath.Base(file), ext)
+		known, indexed := previous[id]
+		entry, _, err := index.Current(known, indexed, file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", file, err)
+		}
+		entries[id] = entry
+	}
+	return entries, nil
+}
+
+// Action is what a sync does with one note
+type Action int
+
+const (
+	Upload Action = iota
+	Download
+	DeleteRemote
+	DeleteLocal
+	// Conflict means both sides changed the note differently
+	Conflict
+)
+
+func (a Action) String() string {
+	return [...]string{"upload", "download", "delete remote", "delete local", "conflict"}[a]
+}
+
+// Step is one planned action
+type Step struct {
+	ID     string
+	Action Action
+}
+
+// Plan compares the current local and remote entries with base, the state
+// after the last sync, and returns the steps that bring both sides in line.
+// A note deleted on one side and changed on the other is copied rather than
+// deleted, so no edit is lost.
+func Plan(local, remote, base index.Index) []Step {
+	ids := make(map[string]bool)
+	for _, ix := range []index.Index{local, remote} {
+		for id := range ix {
+			ids[id] = true
+		}
+	}
+
+	var steps []Step
+	for id := range ids {
+		l, hasLocal := local[id]
+		r, hasRemote := remote[id]
+		b, hasBase := base[id]
+		unchanged := func(e index.Entry) bool { return hasBase && e.Hash == b.Hash }
+
+		var action Action
+		switch {
+		case hasLocal && hasRemote && l.Hash == r.Hash:
+			continue
+		case hasLocal && !hasRemote:
+			action = Upload
+			if unchanged(l) {
+				action = DeleteLocal
+			}
+		case hasRemote && !hasLocal:
+			action = Download
+			if unchanged(r) {
+				action = DeleteRemote
+			}
+		case unchanged(l):
+			action = Download
+		case unchanged(r):
+			action = Upload
+		default:
+			action = Conflict
+		}
+		steps = append(steps, Step{ID: id, Action: action})
+	}
+
+	sort.Slice(steps, func(i, j int) bool { return steps[i].ID < steps[j].ID })
+	return steps
+}
+
+// CopyFile copies src to dst through a temporary file, keeping the
+// modification time so unchanged files are recognized without hashing
+func CopyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	return WriteFile(dst, data, info.ModTime())
+}
+
+// WriteFile replaces dst with data through a temporary file and sets its
+// modification time
+func WriteFile(dst string, data []byte, modTime time.Time) error {
+	tmp := dst + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Chtimes(dst, modTime, modTime)
+}
+
+// SaveBases keeps a copy of every synced file of dir, named by its hash,
+// in baseDir, so the version both sides started from is known when they
+// later conflict. Copies no target in state refers to are removed.
+func SaveBases(baseDir, dir, ext string, synced index.Index, state State) error {
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return fmt.Errorf("error creating sync base directory: %w", err)
+	}
+	for id, entry := range synced {
+		dst := filepath.Join(baseDir, entry.Hash)
+		if _, err := os.Stat(dst); err == nil {
+			continue
+		}
+		// Skipped conflicts keep the entry of a version the file no
+		// longer holds
+		src := filepath.Join(dir, id+ext)
+		if current, err := index.HashFile(src); err != nil || current.Hash != entry.Hash {
+			continue
+		}
+		if err := CopyFile(src, dst); err != nil {
+			return fmt.Errorf("error saving sync base of %s: %w", id, err)
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, entries := range state {
+		for _, entry := range entries {
+			used[entry.Hash] = true
+		}
+	}
+	files, err := os.ReadDir(baseDir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if !used[f.Name()] {
+			os.Remove(filepath.Join(baseDir, f.Name()))
+		}
+	}
+	return nil
+}
+
+// LoadBase returns the content a note had at the last sync, or false when
+// no copy of it was kept
+func LoadBase(baseDir string, entry index.Entry) ([]byte, bool) {
+	if entry.Hash == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(baseDir, entry.Hash))
+	return data, err == nil
+}
@@ -0,0 +1,106 @@
+package recur
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the file inside the notes store holding recurrence rules
+const FileName = ".recur.yaml"
+
+// Rule creates a note from a template on a schedule
+type Rule struct {
+	Name     string    `yaml:"name"`
+	Every    string    `yaml:"every"`
+	Template string    `yaml:"template"`
+	Added    time.Time `yaml:"added"`
+	LastRun  time.Time `yaml:"last_run,omitempty"`
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday,
+	"wednesday": time.Wednesday, "thursday": time.Thursday, "friday": time.Friday,
+	"saturday": time.Saturday,
+}
+
+// ValidateSchedule checks an --every value. Supported schedules are "daily",
+// "weekdays", "monthly" and a weekday name such as "monday".
+func ValidateSchedule(every string) error {
+	switch strings.ToLower(every) {
+	case "daily", "weekdays", "monthly":
+		return nil
+	}
+	if _, ok := weekdays[strings.ToLower(every)]; ok {
+		return nil
+	}
+	return fmt.Errorf("unsupported schedule '%s' (use daily, weekdays, monthly or a weekday name)", every)
+}
+
+// matches reports whether the schedule fires on the given day
+func (r Rule) matches(day time.Time) bool {
+	every := strings.ToLower(r.Every)
+	switch every {
+	case "daily":
+		return true
+	case "weekdays":
+		return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+	case "monthly":
+		return day.Day() == 1
+	}
+	wd, ok := weekdays[every]
+	return ok && day.Weekday() == wd
+}
+
+// DueOccurrence returns the most recent day on which the rule should have
+// fired after its last run (or since it was added) up to now. Missed
+// occurrences are collapsed into one so an infrequent `recur run` does not
+// flood the store.
+func (r Rule) DueOccurrence(now time.Time) (time.Time, bool) {
+	since := r.LastRun
+	if since.IsZero() {
+		since = r.Added.AddDate(0, 0, -1)
+	}
+
+	day := startOfDay(now)
+	floor := startOfDay(since)
+	for ; day.After(floor); day = day.AddDate(0, 0, -1) {
+		if r.matches(day) {
+			return day, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// Load reads the rules file; a missing file yields no rules
+func Load(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading recurrence rules: %w", err)
+	}
+
+	var rules []Rule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("error parsing recurrence rules: %w", err)
+	}
+	return rules, nil
+}
+
+// Save writes the rules file
+func Save(path string, rules []Rule) error {
+	data, err := yaml.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("error marshaling recurrence rules: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
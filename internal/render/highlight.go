@@ -0,0 +1,269 @@
+package render
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// TokenKind classifies a piece of highlighted code
+type TokenKind int
+
+const (
+	Plain TokenKind = iota
+	Keyword
+	String
+	Comment
+	Number
+)
+
+// Token is a run of code of one kind
+type Token struct {
+	Kind TokenKind
+	Text string
+}
+
+// syntax describes just enough of a language to color its code
+type syntax struct {
+	lineComments []string
+	blockComment [2]string
+	quotes       string
+	keywords     map[string]bool
+	// ignoreCase matches keywords regardless of case, as in SQL
+	ignoreCase bool
+}
+
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	cLike = "break case continue default do else for goto if return switch while"
+
+	goSyntax = &syntax{
+		lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}, quotes: "\"'`",
+		keywords: wordSet(cLike + " chan const defer fallthrough func go import interface map package range select struct type var nil true false iota"),
+	}
+	cSyntax = &syntax{
+		lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}, quotes: "\"'",
+		keywords: wordSet(cLike + " auto char const double enum extern float inline int long register short signed sizeof static struct typedef union unsigned void volatile bool class namespace new delete template typename public private protected virtual this nullptr true false using"),
+	}
+	javaSyntax = &syntax{
+		lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}, quotes: "\"'",
+		keywords: wordSet(cLike + " abstract boolean byte catch char class double extends final finally float implements import instanceof int interface long new null package private protected public short static super this throw throws true false try void var val fun object when override"),
+	}
+	jsSyntax = &syntax{
+		lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}, quotes: "\"'`",
+		keywords: wordSet(cLike + " async await catch class const delete export extends finally function import in instanceof let new null of static super this throw true false try typeof undefined var void yield interface type enum implements"),
+	}
+	rustSyntax = &syntax{
+		lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}, quotes: "\"",
+		keywords: wordSet("as async await break const continue crate dyn else enum extern false fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while"),
+	}
+	pythonSyntax = &syntax{
+		lineComments: []string{"#"}, quotes: "\"'",
+		keywords: wordSet("and as assert async await break class continue def del elif else except False finally for from global if import in is lambda None nonlocal not or pass raise return True try while with yield"),
+	}
+	rubySyntax = &syntax{
+		lineComments: []string{"#"}, quotes: "\"'",
+		keywords: wordSet("alias and begin break case class def defined? do else elsif end ensure false for if in module next nil not or redo rescue retry return self super then true undef unless until when while yield require"),
+	}
+	shellSyntax = &syntax{
+		lineComments: []string{"#"}, quotes: "\"'",
+		keywords: wordSet("if then else elif fi for while until do done case esac in function return local export echo exit set unset source"),
+	}
+	sqlSyntax = &syntax{
+		lineComments: []string{"--"}, blockComment: [2]string{"/*", "*/"}, quotes: "'\"", ignoreCase: true,
+		keywords: wordSet("select from where and or not insert into values update set delete create table drop alter index join left right inner outer on as group by order having limit offset distinct null is in like between union all primary key foreign references default case when then else end with"),
+	}
+	yamlSyntax = &syntax{
+		lineComments: []string{"#"}, quotes: "\"'",
+		keywords: wordSet("true false null yes no on off"),
+	}
+	jsonSyntax = &syntax{
+		quotes:   "\"",
+		keywords: wordSet("true false null"),
+	}
+)
+
+// syntaxes maps the language names of code fences to their syntax
+var syntaxes = map[string]*syntax{
+	"go": goSyntax, "golang": goSyntax,
+	"c": cSyntax, "h": cSyntax, "cpp": cSyntax, "c++": cSyntax, "cc": cSyntax,
+	"java": javaSyntax, "kotlin": javaSyntax, "kt": javaSyntax, "cs": javaSyntax, "csharp": javaSyntax,
+	"js": jsSyntax, "javascript": jsSyntax, "ts": jsSyntax, "typescript": jsSyntax, "jsx": jsSyntax, "tsx": jsSyntax,
+	"rust": rustSyntax, "rs": rustSyntax,
+	"python": pythonSyntax, "py": pythonSyntax,
+	"ruby": rubySyntax, "rb": rubySyntax,
+	"sh": shellSyntax, "bash": shellSyntax, "shell": shellSyntax, "zsh": shellSyntax, "console": shellSyntax,
+	"sql":  sqlSyntax,
+	"yaml": yamlSyntax, "yml": yamlSyntax, "toml": yamlSyntax,
+	"json": jsonSyntax,
+}
+
+// Highlight splits code into keywords, strings, comments, numbers and
+// plain text by the language named in its code fence. Code in a language
+// it does not know is one plain token.
+func Highlight(code, lang string) []Token {
+	s := syntaxes[strings.ToLower(lang)]
+	if s == nil {
+		return []Token{{Plain, code}}
+	}
+
+	var tokens []Token
+	emit := func(kind TokenKind, text string) {
+		if n := len(tokens); n > 0 && tokens[n-1].Kind == kind {
+			tokens[n-1].Text += text
+		} else if text != "" {
+			tokens = append(tokens, Token{kind, text})
+		}
+	}
+
+	for i := 0; i < len(code); {
+		rest := code[i:]
+		if end := s.comment(rest); end > 0 {
+			emit(Comment, rest[:end])
+			i += end
+			continue
+		}
+
+		c := rune(code[i])
+		switch {
+		case strings.ContainsRune(s.quotes, c):
+			end := quoted(rest)
+			emit(String, rest[:end])
+			i += end
+		case c < unicode.MaxASCII && unicode.IsDigit(c):
+			end := strings.IndexFunc(rest, func(r rune) bool { return !isWordRune(r) && r != '.' })
+			if end < 0 {
+				end = len(rest)
+			}
+			emit(Number, rest[:end])
+			i += end
+		case isWordRune(c):
+			end := strings.IndexFunc(rest, func(r rune) bool { return !isWordRune(r) && r != '?' })
+			if end < 0 {
+				end = len(rest)
+			}
+			word := rest[:end]
+			key := word
+			if s.ignoreCase {
+				key = strings.ToLower(word)
+			}
+			if s.keywords[key] {
+				emit(Keyword, word)
+			} else {
+				emit(Plain, word)
+			}
+			i += end
+		default:
+			emit(Plain, string(code[i]))
+			i++
+		}
+	}
+	return tokens
+}
+
+// quoted returns the length of the string literal code starts with. Only
+// backquoted strings span lines, and they know no escapes.
+func quoted(code string) int {
+	quote, raw := code[0], code[0] == '`'
+	for i := 1; i < len(code); i++ {
+		switch {
+		case code[i] == quote:
+			return i + 1
+		case raw:
+		case code[i] == '\\':
+			i++
+		case code[i] == '\n':
+			return i
+		}
+	}
+	return len(code)
+}
+
+// comment returns the length of the comment code starts with, or 0
+func (s *syntax) comment(code string) int {
+	for _, prefix := range s.lineComments {
+		if strings.HasPrefix(code, prefix) {
+			if end := strings.IndexByte(code, '\n'); end >= 0 {
+				return end
+			}
+			return len(code)
+		}
+	}
+	if open, close := s.blockComment[0], s.blockComment[1]; open != "" && strings.HasPrefix(code, open) {
+		if end := strings.Index(code[len(open):], close); end >= 0 {
+			return len(open) + end + len(close)
+		}
+		return len(code)
+	}
+	return 0
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r >= unicode.MaxASCII || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// highlightClasses are the CSS classes of the token kinds in HTML
+var highlightClasses = map[TokenKind]string{Keyword: "hl-keyword", String: "hl-string", Comment: "hl-comment", Number: "hl-number"}
+
+// highlightHTML renders code with each token kind in a span of its class
+func highlightHTML(code, lang string) string {
+	var b strings.Builder
+	for _, t := range Highlight(code, lang) {
+		if class, ok := highlightClasses[t.Kind]; ok {
+			b.WriteString(`<span class="` + class + `">` + html.EscapeString(t.Text) + "</span>")
+		} else {
+			b.WriteString(html.EscapeString(t.Text))
+		}
+	}
+	return b.String()
+}
+
+// terminalColors are the ANSI colors of the token kinds
+var terminalColors = map[TokenKind]string{Keyword: "\033[1;34m", String: "\033[32m", Comment: "\033[90m", Number: "\033[35m"}
+
+// HighlightTerminal colors the fenced code blocks of a Markdown text with
+// ANSI escape codes for display on a terminal; the rest is left as it is
+func HighlightTerminal(markdown string) string {
+	lines := strings.SplitAfter(markdown, "\n")
+	var out strings.Builder
+	for i := 0; i < len(lines); i++ {
+		out.WriteString(lines[i])
+		trimmed := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(trimmed, "```") {
+			continue
+		}
+
+		lang := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+		var code strings.Builder
+		for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+			code.WriteString(lines[i])
+		}
+		for _, t := range Highlight(code.String(), lang) {
+			color, ok := terminalColors[t.Kind]
+			if !ok {
+				out.WriteString(t.Text)
+				continue
+			}
+			// Colors are reset at line ends so a pager shows each line right
+			for j, part := range strings.Split(t.Text, "\n") {
+				if j > 0 {
+					out.WriteString("\n")
+				}
+				if part != "" {
+					out.WriteString(color + part + "\033[0m")
+				}
+			}
+		}
+		if i < len(lines) {
+			out.WriteString(lines[i])
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,165 @@
+package render
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	listPattern    = regexp.MustCompile(`^\s*([-*+]|\d+\.)\s+(.*)$`)
+	taskPattern    = regexp.MustCompile(`^\[([ xX])\]\s*(.*)$`)
+	hrPattern      = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+
+	codeSpanPattern = regexp.MustCompile("`([^`]+)`")
+	boldPattern     = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicPattern   = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+	imagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	linkPattern     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// ToHTML converts a practical subset of Markdown to HTML: headings,
+// paragraphs, ordered and unordered lists (including task lists), fenced
+// code blocks, block quotes, horizontal rules and inline emphasis, code,
+// links and images. All text is HTML-escaped.
+func ToHTML(markdown string) string {
+	var out strings.Builder
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+
+	var paragraph []string
+	listTag := ""
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			out.WriteString("<p>" + Inline(strings.Join(paragraph, " ")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if listTag != "" {
+			out.WriteString("</" + listTag + ">\n")
+			listTag = ""
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flushParagraph()
+			closeList()
+			lang := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			out.WriteString(CodeBlockHTML(strings.Join(code, "\n"), lang))
+
+		case trimmed == "":
+			flushParagraph()
+			closeList()
+
+		case headingPattern.MatchString(trimmed):
+			flushParagraph()
+			closeList()
+			m := headingPattern.FindStringSubmatch(trimmed)
+			level := string(rune('0' + len(m[1])))
+			out.WriteString("<h" + level + ">" + Inline(m[2]) + "</h" + level + ">\n")
+
+		case hrPattern.MatchString(trimmed):
+			flushParagraph()
+			closeList()
+			out.WriteString("<hr>\n")
+
+		case strings.HasPrefix(trimmed, ">"):
+			flushParagraph()
+			closeList()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quote = append(quote, strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(lines[i]), ">"), " "))
+			}
+			i--
+			out.WriteString("<blockquote>\n" + ToHTML(strings.Join(quote, "\n")) + "</blockquote>\n")
+
+		case listPattern.MatchString(line):
+			flushParagraph()
+			m := listPattern.FindStringSubmatch(line)
+			tag := "ul"
+			if strings.HasSuffix(m[1], ".") {
+				tag = "ol"
+			}
+			if tag != listTag {
+				closeList()
+				out.WriteString("<" + tag + ">\n")
+				listTag = tag
+			}
+			item := m[2]
+			if t := taskPattern.FindStringSubmatch(item); t != nil {
+				checked := ""
+				if t[1] != " " {
+					checked = " checked"
+				}
+				out.WriteString(`<li class="task"><input type="checkbox" disabled` + checked + "> " + Inline(t[2]) + "</li>\n")
+			} else {
+				out.WriteString("<li>" + Inline(item) + "</li>\n")
+			}
+
+		default:
+			closeList()
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+
+	flushParagraph()
+	closeList()
+	return out.String()
+}
+
+// CodeBlockHTML renders a fenced code block, highlighting the syntax of
+// languages Highlight knows
+func CodeBlockHTML(code, lang string) string {
+	class := ""
+	if lang != "" {
+		class = ` class="language-` + html.EscapeString(lang) + `"`
+	}
+	return "<pre><code" + class + ">" + highlightHTML(code, lang) + "</code></pre>\n"
+}
+
+// Inline renders inline Markdown (code spans, emphasis, links, images)
+func Inline(text string) string {
+	// Code spans are rendered first and protected from further processing
+	var spans []string
+	text = codeSpanPattern.ReplaceAllStringFunc(text, func(m string) string {
+		spans = append(spans, "<code>"+html.EscapeString(codeSpanPattern.FindStringSubmatch(m)[1])+"</code>")
+		return "\x00" + string(rune(len(spans)-1+'A')) + "\x00"
+	})
+
+	text = html.EscapeString(text)
+	text = imagePattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := imagePattern.FindStringSubmatch(m)
+		return `<img src="` + safeURL(parts[2]) + `" alt="` + parts[1] + `">`
+	})
+	text = linkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := linkPattern.FindStringSubmatch(m)
+		return `<a href="` + safeURL(parts[2]) + `">` + parts[1] + `</a>`
+	})
+	text = boldPattern.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = italicPattern.ReplaceAllString(text, "<em>$1$2</em>")
+
+	for i, span := range spans {
+		text = strings.Replace(text, "\x00"+string(rune(i+'A'))+"\x00", span, 1)
+	}
+	return text
+}
+
+// safeURL neutralizes script URLs in links and images
+func safeURL(url string) string {
+	lower := strings.ToLower(strings.TrimSpace(url))
+	if strings.HasPrefix(lower, "javascript:") || strings.HasPrefix(lower, "vbscript:") || strings.HasPrefix(lower, "data:text") {
+		return "#"
+	}
+	return url
+}
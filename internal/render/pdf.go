@@ -0,0 +1,367 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// A4 in points, with the margins around the text
+const (
+	pdfPageWidth  = 595.28
+	pdfPageHeight = 841.89
+	pdfMargin     = 56.0
+	pdfFooter     = 24.0
+)
+
+var (
+	pdfInlinePattern = regexp.MustCompile("`([^`]+)`|\\*\\*([^*]+)\\*\\*|__([^_]+)__|\\*([^*]+)\\*|\\b_([^_]+)_\\b")
+	// Headings get smaller with each level; levels 4-6 share a size
+	pdfHeadingSizes = []float64{18, 15, 13, 11.5}
+)
+
+// PDFDocument is what ToPDF lays out: a title with a few lines of metadata
+// below it, the Markdown content, and a list of attachments at the end
+type PDFDocument struct {
+	Title       string
+	Metadata    []string
+	Markdown    string
+	Attachments []string
+}
+
+// ToPDF renders a document as a PDF of A4 pages. It handles the same
+// Markdown subset as ToHTML and uses the standard Helvetica and Courier
+// fonts, which cover Western European text; other characters print as '?'.
+func ToPDF(doc PDFDocument) []byte {
+	l := &pdfLayout{}
+	l.newPage()
+
+	l.paragraph([]pdfSpan{{text: doc.Title, font: fontBold}}, pdfBlock{size: 20})
+	for _, line := range doc.Metadata {
+		l.paragraph([]pdfSpan{{text: line}}, pdfBlock{size: 9, gray: 0.4})
+	}
+	l.space(6)
+	l.rule()
+	l.space(6)
+
+	l.markdown(doc.Markdown)
+
+	if len(doc.Attachments) > 0 {
+		l.space(10)
+		l.paragraph([]pdfSpan{{text: "Attachments", font: fontBold}}, pdfBlock{size: 13})
+		for _, name := range doc.Attachments {
+			l.paragraph([]pdfSpan{{text: name, font: fontCode}}, pdfBlock{size: 9.5, marker: "•", indent: 14})
+		}
+	}
+
+	// Page numbers can only be written once the page count is known
+	for i := range l.pages {
+		footer := fmt.Sprintf("%d / %d", i+1, len(l.pages))
+		x := (pdfPageWidth - fontRegular.width(footer, 8)) / 2
+		l.pages[i].WriteString(pdfText(x, pdfMargin-pdfFooter, fontRegular, 8, 0.5, footer))
+	}
+
+	var pages []string
+	for _, page := range l.pages {
+		pages = append(pages, page.String())
+	}
+	return writePDF(doc.Title, pages, pdfPageWidth, pdfPageHeight)
+}
+
+// pdfSpan is a run of text in one font
+type pdfSpan struct {
+	text string
+	font pdfFont
+}
+
+// pdfBlock describes how a paragraph is set
+type pdfBlock struct {
+	size   float64
+	indent float64
+	gray   float64
+	// marker is printed in front of the first line, in the indent
+	marker string
+	// bar draws a line to the left of the text, for quotes
+	bar bool
+}
+
+// pdfLayout places text on pages from the top down
+type pdfLayout struct {
+	pages []*strings.Builder
+	y     float64
+}
+
+func (l *pdfLayout) newPage() {
+	l.pages = append(l.pages, &strings.Builder{})
+	l.y = pdfPageHeight - pdfMargin
+}
+
+func (l *pdfLayout) page() *strings.Builder {
+	return l.pages[len(l.pages)-1]
+}
+
+// need starts a new page unless height fits on the current one
+func (l *pdfLayout) need(height float64) {
+	if l.y-height < pdfMargin {
+		l.newPage()
+	}
+}
+
+func (l *pdfLayout) space(height float64) {
+	if l.y < pdfPageHeight-pdfMargin {
+		l.y -= height
+	}
+}
+
+func (l *pdfLayout) rule() {
+	l.need(1)
+	fmt.Fprintf(l.page(), "0.75 G 0.5 w %.2f %.2f m %.2f %.2f l S\n", pdfMargin, l.y, pdfPageWidth-pdfMargin, l.y)
+}
+
+// paragraph sets spans as a paragraph, breaking lines between words
+func (l *pdfLayout) paragraph(spans []pdfSpan, b pdfBlock) {
+	left := pdfMargin + b.indent
+	width := pdfPageWidth - pdfMargin - left
+	leading := b.size * 1.4
+
+	type word struct {
+		text string
+		font pdfFont
+		// glued words follow the previous one without a space, as when
+		// emphasis ends in the middle of a word
+		glued bool
+	}
+	var words []word
+	spaced := true
+	for _, span := range spans {
+		text := winAnsi(span.text)
+		if text == "" {
+			continue
+		}
+		glued := !spaced && text[0] != ' '
+		for _, field := range strings.Fields(text) {
+			words = append(words, word{text: field, font: span.font, glued: glued})
+			glued = false
+		}
+		spaced = text[len(text)-1] == ' '
+	}
+	if len(words) == 0 {
+		return
+	}
+
+	first := true
+	emit := func(line []word) {
+		l.need(leading)
+		l.y -= leading
+		baseline := l.y + (leading-b.size)/2
+		if first && b.marker != "" {
+			marker := winAnsi(b.marker)
+			x := left - fontRegular.width(marker+" ", b.size)
+			l.page().WriteString(pdfText(x, baseline, fontRegular, b.size, b.gray, marker))
+		}
+		if b.bar {
+			fmt.Fprintf(l.page(), "0.7 G 2 w %.2f %.2f m %.2f %.2f l S\n", left-8, l.y, left-8, l.y+leading)
+		}
+		// Words in the same font are drawn together
+		x := left
+		for start := 0; start < len(line); {
+			font := line[start].font
+			run := line[start].text
+			end := start + 1
+			for ; end < len(line) && line[end].font == font; end++ {
+				if !line[end].glued {
+					run += " "
+				}
+				run += line[end].text
+			}
+			l.page().WriteString(pdfText(x, baseline, font, b.size, b.gray, run))
+			x += font.width(run, b.size)
+			if end < len(line) && !line[end].glued {
+				x += font.width(" ", b.size)
+			}
+			start = end
+		}
+		first = false
+	}
+
+	var line []word
+	lineWidth := 0.0
+	for _, w := range words {
+		advance := w.font.width(w.text, b.size)
+		if len(line) > 0 && !w.glued {
+			advance += w.font.width(" ", b.size)
+		}
+		if len(line) > 0 && lineWidth+advance > width {
+			emit(line)
+			line, lineWidth = nil, 0
+			w.glued = false
+			advance = w.font.width(w.text, b.size)
+		}
+		line = append(line, w)
+		lineWidth += advance
+	}
+	emit(line)
+}
+
+// code sets preformatted lines on a shaded background, breaking lines
+// that are too long at the margin
+func (l *pdfLayout) code(lines []string) {
+	const size = 9.0
+	leading := size * 1.35
+	columns := int((pdfPageWidth - 2*pdfMargin - 8) / fontCode.width(" ", size))
+
+	l.space(4)
+	for _, line := range lines {
+		text := winAnsi(line)
+		for {
+			chunk := text
+			if len(chunk) > columns {
+				chunk = text[:columns]
+			}
+			l.need(leading)
+			l.y -= leading
+			fmt.Fprintf(l.page(), "0.95 g %.2f %.2f %.2f %.2f re f\n", pdfMargin, l.y, pdfPageWidth-2*pdfMargin, leading)
+			l.page().WriteString(pdfText(pdfMargin+4, l.y+(leading-size)/2+1, fontCode, size, 0, chunk))
+			if text = text[len(chunk):]; text == "" {
+				break
+			}
+		}
+	}
+	l.space(8)
+}
+
+// markdown lays out blocks the way ToHTML does
+func (l *pdfLayout) markdown(markdown string) {
+	const size = 11.0
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			l.paragraph(pdfInline(strings.Join(paragraph, " "), fontRegular), pdfBlock{size: size})
+			l.space(size * 0.6)
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			l.code(code)
+
+		case trimmed == "":
+			flush()
+
+		case headingPattern.MatchString(trimmed):
+			flush()
+			m := headingPattern.FindStringSubmatch(trimmed)
+			headingSize := pdfHeadingSizes[min(len(m[1]), len(pdfHeadingSizes))-1]
+			l.space(headingSize * 0.5)
+			// Keep a heading on the same page as the line after it
+			l.need(headingSize*1.4 + size*1.4)
+			l.paragraph(pdfInline(m[2], fontBold), pdfBlock{size: headingSize})
+			l.space(2)
+
+		case hrPattern.MatchString(trimmed):
+			flush()
+			l.space(6)
+			l.rule()
+			l.space(8)
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				quote = append(quote, strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(lines[i]), ">")))
+			}
+			i--
+			l.paragraph(pdfInline(strings.Join(quote, " "), fontItalic), pdfBlock{size: size, indent: 16, gray: 0.3, bar: true})
+			l.space(size * 0.6)
+
+		case listPattern.MatchString(line):
+			flush()
+			m := listPattern.FindStringSubmatch(line)
+			depth := (len(line) - len(strings.TrimLeft(line, " \t"))) / 2
+			marker := "•"
+			if strings.HasSuffix(m[1], ".") {
+				marker = m[1]
+			}
+			item := m[2]
+			if t := taskPattern.FindStringSubmatch(item); t != nil {
+				marker = "[ ]"
+				if t[1] != " " {
+					marker = "[x]"
+				}
+				item = t[2]
+			}
+			l.paragraph(pdfInline(item, fontRegular), pdfBlock{size: size, indent: 18 + 14*float64(depth), marker: marker})
+			if i+1 >= len(lines) || !listPattern.MatchString(lines[i+1]) {
+				l.space(size * 0.6)
+			}
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+}
+
+// pdfInline splits inline Markdown into spans: code spans and emphasis
+// change the font, and links, images and wikilinks keep their text, with
+// the address of a web link after it
+func pdfInline(text string, base pdfFont) []pdfSpan {
+	text = imagePattern.ReplaceAllString(text, "[$1]")
+	text = linkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := linkPattern.FindStringSubmatch(m)
+		// Only addresses a reader could visit are worth printing
+		if parts[1] == parts[2] || !strings.Contains(parts[2], "://") && !strings.HasPrefix(parts[2], "mailto:") {
+			return parts[1]
+		}
+		return parts[1] + " <" + parts[2] + ">"
+	})
+	text = wikiLinkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := wikiLinkPattern.FindStringSubmatch(m)
+		if parts[2] != "" {
+			return strings.TrimSpace(parts[2])
+		}
+		return strings.TrimSpace(parts[1])
+	})
+
+	emphasis := fontItalic
+	if base == fontItalic || base == fontBold {
+		emphasis = fontBold
+	}
+
+	var spans []pdfSpan
+	last := 0
+	for _, m := range pdfInlinePattern.FindAllStringSubmatchIndex(text, -1) {
+		spans = append(spans, pdfSpan{text: text[last:m[0]], font: base})
+		switch {
+		case m[2] >= 0:
+			spans = append(spans, pdfSpan{text: text[m[2]:m[3]], font: fontCode})
+		case m[4] >= 0:
+			spans = append(spans, pdfSpan{text: text[m[4]:m[5]], font: fontBold})
+		case m[6] >= 0:
+			spans = append(spans, pdfSpan{text: text[m[6]:m[7]], font: fontBold})
+		case m[8] >= 0:
+			spans = append(spans, pdfSpan{text: text[m[8]:m[9]], font: emphasis})
+		default:
+			spans = append(spans, pdfSpan{text: text[m[10]:m[11]], font: emphasis})
+		}
+		last = m[1]
+	}
+	return append(spans, pdfSpan{text: text[last:], font: base})
+}
+
+// pdfText draws text with its baseline at x, y
+func pdfText(x, y float64, font pdfFont, size, gray float64, text string) string {
+	return fmt.Sprintf("BT /F%d %.1f Tf %.2f g %.2f %.2f Td %s Tj ET\n", int(font)+1, size, gray, x, y, pdfString(text))
+}
@@ -0,0 +1,121 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	wikiLinkPattern = regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]+))?\]\]`)
+	emphasisPattern = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__|\*([^*]+)\*|\b_([^_]+)_\b|~~([^~]+)~~`)
+	sentenceEnd     = regexp.MustCompile(`([.!?…]["')\]]?)\s+`)
+)
+
+// ToPlain strips Markdown down to the text a reader would hear, for piping
+// into text-to-speech tools: code blocks and rules are dropped, links and
+// images keep their text, and headings and list items become sentences of
+// their own. Paragraphs are separated by blank lines; with sentencePerLine
+// every sentence goes on a line of its own.
+func ToPlain(markdown string, sentencePerLine bool) string {
+	var blocks, paragraph, items []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, strings.Join(paragraph, " "))
+			paragraph = nil
+		}
+		if len(items) > 0 {
+			blocks = append(blocks, strings.Join(items, "\n"))
+			items = nil
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			// Code makes no sense read aloud
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+			}
+
+		case trimmed == "" || hrPattern.MatchString(trimmed):
+			flush()
+
+		case headingPattern.MatchString(trimmed):
+			flush()
+			blocks = append(blocks, sentence(PlainInline(headingPattern.FindStringSubmatch(trimmed)[2])))
+
+		case listPattern.MatchString(lines[i]):
+			if len(paragraph) > 0 {
+				flush()
+			}
+			item := listPattern.FindStringSubmatch(lines[i])[2]
+			if t := taskPattern.FindStringSubmatch(item); t != nil {
+				item = t[2]
+			}
+			if text := sentence(PlainInline(item)); text != "" {
+				items = append(items, text)
+			}
+
+		default:
+			if len(items) > 0 {
+				flush()
+			}
+			for strings.HasPrefix(trimmed, ">") {
+				trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+			}
+			if text := PlainInline(trimmed); text != "" {
+				paragraph = append(paragraph, text)
+			}
+		}
+	}
+	flush()
+
+	var out []string
+	for _, block := range blocks {
+		if block == "" {
+			continue
+		}
+		if sentencePerLine {
+			block = strings.TrimSpace(sentenceEnd.ReplaceAllString(block, "$1\n"))
+		}
+		out = append(out, block)
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, "\n\n") + "\n"
+}
+
+// PlainInline removes inline Markdown: code spans, emphasis, links, images
+// and wikilinks keep only their text
+func PlainInline(text string) string {
+	text = codeSpanPattern.ReplaceAllString(text, "$1")
+	text = imagePattern.ReplaceAllString(text, "$1")
+	text = linkPattern.ReplaceAllString(text, "$1")
+	text = wikiLinkPattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := wikiLinkPattern.FindStringSubmatch(m)
+		if parts[2] != "" {
+			return strings.TrimSpace(parts[2])
+		}
+		return strings.TrimSpace(parts[1])
+	})
+	text = emphasisPattern.ReplaceAllString(text, "$1$2$3$4$5")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// sentence ends text with a full stop unless it already ends with
+// punctuation, so speech pauses after headings and list items
+func sentence(text string) string {
+	if text == "" {
+		return ""
+	}
+	if r := []rune(text); unicode.IsPunct(r[len(r)-1]) {
+		return text
+	}
+	return text + "."
+}
@@ -0,0 +1,147 @@
+// Package schema versions the front matter layout of a store. The version
+// is kept in a small file in the store; each change to the layout adds a
+// Migration that brings a note from the previous version up to date.
+package schema
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"memo/internal/note"
+)
+
+// FileName is the file inside the notes store holding its schema version
+const FileName = ".version"
+
+// Base is the version of stores written before the version file existed
+const Base = 1
+
+// Migration upgrades one note to Version. Apply reports whether it changed
+// the note and must leave a note already in the new layout alone, so an
+// interrupted run can simply be repeated.
+type Migration struct {
+	Version     int
+	Description string
+	Apply       func(n *note.Note) (bool, error)
+}
+
+// migrations lists every layout change in version order, starting at Base+1
+var migrations = []Migration{}
+
+// Current is the version of the front matter this build of memo writes
+func Current() int {
+	return Base + len(migrations)
+}
+
+// Pending returns the migrations a store at version from still needs
+func Pending(from int) []Migration {
+	if from < Base {
+		from = Base
+	}
+	if from-Base >= len(migrations) {
+		return nil
+	}
+	return migrations[from-Base:]
+}
+
+// Load reads the version file at path; a store without one is at Base
+func Load(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Base, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error reading store version: %w", err)
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || version < Base {
+		return 0, fmt.Errorf("invalid store version in %s: %q", path, strings.TrimSpace(string(data)))
+	}
+	return version, nil
+}
+
+// Save writes version to the version file at path
+func Save(path string, version int) error {
+	if err := os.WriteFile(path, []byte(strconv.Itoa(version)+"\n"), 0644); err != nil {
+		return fmt.Errorf("error writing store version: %w", err)
+	}
+	return nil
+}
+
+// RenameField returns a migration step moving the front matter key from to
+// the key to. A note that already has to keeps it and loses from.
+func RenameField(from, to string) func(n *note.Note) (bool, error) {
+	return func(n *note.Note) (bool, error) {
+		return editFrontMatter(n, func(m *yaml.Node) bool {
+			i := keyIndex(m, from)
+			if i < 0 {
+				return false
+			}
+			if keyIndex(m, to) >= 0 {
+				m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			} else {
+				m.Content[i].Value = to
+			}
+			return true
+		})
+	}
+}
+
+// DefaultField returns a migration step setting a front matter key that a
+// note lacks to value
+func DefaultField(key string, value any) func(n *note.Note) (bool, error) {
+	return func(n *note.Note) (bool, error) {
+		return editFrontMatter(n, func(m *yaml.Node) bool {
+			if keyIndex(m, key) >= 0 {
+				return false
+			}
+			var v yaml.Node
+			if err := v.Encode(value); err != nil {
+				return false
+			}
+			m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &v)
+			return true
+		})
+	}
+}
+
+// editFrontMatter lets edit change the front matter of n as a YAML mapping,
+// which covers both the known fields and custom ones
+func editFrontMatter(n *note.Note, edit func(m *yaml.Node) bool) (bool, error) {
+	data, err := yaml.Marshal(&n.Metadata)
+	if err != nil {
+		return false, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return false, nil
+	}
+	if !edit(doc.Content[0]) {
+		return false, nil
+	}
+
+	var metadata note.Metadata
+	if err := doc.Content[0].Decode(&metadata); err != nil {
+		return false, fmt.Errorf("error applying migration to %s: %w", n.ID(), err)
+	}
+	n.Metadata = metadata
+	return true, nil
+}
+
+// keyIndex returns the position of key among the keys and values of the
+// mapping m, or -1
+func keyIndex(m *yaml.Node, key string) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"memo/internal/note"
+)
+
+func (s *Server) registerAPIRoutes() {
+	s.mux.HandleFunc("GET /api/notes", s.handleListNotes)
+	s.mux.HandleFunc("POST /api/notes", s.handleCreateNote)
+	s.mux.HandleFunc("GET /api/notes/{id}", s.handleGetNote)
+	s.mux.HandleFunc("PUT /api/notes/{id}", s.handleUpdateNote)
+	s.mux.HandleFunc("DELETE /api/notes/{id}", s.handleDeleteNote)
+	s.mux.HandleFunc("GET /api/notes/tag/{tag}", s.handleNotesByTag)
+	s.mux.HandleFunc("GET /api/tags", s.handleListTags)
+	s.mux.HandleFunc("GET /api/search", s.handleSearch)
+}
+
+// noteInput is the request body for creating and updating notes. Absent
+// fields are left unchanged on update.
+type noteInput struct {
+	Title    *string   `json:"title"`
+	Content  *string   `json:"content"`
+	Tags     *[]string `json:"tags"`
+	Author   *string   `json:"author"`
+	Status   *string   `json:"status"`
+	Priority *int      `json:"priority"`
+}
+
+func (in noteInput) apply(n *note.Note) {
+	if in.Title != nil {
+		n.Metadata.Title = strings.TrimSpace(*in.Title)
+	}
+	if in.Content != nil {
+		n.UpdateContent(*in.Content)
+	}
+	if in.Tags != nil {
+		n.UpdateTags(*in.Tags)
+	}
+	if in.Author != nil {
+		n.Metadata.Author = *in.Author
+	}
+	if in.Status != nil {
+		n.Metadata.Status = *in.Status
+	}
+	if in.Priority != nil {
+		n.Metadata.Priority = *in.Priority
+	}
+}
+
+// decodeInput reads the JSON request body into in and reports a failure
+// itself. Other content types are refused: a browser can send a form or
+// text body to another site without asking it first, but not JSON.
+func decodeInput(w http.ResponseWriter, r *http.Request, in *noteInput) bool {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "the request body must be JSON with Content-Type application/json")
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleListNotes(w http.ResponseWriter, r *http.Request) {
+	var notes []*note.Note
+	var err error
+	if fs, tag := s.store(r), r.URL.Query().Get("tag"); tag != "" {
+		notes, err = fs.FilterNotesByTag(tag)
+	} else {
+		notes, err = fs.GetAllNotes()
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toJSONList(notes))
+}
+
+func (s *Server) handleCreateNote(w http.ResponseWriter, r *http.Request) {
+	var in noteInput
+	if !decodeInput(w, r, &in) {
+		return
+	}
+	if in.Title == nil || strings.TrimSpace(*in.Title) == "" {
+		writeError(w, http.StatusBadRequest, "title is required")
+		return
+	}
+
+	n := note.New("", "", nil)
+	in.apply(n)
+	attribute(r, n)
+
+	if _, err := s.store(r).CreateNote(n); err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toJSON(n, true))
+}
+
+func (s *Server) handleGetNote(w http.ResponseWriter, r *http.Request) {
+	n, err := s.store(r).FindNoteByID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toJSON(n, true))
+}
+
+func (s *Server) handleUpdateNote(w http.ResponseWriter, r *http.Request) {
+	fs := s.store(r)
+	n, err := fs.FindNoteByID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !canModify(r, n) {
+		writeError(w, http.StatusForbidden, "only the author of a note may change it")
+		return
+	}
+
+	var in noteInput
+	if !decodeInput(w, r, &in) {
+		return
+	}
+	if in.Content != nil && n.Locked() {
+		writeError(w, http.StatusConflict, "content of encrypted notes cannot be changed over HTTP")
+		return
+	}
+	if in.Title != nil && strings.TrimSpace(*in.Title) == "" {
+		writeError(w, http.StatusBadRequest, "title must not be empty")
+		return
+	}
+
+	in.apply(n)
+	attribute(r, n)
+	if err := fs.SaveNote(n); err != nil {
+		writeStorageError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toJSON(n, true))
+}
+
+func (s *Server) handleDeleteNote(w http.ResponseWriter, r *http.Request) {
+	fs := s.store(r)
+	if err := fs.CheckWritable(); err != nil {
+		writeStorageError(w, err)
+		return
+	}
+	n, err := fs.FindNoteByID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !canModify(r, n) {
+		writeError(w, http.StatusForbidden, "only the author of a note may delete it")
+		return
+	}
+	if err := fs.DeleteNote(n.ID()); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleNotesByTag(w http.ResponseWriter, r *http.Request) {
+	notes, err := s.store(r).FilterNotesByTag(r.PathValue("tag"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toJSONList(notes))
+}
+
+func (s *Server) handleListTags(w http.ResponseWriter, r *http.Request) {
+	notes, err := s.store(r).GetAllNotes()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, countTags(notes))
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		writeError(w, http.StatusBadRequest, "query parameter q is required")
+		return
+	}
+
+	notes, err := s.store(r).SearchNotes(query)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toJSONList(notes))
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"memo/internal/accounts"
+	"memo/internal/note"
+	"memo/internal/storage"
+)
+
+// userKey is the context key of the authenticated user
+type userKey struct{}
+
+// credentialCache remembers credentials that were verified before, since
+// hashing a password on every request would make WebDAV clients, which
+// send many requests, crawl
+type credentialCache struct {
+	mu    sync.Mutex
+	users map[[sha256.Size]byte]*accounts.User
+}
+
+func (c *credentialCache) lookup(key [sha256.Size]byte) *accounts.User {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.users[key]
+}
+
+func (c *credentialCache) add(key [sha256.Size]byte, u *accounts.User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.users == nil {
+		c.users = make(map[[sha256.Size]byte]*accounts.User)
+	}
+	c.users[key] = u
+}
+
+// authenticate lets requests through only with the basic authentication
+// credentials or bearer token of a user. A user's API token also works as
+// their basic authentication password, for WebDAV clients.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPublic(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		u := s.userFor(r)
+		if u == nil {
+			w.Header().Set("WWW-Authenticate", `Basic realm="memo", charset="UTF-8"`)
+			writeError(w, http.StatusUnauthorized, "authentication required")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, u)))
+	})
+}
+
+// rejectCrossSite refuses requests that change something when a browser
+// sent them on behalf of another site. Browsers add basic authentication
+// credentials they know to such requests, so a page elsewhere could
+// otherwise submit the web forms as the user. Clients other than browsers
+// send neither Sec-Fetch-Site nor Origin and are let through.
+func rejectCrossSite(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !safeMethod(r.Method) && crossSite(r) {
+			slog.Warn("rejected cross-site request", "method", r.Method, "path", r.URL.Path, "origin", r.Header.Get("Origin"))
+			http.Error(w, "cross-site requests are not allowed", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// crossSite reports whether r comes from a page of another origin, judged
+// by Sec-Fetch-Site or, in browsers that do not send it, by Origin
+func crossSite(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "same-origin", "none":
+		return false
+	case "":
+	default:
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	u, err := url.Parse(origin)
+	return err != nil || u.Host != r.Host
+}
+
+func (s *Server) userFor(r *http.Request) *accounts.User {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return accounts.ByToken(s.options.Users, strings.TrimSpace(token))
+	}
+
+	name, secret, ok := r.BasicAuth()
+	if !ok {
+		return nil
+	}
+	key := sha256.Sum256([]byte(name + ":" + secret))
+	if u := s.credentials.lookup(key); u != nil {
+		return u
+	}
+	u := accounts.Authenticate(s.options.Users, name, secret)
+	if u != nil {
+		s.credentials.add(key, u)
+	}
+	return u
+}
+
+// currentUser returns the user making the request, or nil when the server
+// has no accounts
+func currentUser(r *http.Request) *accounts.User {
+	u, _ := r.Context().Value(userKey{}).(*accounts.User)
+	return u
+}
+
+// store returns the notes store the request works on: the store of the
+// user's profile, or the server's shared store
+func (s *Server) store(r *http.Request) *storage.FileStorage {
+	if u := currentUser(r); u != nil && !u.Shared() {
+		return s.options.Stores[u.Profile]
+	}
+	return s.storage
+}
+
+// canModify reports whether the user making the request may change or
+// delete n
+func canModify(r *http.Request, n *note.Note) bool {
+	u := currentUser(r)
+	return u == nil || u.Owns(n.Metadata.Author)
+}
+
+// attribute makes the user making the request the author of n, which they
+// are creating or changing. Admins and users with a store of their own
+// only fill in a missing author, since they may write for others.
+func attribute(r *http.Request, n *note.Note) {
+	u := currentUser(r)
+	if u != nil && (n.Metadata.Author == "" || u.Shared() && !u.Admin) {
+		n.Metadata.Author = u.Name
+	}
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+
+	"memo/api"
+)
+
+// The OpenAPI document is served without authentication so clients can be
+// set up before they have credentials
+const (
+	openAPIYAMLPath = "/api/openapi.yaml"
+	openAPIJSONPath = "/api/openapi.json"
+)
+
+func (s *Server) registerOpenAPIRoutes() {
+	s.mux.HandleFunc("GET "+openAPIYAMLPath, handleOpenAPIYAML)
+	s.mux.HandleFunc("GET "+openAPIJSONPath, handleOpenAPIJSON)
+}
+
+func handleOpenAPIYAML(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(api.OpenAPI)
+}
+
+func handleOpenAPIJSON(w http.ResponseWriter, r *http.Request) {
+	doc, err := OpenAPIJSON()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(doc)
+}
+
+// OpenAPIJSON returns the OpenAPI document of the REST API as JSON
+func OpenAPIJSON() ([]byte, error) {
+	var doc any
+	if err := yaml.Unmarshal(api.OpenAPI, &doc); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// isPublic reports whether a request may be served without credentials
+func isPublic(r *http.Request) bool {
+	return r.Method == http.MethodGet && (r.URL.Path == openAPIYAMLPath || r.URL.Path == openAPIJSONPath)
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"memo/internal/accounts"
+	"memo/internal/note"
+	"memo/internal/storage"
+)
+
+// Options controls which parts of the server are enabled
+type Options struct {
+	// Web enables the browser front-end in addition to the REST API
+	Web bool
+	// WebDAV exposes the notes directory to WebDAV clients under /dav/
+	WebDAV bool
+	// Users are the accounts allowed to use the server; without any, no
+	// authentication is required
+	Users []accounts.User
+	// Stores holds the notes store of each profile named by a user
+	Stores map[string]*storage.FileStorage
+}
+
+// Server exposes the notes store over HTTP
+type Server struct {
+	storage *storage.FileStorage
+	options Options
+	mux     *http.ServeMux
+
+	credentials credentialCache
+}
+
+func New(fs *storage.FileStorage, options Options) *Server {
+	s := &Server{
+		storage: fs,
+		options: options,
+		mux:     http.NewServeMux(),
+	}
+	s.registerAPIRoutes()
+	s.registerOpenAPIRoutes()
+	if options.Web {
+		s.registerWebRoutes()
+	}
+	if options.WebDAV {
+		s.registerWebDAVRoutes()
+	}
+	return s
+}
+
+// Handler returns the HTTP handler serving all enabled routes
+func (s *Server) Handler() http.Handler {
+	var h http.Handler = s.mux
+	if len(s.options.Users) > 0 {
+		h = s.authenticate(h)
+	}
+	h = rejectCrossSite(h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		h.ServeHTTP(w, r)
+		slog.Debug("http request", "method", r.Method, "path", r.URL.Path, "duration", time.Since(start))
+	})
+}
+
+// ListenAndServe serves the store on addr until the process exits
+func (s *Server) ListenAndServe(addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return srv.ListenAndServe()
+}
+
+// noteJSON is the wire representation of a note
+type noteJSON struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Created   time.Time `json:"created"`
+	Modified  time.Time `json:"modified"`
+	Tags      []string  `json:"tags"`
+	Author    string    `json:"author,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Priority  int       `json:"priority,omitempty"`
+	Encrypted bool      `json:"encrypted,omitempty"`
+	// Due, Starts and CompletedAt are plain dates for whole days and
+	// RFC 3339 times otherwise
+	Due         string         `json:"due,omitempty"`
+	Starts      string         `json:"starts,omitempty"`
+	Duration    string         `json:"duration,omitempty"`
+	CompletedAt string         `json:"completedAt,omitempty"`
+	Fields      map[string]any `json:"fields,omitempty"`
+	Content     string         `json:"content,omitempty"`
+}
+
+func toJSON(n *note.Note, withContent bool) noteJSON {
+	tags := n.Metadata.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	j := noteJSON{
+		ID:          n.ID(),
+		Title:       n.Metadata.Title,
+		Created:     n.Metadata.Created,
+		Modified:    n.Metadata.Modified,
+		Tags:        tags,
+		Author:      n.Metadata.Author,
+		Status:      n.Metadata.Status,
+		Priority:    n.Metadata.Priority,
+		Encrypted:   n.Metadata.Encrypted,
+		Due:         n.Metadata.Due.String(),
+		Starts:      n.Metadata.Starts.String(),
+		Duration:    n.Metadata.Duration.String(),
+		CompletedAt: n.Metadata.CompletedAt.String(),
+		Fields:      n.FieldMap(),
+	}
+	if withContent && !n.Locked() {
+		j.Content = n.Content
+	}
+	return j
+}
+
+func toJSONList(notes []*note.Note) []noteJSON {
+	list := make([]noteJSON, 0, len(notes))
+	for _, n := range notes {
+		list = append(list, toJSON(n, false))
+	}
+	return list
+}
+
+// tagCount pairs a tag with the number of notes using it
+type tagCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func countTags(notes []*note.Note) []tagCount {
+	counts := make(map[string]int)
+	for _, n := range notes {
+		for _, tag := range n.Metadata.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]tagCount, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, tagCount{Name: name, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags
+}
+
+// sortByModified orders notes with the most recently modified first
+func sortByModified(notes []*note.Note) {
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].Metadata.Modified.After(notes[j].Metadata.Modified)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("failed to encode response", "error", err)
+	}
+}
+
+// writeStorageError reports a failed write, distinguishing read-only stores
+func writeStorageError(w http.ResponseWriter, err error) {
+	if errors.Is(err, storage.ErrReadOnly) {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	var invalid *note.ConstraintError
+	if errors.As(err, &invalid) {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"memo/internal/note"
+)
+
+// Share serves one rendered note at a hard-to-guess URL, optionally for a
+// limited number of views. It is used by `memo share`.
+type Share struct {
+	note     *note.Note
+	token    string
+	maxViews int
+
+	mu    sync.Mutex
+	views int
+	done  chan struct{}
+}
+
+// NewShare prepares n for sharing; a maxViews of 0 allows unlimited views.
+// The note's content must already be decrypted.
+func NewShare(n *note.Note, maxViews int) (*Share, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	return &Share{
+		note:     n,
+		token:    hex.EncodeToString(buf),
+		maxViews: maxViews,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Path returns the secret URL path of the shared note
+func (s *Share) Path() string {
+	return "/s/" + s.token
+}
+
+// Done is closed once the view limit has been reached
+func (s *Share) Done() <-chan struct{} {
+	return s.done
+}
+
+// Views returns how often the note has been viewed
+func (s *Share) Views() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.views
+}
+
+func (s *Share) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet || r.URL.Path != s.Path() {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	if s.maxViews > 0 && s.views >= s.maxViews {
+		s.mu.Unlock()
+		http.Error(w, "this link has expired", http.StatusGone)
+		return
+	}
+	s.views++
+	if s.maxViews > 0 && s.views == s.maxViews {
+		close(s.done)
+	}
+	s.mu.Unlock()
+
+	css, _ := webFiles.ReadFile("web/style.css")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	data := struct {
+		Note *note.Note
+		CSS  template.CSS
+	}{s.note, template.CSS(css)}
+	if err := templates.ExecuteTemplate(w, "share.html", data); err != nil {
+		slog.Warn("failed to render shared note", "error", err)
+	}
+	slog.Debug("shared note viewed", "note", s.note.ID(), "remote", r.RemoteAddr)
+}
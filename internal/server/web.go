@@ -0,0 +1,198 @@
+package server
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"memo/internal/note"
+	"memo/internal/render"
+)
+
+//go:embed web/*.html web/*.css
+var webFiles embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"markdown": func(s string) template.HTML { return template.HTML(render.ToHTML(s)) },
+	"join":     strings.Join,
+	"date":     func(n *note.Note) string { return n.Metadata.Modified.Format("2006-01-02 15:04") },
+}).ParseFS(webFiles, "web/*.html"))
+
+func (s *Server) registerWebRoutes() {
+	s.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(mustSub("web"))))
+	s.mux.HandleFunc("GET /{$}", s.handleIndexPage)
+	s.mux.HandleFunc("GET /notes/{id}", s.handleNotePage)
+	if s.storage.ReadOnly() {
+		return
+	}
+	s.mux.HandleFunc("GET /new", s.handleNewPage)
+	s.mux.HandleFunc("POST /new", s.handleNewSubmit)
+	s.mux.HandleFunc("GET /notes/{id}/edit", s.handleEditPage)
+	s.mux.HandleFunc("POST /notes/{id}/edit", s.handleEditSubmit)
+}
+
+// pageData is passed to every web template
+type pageData struct {
+	Title     string
+	Notes     []*note.Note
+	Note      *note.Note
+	Tags      []tagCount
+	ActiveTag string
+	Error     string
+	ReadOnly  bool
+}
+
+func (s *Server) renderPage(w http.ResponseWriter, name string, data pageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.ExecuteTemplate(w, name, data); err != nil {
+		slog.Warn("failed to render page", "page", name, "error", err)
+	}
+}
+
+func (s *Server) handleIndexPage(w http.ResponseWriter, r *http.Request) {
+	fs := s.store(r)
+	all, err := fs.GetAllNotes()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	notes := all
+	tag := r.URL.Query().Get("tag")
+	if tag != "" {
+		if notes, err = fs.FilterNotesByTag(tag); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	sortByModified(notes)
+
+	s.renderPage(w, "index.html", pageData{Title: "Notes", Notes: notes, Tags: countTags(all), ActiveTag: tag, ReadOnly: fs.ReadOnly()})
+}
+
+func (s *Server) handleNotePage(w http.ResponseWriter, r *http.Request) {
+	fs := s.store(r)
+	n, err := fs.FindNoteByID(r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.renderPage(w, "note.html", pageData{Title: n.Metadata.Title, Note: n, ReadOnly: fs.ReadOnly() || !canModify(r, n)})
+}
+
+func (s *Server) handleNewPage(w http.ResponseWriter, r *http.Request) {
+	s.renderPage(w, "form.html", pageData{Title: "New note", Note: note.New("", "", nil)})
+}
+
+func (s *Server) handleNewSubmit(w http.ResponseWriter, r *http.Request) {
+	n := note.New("", "", nil)
+	if errMsg := applyForm(n, r); errMsg != "" {
+		s.renderPage(w, "form.html", pageData{Title: "New note", Note: n, Error: errMsg})
+		return
+	}
+	attribute(r, n)
+
+	noteID, err := s.store(r).CreateNote(n)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/notes/"+noteID, http.StatusSeeOther)
+}
+
+func (s *Server) handleEditPage(w http.ResponseWriter, r *http.Request) {
+	n, err := s.store(r).FindNoteByID(r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if n.Locked() {
+		http.Error(w, "encrypted notes cannot be edited in the browser", http.StatusForbidden)
+		return
+	}
+	if !canModify(r, n) {
+		http.Error(w, "only the author of a note may change it", http.StatusForbidden)
+		return
+	}
+
+	s.renderPage(w, "form.html", pageData{Title: "Edit " + n.Metadata.Title, Note: n})
+}
+
+func (s *Server) handleEditSubmit(w http.ResponseWriter, r *http.Request) {
+	fs := s.store(r)
+	n, err := fs.FindNoteByID(r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if n.Locked() {
+		http.Error(w, "encrypted notes cannot be edited in the browser", http.StatusForbidden)
+		return
+	}
+	if !canModify(r, n) {
+		http.Error(w, "only the author of a note may change it", http.StatusForbidden)
+		return
+	}
+
+	if errMsg := applyForm(n, r); errMsg != "" {
+		s.renderPage(w, "form.html", pageData{Title: "Edit " + n.Metadata.Title, Note: n, Error: errMsg})
+		return
+	}
+	attribute(r, n)
+
+	if err := fs.SaveNote(n); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/notes/"+n.ID(), http.StatusSeeOther)
+}
+
+// applyForm copies submitted form values into n and returns a validation
+// message, or "" when the input is valid
+func applyForm(n *note.Note, r *http.Request) string {
+	if err := r.ParseForm(); err != nil {
+		return "invalid form submission"
+	}
+
+	n.Metadata.Title = strings.TrimSpace(r.FormValue("title"))
+	n.UpdateContent(strings.ReplaceAll(r.FormValue("content"), "\r\n", "\n"))
+
+	var tags []string
+	for _, tag := range strings.Split(r.FormValue("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	n.UpdateTags(tags)
+
+	n.Metadata.Status = strings.TrimSpace(r.FormValue("status"))
+	// An empty priority field clears the priority
+	n.Metadata.Priority = 0
+	if p := strings.TrimSpace(r.FormValue("priority")); p != "" {
+		priority, err := strconv.Atoi(p)
+		if err != nil || priority < 1 || priority > 5 {
+			return "priority must be a number between 1 and 5"
+		}
+		n.Metadata.Priority = priority
+	}
+
+	if n.Metadata.Title == "" {
+		return "title is required"
+	}
+	return ""
+}
+
+func mustSub(dir string) fs.FS {
+	sub, err := fs.Sub(webFiles, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
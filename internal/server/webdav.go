@@ -0,0 +1,357 @@
+package server
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"memo/internal/exchange"
+	"memo/internal/storage"
+)
+
+// davPrefix is where the notes directory is mounted for WebDAV clients
+const davPrefix = "/dav/"
+
+// maxDAVUpload limits the size of a note uploaded over WebDAV
+const maxDAVUpload = 10 << 20
+
+// The WebDAV endpoint implements class 1 of RFC 4918 for the flat notes
+// directory: only *.note files are visible and writable, and every write
+// goes through storage so revisions, locks and read-only mode apply just
+// as they do on the command line.
+func (s *Server) registerWebDAVRoutes() {
+	s.mux.HandleFunc(strings.TrimSuffix(davPrefix, "/"), func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, davPrefix, http.StatusMovedPermanently)
+	})
+	s.mux.HandleFunc(davPrefix, s.handleWebDAV)
+}
+
+func (s *Server) handleWebDAV(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, davPrefix)
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("DAV", "1")
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, GET, HEAD, PUT, DELETE, MOVE, COPY")
+		w.Header().Set("MS-Author-Via", "DAV")
+	case "PROPFIND":
+		s.davPropfind(w, r, name)
+	case http.MethodGet, http.MethodHead:
+		s.davGet(w, r, name)
+	case http.MethodPut:
+		s.davPut(w, r, name)
+	case http.MethodDelete:
+		s.davDelete(w, r, name)
+	case "MOVE", "COPY":
+		s.davMoveCopy(w, r, name)
+	case "MKCOL":
+		http.Error(w, "the notes store has no subdirectories", http.StatusForbidden)
+	default:
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, GET, HEAD, PUT, DELETE, MOVE, COPY")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// davNoteID maps a resource name to a note ID; ok is false for names that
+// are not note files, such as editor backups and hidden files
+func (s *Server) davNoteID(name string) (string, bool) {
+	ext := filepath.Ext(name)
+	if ext != storage.DefaultNoteExtension || strings.HasPrefix(name, ".") {
+		return "", false
+	}
+	noteID := strings.TrimSuffix(name, ext)
+	return noteID, storage.ValidateName(noteID) == nil
+}
+
+// davStatus maps a storage error to the matching WebDAV status code
+func davStatus(w http.ResponseWriter, err error) {
+	var locked *storage.LockedError
+	switch {
+	case errors.As(err, &locked):
+		http.Error(w, err.Error(), http.StatusLocked)
+	case errors.Is(err, storage.ErrReadOnly), errors.Is(err, storage.ErrInvalidName):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WebDAV multistatus response, see RFC 4918 section 14
+type davMultistatus struct {
+	XMLName   xml.Name      `xml:"D:multistatus"`
+	Namespace string        `xml:"xmlns:D,attr"`
+	Responses []davResponse `xml:"D:response"`
+}
+
+type davResponse struct {
+	Href     string      `xml:"D:href"`
+	Propstat davPropstat `xml:"D:propstat"`
+}
+
+type davPropstat struct {
+	Prop   davProp `xml:"D:prop"`
+	Status string  `xml:"D:status"`
+}
+
+type davProp struct {
+	DisplayName   string          `xml:"D:displayname"`
+	ResourceType  davResourceType `xml:"D:resourcetype"`
+	ContentLength *int64          `xml:"D:getcontentlength,omitempty"`
+	ContentType   string          `xml:"D:getcontenttype,omitempty"`
+	LastModified  string          `xml:"D:getlastmodified,omitempty"`
+	ETag          string          `xml:"D:getetag,omitempty"`
+}
+
+type davResourceType struct {
+	Collection *struct{} `xml:"D:collection"`
+}
+
+func davFileResponse(info os.FileInfo) davResponse {
+	size := info.Size()
+	return davResponse{
+		Href: davPrefix + url.PathEscape(info.Name()),
+		Propstat: davPropstat{
+			Prop: davProp{
+				DisplayName:   info.Name(),
+				ContentLength: &size,
+				ContentType:   "text/markdown; charset=utf-8",
+				LastModified:  info.ModTime().UTC().Format(http.TimeFormat),
+				ETag:          davETag(info),
+			},
+			Status: "HTTP/1.1 200 OK",
+		},
+	}
+}
+
+func davETag(info os.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+}
+
+// davCheckOwner answers 403 Forbidden and returns false when the note
+// noteID exists and the user may not change it
+func davCheckOwner(w http.ResponseWriter, r *http.Request, fs *storage.FileStorage, noteID string) bool {
+	if currentUser(r) == nil {
+		return true
+	}
+	n, err := fs.FindNoteByID(noteID)
+	if err != nil || canModify(r, n) {
+		return true
+	}
+	http.Error(w, "only the author of a note may change it", http.StatusForbidden)
+	return false
+}
+
+func (s *Server) davPropfind(w http.ResponseWriter, r *http.Request, name string) {
+	var responses []davResponse
+
+	if name == "" {
+		responses = append(responses, davResponse{
+			Href: davPrefix,
+			Propstat: davPropstat{
+				Prop:   davProp{DisplayName: "notes", ResourceType: davResourceType{Collection: &struct{}{}}},
+				Status: "HTTP/1.1 200 OK",
+			},
+		})
+		if r.Header.Get("Depth") != "0" {
+			files, err := s.store(r).NoteFiles()
+			if err != nil {
+				davStatus(w, err)
+				return
+			}
+			for _, file := range files {
+				if info, err := os.Stat(file); err == nil {
+					responses = append(responses, davFileResponse(info))
+				}
+			}
+		}
+	} else {
+		noteID, ok := s.davNoteID(name)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		info, err := os.Stat(s.store(r).GenerateNoteFilePath(noteID))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		responses = append(responses, davFileResponse(info))
+	}
+
+	body, err := xml.Marshal(davMultistatus{Namespace: "DAV:", Responses: responses})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	io.WriteString(w, xml.Header)
+	w.Write(body)
+}
+
+func (s *Server) davGet(w http.ResponseWriter, r *http.Request, name string) {
+	noteID, ok := s.davNoteID(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	f, err := os.Open(s.store(r).GenerateNoteFilePath(noteID))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("ETag", davETag(info))
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+func (s *Server) davPut(w http.ResponseWriter, r *http.Request, name string) {
+	noteID, ok := s.davNoteID(name)
+	if !ok {
+		http.Error(w, "only .note files can be stored", http.StatusForbidden)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxDAVUpload))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// Uploads are parsed like imports, so plain Markdown without front
+	// matter becomes a valid note rather than a file memo cannot read
+	n, err := exchange.FromMarkdown(string(data), noteID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	fs := s.store(r)
+	if !davCheckOwner(w, r, fs, noteID) {
+		return
+	}
+	attribute(r, n)
+
+	_, statErr := os.Stat(fs.GenerateNoteFilePath(noteID))
+	if err := fs.SaveNoteWithID(n, noteID); err != nil {
+		davStatus(w, err)
+		return
+	}
+	slog.Debug("webdav put", "note", noteID)
+
+	if os.IsNotExist(statErr) {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) davDelete(w http.ResponseWriter, r *http.Request, name string) {
+	noteID, ok := s.davNoteID(name)
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	fs := s.store(r)
+	if _, err := os.Stat(fs.GenerateNoteFilePath(noteID)); os.IsNotExist(err) {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if !davCheckOwner(w, r, fs, noteID) {
+		return
+	}
+	if err := fs.DeleteNote(noteID); err != nil {
+		davStatus(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) davMoveCopy(w http.ResponseWriter, r *http.Request, name string) {
+	srcID, ok := s.davNoteID(name)
+	if !ok {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	dest, err := url.Parse(r.Header.Get("Destination"))
+	if err != nil || !strings.HasPrefix(dest.Path, davPrefix) {
+		http.Error(w, "invalid destination", http.StatusBadGateway)
+		return
+	}
+	dstID, ok := s.davNoteID(path.Base(dest.Path))
+	if !ok || path.Dir(dest.Path)+"/" != davPrefix {
+		http.Error(w, "destination must be a .note file in the notes directory", http.StatusForbidden)
+		return
+	}
+	if srcID == dstID {
+		http.Error(w, "source and destination are the same", http.StatusForbidden)
+		return
+	}
+
+	fs := s.store(r)
+	n, err := fs.FindNoteByID(srcID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if r.Method == "MOVE" && !canModify(r, n) {
+		http.Error(w, "only the author of a note may change it", http.StatusForbidden)
+		return
+	}
+
+	_, statErr := os.Stat(fs.GenerateNoteFilePath(dstID))
+	exists := statErr == nil
+	if exists {
+		if r.Header.Get("Overwrite") == "F" {
+			http.Error(w, "destination exists", http.StatusPreconditionFailed)
+			return
+		}
+		if !davCheckOwner(w, r, fs, dstID) {
+			return
+		}
+	}
+
+	switch {
+	case r.Method == "MOVE" && exists:
+		// The destination is overwritten before the source goes, so a
+		// failure loses neither; its old content is kept as a revision
+		attribute(r, n)
+		if err = fs.SaveNoteWithID(n, dstID); err == nil {
+			err = fs.DeleteNote(srcID)
+		}
+	case r.Method == "MOVE":
+		err = fs.RenameNoteFile(srcID, dstID)
+	default:
+		n.Metadata.Created = time.Now()
+		attribute(r, n)
+		err = fs.SaveNoteWithID(n, dstID)
+	}
+	if err != nil {
+		davStatus(w, err)
+		return
+	}
+
+	if exists {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"memo/internal/note"
+)
+
+// DefaultAuthorTags is how many tags per author `memo stats --authors`
+// shows by default
+const DefaultAuthorTags = 3
+
+// AuthorStats summarizes the notes of one author
+type AuthorStats struct {
+	// Author is empty for notes without an author
+	Author       string     `json:"author"`
+	Notes        int        `json:"notes"`
+	Words        int        `json:"words"`
+	AverageWords float64    `json:"average_words"`
+	Tags         []TagCount `json:"tags"`
+}
+
+// AuthorBreakdown is the result of `memo stats --authors`
+type AuthorBreakdown struct {
+	TotalNotes int           `json:"total_notes"`
+	Authors    []AuthorStats `json:"authors"`
+	// Period is set when only notes created in a period were counted
+	Period *Period `json:"period,omitempty"`
+}
+
+// Authors groups notes by author, ignoring case, and gathers counts and the
+// top most used tags for each. Encrypted content is not counted.
+func Authors(notes []*note.Note, top int) *AuthorBreakdown {
+	byKey := make(map[string][]*note.Note)
+	names := make(map[string]string)
+	for _, n := range notes {
+		author := strings.TrimSpace(n.Metadata.Author)
+		key := strings.ToLower(author)
+		if _, ok := names[key]; !ok {
+			names[key] = author
+		}
+		byKey[key] = append(byKey[key], n)
+	}
+
+	ab := &AuthorBreakdown{TotalNotes: len(notes), Authors: []AuthorStats{}}
+	for key, group := range byKey {
+		s := Compute(group)
+		tags := s.Tags
+		if top > 0 && len(tags) > top {
+			tags = tags[:top]
+		}
+		ab.Authors = append(ab.Authors, AuthorStats{
+			Author:       names[key],
+			Notes:        s.TotalNotes,
+			Words:        s.TotalWords,
+			AverageWords: s.AverageWords,
+			Tags:         tags,
+		})
+	}
+	sort.Slice(ab.Authors, func(i, j int) bool {
+		a, b := ab.Authors[i], ab.Authors[j]
+		if a.Notes != b.Notes {
+			return a.Notes > b.Notes
+		}
+		return strings.ToLower(a.Author) < strings.ToLower(b.Author)
+	})
+	return ab
+}
+
+// WriteJSON writes the breakdown as an indented JSON document
+func (ab *AuthorBreakdown) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ab)
+}
+
+// WriteCSV writes one author,notes,words,average_words,tags row per author;
+// tags are separated by semicolons
+func (ab *AuthorBreakdown) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{{"author", "notes", "words", "average_words", "tags"}}
+	for _, a := range ab.Authors {
+		var tags []string
+		for _, tc := range a.Tags {
+			tags = append(tags, tc.Tag)
+		}
+		rows = append(rows, []string{
+			a.Author,
+			strconv.Itoa(a.Notes),
+			strconv.Itoa(a.Words),
+			strconv.FormatFloat(a.AverageWords, 'f', 1, 64),
+			strings.Join(tags, ";"),
+		})
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+	return nil
+}
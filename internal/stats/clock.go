@@ -0,0 +1,61 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"memo/internal/note"
+)
+
+// NoteTime is the time tracked on a note during a period
+type NoteTime struct {
+	NoteRef
+	Time    time.Duration
+	Running bool
+}
+
+// TagTime is the time tracked on the notes carrying a tag
+type TagTime struct {
+	Tag  string
+	Time time.Duration
+}
+
+// ClockReport sums up the time tracked with `memo clock` during a period,
+// per note and per tag, longest first. Time on a note with several tags
+// counts toward each of them.
+type ClockReport struct {
+	Period Period
+	Notes  []NoteTime
+	Tags   []TagTime
+	Total  time.Duration
+}
+
+// NewClockReport computes the time tracked on notes during period; running
+// clocks count up to now
+func NewClockReport(notes []*note.Note, period Period, now time.Time) *ClockReport {
+	r := &ClockReport{Period: period}
+	byTag := make(map[string]time.Duration)
+	for _, n := range notes {
+		spent := n.TimeSpent(period.Since, period.Until, now)
+		if spent == 0 {
+			continue
+		}
+		r.Notes = append(r.Notes, NoteTime{NoteRef: *ref(n), Time: spent, Running: n.Running()})
+		r.Total += spent
+		for _, tag := range n.Metadata.Tags {
+			byTag[tag] += spent
+		}
+	}
+	sort.SliceStable(r.Notes, func(i, j int) bool { return r.Notes[i].Time > r.Notes[j].Time })
+
+	for tag, spent := range byTag {
+		r.Tags = append(r.Tags, TagTime{Tag: tag, Time: spent})
+	}
+	sort.Slice(r.Tags, func(i, j int) bool {
+		if r.Tags[i].Time != r.Tags[j].Time {
+			return r.Tags[i].Time > r.Tags[j].Time
+		}
+		return r.Tags[i].Tag < r.Tags[j].Tag
+	})
+	return r
+}
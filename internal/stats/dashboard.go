@@ -0,0 +1,80 @@
+package stats
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+)
+
+const (
+	// DefaultDashboardWeeks is how many weeks of note creation
+	// `memo stats --dashboard` charts by default
+	DefaultDashboardWeeks = 26
+	// DefaultDashboardTop is how many tags and large notes it lists
+	DefaultDashboardTop = 8
+)
+
+// WeekCount is the number of notes created in the week starting on Start
+type WeekCount struct {
+	Start time.Time `json:"start"`
+	Notes int       `json:"notes"`
+}
+
+// NoteSize is a note with its length in words
+type NoteSize struct {
+	NoteRef
+	Words int `json:"words"`
+}
+
+// Dashboard is an overview of the health of a store: its totals, how many
+// notes were created week by week, its most used tags and its largest notes
+type Dashboard struct {
+	Summary *Stats      `json:"summary"`
+	Weeks   []WeekCount `json:"weeks"`
+	Tags    []TagCount  `json:"tags"`
+	Largest []NoteSize  `json:"largest"`
+}
+
+// NewDashboard computes the dashboard of notes over the given number of
+// weeks up to the one containing now, listing top tags and notes
+func NewDashboard(notes []*note.Note, weeks, top int, now time.Time) *Dashboard {
+	d := &Dashboard{Summary: Compute(notes), Largest: []NoteSize{}}
+
+	first := Week(now).Since.AddDate(0, 0, -7*(weeks-1))
+	d.Weeks = make([]WeekCount, weeks)
+	for i := range d.Weeks {
+		d.Weeks[i].Start = first.AddDate(0, 0, 7*i)
+	}
+	for _, n := range notes {
+		created := n.Metadata.Created.In(now.Location())
+		if created.Before(first) {
+			continue
+		}
+		// Rounding keeps a daylight saving change from shifting the week
+		if i := int(Week(created).Since.Sub(first).Hours()+12) / (7 * 24); i < weeks {
+			d.Weeks[i].Notes++
+		}
+	}
+
+	d.Tags = d.Summary.Tags[:min(top, len(d.Summary.Tags))]
+
+	for _, n := range notes {
+		if !n.Locked() {
+			d.Largest = append(d.Largest, NoteSize{NoteRef: *ref(n), Words: len(strings.Fields(n.Content))})
+		}
+	}
+	sort.SliceStable(d.Largest, func(i, j int) bool { return d.Largest[i].Words > d.Largest[j].Words })
+	d.Largest = d.Largest[:min(top, len(d.Largest))]
+	return d
+}
+
+// WriteJSON writes the dashboard as an indented JSON document
+func (d *Dashboard) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
@@ -0,0 +1,186 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"memo/internal/note"
+)
+
+// DateLayout is the format of period boundaries on the command line
+const DateLayout = "2006-01-02"
+
+// Period is a range of creation times; a zero bound is open-ended. Until is
+// exclusive.
+type Period struct {
+	Since time.Time
+	Until time.Time
+}
+
+// ParsePeriod builds a period from inclusive YYYY-MM-DD dates; either may
+// be empty
+func ParsePeriod(since, until string) (Period, error) {
+	var p Period
+	if since != "" {
+		t, err := time.ParseInLocation(DateLayout, since, time.Local)
+		if err != nil {
+			return p, fmt.Errorf("invalid --since date '%s' (use YYYY-MM-DD)", since)
+		}
+		p.Since = t
+	}
+	if until != "" {
+		t, err := time.ParseInLocation(DateLayout, until, time.Local)
+		if err != nil {
+			return p, fmt.Errorf("invalid --until date '%s' (use YYYY-MM-DD)", until)
+		}
+		p.Until = t.AddDate(0, 0, 1)
+	}
+	if !p.Since.IsZero() && !p.Until.IsZero() && !p.Since.Before(p.Until) {
+		return p, fmt.Errorf("--since must not be after --until")
+	}
+	return p, nil
+}
+
+// IsZero reports whether the period covers all time
+func (p Period) IsZero() bool {
+	return p.Since.IsZero() && p.Until.IsZero()
+}
+
+// Contains reports whether t falls within the period
+func (p Period) Contains(t time.Time) bool {
+	return (p.Since.IsZero() || !t.Before(p.Since)) && (p.Until.IsZero() || t.Before(p.Until))
+}
+
+// Previous returns the period of the same length immediately before p.
+// Periods spanning whole months step back by months, so a quarter is
+// compared with the previous quarter.
+func (p Period) Previous() Period {
+	if p.Since.Day() == 1 && p.Until.Day() == 1 {
+		months := (p.Until.Year()-p.Since.Year())*12 + int(p.Until.Month()-p.Since.Month())
+		return Period{Since: p.Since.AddDate(0, -months, 0), Until: p.Since}
+	}
+	days := int(p.Until.Sub(p.Since).Round(24*time.Hour) / (24 * time.Hour))
+	return Period{Since: p.Since.AddDate(0, 0, -days), Until: p.Since}
+}
+
+// String formats the period with inclusive dates
+func (p Period) String() string {
+	since, until := "…", "…"
+	if !p.Since.IsZero() {
+		since = p.Since.Format(DateLayout)
+	}
+	if !p.Until.IsZero() {
+		until = p.Until.AddDate(0, 0, -1).Format(DateLayout)
+	}
+	return since + " – " + until
+}
+
+// MarshalJSON writes the inclusive boundary dates, omitting open ends
+func (p Period) MarshalJSON() ([]byte, error) {
+	dates := make(map[string]string)
+	if !p.Since.IsZero() {
+		dates["since"] = p.Since.Format(DateLayout)
+	}
+	if !p.Until.IsZero() {
+		dates["until"] = p.Until.AddDate(0, 0, -1).Format(DateLayout)
+	}
+	return json.Marshal(dates)
+}
+
+// Filter returns the notes created within the period
+func (p Period) Filter(notes []*note.Note) []*note.Note {
+	var filtered []*note.Note
+	for _, n := range notes {
+		if p.Contains(n.Metadata.Created) {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered
+}
+
+// TagDelta is the change in usage of a tag between two periods
+type TagDelta struct {
+	Tag      string `json:"tag"`
+	Previous int    `json:"previous"`
+	Current  int    `json:"current"`
+}
+
+// Comparison holds the statistics of two periods side by side
+type Comparison struct {
+	PreviousPeriod Period     `json:"previous_period"`
+	CurrentPeriod  Period     `json:"current_period"`
+	Previous       *Stats     `json:"previous"`
+	Current        *Stats     `json:"current"`
+	Tags           []TagDelta `json:"tags"`
+}
+
+// Compare computes statistics for the notes created in current and in the
+// period of equal length before it
+func Compare(notes []*note.Note, current Period) *Comparison {
+	previous := current.Previous()
+	c := &Comparison{
+		PreviousPeriod: previous,
+		CurrentPeriod:  current,
+		Previous:       Compute(previous.Filter(notes)),
+		Current:        Compute(current.Filter(notes)),
+		Tags:           []TagDelta{},
+	}
+
+	deltas := make(map[string]*TagDelta)
+	for _, tc := range c.Previous.Tags {
+		deltas[tc.Tag] = &TagDelta{Tag: tc.Tag, Previous: tc.Count}
+	}
+	for _, tc := range c.Current.Tags {
+		if d, ok := deltas[tc.Tag]; ok {
+			d.Current = tc.Count
+		} else {
+			deltas[tc.Tag] = &TagDelta{Tag: tc.Tag, Current: tc.Count}
+		}
+	}
+	for _, d := range deltas {
+		c.Tags = append(c.Tags, *d)
+	}
+	sort.Slice(c.Tags, func(i, j int) bool {
+		di, dj := c.Tags[i].Current-c.Tags[i].Previous, c.Tags[j].Current-c.Tags[j].Previous
+		if di != dj {
+			return di > dj
+		}
+		return c.Tags[i].Tag < c.Tags[j].Tag
+	})
+	return c
+}
+
+// WriteJSON writes the comparison as an indented JSON document
+func (c *Comparison) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+// WriteCSV writes metric,previous,current,change rows
+func (c *Comparison) WriteCSV(w io.Writer) error {
+	row := func(metric string, prev, cur int) []string {
+		return []string{metric, strconv.Itoa(prev), strconv.Itoa(cur), strconv.Itoa(cur - prev)}
+	}
+
+	rows := [][]string{
+		{"metric", "previous", "current", "change"},
+		row("total_notes", c.Previous.TotalNotes, c.Current.TotalNotes),
+		row("total_words", c.Previous.TotalWords, c.Current.TotalWords),
+		row("open_tasks", c.Previous.OpenTasks, c.Current.OpenTasks),
+		row("done_tasks", c.Previous.DoneTasks, c.Current.DoneTasks),
+	}
+	for _, d := range c.Tags {
+		rows = append(rows, row("tag:"+d.Tag, d.Previous, d.Current))
+	}
+
+	if err := csv.NewWriter(w).WriteAll(rows); err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+)
+
+// Week returns the Monday-to-Sunday week containing t
+func Week(t time.Time) Period {
+	y, m, d := t.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7
+	since := day.AddDate(0, 0, -offset)
+	return Period{Since: since, Until: since.AddDate(0, 0, 7)}
+}
+
+// Month returns the calendar month containing t
+func Month(t time.Time) Period {
+	since := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	return Period{Since: since, Until: since.AddDate(0, 1, 0)}
+}
+
+// ReportEntry is a note that was created or changed during a report period
+type ReportEntry struct {
+	Note    *note.Note
+	Created bool
+	Words   int
+}
+
+// CompletedTask is a finished checklist item in a note changed during the
+// report period
+type CompletedTask struct {
+	Text string
+	Note *note.Note
+}
+
+// Report summarizes the notes created and modified during a period
+type Report struct {
+	Title   string
+	Period  Period
+	Entries []ReportEntry
+	Tasks   []CompletedTask
+}
+
+// ReportField marks notes saved by `memo report --as-note`, which later
+// reports leave out
+const ReportField = "generated"
+
+// NewReport collects the notes created or modified during period, newest
+// first. Encrypted content is neither counted nor searched for tasks.
+func NewReport(title string, notes []*note.Note, period Period) *Report {
+	r := &Report{Title: title, Period: period}
+	for _, n := range notes {
+		if n.MatchField(ReportField, "report") {
+			continue
+		}
+		created := period.Contains(n.Metadata.Created)
+		if !created && !period.Contains(n.Metadata.Modified) {
+			continue
+		}
+		entry := ReportEntry{Note: n, Created: created}
+		if !n.Locked() {
+			entry.Words = len(strings.Fields(n.Content))
+			for _, task := range n.Tasks() {
+				if task.Done {
+					r.Tasks = append(r.Tasks, CompletedTask{Text: task.Text, Note: n})
+				}
+			}
+		}
+		r.Entries = append(r.Entries, entry)
+	}
+	sort.SliceStable(r.Entries, func(i, j int) bool {
+		return r.Entries[i].Note.Metadata.Modified.After(r.Entries[j].Note.Metadata.Modified)
+	})
+	return r
+}
+
+// Markdown renders the report with notes grouped by tag; a note with
+// several tags is listed under each of them. Notes are linked with
+// [[wikilinks]] so the report works as a note of its own.
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s: %s\n\n", r.Title, r.Period)
+
+	created, modified, words := 0, 0, 0
+	for _, e := range r.Entries {
+		if e.Created {
+			created++
+		} else {
+			modified++
+		}
+		words += e.Words
+	}
+	fmt.Fprintf(&b, "- Notes created: %d\n", created)
+	fmt.Fprintf(&b, "- Notes modified: %d\n", modified)
+	fmt.Fprintf(&b, "- Words in these notes: %d\n", words)
+	fmt.Fprintf(&b, "- Completed tasks: %d\n", len(r.Tasks))
+
+	if len(r.Entries) == 0 {
+		b.WriteString("\nNo notes were created or modified in this period.\n")
+		return b.String()
+	}
+
+	groups := make(map[string][]ReportEntry)
+	var tags []string
+	for _, e := range r.Entries {
+		entryTags := e.Note.Metadata.Tags
+		if len(entryTags) == 0 {
+			entryTags = []string{""}
+		}
+		for _, tag := range entryTags {
+			if _, ok := groups[tag]; !ok && tag != "" {
+				tags = append(tags, tag)
+			}
+			groups[tag] = append(groups[tag], e)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if len(groups[tags[i]]) != len(groups[tags[j]]) {
+			return len(groups[tags[i]]) > len(groups[tags[j]])
+		}
+		return tags[i] < tags[j]
+	})
+	if _, ok := groups[""]; ok {
+		tags = append(tags, "")
+	}
+
+	b.WriteString("\n## Notes by tag\n")
+	for _, tag := range tags {
+		heading := tag
+		if tag == "" {
+			heading = "Untagged"
+		}
+		fmt.Fprintf(&b, "\n### %s (%d)\n\n", heading, len(groups[tag]))
+		for _, e := range groups[tag] {
+			change, when := "modified", e.Note.Metadata.Modified
+			if e.Created {
+				change, when = "created", e.Note.Metadata.Created
+			}
+			fmt.Fprintf(&b, "- [[%s]] — %s %s, %d words\n", e.Note.Metadata.Title, change,
+				when.Format("Mon 2006-01-02"), e.Words)
+		}
+	}
+
+	if len(r.Tasks) > 0 {
+		b.WriteString("\n## Completed tasks\n\n")
+		for _, t := range r.Tasks {
+			// Not written as a checklist, so the report adds no tasks itself
+			fmt.Fprintf(&b, "- %s (in [[%s]])\n", t.Text, t.Note.Metadata.Title)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,130 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+)
+
+// NoteRef identifies a note in statistics output
+type NoteRef struct {
+	ID      string    `json:"id"`
+	Title   string    `json:"title"`
+	Created time.Time `json:"created"`
+}
+
+// TagCount is the number of notes carrying a tag
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// Stats summarizes a set of notes
+type Stats struct {
+	TotalNotes   int        `json:"total_notes"`
+	TotalWords   int        `json:"total_words"`
+	AverageWords float64    `json:"average_words"`
+	Oldest       *NoteRef   `json:"oldest,omitempty"`
+	Newest       *NoteRef   `json:"newest,omitempty"`
+	OpenTasks    int        `json:"open_tasks"`
+	DoneTasks    int        `json:"done_tasks"`
+	Tags         []TagCount `json:"tags"`
+	// Period is set when only notes created in a period were counted
+	Period *Period `json:"period,omitempty"`
+}
+
+func ref(n *note.Note) *NoteRef {
+	return &NoteRef{ID: n.ID(), Title: n.Metadata.Title, Created: n.Metadata.Created}
+}
+
+// Compute gathers statistics for notes. Encrypted content is not counted.
+func Compute(notes []*note.Note) *Stats {
+	s := &Stats{TotalNotes: len(notes), Tags: []TagCount{}}
+	tagCount := make(map[string]int)
+	var oldest, newest *note.Note
+
+	for _, n := range notes {
+		if !n.Locked() {
+			s.TotalWords += len(strings.Fields(n.Content))
+		}
+
+		for _, task := range n.Tasks() {
+			if task.Done {
+				s.DoneTasks++
+			} else {
+				s.OpenTasks++
+			}
+		}
+
+		if oldest == nil || n.Metadata.Created.Before(oldest.Metadata.Created) {
+			oldest = n
+		}
+		if newest == nil || n.Metadata.Created.After(newest.Metadata.Created) {
+			newest = n
+		}
+
+		for _, tag := range n.Metadata.Tags {
+			tagCount[tag]++
+		}
+	}
+
+	if len(notes) > 0 {
+		s.AverageWords = float64(s.TotalWords) / float64(len(notes))
+		s.Oldest = ref(oldest)
+		s.Newest = ref(newest)
+	}
+
+	for tag, count := range tagCount {
+		s.Tags = append(s.Tags, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(s.Tags, func(i, j int) bool {
+		if s.Tags[i].Count != s.Tags[j].Count {
+			return s.Tags[i].Count > s.Tags[j].Count
+		}
+		return s.Tags[i].Tag < s.Tags[j].Tag
+	})
+
+	return s
+}
+
+// WriteJSON writes the statistics as an indented JSON document
+func (s *Stats) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// WriteCSV writes the statistics as metric,value rows. Tag counts use the
+// metric name "tag:<name>".
+func (s *Stats) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{
+		{"metric", "value"},
+		{"total_notes", strconv.Itoa(s.TotalNotes)},
+		{"total_words", strconv.Itoa(s.TotalWords)},
+		{"average_words", strconv.FormatFloat(s.AverageWords, 'f', 1, 64)},
+		{"open_tasks", strconv.Itoa(s.OpenTasks)},
+		{"done_tasks", strconv.Itoa(s.DoneTasks)},
+	}
+	if s.Oldest != nil {
+		rows = append(rows, []string{"oldest_note", s.Oldest.ID})
+	}
+	if s.Newest != nil {
+		rows = append(rows, []string{"newest_note", s.Newest.ID})
+	}
+	for _, tc := range s.Tags {
+		rows = append(rows, []string{"tag:" + tc.Tag, strconv.Itoa(tc.Count)})
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+	return nil
+}
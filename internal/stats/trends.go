@@ -0,0 +1,156 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"memo/internal/note"
+)
+
+const (
+	// DefaultTrendMonths is how many months `memo stats --tags-over-time`
+	// covers by default
+	DefaultTrendMonths = 12
+	// DefaultTrendTop is how many of the most used tags it charts
+	DefaultTrendTop = 10
+)
+
+// Trend classifies how the use of a tag changed over the months
+type Trend string
+
+const (
+	Steady Trend = ""
+	// New tags were first used in the recent months
+	New Trend = "new"
+	// Fading tags were used earlier but not in the recent months
+	Fading Trend = "fading"
+	// Rising tags were used at least twice as often in the second half of
+	// the months as in the first, falling ones at most half as often
+	Rising  Trend = "rising"
+	Falling Trend = "falling"
+)
+
+// TagTrend is the number of notes created with a tag in each month
+type TagTrend struct {
+	Tag    string `json:"tag"`
+	Counts []int  `json:"counts"`
+	Total  int    `json:"total"`
+	Trend  Trend  `json:"trend,omitempty"`
+}
+
+// TagTrends shows how tag usage shifted month by month: the most used tags
+// with their monthly counts, and every tag that appeared or faded
+type TagTrends struct {
+	Months []time.Time `json:"months"`
+	Tags   []TagTrend  `json:"tags"`
+	New    []string    `json:"new"`
+	Fading []string    `json:"fading"`
+}
+
+// NewTagTrends counts the notes created with each tag in the given number
+// of months up to the one containing now, charting the top most used tags.
+// The last quarter of the months, at least one, counts as recent.
+func NewTagTrends(notes []*note.Note, months, top int, now time.Time) *TagTrends {
+	t := &TagTrends{Months: make([]time.Time, months), Tags: []TagTrend{}, New: []string{}, Fading: []string{}}
+	first := Month(now).Since.AddDate(0, -(months - 1), 0)
+	for i := range t.Months {
+		t.Months[i] = first.AddDate(0, i, 0)
+	}
+
+	counts := make(map[string][]int)
+	usedBefore := make(map[string]bool)
+	for _, n := range notes {
+		created := n.Metadata.Created.In(now.Location())
+		i := (created.Year()-first.Year())*12 + int(created.Month()-first.Month())
+		for _, tag := range n.Metadata.Tags {
+			switch {
+			case i < 0:
+				usedBefore[tag] = true
+			case i < months:
+				if counts[tag] == nil {
+					counts[tag] = make([]int, months)
+				}
+				counts[tag][i]++
+			}
+		}
+	}
+
+	recent := max(1, months/4)
+	var trends []TagTrend
+	for tag, c := range counts {
+		tt := TagTrend{Tag: tag, Counts: c}
+		firstUse, recentUses, earlier, later := -1, 0, 0, 0
+		for i, count := range c {
+			tt.Total += count
+			if firstUse < 0 && count > 0 {
+				firstUse = i
+			}
+			if i >= months-recent {
+				recentUses += count
+			}
+			if i < months/2 {
+				earlier += count
+			} else if i >= (months+1)/2 {
+				later += count
+			}
+		}
+		switch {
+		case !usedBefore[tag] && firstUse >= months-recent:
+			tt.Trend = New
+			t.New = append(t.New, tag)
+		case recentUses == 0:
+			tt.Trend = Fading
+			t.Fading = append(t.Fading, tag)
+		case later >= 2 && later >= 2*earlier:
+			tt.Trend = Rising
+		case earlier >= 2 && 2*later <= earlier:
+			tt.Trend = Falling
+		}
+		trends = append(trends, tt)
+	}
+	sort.Strings(t.New)
+	sort.Strings(t.Fading)
+
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Total != trends[j].Total {
+			return trends[i].Total > trends[j].Total
+		}
+		return trends[i].Tag < trends[j].Tag
+	})
+	t.Tags = append(t.Tags, trends[:min(top, len(trends))]...)
+	return t
+}
+
+// WriteJSON writes the trends as an indented JSON document
+func (t *TagTrends) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+// WriteCSV writes one tag row per charted tag with a column per month,
+// named YYYY-MM, then its total and trend
+func (t *TagTrends) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"tag"}
+	for _, m := range t.Months {
+		header = append(header, m.Format("2006-01"))
+	}
+	rows := [][]string{append(header, "total", "trend")}
+	for _, tt := range t.Tags {
+		row := []string{tt.Tag}
+		for _, count := range tt.Counts {
+			row = append(row, strconv.Itoa(count))
+		}
+		rows = append(rows, append(row, strconv.Itoa(tt.Total), string(tt.Trend)))
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+	return nil
+}
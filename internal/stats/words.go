@@ -0,0 +1,126 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"memo/internal/language"
+	"memo/internal/note"
+)
+
+// DefaultTopWords is how many terms `memo stats --words` shows by default
+const DefaultTopWords = 20
+
+// minWordLength leaves out short words, which are rarely topics
+const minWordLength = 3
+
+// WordCount is how often a term occurs across notes
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+	Notes int    `json:"notes"`
+	// Tagged is set when a tag, or the last part of a nested tag, already
+	// has this name
+	Tagged bool `json:"tagged"`
+}
+
+// WordFrequency is the result of `memo stats --words`
+type WordFrequency struct {
+	TotalNotes int         `json:"total_notes"`
+	Words      []WordCount `json:"words"`
+}
+
+// Words returns the top most frequent terms in the content and titles of
+// notes, leaving out stopwords, numbers and short words. Encrypted content
+// is not counted.
+func Words(notes []*note.Note, top int) *WordFrequency {
+	counts := make(map[string]*WordCount)
+	tags := make(map[string]bool)
+
+	for _, n := range notes {
+		for _, tag := range n.Metadata.Tags {
+			tags[strings.ToLower(path.Base(tag))] = true
+		}
+
+		text := n.Metadata.Title
+		if !n.Locked() {
+			text += " " + n.Content
+		}
+		seen := make(map[string]bool)
+		for _, w := range Terms(text) {
+			wc := counts[w]
+			if wc == nil {
+				wc = &WordCount{Word: w}
+				counts[w] = wc
+			}
+			wc.Count++
+			if !seen[w] {
+				seen[w] = true
+				wc.Notes++
+			}
+		}
+	}
+
+	wf := &WordFrequency{TotalNotes: len(notes), Words: []WordCount{}}
+	for w, wc := range counts {
+		wc.Tagged = tags[w]
+		wf.Words = append(wf.Words, *wc)
+	}
+	sort.Slice(wf.Words, func(i, j int) bool {
+		a, b := wf.Words[i], wf.Words[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Notes != b.Notes {
+			return a.Notes > b.Notes
+		}
+		return a.Word < b.Word
+	})
+	if top > 0 && len(wf.Words) > top {
+		wf.Words = wf.Words[:top]
+	}
+	return wf
+}
+
+// Terms splits text into lowercase words worth counting, leaving out
+// English and German stopwords, numbers and short words
+func Terms(text string) []string {
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '\''
+	}) {
+		w = strings.Trim(w, "-'")
+		if len([]rune(w)) < minWordLength || language.Stopwords["en"][w] || language.Stopwords["de"][w] || !strings.ContainsFunc(w, unicode.IsLetter) {
+			continue
+		}
+		words = append(words, w)
+	}
+	return words
+}
+
+// WriteJSON writes the word frequencies as an indented JSON document
+func (wf *WordFrequency) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(wf)
+}
+
+// WriteCSV writes one word,count,notes,tagged row per term
+func (wf *WordFrequency) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{{"word", "count", "notes", "tagged"}}
+	for _, wc := range wf.Words {
+		rows = append(rows, []string{wc.Word, strconv.Itoa(wc.Count), strconv.Itoa(wc.Notes), strconv.FormatBool(wc.Tagged)})
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+	return nil
+}
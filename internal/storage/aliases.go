@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AliasesFileName is the file inside the notes store mapping the former
+// IDs of renamed notes to their current ones
+const AliasesFileName = ".aliases.yaml"
+
+// Aliases returns the former IDs of renamed notes and the ID each now has
+func (fs *FileStorage) Aliases() (map[string]string, error) {
+	data, err := os.ReadFile(fs.StorePath(AliasesFileName))
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading aliases: %w", err)
+	}
+	aliases := map[string]string{}
+	if err := yaml.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("error parsing aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// ResolveAlias returns the current ID of a note that was once called
+// oldID. A note that now has the ID itself takes precedence.
+func (fs *FileStorage) ResolveAlias(oldID string) (string, bool) {
+	if fs.NoteExists(oldID) {
+		return "", false
+	}
+	aliases, err := fs.Aliases()
+	if err != nil {
+		slog.Warn("ignoring note aliases", "error", err)
+		return "", false
+	}
+	for alias, id := range aliases {
+		if strings.EqualFold(alias, oldID) && fs.NoteExists(id) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// addAlias records that the note oldID is now called newID. Aliases of
+// oldID move along, and newID stops being an alias as it names a note.
+func (fs *FileStorage) addAlias(oldID, newID string) {
+	aliases, err := fs.Aliases()
+	if err != nil {
+		slog.Warn("failed to record alias", "id", oldID, "error", err)
+		return
+	}
+	for alias, id := range aliases {
+		if strings.EqualFold(id, oldID) {
+			aliases[alias] = newID
+		}
+		if strings.EqualFold(alias, newID) {
+			delete(aliases, alias)
+		}
+	}
+	aliases[oldID] = newID
+
+	data, err := yaml.Marshal(aliases)
+	if err == nil {
+		err = fs.journal(fs.StorePath(AliasesFileName))
+	}
+	if err == nil {
+		err = os.WriteFile(fs.StorePath(AliasesFileName), data, 0644)
+	}
+	if err != nil {
+		slog.Warn("failed to record alias", "id", oldID, "error", err)
+	}
+}
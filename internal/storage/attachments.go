@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"memo/internal/attachments"
+	"memo/internal/note"
+	"memo/internal/vault"
+)
+
+// attachmentsDir is the location of the stored attachments
+func (fs *FileStorage) attachmentsDir() string {
+	return fs.StorePath(attachments.DirName)
+}
+
+// AttachFile stores the file at src among the attachments and returns the
+// link notes use to refer to it. reused is true when an identical file was
+// already stored.
+func (fs *FileStorage) AttachFile(src string) (link string, reused bool, err error) {
+	if err := fs.CheckWritable(); err != nil {
+		return "", false, err
+	}
+	if fs.skip("attach", src) {
+		return attachments.Link(filepath.Base(src)), false, nil
+	}
+	name, reused, err := attachments.Store(fs.attachmentsDir(), src)
+	if err != nil {
+		return "", false, err
+	}
+	slog.Debug("stored attachment", "src", src, "name", name, "reused", reused)
+	return attachments.Link(name), reused, nil
+}
+
+// AttachmentRefCount returns the number of notes recorded as referring to
+// the attachment behind link
+func (fs *FileStorage) AttachmentRefCount(link string) (int, error) {
+	refs, err := attachments.LoadRefs(fs.attachmentsDir())
+	if err != nil {
+		return 0, err
+	}
+	name := strings.TrimPrefix(link, attachments.DirName+"/")
+	count := 0
+	for _, names := range refs {
+		if slices.Contains(names, name) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// updateAttachmentRefs records the attachments n refers to. The content of
+// a locked note cannot be read, so its last recorded references are kept.
+// Like the content index, a failure is only logged: `memo doctor` reads the
+// references from the notes themselves.
+func (fs *FileStorage) updateAttachmentRefs(n *note.Note) {
+	if n.Locked() {
+		return
+	}
+	fs.changeAttachmentRefs(func(refs attachments.Refs) {
+		if names := attachments.Scan(n.Content); len(names) > 0 {
+			refs[n.ID()] = names
+		} else {
+			delete(refs, n.ID())
+		}
+	})
+}
+
+// moveAttachmentRefs moves the references recorded for a renamed note, or
+// drops them when newID is empty
+func (fs *FileStorage) moveAttachmentRefs(oldID, newID string) {
+	fs.changeAttachmentRefs(func(refs attachments.Refs) {
+		if names, ok := refs[oldID]; ok && newID != "" {
+			refs[newID] = names
+		}
+		delete(refs, oldID)
+	})
+}
+
+func (fs *FileStorage) changeAttachmentRefs(change func(attachments.Refs)) {
+	fs.refsMu.Lock()
+	defer fs.refsMu.Unlock()
+
+	refs, err := attachments.LoadRefs(fs.attachmentsDir())
+	if err == nil {
+		before := maps.Clone(refs)
+		change(refs)
+		if maps.EqualFunc(before, refs, slices.Equal[[]string]) {
+			return
+		}
+		if err = fs.journal(filepath.Join(fs.attachmentsDir(), attachments.RefsFileName)); err == nil {
+			err = attachments.SaveRefs(fs.attachmentsDir(), refs)
+		}
+	}
+	if err != nil {
+		slog.Warn("could not update attachment references", "error", err)
+	}
+}
+
+// Attachments returns the stored attachments with the notes and revisions
+// referring to them. References are read from the note files, so edits made
+// outside memo count; for encrypted content the references recorded when
+// the note was last saved are used instead.
+func (fs *FileStorage) Attachments() ([]attachments.Blob, error) {
+	blobs, err := attachments.List(fs.attachmentsDir())
+	if err != nil || len(blobs) == 0 {
+		return nil, err
+	}
+	refs, err := attachments.LoadRefs(fs.attachmentsDir())
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*attachments.Blob, len(blobs))
+	for i := range blobs {
+		byName[blobs[i].Name] = &blobs[i]
+	}
+
+	files, err := fs.NoteFiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		id := strings.TrimSuffix(filepath.Base(file), fs.noteExtension)
+		names := attachments.Scan(string(data))
+		if strings.Contains(string(data), vault.Prefix) {
+			names = append(names, refs[id]...)
+		}
+		for _, name := range names {
+			if b := byName[name]; b != nil && !slices.Contains(b.Notes, id) {
+				b.Notes = append(b.Notes, id)
+			}
+		}
+	}
+
+	// A rollback must not bring back a link to a deleted attachment
+	revisions, err := filepath.Glob(filepath.Join(fs.notesDir, VersionsDirName, "*", "*"+fs.noteExtension))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range revisions {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range attachments.Scan(string(data)) {
+			if b := byName[name]; b != nil {
+				b.Revisions++
+			}
+		}
+	}
+	return blobs, nil
+}
+
+// RemoveAttachment deletes a stored attachment
+func (fs *FileStorage) RemoveAttachment(name string) error {
+	if err := fs.CheckWritable(); err != nil {
+		return err
+	}
+	if fs.skip("remove attachment", name) {
+		return nil
+	}
+	slog.Debug("removing attachment", "name", name)
+	return os.Remove(filepath.Join(fs.attachmentsDir(), filepath.Base(name)))
+}
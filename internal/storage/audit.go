@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"log/slog"
+	"time"
+
+	"memo/internal/audit"
+	"memo/internal/note"
+)
+
+// AuditLogPath is the location of the audit log
+func (fs *FileStorage) AuditLogPath() string {
+	return fs.StorePath(audit.FileName)
+}
+
+// AuditLog returns every recorded change, oldest first
+func (fs *FileStorage) AuditLog() ([]audit.Entry, error) {
+	return audit.Load(fs.AuditLogPath())
+}
+
+// recordChange logs the change from old to cur, either of which is nil when
+// the note did not exist before or does not any more. Saves that change
+// nothing but the modification time are not recorded. The change has
+// already happened when this runs, so a failure is logged, not returned.
+func (fs *FileStorage) recordChange(old, cur *note.Note) {
+	e := audit.Entry{Old: audit.MetadataOf(old), New: audit.MetadataOf(cur)}
+	switch {
+	case old == nil && cur == nil:
+		return
+	case old == nil:
+		e.Op, e.ID = audit.OpCreate, cur.ID()
+	case cur == nil:
+		e.Op, e.ID = audit.OpDelete, old.ID()
+	default:
+		e.Op, e.ID = audit.OpEdit, cur.ID()
+		e.ContentChanged = !old.Metadata.Encrypted && !cur.Metadata.Encrypted && old.Content != cur.Content
+		changes := e.Changes()
+		if len(changes) == 0 && !e.ContentChanged {
+			return
+		}
+		if len(changes) == 1 && changes[0].Field == "tags" && !e.ContentChanged {
+			e.Op = audit.OpTag
+		}
+	}
+	fs.appendAudit(e)
+}
+
+func (fs *FileStorage) appendAudit(e audit.Entry) {
+	e.Time = time.Now()
+	e.User = audit.User()
+	if err := audit.Append(fs.AuditLogPath(), e); err != nil {
+		slog.Warn("could not record the change in the audit log", "error", err)
+	}
+}
@@ -0,0 +1,26 @@
+package storage
+
+// DryRunFunc is told about each change a dry run leaves out: the action,
+// such as "delete", and the note or file it would have applied to
+type DryRunFunc func(action, target string)
+
+// SetDryRun makes every operation that would modify the store report the
+// change to fn instead of making it. A nil fn turns dry runs off.
+func (fs *FileStorage) SetDryRun(fn DryRunFunc) {
+	fs.dryRun = fn
+}
+
+// DryRun reports whether changes are only reported
+func (fs *FileStorage) DryRun() bool {
+	return fs.dryRun != nil
+}
+
+// skip reports a change to the dry-run callback and returns true when the
+// change must not be made
+func (fs *FileStorage) skip(action, target string) bool {
+	if fs.dryRun == nil {
+		return false
+	}
+	fs.dryRun(action, target)
+	return true
+}
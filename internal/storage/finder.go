@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+
+	"memo/internal/language"
+	"memo/internal/note"
+)
+
+// Finder is an in-memory index of the searchable text of notes. Built once,
+// it answers queries without touching the disk, fast enough to update a
+// list of matches at every keystroke.
+type Finder struct {
+	entries []finderEntry
+}
+
+type finderEntry struct {
+	note  *note.Note
+	title string
+	// text is the title, tags and content, folded to lowercase without
+	// accents
+	text string
+}
+
+// NewFinder indexes notes. Locked notes are found by their title and tags
+// only.
+func NewFinder(notes []*note.Note) *Finder {
+	f := &Finder{entries: make([]finderEntry, len(notes))}
+	for i, n := range notes {
+		title := language.Fold(n.Metadata.Title)
+		text := title + "\n" + language.Fold(strings.Join(n.Metadata.Tags, " "))
+		if !n.Locked() {
+			text += "\n" + language.Fold(n.Content)
+		}
+		f.entries[i] = finderEntry{note: n, title: title, text: text}
+	}
+	return f
+}
+
+// Match returns up to limit notes containing every word of query, ignoring
+// case and accents. Notes whose title has all the words come first;
+// otherwise the most recently modified notes do. An empty query matches
+// every note.
+func (f *Finder) Match(query string, limit int) []*note.Note {
+	words := strings.Fields(language.Fold(query))
+
+	type match struct {
+		entry   *finderEntry
+		inTitle bool
+	}
+	var matches []match
+	for i := range f.entries {
+		e := &f.entries[i]
+		if containsAll(e.text, words) {
+			matches = append(matches, match{e, len(words) > 0 && containsAll(e.title, words)})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].inTitle != matches[j].inTitle {
+			return matches[i].inTitle
+		}
+		return matches[i].entry.note.Metadata.Modified.After(matches[j].entry.note.Metadata.Modified)
+	})
+
+	notes := make([]*note.Note, 0, min(limit, len(matches)))
+	for _, m := range matches[:min(limit, len(matches))] {
+		notes = append(notes, m.entry.note)
+	}
+	return notes
+}
+
+func containsAll(text string, words []string) bool {
+	for _, w := range words {
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"regexp"
+	"strings"
+
+	"memo/internal/note"
+)
+
+// GrepResult holds the lines of one note that match a pattern
+type GrepResult struct {
+	Note  *note.Note
+	Lines []string
+	// Matches holds the zero-based indexes into Lines that matched
+	Matches []int
+}
+
+// GrepNotes returns, for each note whose content matches pattern, the
+// note's lines and the indexes of the matching ones
+func (fs *FileStorage) GrepNotes(pattern *regexp.Regexp) ([]GrepResult, error) {
+	notes, err := fs.GetAllNotes()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []GrepResult
+	for _, n := range notes {
+		if n.Locked() {
+			continue
+		}
+		lines := strings.Split(n.Content, "\n")
+		var matches []int
+		for i, line := range lines {
+			if pattern.MatchString(line) {
+				matches = append(matches, i)
+			}
+		}
+		if len(matches) > 0 {
+			results = append(results, GrepResult{Note: n, Lines: lines, Matches: matches})
+		}
+	}
+
+	return results, nil
+}
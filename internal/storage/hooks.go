@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"log/slog"
+	"os"
+
+	"memo/internal/hooks"
+	"memo/internal/note"
+)
+
+// runHook runs a pre- hook from the store's .hooks directory with the note
+// as it is about to be written; an error cancels the operation
+func (fs *FileStorage) runHook(hook string, n *note.Note) error {
+	path := hooks.Find(fs.StorePath(hooks.DirName), hook)
+	if path == "" {
+		return nil
+	}
+	content, err := n.Format()
+	if err != nil {
+		return err
+	}
+	return hooks.Run(path, hook, n, content)
+}
+
+// runPostHook runs a post- hook with the note's file content. The operation
+// has already happened, so a failure is only logged.
+func (fs *FileStorage) runPostHook(hook string, n *note.Note) {
+	path := hooks.Find(fs.StorePath(hooks.DirName), hook)
+	if path == "" {
+		return
+	}
+	content, err := n.Format()
+	if data, readErr := os.ReadFile(n.FilePath); readErr == nil {
+		content, err = string(data), nil
+	}
+	if err == nil {
+		err = hooks.Run(path, hook, n, content)
+	}
+	if err != nil {
+		slog.Warn("hook failed", "hook", hook, "note", n.ID(), "error", err)
+	}
+}
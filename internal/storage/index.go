@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"memo/internal/index"
+)
+
+// indexPath is the location of the content index
+func (fs *FileStorage) indexPath() string {
+	return fs.StorePath(index.FileName)
+}
+
+// updateIndex records the current content hash of the given notes, or
+// drops them from the index when their files are gone. A failure only
+// leaves the index stale, which `memo doctor --fix` repairs, so it is logged
+// rather than returned.
+func (fs *FileStorage) updateIndex(noteIDs ...string) {
+	if err := fs.RefreshIndex(noteIDs...); err != nil {
+		slog.Warn("could not update the content index", "error", err)
+	}
+}
+
+// RefreshIndex re-hashes the given notes, or every note when none are
+// given, and saves the index
+func (fs *FileStorage) RefreshIndex(noteIDs ...string) error {
+	fs.indexMu.Lock()
+	defer fs.indexMu.Unlock()
+
+	ix, err := index.Load(fs.indexPath())
+	if err != nil {
+		return err
+	}
+	if ix == nil || len(noteIDs) == 0 {
+		ix = make(index.Index)
+		files, err := fs.NoteFiles()
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			noteIDs = append(noteIDs, strings.TrimSuffix(filepath.Base(file), fs.noteExtension))
+		}
+	}
+
+	for _, id := range noteIDs {
+		entry, err := index.HashFile(fs.GenerateNoteFilePath(id))
+		if os.IsNotExist(err) {
+			delete(ix, id)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		ix[id] = entry
+	}
+	return index.Save(fs.indexPath(), ix)
+}
+
+// IndexChanges compares the note files with the content index. The returned
+// flag is false when there is no index yet, in which case every note would
+// show up as added.
+func (fs *FileStorage) IndexChanges() ([]index.Change, bool, error) {
+	ix, err := index.Load(fs.indexPath())
+	if err != nil || ix == nil {
+		return nil, false, err
+	}
+	files, err := fs.noteFileMap()
+	if err != nil {
+		return nil, true, err
+	}
+	changes, err := ix.Diff(files)
+	return changes, true, err
+}
+
+// LoadIndex returns the content index, building it when it does not exist
+func (fs *FileStorage) LoadIndex() (index.Index, error) {
+	ix, err := index.Load(fs.indexPath())
+	if err != nil || ix != nil {
+		return ix, err
+	}
+	if fs.readOnly {
+		return make(index.Index), nil
+	}
+	if err := fs.RefreshIndex(); err != nil {
+		return nil, err
+	}
+	return index.Load(fs.indexPath())
+}
+
+// noteFileMap returns the note files keyed by note ID
+func (fs *FileStorage) noteFileMap() (map[string]string, error) {
+	files, err := fs.NoteFiles()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]string, len(files))
+	for _, file := range files {
+		byID[strings.TrimSuffix(filepath.Base(file), fs.noteExtension)] = file
+	}
+	return byID, nil
+}
+
+// CurrentIndex returns the content entries of all note files as they are
+// now. Files whose size and modification time match the index are not read.
+func (fs *FileStorage) CurrentIndex() (index.Index, error) {
+	known, err := fs.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	files, err := fs.noteFileMap()
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(index.Index, len(files))
+	for id, path := range files {
+		entry, indexed := known[id]
+		entry, _, err := index.Current(entry, indexed, path)
+		if err != nil {
+			return nil, err
+		}
+		current[id] = entry
+	}
+	return current, nil
+}
+
+// WriteNoteFile replaces the file of a note with data exactly as given, for
+// example a version fetched by `memo sync`. The previous content is kept as
+// a revision.
+func (fs *FileStorage) WriteNoteFile(noteID string, data []byte, modTime time.Time) error {
+	if err := fs.CheckWritable(); err != nil {
+		return err
+	}
+	if err := fs.CheckLock(noteID); err != nil {
+		return err
+	}
+	path, err := fs.notePath(noteID)
+	if err != nil {
+		return err
+	}
+	if fs.skip("write", noteID) {
+		return nil
+	}
+	if err := fs.EnsureNotesDir(); err != nil {
+		return fmt.Errorf("error ensuring notes directory: %w", err)
+	}
+
+	old, err := fs.ParseNote(path)
+	if err == nil {
+		if err := fs.saveRevision(old); err != nil {
+			return err
+		}
+	}
+
+	slog.Debug("writing note file", "path", path)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	if !modTime.IsZero() {
+		os.Chtimes(path, modTime, modTime)
+	}
+	if cur, err := fs.ParseNote(path); err == nil {
+		fs.recordChange(old, cur)
+	}
+	fs.updateIndex(noteID)
+	return nil
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	LocksDirName = ".locks"
+
+	// staleLockAge is how long a lock is honored when its owner cannot be checked
+	staleLockAge = 24 * time.Hour
+)
+
+// Lock records which process is editing a note
+type Lock struct {
+	NoteID string    `yaml:"note"`
+	PID    int       `yaml:"pid"`
+	Host   string    `yaml:"host"`
+	Since  time.Time `yaml:"since"`
+}
+
+// LockedError is returned when a note is locked by another process
+type LockedError struct {
+	Lock Lock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("note '%s' is being edited by process %d on %s since %s (use --force to override)",
+		e.Lock.NoteID, e.Lock.PID, e.Lock.Host, e.Lock.Since.Format("2006-01-02 15:04"))
+}
+
+// SetIgnoreLocks makes writes proceed even when another process holds a lock
+func (fs *FileStorage) SetIgnoreLocks(ignore bool) {
+	fs.ignoreLocks = ignore
+}
+
+func (fs *FileStorage) lockPath(noteID string) string {
+	return filepath.Join(fs.notesDir, LocksDirName, noteID+".lock")
+}
+
+// LockInfo returns the active lock of a note held by another process, or nil
+func (fs *FileStorage) LockInfo(noteID string) (*Lock, error) {
+	data, err := os.ReadFile(fs.lockPath(noteID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading lock: %w", err)
+	}
+
+	var lock Lock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		slog.Warn("ignoring unreadable lock file", "path", fs.lockPath(noteID), "error", err)
+		return nil, nil
+	}
+
+	host, _ := os.Hostname()
+	if lock.Host == host && lock.PID == os.Getpid() {
+		return nil, nil
+	}
+	if isStale(lock, host) {
+		slog.Debug("ignoring stale lock", "note", noteID, "pid", lock.PID)
+		return nil, nil
+	}
+	return &lock, nil
+}
+
+// writeLockFile creates the lock file at path with data, failing with an
+// error matching os.ErrExist when it is already there
+func writeLockFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+func isStale(lock Lock, host string) bool {
+	if time.Since(lock.Since) > staleLockAge {
+		return true
+	}
+	return lock.Host == host && !processAlive(lock.PID)
+}
+
+// CheckLock fails with a LockedError when another process edits the note
+func (fs *FileStorage) CheckLock(noteID string) error {
+	if fs.ignoreLocks {
+		return nil
+	}
+	lock, err := fs.LockInfo(noteID)
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		return &LockedError{Lock: *lock}
+	}
+	return nil
+}
+
+// AcquireLock marks a note as being edited by this process. The returned
+// function releases the lock. The lock file is created only if it does not
+// exist, so of two processes locking a note at once, one gets the lock and
+// the other a LockedError; a stale lock is removed first.
+func (fs *FileStorage) AcquireLock(noteID string) (func(), error) {
+	if err := fs.CheckWritable(); err != nil {
+		return nil, err
+	}
+	if err := ValidateName(noteID); err != nil {
+		return nil, fmt.Errorf("invalid note ID: %w", err)
+	}
+	if err := fs.CheckLock(noteID); err != nil {
+		return nil, err
+	}
+
+	if fs.DryRun() {
+		// Nothing will be written, so there is nothing to guard
+		return func() {}, nil
+	}
+
+	host, _ := os.Hostname()
+	data, err := yaml.Marshal(Lock{NoteID: noteID, PID: os.Getpid(), Host: host, Since: time.Now()})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling lock: %w", err)
+	}
+
+	path := fs.lockPath(noteID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("error creating locks directory: %w", err)
+	}
+	for attempt := 0; ; attempt++ {
+		err := writeLockFile(path, data)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("error writing lock: %w", err)
+		}
+		lock, err := fs.LockInfo(noteID)
+		if err != nil {
+			return nil, err
+		}
+		if lock != nil && (!fs.ignoreLocks || attempt > 0) {
+			return nil, &LockedError{Lock: *lock}
+		}
+		if attempt > 0 {
+			return nil, fmt.Errorf("error writing lock: note '%s' was locked again while its stale lock was removed", noteID)
+		}
+		// The lock is stale, our own or overridden with --force
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("error removing stale lock: %w", err)
+		}
+	}
+	slog.Debug("acquired lock", "note", noteID)
+
+	return func() {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("failed to release lock", "note", noteID, "error", err)
+		}
+	}, nil
+}
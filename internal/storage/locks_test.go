@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// writeTestLock leaves a lock on id as if process pid held it
+func writeTestLock(t *testing.T, fs *FileStorage, id string, pid int, since time.Time) {
+	t.Helper()
+	host, _ := os.Hostname()
+	data, err := yaml.Marshal(Lock{NoteID: id, PID: pid, Host: host, Since: since})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(fs.lockPath(id)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fs.lockPath(id), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireLock(t *testing.T) {
+	// The test's parent process is alive; a PID beyond the usual limits is not
+	const deadPID = 1 << 30
+	tests := []struct {
+		name   string
+		pid    int
+		since  time.Time
+		force  bool
+		locked bool
+	}{
+		{name: "unlocked"},
+		{name: "held", pid: os.Getppid(), since: time.Now(), locked: true},
+		{name: "held, forced", pid: os.Getppid(), since: time.Now(), force: true},
+		{name: "owner gone", pid: deadPID, since: time.Now()},
+		{name: "too old", pid: os.Getppid(), since: time.Now().Add(-2 * staleLockAge)},
+		{name: "own", pid: os.Getpid(), since: time.Now()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newTestStorage(t, "n")
+			fs.SetIgnoreLocks(tt.force)
+			if tt.pid != 0 {
+				writeTestLock(t, fs, "n", tt.pid, tt.since)
+			}
+
+			release, err := fs.AcquireLock("n")
+			var locked *LockedError
+			if tt.locked {
+				if !errors.As(err, &locked) {
+					t.Fatalf("AcquireLock = %v, want a LockedError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AcquireLock = %v", err)
+			}
+			data, err := os.ReadFile(fs.lockPath("n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lock Lock
+			if err := yaml.Unmarshal(data, &lock); err != nil || lock.PID != os.Getpid() {
+				t.Errorf("lock file holds %q, want a lock of this process", data)
+			}
+			release()
+			if _, err := os.Stat(fs.lockPath("n")); !os.IsNotExist(err) {
+				t.Error("the lock was not released")
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Migration outcomes of a note file
+const (
+	MigrateCopied    = "copied"
+	MigrateRenamed   = "renamed"
+	MigrateIdentical = "identical"
+)
+
+// MigratedNote describes what MigrateFrom did with one note file
+type MigratedNote struct {
+	Source string
+	ID     string
+	// NewID differs from ID when the ID was already taken by another note
+	NewID    string
+	Notebook string
+	Outcome  string
+}
+
+// MigrateFrom copies the notes of another store directory, including its
+// notebooks and revision history, into this store. A note whose ID is taken
+// by a note with different content is copied under a new ID; identical
+// notes are skipped. The source directory is left untouched.
+func (fs *FileStorage) MigrateFrom(src string, dryRun bool) ([]MigratedNote, error) {
+	if !dryRun {
+		if err := fs.CheckWritable(); err != nil {
+			return nil, err
+		}
+	}
+	if sameDir(src, fs.notesDir) {
+		return nil, fmt.Errorf("'%s' is already the notes directory", src)
+	}
+	if info, err := os.Stat(src); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("'%s' is not a directory", src)
+	}
+
+	source := NewFileStorageWithConfig(src, fs.noteExtension)
+	source.SetReadOnly(true)
+	files, err := source.NoteFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var migrated []MigratedNote
+	for _, file := range files {
+		m, err := fs.migrateFile(source, file, dryRun)
+		if err != nil {
+			return migrated, fmt.Errorf("error migrating %s: %w", file, err)
+		}
+		migrated = append(migrated, m)
+	}
+	return migrated, nil
+}
+
+func (fs *FileStorage) migrateFile(source *FileStorage, file string, dryRun bool) (MigratedNote, error) {
+	id := strings.TrimSuffix(filepath.Base(file), fs.noteExtension)
+	m := MigratedNote{Source: file, ID: id, NewID: id, Outcome: MigrateCopied}
+	if dir := filepath.Dir(file); !sameDir(dir, source.notesDir) {
+		m.Notebook = filepath.Base(dir)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return m, err
+	}
+	if fs.idTaken(id) {
+		existing, err := os.ReadFile(fs.GenerateNoteFilePath(id))
+		if err == nil && bytes.Equal(existing, data) {
+			m.Outcome = MigrateIdentical
+			return m, nil
+		}
+		m.NewID = fs.freeID(id)
+		m.Outcome = MigrateRenamed
+	}
+	if dryRun {
+		return m, nil
+	}
+
+	if err := fs.EnsureNotesDir(); err != nil {
+		return m, fmt.Errorf("error ensuring notes directory: %w", err)
+	}
+	dir := fs.notesDir
+	if m.Notebook != "" {
+		dir = filepath.Join(fs.notesDir, m.Notebook)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return m, err
+		}
+	}
+	target := filepath.Join(dir, m.NewID+fs.noteExtension)
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return m, err
+	}
+	if info, err := os.Stat(file); err == nil {
+		os.Chtimes(target, info.ModTime(), info.ModTime())
+	}
+	fs.updateIndex(m.NewID)
+
+	return m, copyMissing(source.versionsDir(id), fs.versionsDir(m.NewID))
+}
+
+// freeID returns id with the lowest numeric suffix that makes it unused
+func (fs *FileStorage) freeID(id string) string {
+	taken := fs.takenNames()
+	candidate := id
+	for i := 2; taken[strings.ToLower(candidate+fs.noteExtension)]; i++ {
+		candidate = fmt.Sprintf("%s_%d", id, i)
+	}
+	return candidate
+}
+
+// CopyStoreDir copies the files of an auxiliary directory such as
+// ".templates" from another store, keeping files this store already has.
+// It returns the names of the copied files.
+func (fs *FileStorage) CopyStoreDir(src, name string, dryRun bool) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(src, name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var copied []string
+	for _, e := range entries {
+		target := fs.StorePath(filepath.Join(name, e.Name()))
+		if e.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+		copied = append(copied, filepath.Join(name, e.Name()))
+		if dryRun {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, name, e.Name()), target); err != nil {
+			return copied, err
+		}
+	}
+	return copied, nil
+}
+
+// copyMissing copies the files of src into dst unless dst already exists
+func copyMissing(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, e.Name()), filepath.Join(dst, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile copies src to dst, creating the directory of dst and keeping
+// the file mode, which matters for hooks
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(dst, data, info.Mode().Perm()); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
+
+func sameDir(a, b string) bool {
+	a, errA := filepath.Abs(a)
+	b, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	if resolved, err := filepath.EvalSymlinks(a); err == nil {
+		a = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(b); err == nil {
+		b = resolved
+	}
+	return a == b
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"memo/internal/hooks"
+	"memo/internal/note"
+)
+
+// Notebooks returns the names of the notebooks, the subdirectories of the
+// notes directory that do not start with a dot
+func (fs *FileStorage) Notebooks() ([]string, error) {
+	entries, err := os.ReadDir(fs.notesDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading notes directory: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ValidateNotebook checks that name can be used as a notebook; names
+// starting with a dot are reserved for the store's own directories
+func ValidateNotebook(name string) error {
+	if err := ValidateName(name); err != nil || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid notebook name '%s'", name)
+	}
+	return nil
+}
+
+// NotebookOf returns the notebook holding n, or "" for notes at the top of
+// the notes directory
+func (fs *FileStorage) NotebookOf(n *note.Note) string {
+	return fs.notebookOfPath(n.FilePath)
+}
+
+func (fs *FileStorage) notebookOfPath(path string) string {
+	dir := filepath.Dir(path)
+	if dir == filepath.Clean(fs.notesDir) {
+		return ""
+	}
+	return filepath.Base(dir)
+}
+
+// SetIDPrefixes configures the ID scheme of notebooks. A prefix may contain
+// {notebook}, {year}, {month}, {day} and {date}; new notes in the notebook
+// get the expanded prefix followed by a sequence number.
+func (fs *FileStorage) SetIDPrefixes(prefixes map[string]string) {
+	fs.idPrefixes = prefixes
+}
+
+// CreateNoteIn is CreateNote for a notebook, which is created if needed. An
+// empty notebook creates the note at the top of the notes directory.
+func (fs *FileStorage) CreateNoteIn(n *note.Note, notebook string) (string, error) {
+	if notebook == "" {
+		return fs.CreateNote(n)
+	}
+	if err := ValidateNotebook(notebook); err != nil {
+		return "", err
+	}
+	if err := fs.CheckWritable(); err != nil {
+		return "", err
+	}
+	dir := filepath.Join(fs.notesDir, notebook)
+	if !fs.DryRun() {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("error creating notebook: %w", err)
+		}
+	}
+
+	noteID := fs.GenerateNoteID()
+	if prefix := fs.idPrefixes[notebook]; prefix != "" {
+		var err error
+		if noteID, err = fs.generatePrefixedID(expandIDPrefix(prefix, notebook, time.Now())); err != nil {
+			return "", err
+		}
+	}
+	n.SetFilePath(filepath.Join(dir, noteID+fs.noteExtension))
+
+	if err := fs.saveNote(n, hooks.PreCreate, hooks.PostCreate); err != nil {
+		return "", err
+	}
+	return noteID, nil
+}
+
+// generatePrefixedID returns prefix followed by the lowest sequence number
+// that is not yet used anywhere in the store
+func (fs *FileStorage) generatePrefixedID(prefix string) (string, error) {
+	if err := ValidateName(prefix + "1"); err != nil {
+		return "", fmt.Errorf("invalid ID prefix: %w", err)
+	}
+	taken := fs.takenNames()
+	noteID := prefix + "1"
+	for i := 2; taken[strings.ToLower(noteID+fs.noteExtension)]; i++ {
+		noteID = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return noteID, nil
+}
+
+func expandIDPrefix(prefix, notebook string, now time.Time) string {
+	return strings.NewReplacer(
+		"{notebook}", notebook,
+		"{year}", now.Format("2006"),
+		"{month}", now.Format("01"),
+		"{day}", now.Format("02"),
+		"{date}", now.Format("2006-01-02"),
+	).Replace(prefix)
+}
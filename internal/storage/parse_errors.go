@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+)
+
+// ParseError is a note file that could not be parsed while loading notes
+type ParseError struct {
+	Path string
+	Err  error
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors returns the note files that failed to parse in any load or
+// search so far, sorted by path
+func (fs *FileStorage) ParseErrors() []ParseError {
+	fs.parseMu.Lock()
+	defer fs.parseMu.Unlock()
+
+	errs := make([]ParseError, 0, len(fs.parseErrors))
+	for path, err := range fs.parseErrors {
+		errs = append(errs, ParseError{Path: path, Err: err})
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs
+}
+
+// recordParseError remembers a file that failed to parse; the details are
+// only logged with --verbose
+func (fs *FileStorage) recordParseError(path string, err error) {
+	slog.Debug("failed to parse note", "path", path, "error", err)
+
+	fs.parseMu.Lock()
+	defer fs.parseMu.Unlock()
+	if fs.parseErrors == nil {
+		fs.parseErrors = make(map[string]error)
+	}
+	fs.parseErrors[path] = err
+}
+
+// warnParseErrors prints one summary of the files that failed to parse.
+// Loading the same broken files again stays quiet; new failures warn anew.
+func (fs *FileStorage) warnParseErrors() {
+	fs.parseMu.Lock()
+	defer fs.parseMu.Unlock()
+
+	if count := len(fs.parseErrors); count > fs.parseErrorsWarned {
+		fs.parseErrorsWarned = count
+		slog.Warn(fmt.Sprintf("%d note(s) failed to parse; run 'memo doctor' for details", count))
+	}
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidName is returned for note IDs and store file names that are not
+// safe to use as file names on every supported platform
+var ErrInvalidName = errors.New("invalid name")
+
+// maxNameLength leaves room for the extension and revision suffixes within
+// the common 255 byte file name limit
+const maxNameLength = 200
+
+// windowsReservedChars cannot appear in file names on Windows
+const windowsReservedChars = `<>:"/\|?*`
+
+// windowsReservedNames are device names Windows refuses as file names, with
+// or without an extension
+var windowsReservedNames = map[string]bool{
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true,
+	"com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true,
+	"lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// ValidateName checks that name can be used as a file name inside the store
+// on Windows, macOS and Linux alike. It rejects path separators, so a name
+// can never point outside the store.
+func ValidateName(name string) error {
+	if name == "" || name == "." || name == ".." {
+		return fmt.Errorf("%w: '%s'", ErrInvalidName, name)
+	}
+	if len(name) > maxNameLength {
+		return fmt.Errorf("%w: '%s' is longer than %d bytes", ErrInvalidName, name, maxNameLength)
+	}
+	for _, r := range name {
+		if strings.ContainsRune(windowsReservedChars, r) || unicode.IsControl(r) {
+			return fmt.Errorf("%w: '%s' contains '%c'", ErrInvalidName, name, r)
+		}
+	}
+	if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return fmt.Errorf("%w: '%s' ends with a dot or space", ErrInvalidName, name)
+	}
+	base, _, _ := strings.Cut(strings.ToLower(name), ".")
+	if windowsReservedNames[strings.TrimSpace(base)] {
+		return fmt.Errorf("%w: '%s' is reserved on Windows", ErrInvalidName, name)
+	}
+	return nil
+}
+
+// notePath returns the file of a note after checking that its ID is a safe
+// file name
+func (fs *FileStorage) notePath(noteID string) (string, error) {
+	if err := ValidateName(noteID); err != nil {
+		return "", fmt.Errorf("invalid note ID: %w", err)
+	}
+	return fs.GenerateNoteFilePath(noteID), nil
+}
+
+// idTaken reports whether a note ID is in use, ignoring case, since notes
+// whose IDs differ only by case collide on Windows and macOS
+func (fs *FileStorage) idTaken(noteID string) bool {
+	return fs.takenNames()[strings.ToLower(noteID+fs.noteExtension)]
+}
+
+// takenNames returns the lowercased names of the files in the notes
+// directory and its notebooks; note IDs are unique across notebooks
+func (fs *FileStorage) takenNames() map[string]bool {
+	entries, err := os.ReadDir(fs.notesDir)
+	if err != nil {
+		return nil
+	}
+	names := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		names[strings.ToLower(e.Name())] = true
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+			inner, _ := os.ReadDir(filepath.Join(fs.notesDir, e.Name()))
+			for _, f := range inner {
+				names[strings.ToLower(f.Name())] = true
+			}
+		}
+	}
+	return names
+}
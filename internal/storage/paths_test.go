@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"note_1700000000", true},
+		{"meeting-notes", true},
+		{"notes.2024", true},
+		{"café", true},
+		{"", false},
+		{".", false},
+		{"..", false},
+		{"con", false},
+		{"CON", false},
+		{"con.txt", false},
+		{"nul.tar.gz", false},
+		{"LPT1.txt", false},
+		{"com9", false},
+		{"aux.log", false},
+		{"console", true},
+		{"lpt10", true},
+		{"trailing.", false},
+		{"trailing ", false},
+		{"tab\there", false},
+		{"nul\x00byte", false},
+		{"line\nbreak", false},
+		{"del\x7f", false},
+		{strings.Repeat("a", maxNameLength), true},
+		{strings.Repeat("a", maxNameLength+1), false},
+		{strings.Repeat("é", maxNameLength/2), true},
+		{strings.Repeat("é", maxNameLength/2) + "a", false},
+	}
+	for _, c := range windowsReservedChars {
+		tests = append(tests, struct {
+			name  string
+			valid bool
+		}{fmt.Sprintf("a%cb", c), false})
+	}
+
+	for _, tt := range tests {
+		err := ValidateName(tt.name)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateName(%q) = %v, want valid %v", tt.name, err, tt.valid)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidName) {
+			t.Errorf("ValidateName(%q) = %v, want ErrInvalidName", tt.name, err)
+		}
+	}
+}
+
+// newTestStorage returns a store in a temporary directory holding empty
+// notes with the given IDs
+func newTestStorage(t *testing.T, ids ...string) *FileStorage {
+	t.Helper()
+	dir := t.TempDir()
+	for _, id := range ids {
+		if err := os.WriteFile(filepath.Join(dir, id+DefaultNoteExtension), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewFileStorageWithConfig(dir, DefaultNoteExtension)
+}
+
+func TestIDTaken(t *testing.T) {
+	fs := newTestStorage(t, "Meeting", "lower")
+	if err := os.MkdirAll(filepath.Join(fs.NotesDir(), "work"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(fs.NotesDir(), "work", "InNotebook"+DefaultNoteExtension), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id    string
+		taken bool
+	}{
+		{"Meeting", true},
+		{"meeting", true},
+		{"MEETING", true},
+		{"LOWER", true},
+		{"innotebook", true},
+		{"other", false},
+		{"Meeting2", false},
+	}
+	for _, tt := range tests {
+		if got := fs.idTaken(tt.id); got != tt.taken {
+			t.Errorf("idTaken(%q) = %v, want %v", tt.id, got, tt.taken)
+		}
+	}
+}
+
+func TestGenerateNoteIDAvoidsCaseCollisions(t *testing.T) {
+	// The ID depends on the clock, so every second the test may run in is
+	// taken, in upper case
+	now := time.Now().Unix()
+	var taken []string
+	for s := now; s <= now+2; s++ {
+		taken = append(taken, fmt.Sprintf("NOTE_%d", s))
+	}
+	fs := newTestStorage(t, taken...)
+
+	id := fs.GenerateNoteID()
+	if fs.idTaken(id) {
+		t.Errorf("GenerateNoteID() = %q, which collides with an existing note", id)
+	}
+	if !strings.HasPrefix(id, "note_") || !strings.Contains(strings.TrimPrefix(id, "note_"), "_") {
+		t.Errorf("GenerateNoteID() = %q, want a numbered ID", id)
+	}
+}
+
+func TestRenameNoteFileRejectsCaseCollisions(t *testing.T) {
+	fs := newTestStorage(t, "first", "Second")
+
+	for _, newID := range []string{"second", "SECOND", "Second"} {
+		if err := fs.RenameNoteFile("first", newID); err == nil {
+			t.Errorf("RenameNoteFile(first, %q) succeeded, want a collision error", newID)
+		}
+	}
+	if !fs.NoteExists("first") || !fs.NoteExists("Second") {
+		t.Fatal("a refused rename changed the notes")
+	}
+
+	if err := fs.RenameNoteFile("first", "con"); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("RenameNoteFile(first, con) = %v, want ErrInvalidName", err)
+	}
+	if err := fs.RenameNoteFile("first", "third"); err != nil {
+		t.Errorf("RenameNoteFile(first, third) = %v", err)
+	}
+	if !fs.NoteExists("third") || fs.NoteExists("first") {
+		t.Error("RenameNoteFile(first, third) did not move the note")
+	}
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"memo/internal/note"
+)
+
+// fieldTerm matches "field:value" search terms, with an optional comparison
+// operator before the value as in "priority:>=4"
+var fieldTerm = regexp.MustCompile(`^([A-Za-z_][\w-]*):(>=|<=|!=|>|<|=)?(.+)$`)
+
+// FieldFilter restricts a search to notes whose front matter field compares
+// to Value with Op
+type FieldFilter struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// Match reports whether n passes the filter
+func (f FieldFilter) Match(n *note.Note) bool {
+	return n.CompareField(f.Field, f.Op, f.Value)
+}
+
+// Query is a parsed search: text terms plus field filters. A query such as
+// `standup "action items" priority:>=4` looks for "standup" and the phrase
+// "action items" in notes with a priority of at least 4.
+type Query struct {
+	Terms   []string
+	Filters []FieldFilter
+}
+
+// ParseQuery splits query into field filters and text terms. Double quotes
+// group words into a single phrase term, which is never taken as a field
+// filter.
+func ParseQuery(query string) Query {
+	var q Query
+	for _, word := range splitQuery(query) {
+		if f, ok := filterTerm(word.text); ok && !word.quoted {
+			q.Filters = append(q.Filters, f)
+		} else {
+			q.Terms = append(q.Terms, word.text)
+		}
+	}
+	return q
+}
+
+// filterTerm parses word as a field filter. Built-in fields filter as in
+// "status:active"; custom fields need an operator, as in "project:=alpha",
+// so that text such as "TODO:fix" or a URL stays a search term.
+func filterTerm(word string) (FieldFilter, bool) {
+	m := fieldTerm.FindStringSubmatch(word)
+	if m == nil || m[2] == "" && !note.IsMetadataField(m[1]) {
+		return FieldFilter{}, false
+	}
+	op := m[2]
+	if op == "" {
+		op = "="
+	}
+	return FieldFilter{Field: m[1], Op: op, Value: m[3]}, true
+}
+
+type queryWord struct {
+	text   string
+	quoted bool
+}
+
+// splitQuery splits query at spaces outside double quotes. An unterminated
+// quote runs to the end of the query.
+func splitQuery(query string) []queryWord {
+	var words []queryWord
+	var current strings.Builder
+	quoted, inQuotes := false, false
+	flush := func() {
+		if text := strings.TrimSpace(current.String()); text != "" {
+			words = append(words, queryWord{text: text, quoted: quoted})
+		}
+		current.Reset()
+		quoted = false
+	}
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			if inQuotes {
+				flush()
+			} else {
+				flush()
+				quoted = true
+			}
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return words
+}
+
+// QuoteTerms joins command line arguments into a query, quoting arguments
+// that contain spaces so the shell's grouping survives. Field filters in an
+// argument, as in "priority:>=4 status:active", stay filters; only the rest
+// of its words are quoted.
+func QuoteTerms(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsFunc(arg, unicode.IsSpace) && !strings.Contains(arg, `"`) {
+			arg = quoteText(arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// quoteText turns text into query words: its field filters as they are and
+// its other words as one quoted phrase
+func quoteText(text string) string {
+	var filters, words []string
+	for _, word := range strings.Fields(text) {
+		if _, ok := filterTerm(word); ok {
+			filters = append(filters, word)
+		} else {
+			words = append(words, word)
+		}
+	}
+	switch {
+	case len(words) > 1:
+		filters = append(filters, `"`+strings.Join(words, " ")+`"`)
+	case len(words) == 1:
+		filters = append(filters, words[0])
+	}
+	return strings.Join(filters, " ")
+}
+
+// Match reports whether n passes every filter
+func (q Query) Match(n *note.Note) bool {
+	for _, f := range q.Filters {
+		if !f.Match(n) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		terms   []string
+		filters []FieldFilter
+	}{
+		{query: "https://example.com/docs", terms: []string{"https://example.com/docs"}},
+		{query: "TODO:fix parser", terms: []string{"TODO:fix", "parser"}},
+		{query: "status:done", filters: []FieldFilter{{Field: "status", Op: "=", Value: "done"}}},
+		{query: "Priority:>=4", filters: []FieldFilter{{Field: "Priority", Op: ">=", Value: "4"}}},
+		{query: "project:=alpha", filters: []FieldFilter{{Field: "project", Op: "=", Value: "alpha"}}},
+		{query: `"status:done" later`, terms: []string{"status:done", "later"}},
+	}
+	for _, tt := range tests {
+		q := ParseQuery(tt.query)
+		if !slices.Equal(q.Terms, tt.terms) || !slices.Equal(q.Filters, tt.filters) {
+			t.Errorf("ParseQuery(%q) = %q %v, want %q %v", tt.query, q.Terms, q.Filters, tt.terms, tt.filters)
+		}
+	}
+}
+
+func TestQuoteTerms(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"meeting", "notes"}, "meeting notes"},
+		{[]string{"meeting notes"}, `"meeting notes"`},
+		{[]string{"priority:>=4 status:active"}, "priority:>=4 status:active"},
+		{[]string{"priority:>=4", "status:active"}, "priority:>=4 status:active"},
+		{[]string{"meeting notes status:active"}, `status:active "meeting notes"`},
+		{[]string{"status:active meeting"}, "status:active meeting"},
+		{[]string{`"already quoted" text`}, `"already quoted" text`},
+		{[]string{"see https://example.com/docs"}, `"see https://example.com/docs"`},
+		{[]string{"TODO:fix the parser"}, `"TODO:fix the parser"`},
+	}
+	for _, tt := range tests {
+		if got := QuoteTerms(tt.args); got != tt.want {
+			t.Errorf("QuoteTerms(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"memo/internal/audit"
+)
+
+// ErrNotRevertible is returned by RevertChange for changes that left
+// nothing to go back to
+var ErrNotRevertible = errors.New("the change cannot be reverted")
+
+// CurrentID follows the renames recorded after entry i of the audit log
+// and returns the ID the note of that entry has now
+func CurrentID(log []audit.Entry, i int) string {
+	id := log[i].ID
+	if log[i].Op == audit.OpRename {
+		id = log[i].NewID
+	}
+	for _, e := range log[i+1:] {
+		if e.Op == audit.OpRename && e.ID == id {
+			id = e.NewID
+		}
+	}
+	return id
+}
+
+// LaterChanges counts the changes recorded for the note of entry i after it
+func LaterChanges(log []audit.Entry, i int) int {
+	id := log[i].ID
+	if log[i].Op == audit.OpRename {
+		id = log[i].NewID
+	}
+	count := 0
+	for _, e := range log[i+1:] {
+		if e.ID == id {
+			count++
+			if e.Op == audit.OpRename {
+				id = e.NewID
+			}
+		}
+	}
+	return count
+}
+
+// RevertChange undoes the change recorded in entry i of the audit log:
+// a created note is deleted, a deleted one restored from the trash, a
+// renamed one gets its old ID back and an edited one the revision saved
+// before the edit, or else its old front matter. Later changes to the
+// same note are undone along with an edit. The revert is recorded like any
+// other change, so reverting it redoes the original one. It returns the
+// ID the note has afterwards.
+func (fs *FileStorage) RevertChange(log []audit.Entry, i int) (string, error) {
+	e := log[i]
+	id := CurrentID(log, i)
+
+	switch e.Op {
+	case audit.OpCreate:
+		if err := fs.DeleteNote(id); err != nil {
+			return "", err
+		}
+		return id, nil
+	case audit.OpDelete:
+		if _, err := fs.RestoreNote(e.ID); err != nil {
+			return "", err
+		}
+		return e.ID, nil
+	case audit.OpRename:
+		if err := fs.RenameNoteFile(id, e.ID); err != nil {
+			return "", err
+		}
+		return e.ID, nil
+	case audit.OpRepair:
+		return "", fmt.Errorf("%w: repairs by memo doctor keep no earlier version", ErrNotRevertible)
+	case audit.OpRollback:
+		return "", fmt.Errorf("%w: a rollback undid an interrupted operation; run that command again instead", ErrNotRevertible)
+	}
+
+	n, err := fs.FindNoteByID(id)
+	if err != nil {
+		return "", err
+	}
+
+	// The revision saved by the edit is the newest one written after the
+	// note's previous change and before the edit was recorded
+	var previous audit.Entry
+	for j := i - 1; j >= 0; j-- {
+		if log[j].ID == e.ID || log[j].NewID == e.ID {
+			previous = log[j]
+			break
+		}
+	}
+	revisions, err := fs.ListRevisions(id)
+	if err != nil {
+		return "", err
+	}
+	for _, rev := range revisions {
+		if rev.Saved.After(e.Time) {
+			continue
+		}
+		if !rev.Saved.After(previous.Time) {
+			break
+		}
+		if _, err := fs.RollbackNote(id, rev.Number); err != nil {
+			return "", err
+		}
+		return id, nil
+	}
+
+	// Without the revision, changes to the front matter alone can still be
+	// undone from the log
+	if e.ContentChanged || e.Old == nil {
+		return "", fmt.Errorf("%w: no revision of the note from before the change is kept", ErrNotRevertible)
+	}
+	for _, ch := range e.Changes() {
+		if err := n.SetMetadata(ch.Field, ch.Old); err != nil {
+			return "", fmt.Errorf("%w: %v", ErrNotRevertible, err)
+		}
+	}
+	if err := fs.SaveNote(n); err != nil {
+		return "", err
+	}
+	return id, nil
+}
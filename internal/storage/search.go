@@ -0,0 +1,249 @@
+package storage
+
+import (
+	"context"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"memo/internal/note"
+)
+
+// SearchOptions controls how SearchNotesWithOptions matches the query
+type SearchOptions struct {
+	CaseSensitive bool
+	WholeWord     bool
+	// MatchAny finds notes containing any of the terms instead of all
+	MatchAny bool
+	// Limit stops the search once this many notes matched; 0 finds all
+	Limit int
+	// Stem matches words by their stems in each note's language, so
+	// "running" finds "run", ignoring accents and stop words in the query.
+	// CaseSensitive and WholeWord do not apply then.
+	Stem bool
+}
+
+// Pattern compiles query into the regular expression used for matching
+func (o SearchOptions) Pattern(query string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(query)
+	if o.WholeWord {
+		// \b only applies next to word characters, so "c++" still matches
+		if first, _ := utf8.DecodeRuneInString(query); isWordRune(first) {
+			expr = `\b` + expr
+		}
+		if last, _ := utf8.DecodeLastRuneInString(query); isWordRune(last) {
+			expr += `\b`
+		}
+	}
+	if !o.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	return regexp.MustCompile(expr)
+}
+
+// TermsPattern compiles the text terms of query, leaving out field
+// filters, into one expression matching any of them; nil when query has no
+// text terms. It is used to show and highlight where notes matched.
+func (o SearchOptions) TermsPattern(query string) *regexp.Regexp {
+	terms := ParseQuery(query).Terms
+	if len(terms) == 0 {
+		return nil
+	}
+	exprs := make([]string, len(terms))
+	for i, term := range terms {
+		if o.Stem {
+			exprs[i] = "(?:" + stemPattern(term) + ")"
+		} else {
+			exprs[i] = "(?:" + o.Pattern(term).String() + ")"
+		}
+	}
+	return regexp.MustCompile(strings.Join(exprs, "|"))
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || ('0' <= r && r <= '9') || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
+}
+
+// matcher returns the predicate deciding whether a note matches query
+func matcher(query string, opts SearchOptions) func(n *note.Note) bool {
+	q := ParseQuery(query)
+	contains := patternsContained(q.Terms, opts)
+	if opts.Stem {
+		contains = stemsContained(q.Terms)
+	}
+
+	return func(n *note.Note) bool {
+		if !q.Match(n) {
+			return false
+		}
+		if len(q.Terms) == 0 {
+			return true
+		}
+		found := contains(n)
+		for i := range q.Terms {
+			if found(i) == opts.MatchAny {
+				return opts.MatchAny
+			}
+		}
+		return !opts.MatchAny
+	}
+}
+
+// patternsContained returns, for a note, whether it contains each of
+// terms by the patterns opts compiles them into
+func patternsContained(terms []string, opts SearchOptions) func(n *note.Note) func(i int) bool {
+	patterns := make([]*regexp.Regexp, len(terms))
+	for i, term := range terms {
+		patterns[i] = opts.Pattern(term)
+	}
+	return func(n *note.Note) func(i int) bool {
+		return func(i int) bool { return containsPattern(n, patterns[i]) }
+	}
+}
+
+// containsPattern reports whether the title, content or a tag of n matches
+// pattern. Encrypted content is never searched; only its title and tags.
+func containsPattern(n *note.Note, pattern *regexp.Regexp) bool {
+	if pattern.MatchString(n.Metadata.Title) ||
+		(!n.Locked() && pattern.MatchString(n.Content)) {
+		return true
+	}
+	for _, tag := range n.Metadata.Tags {
+		if pattern.MatchString(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchNotes finds notes whose title, content or tags contain every term
+// of query, ignoring case. Terms like "status:active" filter on fields; see
+// ParseQuery.
+func (fs *FileStorage) SearchNotes(query string) ([]*note.Note, error) {
+	return fs.SearchNotesWithOptions(query, SearchOptions{})
+}
+
+// SearchNotesWithOptions is SearchNotes with control over case, word
+// boundaries and the number of results. Matches are returned in file order;
+// with a limit they are the first ones found, which need not be the first
+// ones in that order.
+func (fs *FileStorage) SearchNotesWithOptions(query string, opts SearchOptions) ([]*note.Note, error) {
+	files, err := fs.NoteFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	type indexed struct {
+		index int
+		note  *note.Note
+	}
+	var matches []indexed
+	fs.scan(context.Background(), files, matcher(query, opts), func(index int, n *note.Note) bool {
+		matches = append(matches, indexed{index, n})
+		return opts.Limit == 0 || len(matches) < opts.Limit
+	})
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].index < matches[j].index })
+	notes := make([]*note.Note, len(matches))
+	for i, m := range matches {
+		notes[i] = m.note
+	}
+	return notes, nil
+}
+
+// SearchStream sends matching notes over the returned channel as parallel
+// workers find them. The channel is closed when the search is complete, the
+// limit is reached or ctx is cancelled.
+func (fs *FileStorage) SearchStream(ctx context.Context, query string, opts SearchOptions) (<-chan *note.Note, error) {
+	files, err := fs.NoteFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *note.Note)
+	go func() {
+		defer close(out)
+		found := 0
+		fs.scan(ctx, files, matcher(query, opts), func(_ int, n *note.Note) bool {
+			select {
+			case out <- n:
+			case <-ctx.Done():
+				return false
+			}
+			found++
+			return opts.Limit == 0 || found < opts.Limit
+		})
+	}()
+	return out, nil
+}
+
+// scanResult is a file handed from a search worker back to scan
+type scanResult struct {
+	index   int
+	note    *note.Note
+	matched bool
+}
+
+// scan parses files on one worker per CPU and calls visit, always from the
+// calling goroutine, with each note that match accepts. Once visit returns
+// false or ctx is cancelled, no further files are read.
+func (fs *FileStorage) scan(ctx context.Context, files []string, match func(*note.Note) bool, visit func(index int, n *note.Note) bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	results := make(chan scanResult)
+
+	go func() {
+		defer close(jobs)
+		for i := range files {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range min(runtime.NumCPU(), max(len(files), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				n, err := fs.ParseNote(files[i])
+				if err != nil {
+					fs.recordParseError(files[i], err)
+				}
+				r := scanResult{index: i, note: n, matched: err == nil && match(n)}
+				select {
+				case results <- r:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	done := 0
+	for r := range results {
+		done++
+		fs.reportProgress(done, len(files))
+		if r.matched && !visit(r.index, r.note) {
+			cancel()
+			break
+		}
+	}
+	fs.reportProgress(len(files), len(files))
+	fs.warnParseErrors()
+	// Let the workers see the cancellation and exit
+	for range results {
+	}
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"regexp"
+	"strings"
+
+	"memo/internal/language"
+	"memo/internal/note"
+)
+
+// defaultLanguage stems notes whose language is neither set nor detected
+const defaultLanguage = "en"
+
+// stemmedTerm holds the stems of the words of a search term in each
+// language, leaving out stop words unless the term has nothing else
+type stemmedTerm map[string][]string
+
+func stemTerm(term string) stemmedTerm {
+	words := language.Words(term)
+	st := make(stemmedTerm)
+	for _, code := range language.Codes() {
+		var stems []string
+		for _, w := range words {
+			if !language.Stopword(w, code) {
+				stems = append(stems, language.Stem(w, code))
+			}
+		}
+		if len(stems) == 0 {
+			for _, w := range words {
+				stems = append(stems, language.Stem(w, code))
+			}
+		}
+		st[code] = stems
+	}
+	return st
+}
+
+// noteStems returns the language of n and the stems of the words of its
+// title, tags and, unless it is encrypted, content in that language
+func noteStems(n *note.Note) (string, map[string]bool) {
+	code := n.Language()
+	if !language.Known(code) {
+		code = defaultLanguage
+	}
+	text := n.Metadata.Title + " " + strings.Join(n.Metadata.Tags, " ")
+	if !n.Locked() {
+		text += " " + n.Content
+	}
+	stems := make(map[string]bool)
+	for _, w := range language.Words(text) {
+		stems[language.Stem(w, code)] = true
+	}
+	return code, stems
+}
+
+// stemsContained returns, for a note, whether it contains each of terms
+// by the stems of their words in the note's language
+func stemsContained(terms []string) func(n *note.Note) func(i int) bool {
+	stemmed := make([]stemmedTerm, len(terms))
+	for i, term := range terms {
+		stemmed[i] = stemTerm(term)
+	}
+	return func(n *note.Note) func(i int) bool {
+		code, stems := noteStems(n)
+		return func(i int) bool {
+			for _, stem := range stemmed[i][code] {
+				if !stems[stem] {
+					return false
+				}
+			}
+			return true
+		}
+	}
+}
+
+// stemPattern matches the words of term starting with their English stems,
+// to show where a stemmed search matched
+func stemPattern(term string) string {
+	stems := stemTerm(term)[defaultLanguage]
+	exprs := make([]string, len(stems))
+	for i, stem := range stems {
+		exprs[i] = `\b` + regexp.QuoteMeta(stem) + `\w*`
+	}
+	return "(?i)" + strings.Join(exprs, "|")
+}
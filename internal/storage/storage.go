@@ -1,14 +1,23 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
+	"memo/internal/audit"
+	"memo/internal/hooks"
 	"memo/internal/note"
+	"memo/internal/schema"
+	"memo/internal/trash"
 )
 
 const (
@@ -16,15 +25,45 @@ const (
 	DefaultNoteExtension = ".note"
 )
 
+// ErrReadOnly is returned by write operations on a read-only store
+var ErrReadOnly = errors.New("notes store is read-only")
+
 type FileStorage struct {
 	notesDir      string
 	noteExtension string
+	maxRevisions  int
+	readOnly      bool
+	ignoreLocks   bool
+	progress      ProgressFunc
+	dryRun        DryRunFunc
+	lint          LintFunc
+	indexMu       sync.Mutex
+	refsMu        sync.Mutex
+	trashMu       sync.Mutex
+	idPrefixes    map[string]string
+	mergeHashtags bool
+	sizeLimits    note.SizeLimits
+	constraints   map[string]note.Constraints
+	trash         *trash.Policy
+
+	// tx is the open transaction, whose journal txMu guards
+	tx   *Transaction
+	txMu sync.Mutex
+
+	parseMu           sync.Mutex
+	parseErrors       map[string]error
+	parseErrorsWarned int
 }
 
+// ProgressFunc is told how many of total items an operation has processed
+type ProgressFunc func(done, total int)
+
 func NewFileStorage() *FileStorage {
 	return &FileStorage{
 		notesDir:      DefaultNotesDir,
 		noteExtension: DefaultNoteExtension,
+		maxRevisions:  DefaultMaxRevisions,
+		sizeLimits:    note.DefaultSizeLimits,
 	}
 }
 
@@ -32,22 +71,159 @@ func NewFileStorageWithConfig(notesDir, noteExtension string) *FileStorage {
 	return &FileStorage{
 		notesDir:      notesDir,
 		noteExtension: noteExtension,
+		maxRevisions:  DefaultMaxRevisions,
+		sizeLimits:    note.DefaultSizeLimits,
+	}
+}
+
+// SetProgress installs a callback for reporting progress while loading
+// notes; nil disables reporting
+func (fs *FileStorage) SetProgress(fn ProgressFunc) {
+	fs.progress = fn
+}
+
+func (fs *FileStorage) reportProgress(done, total int) {
+	if fs.progress != nil {
+		fs.progress(done, total)
+	}
+}
+
+// LintFunc checks a note before it is saved; an error stops the save
+type LintFunc func(n *note.Note) error
+
+// SetLint installs a check run on every note before it is saved
+func (fs *FileStorage) SetLint(fn LintFunc) {
+	fs.lint = fn
+}
+
+// SetMergeHashtags makes saving a note add the #hashtags in its content to
+// its tags
+func (fs *FileStorage) SetMergeHashtags(merge bool) {
+	fs.mergeHashtags = merge
+}
+
+// SetSizeLimits sets when a note counts as large
+func (fs *FileStorage) SetSizeLimits(limits note.SizeLimits) {
+	fs.sizeLimits = limits
+}
+
+// SizeLimits returns the limits set with SetSizeLimits
+func (fs *FileStorage) SizeLimits() note.SizeLimits {
+	return fs.sizeLimits
+}
+
+// SetConstraints sets the metadata rules saved notes must meet, by notebook;
+// the rules for "" apply to notebooks without rules of their own
+func (fs *FileStorage) SetConstraints(constraints map[string]note.Constraints) {
+	fs.constraints = constraints
+}
+
+// checkConstraints returns an error when n breaks the metadata rules of its
+// notebook
+func (fs *FileStorage) checkConstraints(n *note.Note) error {
+	c, ok := fs.constraints[fs.notebookOfPath(n.FilePath)]
+	if !ok {
+		c = fs.constraints[""]
+	}
+	return c.Check(n)
+}
+
+// NotesDir returns the directory holding the notes
+func (fs *FileStorage) NotesDir() string {
+	return fs.notesDir
+}
+
+// NoteExtension returns the file extension of note files, such as ".note"
+func (fs *FileStorage) NoteExtension() string {
+	return fs.noteExtension
+}
+
+// StorePath returns the path of an auxiliary file or directory kept inside
+// the notes directory, such as ".templates"
+func (fs *FileStorage) StorePath(name string) string {
+	return filepath.Join(fs.notesDir, name)
+}
+
+// SetReadOnly disables every operation that would modify the store
+func (fs *FileStorage) SetReadOnly(readOnly bool) {
+	fs.readOnly = readOnly
+}
+
+// ReadOnly reports whether the store rejects modifications
+func (fs *FileStorage) ReadOnly() bool {
+	return fs.readOnly
+}
+
+// SchemaVersion returns the front matter schema version of the store
+func (fs *FileStorage) SchemaVersion() (int, error) {
+	return schema.Load(fs.StorePath(schema.FileName))
+}
+
+// SetSchemaVersion records that every note in the store is in the layout
+// of version
+func (fs *FileStorage) SetSchemaVersion(version int) error {
+	if err := fs.CheckWritable(); err != nil {
+		return err
 	}
+	if fs.skip("schema", strconv.Itoa(version)) {
+		return nil
+	}
+	return schema.Save(fs.StorePath(schema.FileName), version)
+}
+
+// CheckWritable returns ErrReadOnly when the store may not be modified.
+// Commands that write auxiliary files into the store call it first.
+func (fs *FileStorage) CheckWritable() error {
+	if fs.readOnly {
+		return ErrReadOnly
+	}
+	return nil
 }
 
 func (fs *FileStorage) EnsureNotesDir() error {
 	if _, err := os.Stat(fs.notesDir); os.IsNotExist(err) {
-		return os.MkdirAll(fs.notesDir, 0755)
+		if fs.readOnly {
+			// Nothing to read; never create the directory
+			return nil
+		}
+		slog.Debug("creating notes directory", "dir", fs.notesDir)
+		if err := os.MkdirAll(fs.notesDir, 0755); err != nil {
+			return err
+		}
+		// A new store starts out in the current layout
+		return schema.Save(fs.StorePath(schema.FileName), schema.Current())
 	}
 	return nil
 }
 
+// GenerateNoteID returns a timestamp-based ID that is not yet used in the store
 func (fs *FileStorage) GenerateNoteID() string {
-	return fmt.Sprintf("note_%d", time.Now().Unix())
+	base := fmt.Sprintf("note_%d", time.Now().Unix())
+	noteID := base
+	// Read the directory once; bulk imports create many notes per second
+	taken := fs.takenNames()
+	for i := 2; taken[strings.ToLower(noteID+fs.noteExtension)]; i++ {
+		noteID = fmt.Sprintf("%s_%d", base, i)
+	}
+	return noteID
 }
 
+// GenerateNoteFilePath returns the file of a note. An existing note is found
+// in whichever notebook holds it; otherwise the path is at the top of the
+// notes directory.
 func (fs *FileStorage) GenerateNoteFilePath(noteID string) string {
-	return filepath.Join(fs.notesDir, noteID+fs.noteExtension)
+	path := filepath.Join(fs.notesDir, noteID+fs.noteExtension)
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	notebooks, _ := fs.Notebooks()
+	for _, notebook := range notebooks {
+		candidate := filepath.Join(fs.notesDir, notebook, noteID+fs.noteExtension)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return path
 }
 
 func (fs *FileStorage) ParseNote(filePath string) (*note.Note, error) {
@@ -56,8 +232,16 @@ func (fs *FileStorage) ParseNote(filePath string) (*note.Note, error) {
 		return nil, fmt.Errorf("error reading file: %w", err)
 	}
 
-	contentStr := string(content)
+	n, err := ParseNoteContent(string(content))
+	if err != nil {
+		return nil, err
+	}
+	n.SetFilePath(filePath)
+	return n, nil
+}
 
+// ParseNoteContent parses note text consisting of YAML front matter and content
+func ParseNoteContent(contentStr string) (*note.Note, error) {
 	if !strings.HasPrefix(contentStr, "---\n") {
 		return nil, fmt.Errorf("note file must start with YAML front matter")
 	}
@@ -71,7 +255,7 @@ func (fs *FileStorage) ParseNote(filePath string) (*note.Note, error) {
 	noteContent := strings.Join(parts[1:], "\n---\n")
 
 	var metadata note.Metadata
-	err = yaml.Unmarshal([]byte(yamlContent), &metadata)
+	err := yaml.Unmarshal([]byte(yamlContent), &metadata)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing YAML metadata: %w", err)
 	}
@@ -79,21 +263,89 @@ func (fs *FileStorage) ParseNote(filePath string) (*note.Note, error) {
 	n := &note.Note{
 		Metadata: metadata,
 		Content:  strings.TrimSpace(noteContent),
-		FilePath: filePath,
 	}
 
 	return n, nil
 }
 
+// SaveNote writes an existing note, running the pre-save and post-save hooks
 func (fs *FileStorage) SaveNote(n *note.Note) error {
+	return fs.saveNote(n, hooks.PreSave, hooks.PostSave)
+}
+
+func (fs *FileStorage) saveNote(n *note.Note, preHook, postHook string) error {
+	if err := fs.CheckWritable(); err != nil {
+		return err
+	}
+	if err := fs.CheckLock(n.ID()); err != nil {
+		return err
+	}
+	// A note that would be created has no ID yet worth showing
+	action, target := "save", n.ID()
+	if preHook == hooks.PreCreate {
+		action, target = "create", n.Metadata.Title
+	}
+	if fs.skip(action, target) {
+		return nil
+	}
 	if err := fs.EnsureNotesDir(); err != nil {
 		return fmt.Errorf("error ensuring notes directory: %w", err)
 	}
 
-	return n.Save()
+	if fs.mergeHashtags && n.MergeHashtags() {
+		slog.Debug("merged hashtags into tags", "id", n.ID(), "tags", n.Metadata.Tags)
+	}
+	if n.DetectLanguage() {
+		slog.Debug("detected language", "id", n.ID(), "language", n.Language())
+	}
+
+	if err := fs.checkConstraints(n); err != nil {
+		return err
+	}
+
+	if fs.lint != nil {
+		if err := fs.lint(n); err != nil {
+			return err
+		}
+	}
+
+	if err := fs.runHook(preHook, n); err != nil {
+		return err
+	}
+
+	if err := fs.saveRevision(n); err != nil {
+		return err
+	}
+
+	old, _ := fs.ParseNote(n.FilePath)
+	if err := fs.journal(n.FilePath); err != nil {
+		return err
+	}
+	slog.Debug("saving note", "path", n.FilePath)
+	if err := n.Save(); err != nil {
+		return err
+	}
+	fs.recordChange(old, n)
+	fs.updateIndex(n.ID())
+	fs.updateAttachmentRefs(n)
+	fs.runPostHook(postHook, n)
+	return nil
 }
 
-func (fs *FileStorage) GetAllNotes() ([]*note.Note, error) {
+// CreateNote assigns a new ID to n, saves it and returns the ID
+func (fs *FileStorage) CreateNote(n *note.Note) (string, error) {
+	noteID := fs.GenerateNoteID()
+	n.SetFilePath(fs.GenerateNoteFilePath(noteID))
+
+	if err := fs.saveNote(n, hooks.PreCreate, hooks.PostCreate); err != nil {
+		return "", err
+	}
+	return noteID, nil
+}
+
+// NoteFiles returns the paths of all note files in the store and its
+// notebooks, including ones that may fail to parse
+func (fs *FileStorage) NoteFiles() ([]string, error) {
 	if err := fs.EnsureNotesDir(); err != nil {
 		return nil, fmt.Errorf("error ensuring notes directory: %w", err)
 	}
@@ -102,63 +354,276 @@ func (fs *FileStorage) GetAllNotes() ([]*note.Note, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error finding note files: %w", err)
 	}
+	notebooks, err := fs.Notebooks()
+	if err != nil {
+		return nil, err
+	}
+	for _, notebook := range notebooks {
+		more, err := filepath.Glob(filepath.Join(fs.notesDir, notebook, "*"+fs.noteExtension))
+		if err != nil {
+			return nil, fmt.Errorf("error finding note files: %w", err)
+		}
+		files = append(files, more...)
+	}
+	return files, nil
+}
+
+func (fs *FileStorage) GetAllNotes() ([]*note.Note, error) {
+	files, err := fs.NoteFiles()
+	if err != nil {
+		return nil, err
+	}
 
 	var notes []*note.Note
-	for _, file := range files {
+	for i, file := range files {
+		fs.reportProgress(i, len(files))
 		n, err := fs.ParseNote(file)
 		if err != nil {
-			fmt.Printf("Warning: failed to parse note %s: %v\n", file, err)
+			fs.recordParseError(file, err)
 			continue
 		}
 		notes = append(notes, n)
 	}
+	fs.reportProgress(len(files), len(files))
+	fs.warnParseErrors()
 
+	slog.Debug("loaded notes", "dir", fs.notesDir, "count", len(notes))
 	return notes, nil
 }
 
+// DefaultBatchSize is how many notes EachNote parses at a time unless told
+// otherwise
+const DefaultBatchSize = 500
+
+// EachNote parses the notes of the store size at a time and passes each
+// batch to fn, so work on every note of a large store only holds one batch
+// in memory. Files that fail to parse are skipped as in GetAllNotes; an
+// error from fn stops the iteration.
+func (fs *FileStorage) EachNote(size int, fn func([]*note.Note) error) error {
+	if size < 1 {
+		size = DefaultBatchSize
+	}
+	files, err := fs.NoteFiles()
+	if err != nil {
+		return err
+	}
+
+	batch := make([]*note.Note, 0, min(size, len(files)))
+	for i, file := range files {
+		fs.reportProgress(i, len(files))
+		n, err := fs.ParseNote(file)
+		if err != nil {
+			fs.recordParseError(file, err)
+			continue
+		}
+		batch = append(batch, n)
+		if len(batch) == size {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	fs.reportProgress(len(files), len(files))
+	fs.warnParseErrors()
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
 func (fs *FileStorage) FindNoteByID(noteID string) (*note.Note, error) {
-	notePath := fs.GenerateNoteFilePath(noteID)
+	notePath, err := fs.notePath(noteID)
+	if err != nil {
+		return nil, err
+	}
 	if _, err := os.Stat(notePath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("note with ID '%s' not found", noteID)
 	}
 	return fs.ParseNote(notePath)
 }
 
-func (fs *FileStorage) DeleteNote(noteID string) error {
-	notePath := fs.GenerateNoteFilePath(noteID)
-	if _, err := os.Stat(notePath); os.IsNotExist(err) {
-		return fmt.Errorf("note with ID '%s' not found", noteID)
+// NoteExists reports whether a note with exactly this ID is stored
+func (fs *FileStorage) NoteExists(noteID string) bool {
+	notePath, err := fs.notePath(noteID)
+	if err != nil {
+		return false
 	}
-	return os.Remove(notePath)
+	_, err = os.Stat(notePath)
+	return err == nil
 }
 
-func (fs *FileStorage) SearchNotes(query string) ([]*note.Note, error) {
-	notes, err := fs.GetAllNotes()
+// NoteIDsWithPrefix returns the sorted IDs of the notes whose ID starts
+// with prefix
+func (fs *FileStorage) NoteIDsWithPrefix(prefix string) ([]string, error) {
+	files, err := fs.NoteFiles()
 	if err != nil {
 		return nil, err
 	}
+	var ids []string
+	for _, file := range files {
+		if id := strings.TrimSuffix(filepath.Base(file), fs.noteExtension); strings.HasPrefix(id, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
 
-	var matches []*note.Note
-	queryLower := strings.ToLower(query)
+func (fs *FileStorage) DeleteNote(noteID string) error {
+	if err := fs.CheckWritable(); err != nil {
+		return err
+	}
+	if err := fs.CheckLock(noteID); err != nil {
+		return err
+	}
+	notePath, err := fs.notePath(noteID)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(notePath); os.IsNotExist(err) {
+		return fmt.Errorf("note with ID '%s' not found", noteID)
+	}
+	if fs.skip("delete", noteID) {
+		return nil
+	}
 
-	for _, n := range notes {
-		if strings.Contains(strings.ToLower(n.Metadata.Title), queryLower) ||
-			strings.Contains(strings.ToLower(n.Content), queryLower) {
-			matches = append(matches, n)
-			continue
+	// Hooks see the note as it was; an unparsable file is deleted without them
+	n, parseErr := fs.ParseNote(notePath)
+	if parseErr == nil {
+		if err := fs.runHook(hooks.PreDelete, n); err != nil {
+			return err
 		}
+	}
 
-		for _, tag := range n.Metadata.Tags {
-			if strings.Contains(strings.ToLower(tag), queryLower) {
-				matches = append(matches, n)
-				break
-			}
+	if fs.trash != nil {
+		var trashed *note.Note
+		if parseErr == nil {
+			trashed = n
+		}
+		if err := fs.moveToTrash(noteID, notePath, trashed); err != nil {
+			return err
 		}
+	} else {
+		if err := fs.journal(notePath); err != nil {
+			return err
+		}
+		slog.Debug("deleting note", "path", notePath)
+		if err := os.Remove(notePath); err != nil {
+			return err
+		}
+	}
+	if parseErr == nil {
+		fs.recordChange(n, nil)
+	} else {
+		fs.appendAudit(audit.Entry{Op: audit.OpDelete, ID: noteID})
 	}
+	fs.updateIndex(noteID)
+	// Notes in the trash keep their revisions and attachments until they
+	// are removed from it
+	if fs.trash == nil {
+		fs.moveAttachmentRefs(noteID, "")
+		if err := fs.deleteRevisions(noteID); err != nil {
+			return err
+		}
+	}
+	if parseErr == nil {
+		fs.runPostHook(hooks.PostDelete, n)
+	}
+	return nil
+}
 
-	return matches, nil
+// SaveNoteWithID saves n under a caller-chosen ID, such as a file name
+// received over WebDAV. A new ID must not collide, ignoring case, with an
+// existing note.
+func (fs *FileStorage) SaveNoteWithID(n *note.Note, noteID string) error {
+	path, err := fs.notePath(noteID)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if fs.idTaken(noteID) {
+			return fmt.Errorf("a note whose ID differs from '%s' only by case already exists", noteID)
+		}
+		n.SetFilePath(path)
+		return fs.saveNote(n, hooks.PreCreate, hooks.PostCreate)
+	}
+	n.SetFilePath(path)
+	return fs.SaveNote(n)
 }
 
+// RepairNote writes n back to its file without touching its timestamps or
+// recording a revision; it is used by `memo doctor --fix`
+func (fs *FileStorage) RepairNote(n *note.Note) error {
+	if err := fs.CheckWritable(); err != nil {
+		return err
+	}
+	if err := fs.CheckLock(n.ID()); err != nil {
+		return err
+	}
+	content, err := n.Format()
+	if err != nil {
+		return err
+	}
+	if fs.skip("repair", n.ID()) {
+		return nil
+	}
+	if err := fs.journal(n.FilePath); err != nil {
+		return err
+	}
+	slog.Debug("repairing note", "path", n.FilePath)
+	if err := os.WriteFile(n.FilePath, []byte(content), 0644); err != nil {
+		return err
+	}
+	fs.appendAudit(audit.Entry{Op: audit.OpRepair, ID: n.ID(), New: audit.MetadataOf(n)})
+	fs.updateIndex(n.ID())
+	return nil
+}
+
+// RenameNoteFile gives a note a new ID, moving its revision history along
+// with it. The old ID is kept as an alias of the new one.
+func (fs *FileStorage) RenameNoteFile(oldID, newID string) error {
+	if err := fs.CheckWritable(); err != nil {
+		return err
+	}
+	if err := fs.CheckLock(oldID); err != nil {
+		return err
+	}
+	if err := ValidateName(newID); err != nil {
+		return fmt.Errorf("invalid note ID: %w", err)
+	}
+	if fs.idTaken(newID) {
+		return fmt.Errorf("note with ID '%s' already exists", newID)
+	}
+	if fs.skip("rename", oldID+" -> "+newID) {
+		return nil
+	}
+	// The note stays in its notebook
+	oldPath := fs.GenerateNoteFilePath(oldID)
+	newPath := filepath.Join(filepath.Dir(oldPath), newID+fs.noteExtension)
+	for _, path := range []string{oldPath, newPath} {
+		if err := fs.journal(path); err != nil {
+			return err
+		}
+	}
+	slog.Debug("renaming note", "from", oldID, "to", newID)
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	fs.appendAudit(audit.Entry{Op: audit.OpRename, ID: oldID, NewID: newID})
+	fs.addAlias(oldID, newID)
+	fs.updateIndex(oldID, newID)
+	fs.moveAttachmentRefs(oldID, newID)
+	if _, err := os.Stat(fs.versionsDir(oldID)); err == nil {
+		if err := fs.journalMove(fs.versionsDir(oldID), fs.versionsDir(newID)); err != nil {
+			return err
+		}
+		return os.Rename(fs.versionsDir(oldID), fs.versionsDir(newID))
+	}
+	return nil
+}
+
+// FilterNotesByTag returns the notes tagged with tag or any tag nested below it
 func (fs *FileStorage) FilterNotesByTag(tag string) ([]*note.Note, error) {
 	notes, err := fs.GetAllNotes()
 	if err != nil {
@@ -166,16 +631,11 @@ func (fs *FileStorage) FilterNotesByTag(tag string) ([]*note.Note, error) {
 	}
 
 	var matches []*note.Note
-	tagLower := strings.ToLower(tag)
-
 	for _, n := range notes {
-		for _, noteTag := range n.Metadata.Tags {
-			if strings.ToLower(noteTag) == tagLower {
-				matches = append(matches, n)
-				break
-			}
+		if n.HasTag(tag) {
+			matches = append(matches, n)
 		}
 	}
 
 	return matches, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,379 @@
+package storage
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"memo/internal/audit"
+	"memo/internal/note"
+)
+
+const (
+	// TransactionDirName holds the journal of the multi-file operation in
+	// progress, with the earlier contents of the files it changed
+	TransactionDirName = ".transaction"
+
+	journalFileName = "journal.yaml"
+)
+
+// Transaction groups the changes of an operation that writes several
+// files, such as a rename updating links in other notes, so they take
+// effect together. Before a file is first changed in a transaction, its
+// earlier content is kept in the store's journal, and Rollback puts it back.
+// A memo that crashes or is killed halfway leaves the journal behind; the
+// next one finds it with InterruptedTransaction. Only one transaction can
+// be open in a store at a time, so such operations of concurrent memo
+// processes do not interleave.
+type Transaction struct {
+	// Command holds the arguments of the memo that opened the transaction
+	Command []string       `yaml:"command"`
+	PID     int            `yaml:"pid"`
+	Host    string         `yaml:"host"`
+	Started time.Time      `yaml:"started"`
+	Files   []JournalEntry `yaml:"files"`
+
+	// fs is nil once the transaction is closed, and in dry runs
+	fs *FileStorage
+}
+
+// JournalEntry is a file changed in a transaction, relative to the notes
+// directory. Backup names the copy of its earlier content in the journal
+// and is empty for a file that did not exist; a directory moved to Path
+// from MovedFrom is moved back instead.
+type JournalEntry struct {
+	Path      string    `yaml:"path"`
+	Backup    string    `yaml:"backup,omitempty"`
+	ModTime   time.Time `yaml:"mod_time,omitempty"`
+	MovedFrom string    `yaml:"moved_from,omitempty"`
+}
+
+// TransactionError is returned by Begin while another transaction is open,
+// either in a running memo or left behind by an interrupted one
+type TransactionError struct {
+	Transaction *Transaction
+	Interrupted bool
+}
+
+func (e *TransactionError) Error() string {
+	t := e.Transaction
+	if e.Interrupted {
+		return fmt.Sprintf("'%s' was interrupted on %s; run 'memo recover' to roll it back or complete it",
+			t.CommandLine(), t.Started.Format("2006-01-02 15:04"))
+	}
+	return fmt.Sprintf("'%s' is running in process %d on %s since %s; try again when it is done",
+		t.CommandLine(), t.PID, t.Host, t.Started.Format("2006-01-02 15:04"))
+}
+
+// CommandLine shows the command that opened the transaction as it was
+// typed, quoting arguments with spaces
+func (t *Transaction) CommandLine() string {
+	words := []string{"memo"}
+	for _, arg := range t.Command {
+		if arg == "" || strings.ContainsAny(arg, " \t'\"") {
+			arg = strconv.Quote(arg)
+		}
+		words = append(words, arg)
+	}
+	return strings.Join(words, " ")
+}
+
+// Begin opens a transaction for the operation run by command, the
+// arguments memo was started with. Every file the store changes until
+// Commit or Rollback is journaled. In a dry run the transaction does
+// nothing.
+func (fs *FileStorage) Begin(command []string) (*Transaction, error) {
+	if err := fs.CheckWritable(); err != nil {
+		return nil, err
+	}
+	t := &Transaction{Command: command, PID: os.Getpid(), Started: time.Now()}
+	t.Host, _ = os.Hostname()
+	if fs.DryRun() {
+		return t, nil
+	}
+	if fs.tx != nil {
+		return nil, fmt.Errorf("a transaction is already open for '%s'", fs.tx.CommandLine())
+	}
+	if err := fs.EnsureNotesDir(); err != nil {
+		return nil, err
+	}
+
+	// The journal is written first and moved into place in one step, which
+	// fails when another transaction's directory is there
+	staging := fs.StorePath(TransactionDirName + "-" + strconv.Itoa(t.PID))
+	if err := os.RemoveAll(staging); err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	if err := os.Mkdir(staging, 0755); err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	if err := writeJournal(filepath.Join(staging, journalFileName), t); err != nil {
+		os.RemoveAll(staging)
+		return nil, err
+	}
+	if err := os.Rename(staging, fs.StorePath(TransactionDirName)); err != nil {
+		os.RemoveAll(staging)
+		open, loadErr := fs.loadTransaction()
+		if loadErr != nil || open == nil {
+			return nil, fmt.Errorf("error starting transaction: %w", err)
+		}
+		return nil, &TransactionError{Transaction: open, Interrupted: open.interrupted()}
+	}
+
+	t.fs = fs
+	fs.tx = t
+	slog.Debug("began transaction", "command", t.CommandLine())
+	return t, nil
+}
+
+// Commit keeps the changes made in the transaction and closes it. Like
+// Rollback, it does nothing on a nil transaction.
+func (t *Transaction) Commit() error {
+	if t == nil || t.fs == nil {
+		return nil
+	}
+	if err := t.close(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+	return nil
+}
+
+// Rollback undoes the changes made in the transaction, newest first, and
+// closes it. After Commit it does nothing, so it can be deferred. When a
+// file cannot be restored the journal is kept, so a later Rollback can
+// finish the job. The audit log is not journaled: the changes already
+// recorded stay in it, followed by a rollback entry for each note put back.
+func (t *Transaction) Rollback() error {
+	if t == nil || t.fs == nil {
+		return nil
+	}
+	fs := t.fs
+	dir := fs.StorePath(TransactionDirName)
+	var rolledBack []audit.Entry
+	for i := len(t.Files) - 1; i >= 0; i-- {
+		e := t.Files[i]
+		path := filepath.Join(fs.notesDir, e.Path)
+		isNote := e.MovedFrom == "" && fs.isNotePath(e.Path)
+		var before *note.Note
+		if isNote {
+			before, _ = fs.ParseNote(path)
+		}
+		var err error
+		switch {
+		case e.MovedFrom != "":
+			if err = os.Rename(path, filepath.Join(fs.notesDir, e.MovedFrom)); os.IsNotExist(err) {
+				err = nil
+			}
+		case e.Backup == "":
+			if err = os.Remove(path); os.IsNotExist(err) {
+				err = nil
+			}
+		default:
+			var data []byte
+			if data, err = os.ReadFile(filepath.Join(dir, e.Backup)); err == nil {
+				if err = os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+					err = os.WriteFile(path, data, 0644)
+				}
+			}
+			if err == nil && !e.ModTime.IsZero() {
+				err = os.Chtimes(path, e.ModTime, e.ModTime)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("error rolling back %s: %w", e.Path, err)
+		}
+		slog.Debug("rolled back", "path", e.Path)
+		if isNote {
+			after, _ := fs.ParseNote(path)
+			if before != nil || after != nil {
+				rolledBack = append(rolledBack, audit.Entry{
+					Op:  audit.OpRollback,
+					ID:  strings.TrimSuffix(filepath.Base(e.Path), fs.noteExtension),
+					Old: audit.MetadataOf(before),
+					New: audit.MetadataOf(after),
+				})
+			}
+		}
+	}
+
+	if err := t.close(); err != nil {
+		return fmt.Errorf("error rolling back transaction: %w", err)
+	}
+	for _, e := range rolledBack {
+		fs.appendAudit(e)
+	}
+	// The index follows the files, so it is rebuilt rather than journaled
+	if err := fs.RefreshIndex(); err != nil {
+		slog.Warn("could not update the content index", "error", err)
+	}
+	return nil
+}
+
+// close removes the journal. Moving it aside first makes the end of the
+// transaction a single step even if the removal is cut short.
+func (t *Transaction) close() error {
+	fs := t.fs
+	dir := fs.StorePath(TransactionDirName)
+	closed := dir + "-" + strconv.Itoa(os.Getpid()) + "-closed"
+	if err := os.Rename(dir, closed); err != nil {
+		return err
+	}
+	if fs.tx == t {
+		fs.tx = nil
+	}
+	t.fs = nil
+	return os.RemoveAll(closed)
+}
+
+// InterruptedTransaction returns the transaction left open by a memo that
+// is no longer running, or nil when there is none. Its Rollback undoes
+// what the interrupted operation had done.
+func (fs *FileStorage) InterruptedTransaction() (*Transaction, error) {
+	t, err := fs.loadTransaction()
+	if t == nil || err != nil || !t.interrupted() {
+		return nil, err
+	}
+	t.fs = fs
+	return t, nil
+}
+
+// loadTransaction reads the journal of the open transaction, if any
+func (fs *FileStorage) loadTransaction() (*Transaction, error) {
+	data, err := os.ReadFile(filepath.Join(fs.StorePath(TransactionDirName), journalFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading transaction journal: %w", err)
+	}
+	var t Transaction
+	if err := yaml.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("error reading transaction journal: %w", err)
+	}
+	return &t, nil
+}
+
+// interrupted reports whether the process that opened t is gone, judged as
+// for note locks
+func (t *Transaction) interrupted() bool {
+	host, _ := os.Hostname()
+	return isStale(Lock{PID: t.PID, Host: t.Host, Since: t.Started}, host)
+}
+
+// isNotePath reports whether rel, relative to the notes directory, is a
+// note rather than a file of the store such as the trash
+func (fs *FileStorage) isNotePath(rel string) bool {
+	if filepath.Ext(rel) != fs.noteExtension {
+		return false
+	}
+	for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+		if strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	return true
+}
+
+// journal records the state of the file at path before the open
+// transaction first changes it; without a transaction it does nothing
+func (fs *FileStorage) journal(path string) error {
+	fs.txMu.Lock()
+	defer fs.txMu.Unlock()
+	t := fs.tx
+	if t == nil {
+		return nil
+	}
+	rel, err := filepath.Rel(fs.notesDir, path)
+	if err != nil {
+		return fmt.Errorf("error journaling %s: %w", path, err)
+	}
+	for _, e := range t.Files {
+		if e.Path == rel {
+			return nil
+		}
+	}
+
+	e := JournalEntry{Path: rel}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		e.Backup = strconv.Itoa(len(t.Files) + 1)
+		if info, err := os.Stat(path); err == nil {
+			e.ModTime = info.ModTime()
+		}
+		if err := writeSynced(filepath.Join(fs.StorePath(TransactionDirName), e.Backup), data); err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("error journaling %s: %w", rel, err)
+	}
+	return fs.addJournalEntry(e)
+}
+
+// journalMove records that the directory from is about to be moved to to
+func (fs *FileStorage) journalMove(from, to string) error {
+	fs.txMu.Lock()
+	defer fs.txMu.Unlock()
+	if fs.tx == nil {
+		return nil
+	}
+	rel, err := filepath.Rel(fs.notesDir, to)
+	if err != nil {
+		return fmt.Errorf("error journaling %s: %w", to, err)
+	}
+	relFrom, err := filepath.Rel(fs.notesDir, from)
+	if err != nil {
+		return fmt.Errorf("error journaling %s: %w", from, err)
+	}
+	return fs.addJournalEntry(JournalEntry{Path: rel, MovedFrom: relFrom})
+}
+
+// addJournalEntry appends e to the journal of the open transaction; the
+// caller holds txMu
+func (fs *FileStorage) addJournalEntry(e JournalEntry) error {
+	t := fs.tx
+	t.Files = append(t.Files, e)
+	path := filepath.Join(fs.StorePath(TransactionDirName), journalFileName)
+	if err := writeJournal(path+".tmp", t); err != nil {
+		t.Files = t.Files[:len(t.Files)-1]
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		t.Files = t.Files[:len(t.Files)-1]
+		return fmt.Errorf("error writing transaction journal: %w", err)
+	}
+	return nil
+}
+
+// writeJournal writes the journal of t to path
+func writeJournal(path string, t *Transaction) error {
+	data, err := yaml.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("error marshaling transaction journal: %w", err)
+	}
+	return writeSynced(path, data)
+}
+
+// writeSynced writes data to path and flushes it to disk, so the journal
+// never claims more than what survived a crash
+func writeSynced(path string, data []byte) error {
+	f, err := os.Create(path)
+	if err == nil {
+		_, err = f.Write(data)
+		if err == nil {
+			err = f.Sync()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error writing transaction journal: %w", err)
+	}
+	return nil
+}
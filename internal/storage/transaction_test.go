@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+
+	"memo/internal/audit"
+	"memo/internal/note"
+)
+
+func revisionFiles(t *testing.T, fs *FileStorage, id string) []string {
+	t.Helper()
+	revisions, err := fs.ListRevisions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	for _, rev := range revisions {
+		files = append(files, rev.FilePath)
+	}
+	return files
+}
+
+func TestRollbackRestoresNotesAndRevisions(t *testing.T) {
+	fs := newTestStorage(t)
+	fs.SetMaxRevisions(1)
+
+	n := note.New("Plan", "first", nil)
+	id, err := fs.CreateNote(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Content = "second"
+	if err := fs.SaveNote(n); err != nil {
+		t.Fatal(err)
+	}
+	before := revisionFiles(t, fs, id)
+	if len(before) != 1 {
+		t.Fatalf("got %d revisions before the transaction, want 1", len(before))
+	}
+
+	tx, err := fs.Begin([]string{"edit", id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Content = "third"
+	if err := fs.SaveNote(n); err != nil {
+		t.Fatal(err)
+	}
+	created, err := fs.CreateNote(note.New("Other", "", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := fs.FindNoteByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Content != "second" {
+		t.Errorf("content after rollback = %q, want %q", got.Content, "second")
+	}
+	if fs.NoteExists(created) {
+		t.Errorf("note %s created in the transaction survived the rollback", created)
+	}
+	// The revision written by the undone save is gone and the one it pruned
+	// is back
+	if after := revisionFiles(t, fs, id); !slices.Equal(after, before) {
+		t.Errorf("revisions after rollback = %v, want %v", after, before)
+	}
+
+	log, err := fs.AuditLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rolledBack []string
+	for _, e := range log {
+		if e.Op == audit.OpRollback {
+			rolledBack = append(rolledBack, e.ID)
+		}
+	}
+	slices.Sort(rolledBack)
+	want := []string{id, created}
+	slices.Sort(want)
+	if !slices.Equal(rolledBack, want) {
+		t.Errorf("rollback entries for %v, want %v", rolledBack, want)
+	}
+	if len(log) < 4 || log[2].Op != audit.OpEdit || log[3].Op != audit.OpCreate {
+		t.Errorf("the changes made in the transaction are no longer in the audit log: %+v", log)
+	}
+}
@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"memo/internal/note"
+	"memo/internal/trash"
+)
+
+// SetTrash makes DeleteNote move notes into the trash, which keeps them as
+// long as policy allows. With a nil policy notes are deleted right away.
+func (fs *FileStorage) SetTrash(policy *trash.Policy) {
+	fs.trash = policy
+}
+
+// TrashPolicy returns the retention policy of the trash, or nil when
+// deleted notes are not kept
+func (fs *FileStorage) TrashPolicy() *trash.Policy {
+	return fs.trash
+}
+
+func (fs *FileStorage) trashDir() string {
+	return fs.StorePath(trash.DirName)
+}
+
+// TrashEntries returns the notes in the trash, oldest first
+func (fs *FileStorage) TrashEntries() ([]trash.Entry, error) {
+	return trash.Load(fs.trashDir())
+}
+
+// moveToTrash moves the file of a note being deleted into the trash and
+// drops whatever the policy no longer keeps. n is nil when the file could
+// not be parsed.
+func (fs *FileStorage) moveToTrash(noteID, notePath string, n *note.Note) error {
+	fs.trashMu.Lock()
+	defer fs.trashMu.Unlock()
+
+	entries, err := trash.Load(fs.trashDir())
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(notePath)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	e := trash.Entry{
+		ID:       noteID,
+		Notebook: fs.notebookOfPath(notePath),
+		File:     trash.FileName(noteID, now, fs.noteExtension),
+		Deleted:  now,
+		Size:     info.Size(),
+	}
+	if n != nil {
+		e.Title = n.Metadata.Title
+	}
+
+	if err := os.MkdirAll(fs.trashDir(), 0755); err != nil {
+		return fmt.Errorf("error creating trash directory: %w", err)
+	}
+	for _, path := range []string{notePath, filepath.Join(fs.trashDir(), e.File), filepath.Join(fs.trashDir(), trash.IndexFileName)} {
+		if err := fs.journal(path); err != nil {
+			return err
+		}
+	}
+	slog.Debug("moving note to trash", "path", notePath, "file", e.File)
+	if err := os.Rename(notePath, filepath.Join(fs.trashDir(), e.File)); err != nil {
+		return err
+	}
+
+	keep, drop := fs.trash.Expired(append(entries, e), now)
+	fs.purgeTrash(drop)
+	return trash.Save(fs.trashDir(), keep)
+}
+
+// PruneTrash deletes the notes the trash policy no longer keeps for good
+// and returns them
+func (fs *FileStorage) PruneTrash(now time.Time) ([]trash.Entry, error) {
+	if fs.trash == nil || fs.readOnly {
+		return nil, nil
+	}
+	fs.trashMu.Lock()
+	defer fs.trashMu.Unlock()
+
+	entries, err := trash.Load(fs.trashDir())
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	keep, drop := fs.trash.Expired(entries, now)
+	if len(drop) == 0 {
+		return nil, nil
+	}
+	if fs.DryRun() {
+		for _, e := range drop {
+			fs.skip("purge", e.ID)
+		}
+		return drop, nil
+	}
+	fs.purgeTrash(drop)
+	return drop, trash.Save(fs.trashDir(), keep)
+}
+
+// EmptyTrash deletes every note in the trash for good and returns them
+func (fs *FileStorage) EmptyTrash() ([]trash.Entry, error) {
+	if err := fs.CheckWritable(); err != nil {
+		return nil, err
+	}
+	fs.trashMu.Lock()
+	defer fs.trashMu.Unlock()
+
+	entries, err := trash.Load(fs.trashDir())
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	if fs.DryRun() {
+		for _, e := range entries {
+			fs.skip("purge", e.ID)
+		}
+		return entries, nil
+	}
+	fs.purgeTrash(entries)
+	return entries, trash.Save(fs.trashDir(), nil)
+}
+
+// purgeTrash removes trashed files along with the revisions and attachment
+// references their notes kept while in the trash
+func (fs *FileStorage) purgeTrash(entries []trash.Entry) {
+	for _, e := range entries {
+		if err := fs.journal(filepath.Join(fs.trashDir(), e.File)); err != nil {
+			slog.Warn("could not remove note from trash", "id", e.ID, "error", err)
+			continue
+		}
+		slog.Debug("removing note from trash", "id", e.ID, "file", e.File)
+		if err := os.Remove(filepath.Join(fs.trashDir(), e.File)); err != nil && !os.IsNotExist(err) {
+			slog.Warn("could not remove note from trash", "id", e.ID, "error", err)
+		}
+		// A note created with the same ID since owns them now
+		if fs.NoteExists(e.ID) {
+			continue
+		}
+		fs.moveAttachmentRefs(e.ID, "")
+		if err := fs.deleteRevisions(e.ID); err != nil {
+			slog.Warn("could not delete revisions", "id", e.ID, "error", err)
+		}
+	}
+}
+
+// RestoreNote moves the most recently deleted note with an ID out of the
+// trash, back into the notebook it was deleted from
+func (fs *FileStorage) RestoreNote(noteID string) (*note.Note, error) {
+	if err := fs.CheckWritable(); err != nil {
+		return nil, err
+	}
+	fs.trashMu.Lock()
+	defer fs.trashMu.Unlock()
+
+	entries, err := trash.Load(fs.trashDir())
+	if err != nil {
+		return nil, err
+	}
+	found := -1
+	for i, e := range entries {
+		if e.ID == noteID {
+			found = i
+		}
+	}
+	if found < 0 {
+		return nil, fmt.Errorf("note with ID '%s' is not in the trash", noteID)
+	}
+	e := entries[found]
+	if fs.idTaken(noteID) {
+		return nil, fmt.Errorf("a note with ID '%s' exists; rename it before restoring the deleted one", noteID)
+	}
+
+	dir := fs.notesDir
+	if e.Notebook != "" {
+		dir = filepath.Join(dir, e.Notebook)
+	}
+	notePath := filepath.Join(dir, noteID+fs.noteExtension)
+	if fs.skip("restore", noteID) {
+		return &note.Note{Metadata: note.Metadata{Title: e.Title}, FilePath: notePath}, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(filepath.Join(fs.trashDir(), e.File), notePath); err != nil {
+		return nil, err
+	}
+	if err := trash.Save(fs.trashDir(), append(entries[:found:found], entries[found+1:]...)); err != nil {
+		return nil, err
+	}
+
+	n, err := fs.ParseNote(notePath)
+	if err != nil {
+		return nil, err
+	}
+	fs.recordChange(nil, n)
+	fs.updateIndex(noteID)
+	fs.updateAttachmentRefs(n)
+	return n, nil
+}
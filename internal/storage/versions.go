@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"memo/internal/note"
+	"memo/internal/vault"
+)
+
+const (
+	VersionsDirName     = ".versions"
+	DefaultMaxRevisions = 10
+)
+
+// Revision describes a stored previous version of a note
+type Revision struct {
+	Number   int
+	Saved    time.Time
+	FilePath string
+}
+
+// SetMaxRevisions sets how many previous versions are kept per note.
+// Zero disables versioning.
+func (fs *FileStorage) SetMaxRevisions(n int) {
+	fs.maxRevisions = n
+}
+
+func (fs *FileStorage) versionsDir(noteID string) string {
+	return filepath.Join(fs.notesDir, VersionsDirName, noteID)
+}
+
+// saveRevision copies the current on-disk content of a note into its
+// versions directory before it is overwritten. Like the note itself, the
+// revisions written and pruned are journaled in an open transaction, so a
+// rollback leaves no revision of a save that was undone.
+func (fs *FileStorage) saveRevision(n *note.Note) error {
+	if fs.maxRevisions <= 0 {
+		return nil
+	}
+
+	content, err := os.ReadFile(n.FilePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading note for revision: %w", err)
+	}
+
+	noteID := n.ID()
+	if n.Metadata.Encrypted && !strings.Contains(string(content), vault.Prefix) {
+		// The note is being encrypted: drop the plain-text history
+		return fs.deleteRevisions(noteID)
+	}
+
+	dir := fs.versionsDir(noteID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating versions directory: %w", err)
+	}
+
+	revPath := filepath.Join(dir, strconv.FormatInt(time.Now().UnixNano(), 10)+fs.noteExtension)
+	if err := fs.journal(revPath); err != nil {
+		return err
+	}
+	if err := os.WriteFile(revPath, content, 0644); err != nil {
+		return fmt.Errorf("error writing revision: %w", err)
+	}
+	slog.Debug("saved revision", "note", noteID, "path", revPath)
+
+	return fs.pruneRevisions(noteID)
+}
+
+func (fs *FileStorage) pruneRevisions(noteID string) error {
+	revisions, err := fs.ListRevisions(noteID)
+	if err != nil {
+		return err
+	}
+
+	for _, rev := range revisions {
+		if rev.Number > fs.maxRevisions {
+			if err := fs.journal(rev.FilePath); err != nil {
+				return err
+			}
+			if err := os.Remove(rev.FilePath); err != nil {
+				return fmt.Errorf("error pruning revision: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// ListRevisions returns the stored revisions of a note, newest first.
+// Revision 1 is the version saved immediately before the current one.
+func (fs *FileStorage) ListRevisions(noteID string) ([]Revision, error) {
+	if err := ValidateName(noteID); err != nil {
+		return nil, fmt.Errorf("invalid note ID: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(fs.versionsDir(noteID), "*"+fs.noteExtension))
+	if err != nil {
+		return nil, fmt.Errorf("error finding revisions: %w", err)
+	}
+
+	var revisions []Revision
+	for _, file := range files {
+		stamp, err := strconv.ParseInt(strings.TrimSuffix(filepath.Base(file), fs.noteExtension), 10, 64)
+		if err != nil {
+			continue
+		}
+		revisions = append(revisions, Revision{Saved: time.Unix(0, stamp), FilePath: file})
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Saved.After(revisions[j].Saved)
+	})
+	for i := range revisions {
+		revisions[i].Number = i + 1
+	}
+
+	return revisions, nil
+}
+
+// LoadRevision parses a stored revision of a note
+func (fs *FileStorage) LoadRevision(noteID string, number int) (*note.Note, error) {
+	revisions, err := fs.ListRevisions(noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if number < 1 || number > len(revisions) {
+		return nil, fmt.Errorf("revision %d not found for note '%s'", number, noteID)
+	}
+
+	return fs.ParseNote(revisions[number-1].FilePath)
+}
+
+// RollbackNote replaces a note with one of its stored revisions. The current
+// version is kept as a new revision so the rollback itself can be undone.
+func (fs *FileStorage) RollbackNote(noteID string, number int) (*note.Note, error) {
+	current, err := fs.FindNoteByID(noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	rev, err := fs.LoadRevision(noteID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	rev.SetFilePath(current.FilePath)
+	if err := fs.SaveNote(rev); err != nil {
+		return nil, fmt.Errorf("error restoring revision: %w", err)
+	}
+	return rev, nil
+}
+
+func (fs *FileStorage) deleteRevisions(noteID string) error {
+	files, _ := filepath.Glob(filepath.Join(fs.versionsDir(noteID), "*"))
+	for _, file := range files {
+		if err := fs.journal(file); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(fs.versionsDir(noteID))
+}
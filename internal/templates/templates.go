@@ -0,0 +1,166 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"memo/internal/note"
+	"memo/internal/storage"
+)
+
+// DirName is the directory inside the notes store that holds templates
+const DirName = ".templates"
+
+// VariablesField is the front matter field of a template declaring its
+// variables
+const VariablesField = "variables"
+
+// Template is a note skeleton. Its title and content may use Go template
+// syntax with the fields of Data, e.g. "Standup {{.Date}}" or
+// "Meeting with {{.Vars.attendees}}".
+type Template struct {
+	Name string
+	Note *note.Note
+}
+
+// Variable is a value a template asks for when a note is created from it,
+// declared in the template's front matter:
+//
+//	variables:
+//	  - name: attendees
+//	    prompt: Who is attending?
+//	    default: the team
+type Variable struct {
+	Name    string `yaml:"name"`
+	Prompt  string `yaml:"prompt"`
+	Default string `yaml:"default"`
+}
+
+// Data is available to template title and content
+type Data struct {
+	Date    string
+	Time    string
+	Weekday string
+	Now     time.Time
+	// Vars holds the values of the template's variables; variables left
+	// out get their default
+	Vars map[string]string
+}
+
+// NewData returns template data for the given moment
+func NewData(now time.Time) Data {
+	return Data{
+		Date:    now.Format("2006-01-02"),
+		Time:    now.Format("15:04"),
+		Weekday: now.Weekday().String(),
+		Now:     now,
+	}
+}
+
+func path(fs *storage.FileStorage, name string) string {
+	return filepath.Join(fs.StorePath(DirName), name+storage.DefaultNoteExtension)
+}
+
+// Load reads a named template from the store
+func Load(fs *storage.FileStorage, name string) (*Template, error) {
+	if err := storage.ValidateName(name); err != nil {
+		return nil, fmt.Errorf("invalid template name: %w", err)
+	}
+	p := path(fs, name)
+	if _, err := os.Stat(p); os.IsNotExist(err) {
+		return nil, fmt.Errorf("template '%s' not found (expected %s)", name, p)
+	}
+
+	n, err := fs.ParseNote(p)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template '%s': %w", name, err)
+	}
+	return &Template{Name: name, Note: n}, nil
+}
+
+// List returns the names of all templates in the store
+func List(fs *storage.FileStorage) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(fs.StorePath(DirName), "*"+storage.DefaultNoteExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, f := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(f), storage.DefaultNoteExtension))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Variables returns the variables the template declares
+func (t *Template) Variables() ([]Variable, error) {
+	node, ok := t.Note.Metadata.Fields[VariablesField]
+	if !ok {
+		return nil, nil
+	}
+	var vars []Variable
+	if err := node.Decode(&vars); err != nil {
+		return nil, fmt.Errorf("invalid variables in template '%s': %w", t.Name, err)
+	}
+	for i, v := range vars {
+		if v.Name == "" {
+			return nil, fmt.Errorf("invalid variables in template '%s': variable %d has no name", t.Name, i+1)
+		}
+	}
+	return vars, nil
+}
+
+// Instantiate creates a new, unsaved note from the template
+func (t *Template) Instantiate(data Data) (*note.Note, error) {
+	vars, err := t.Variables()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		values[v.Name] = v.Default
+	}
+	for name, value := range data.Vars {
+		if _, ok := values[name]; !ok {
+			return nil, fmt.Errorf("template '%s' has no variable '%s'", t.Name, name)
+		}
+		values[name] = value
+	}
+	data.Vars = values
+
+	title, err := execute(t.Name+":title", t.Note.Metadata.Title, data)
+	if err != nil {
+		return nil, err
+	}
+	content, err := execute(t.Name+":content", t.Note.Content, data)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := append([]string(nil), t.Note.Metadata.Tags...)
+	n := note.New(title, content, tags)
+	n.Metadata.Author = t.Note.Metadata.Author
+	n.Metadata.Status = t.Note.Metadata.Status
+	n.Metadata.Priority = t.Note.Metadata.Priority
+	return n, nil
+}
+
+func execute(name, text string, data Data) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("error executing template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,389 @@
+// Command genclient generates the Go client in pkg/client from the OpenAPI
+// document of the REST API. It understands the parts of OpenAPI that
+// api/openapi.yaml uses: object schemas with scalar, array, map and
+// referenced properties, path and query parameters, JSON request bodies and
+// JSON or empty responses.
+//
+//	go run memo/internal/tools/genclient <openapi.yaml> <output.go> <package>
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type document struct {
+	Paths      map[string]pathItem `yaml:"paths"`
+	Components struct {
+		Schemas   yaml.Node           `yaml:"schemas"`
+		Responses map[string]response `yaml:"responses"`
+	} `yaml:"components"`
+}
+
+type pathItem struct {
+	Parameters []parameter `yaml:"parameters"`
+	Get        *operation  `yaml:"get"`
+	Post       *operation  `yaml:"post"`
+	Put        *operation  `yaml:"put"`
+	Delete     *operation  `yaml:"delete"`
+}
+
+type operation struct {
+	OperationID string      `yaml:"operationId"`
+	Summary     string      `yaml:"summary"`
+	Parameters  []parameter `yaml:"parameters"`
+	RequestBody *struct {
+		Content map[string]mediaType `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]response `yaml:"responses"`
+}
+
+type parameter struct {
+	Name        string `yaml:"name"`
+	In          string `yaml:"in"`
+	Required    bool   `yaml:"required"`
+	Description string `yaml:"description"`
+	Schema      schema `yaml:"schema"`
+}
+
+type response struct {
+	Ref     string               `yaml:"$ref"`
+	Content map[string]mediaType `yaml:"content"`
+}
+
+type mediaType struct {
+	Schema schema `yaml:"schema"`
+}
+
+type schema struct {
+	Ref         string    `yaml:"$ref"`
+	Type        string    `yaml:"type"`
+	Format      string    `yaml:"format"`
+	Description string    `yaml:"description"`
+	Items       *schema   `yaml:"items"`
+	Required    []string  `yaml:"required"`
+	Properties  yaml.Node `yaml:"properties"`
+	// AdditionalProperties is only looked at for its presence
+	AdditionalProperties any `yaml:"additionalProperties"`
+}
+
+func main() {
+	if len(os.Args) != 4 {
+		fmt.Fprintln(os.Stderr, "Usage: genclient <openapi.yaml> <output.go> <package>")
+		os.Exit(2)
+	}
+	if err := run(os.Args[1], os.Args[2], os.Args[3]); err != nil {
+		fmt.Fprintln(os.Stderr, "genclient:", err)
+		os.Exit(1)
+	}
+}
+
+func run(specPath, outPath, pkg string) error {
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		return err
+	}
+	var doc document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("error parsing %s: %w", specPath, err)
+	}
+
+	var body bytes.Buffer
+	if err := writeSchemas(&body, doc.Components.Schemas, requestSchemas(doc)); err != nil {
+		return err
+	}
+	if err := writeOperations(&body, doc); err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	// Name the document relative to the module, wherever go generate runs
+	source := specPath
+	for strings.HasPrefix(source, "../") {
+		source = strings.TrimPrefix(source, "../")
+	}
+	fmt.Fprintf(&b, "// Code generated by genclient from %s. DO NOT EDIT.\n\npackage %s\n\n", source, pkg)
+	b.WriteString("import (\n\"context\"\n\"net/url\"\n")
+	if bytes.Contains(body.Bytes(), []byte("time.Time")) {
+		b.WriteString("\"time\"\n")
+	}
+	b.WriteString(")\n\n")
+	b.Write(body.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("generated code does not compile: %w\n%s", err, b.String())
+	}
+	return os.WriteFile(outPath, src, 0644)
+}
+
+// requestSchemas returns the names of the schemas sent as request bodies.
+// Their optional properties become pointers, so that leaving a field out
+// can be told apart from setting it to its zero value.
+func requestSchemas(doc document) map[string]bool {
+	names := make(map[string]bool)
+	for _, item := range doc.Paths {
+		for _, m := range item.methods() {
+			if m.op.RequestBody == nil {
+				continue
+			}
+			for _, media := range m.op.RequestBody.Content {
+				names[refName(media.Schema.Ref)] = true
+			}
+		}
+	}
+	return names
+}
+
+func writeSchemas(b *bytes.Buffer, schemas yaml.Node, inputs map[string]bool) error {
+	for i := 0; i+1 < len(schemas.Content); i += 2 {
+		name := schemas.Content[i].Value
+		var s schema
+		if err := schemas.Content[i+1].Decode(&s); err != nil {
+			return fmt.Errorf("schema %s: %w", name, err)
+		}
+
+		comment := name + " is the " + name + " schema of the API"
+		if s.Description != "" {
+			comment += ". " + s.Description
+		}
+		writeComment(b, comment)
+		fmt.Fprintf(b, "type %s struct {\n", name)
+		props := s.Properties.Content
+		for j := 0; j+1 < len(props); j += 2 {
+			prop := props[j].Value
+			var ps schema
+			if err := props[j+1].Decode(&ps); err != nil {
+				return fmt.Errorf("schema %s, property %s: %w", name, prop, err)
+			}
+			typ, err := goType(ps)
+			if err != nil {
+				return fmt.Errorf("schema %s, property %s: %w", name, prop, err)
+			}
+			tag := prop
+			if !slices.Contains(s.Required, prop) {
+				tag += ",omitempty"
+				if inputs[name] {
+					typ = "*" + typ
+				}
+			}
+			writeComment(b, ps.Description)
+			fmt.Fprintf(b, "%s %s `json:\"%s\"`\n", exported(prop), typ, tag)
+		}
+		b.WriteString("}\n\n")
+	}
+	return nil
+}
+
+func writeOperations(b *bytes.Buffer, doc document) error {
+	var paths []string
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		item := doc.Paths[path]
+		for _, m := range item.methods() {
+			if err := writeOperation(b, doc, path, m.method, m.op, append(slices.Clone(item.Parameters), m.op.Parameters...)); err != nil {
+				return fmt.Errorf("%s %s: %w", m.method, path, err)
+			}
+		}
+	}
+	return nil
+}
+
+func writeOperation(b *bytes.Buffer, doc document, path, method string, op *operation, params []parameter) error {
+	if op.OperationID == "" {
+		return fmt.Errorf("operationId missing")
+	}
+	name := exported(op.OperationID)
+
+	args := []string{"ctx context.Context"}
+	for _, p := range params {
+		typ, err := goType(p.Schema)
+		if err != nil {
+			return fmt.Errorf("parameter %s: %w", p.Name, err)
+		}
+		args = append(args, p.Name+" "+typ)
+	}
+	body := "nil"
+	if op.RequestBody != nil {
+		media, ok := op.RequestBody.Content["application/json"]
+		if !ok {
+			return fmt.Errorf("only JSON request bodies are supported")
+		}
+		typ, err := goType(media.Schema)
+		if err != nil {
+			return fmt.Errorf("request body: %w", err)
+		}
+		args = append(args, "body "+typ)
+		body = "body"
+	}
+
+	result, err := resultType(doc, op)
+	if err != nil {
+		return err
+	}
+
+	comment := fmt.Sprintf("%s sends %s %s", name, strings.ToUpper(method), path)
+	if op.Summary != "" {
+		comment += ": " + strings.ToLower(op.Summary[:1]) + op.Summary[1:]
+	}
+	writeComment(b, comment)
+	if result == "" {
+		fmt.Fprintf(b, "func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+	} else {
+		fmt.Fprintf(b, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), result)
+	}
+
+	fmt.Fprintf(b, "path := %s\n", pathExpression(path))
+	b.WriteString("query := url.Values{}\n")
+	for _, p := range params {
+		if p.In != "query" {
+			continue
+		}
+		if p.Schema.Type != "string" {
+			return fmt.Errorf("query parameter %s: only strings are supported", p.Name)
+		}
+		if p.Required {
+			fmt.Fprintf(b, "query.Set(%q, %s)\n", p.Name, p.Name)
+		} else {
+			fmt.Fprintf(b, "if %s != \"\" {\nquery.Set(%q, %s)\n}\n", p.Name, p.Name, p.Name)
+		}
+	}
+
+	verb := strings.ToUpper(method)
+	if result == "" {
+		fmt.Fprintf(b, "return c.do(ctx, %q, path, query, %s, nil)\n}\n\n", verb, body)
+		return nil
+	}
+	// Objects come back as pointers, arrays as slices
+	decl, target := "var out "+result, "&out"
+	if strings.HasPrefix(result, "*") {
+		decl, target = "out := new("+result[1:]+")", "out"
+	}
+	fmt.Fprintf(b, "%s\nif err := c.do(ctx, %q, path, query, %s, %s); err != nil {\nreturn nil, err\n}\nreturn out, nil\n}\n\n",
+		decl, verb, body, target)
+	return nil
+}
+
+// resultType is the Go type of an operation's successful JSON response,
+// or "" when it has no content
+func resultType(doc document, op *operation) (string, error) {
+	for _, status := range []string{"200", "201"} {
+		resp, ok := op.Responses[status]
+		if !ok {
+			continue
+		}
+		if resp.Ref != "" {
+			resp = doc.Components.Responses[refName(resp.Ref)]
+		}
+		media, ok := resp.Content["application/json"]
+		if !ok {
+			return "", nil
+		}
+		typ, err := goType(media.Schema)
+		if err != nil {
+			return "", fmt.Errorf("response %s: %w", status, err)
+		}
+		if media.Schema.Ref != "" {
+			typ = "*" + typ
+		}
+		return typ, nil
+	}
+	return "", nil
+}
+
+// goType maps a schema to the Go type representing it
+func goType(s schema) (string, error) {
+	if s.Ref != "" {
+		return refName(s.Ref), nil
+	}
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			return "time.Time", nil
+		}
+		return "string", nil
+	case "integer":
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if s.Items == nil {
+			return "", fmt.Errorf("array without items")
+		}
+		item, err := goType(*s.Items)
+		return "[]" + item, err
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "map[string]any", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported schema type '%s'", s.Type)
+}
+
+// pathExpression builds a Go expression for a path with {parameters}
+func pathExpression(path string) string {
+	var parts []string
+	for path != "" {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			parts = append(parts, fmt.Sprintf("%q", path))
+			break
+		}
+		end := strings.Index(path[start:], "}") + start
+		if start > 0 {
+			parts = append(parts, fmt.Sprintf("%q", path[:start]))
+		}
+		parts = append(parts, "url.PathEscape("+path[start+1:end]+")")
+		path = path[end+1:]
+	}
+	return strings.Join(parts, " + ")
+}
+
+type methodOperation struct {
+	method string
+	op     *operation
+}
+
+// methods lists the operations of a path in a fixed order, so the
+// generated file does not change from run to run
+func (item pathItem) methods() []methodOperation {
+	var ops []methodOperation
+	for _, m := range []methodOperation{{"get", item.Get}, {"post", item.Post}, {"put", item.Put}, {"delete", item.Delete}} {
+		if m.op != nil {
+			ops = append(ops, m)
+		}
+	}
+	return ops
+}
+
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// exported turns a camelCase API name into an exported Go name, keeping
+// the initialism ID in capitals
+func exported(name string) string {
+	if name == "id" {
+		return "ID"
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func writeComment(b *bytes.Buffer, text string) {
+	if text != "" {
+		fmt.Fprintf(b, "// %s\n", text)
+	}
+}
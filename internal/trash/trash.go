@@ -0,0 +1,112 @@
+// Package trash keeps deleted notes for a while so they can be restored,
+// and decides which of them to drop for good under a retention policy.
+package trash
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DirName is the directory inside the notes store holding deleted notes
+	DirName = ".trash"
+	// IndexFileName is the file inside DirName describing its notes
+	IndexFileName = "trash.yaml"
+	// DefaultMaxAge is how long deleted notes are kept unless configured
+	DefaultMaxAge = 30 * 24 * time.Hour
+)
+
+// Entry is a note in the trash
+type Entry struct {
+	ID    string `yaml:"id"`
+	Title string `yaml:"title,omitempty"`
+	// Notebook is where the note was, so it can be put back there
+	Notebook string `yaml:"notebook,omitempty"`
+	// File is the name of the note's file inside the trash directory
+	File    string    `yaml:"file"`
+	Deleted time.Time `yaml:"deleted"`
+	Size    int64     `yaml:"size"`
+}
+
+// Policy limits what the trash keeps. Zero values mean no limit.
+type Policy struct {
+	MaxAge   time.Duration
+	MaxBytes int64
+}
+
+// Load reads the entries recorded in dir, oldest first; a missing index
+// yields no entries
+func Load(dir string) ([]Entry, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IndexFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading trash: %w", err)
+	}
+	var entries []Entry
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing trash: %w", err)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Deleted.Before(entries[j].Deleted) })
+	return entries, nil
+}
+
+// Save writes the entries into dir
+func Save(dir string, entries []Entry) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating trash directory: %w", err)
+	}
+	data, err := yaml.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("error marshaling trash: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, IndexFileName), data, 0644)
+}
+
+// FileName names the trashed file of a note deleted at a given time, so a
+// note deleted, restored and deleted again does not overwrite itself
+func FileName(noteID string, deleted time.Time, ext string) string {
+	return fmt.Sprintf("%s.%d%s", noteID, deleted.Unix(), ext)
+}
+
+// Size returns the total size of the entries in bytes
+func Size(entries []Entry) int64 {
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+	return total
+}
+
+// Expired splits entries, which must be oldest first, into the ones the
+// policy keeps and the ones it drops: first everything older than MaxAge,
+// then the oldest notes until the rest fit in MaxBytes.
+func (p Policy) Expired(entries []Entry, now time.Time) (keep, drop []Entry) {
+	total := Size(entries)
+	for _, e := range entries {
+		tooOld := p.MaxAge > 0 && now.Sub(e.Deleted) > p.MaxAge
+		tooBig := p.MaxBytes > 0 && total > p.MaxBytes
+		if tooOld || tooBig {
+			drop = append(drop, e)
+			total -= e.Size
+		} else {
+			keep = append(keep, e)
+		}
+	}
+	return keep, drop
+}
+
+// ExpiresAt returns when an entry will be dropped for its age, and false
+// when the policy has no age limit
+func (p Policy) ExpiresAt(e Entry) (time.Time, bool) {
+	if p.MaxAge <= 0 {
+		return time.Time{}, false
+	}
+	return e.Deleted.Add(p.MaxAge), true
+}
@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+	"unicode/utf8"
+
+	"memo/internal/analysis"
+	"memo/internal/note"
+)
+
+// Column is one optional column of `memo list --columns`
+type Column struct {
+	Name  string
+	Value func(n *note.Note) string
+}
+
+// listColumns are the built-in columns; any other name shows the custom
+// front matter field of that name
+var listColumns = map[string]func(n *note.Note) string{
+	"id": func(n *note.Note) string { return n.ID() },
+	"words": func(n *note.Note) string {
+		if n.Locked() {
+			return "-"
+		}
+		return strconv.Itoa(analysis.CountText(n.Content).Words)
+	},
+	"chars": func(n *note.Note) string {
+		if n.Locked() {
+			return "-"
+		}
+		return strconv.Itoa(analysis.CountText(n.Content).Characters)
+	},
+	"reading": func(n *note.Note) string {
+		if n.Locked() {
+			return "-"
+		}
+		return readingTime(analysis.CountText(n.Content))
+	},
+	"created":  func(n *note.Note) string { return n.Metadata.Created.Format("2006-01-02 15:04") },
+	"modified": func(n *note.Note) string { return n.Metadata.Modified.Format("2006-01-02 15:04") },
+	"tags":     func(n *note.Note) string { return strings.Join(n.Metadata.Tags, ", ") },
+	"author":   func(n *note.Note) string { return n.Metadata.Author },
+	"status":   func(n *note.Note) string { return n.Metadata.Status },
+	"priority": func(n *note.Note) string {
+		if n.Metadata.Priority == 0 {
+			return ""
+		}
+		return strconv.Itoa(n.Metadata.Priority)
+	},
+}
+
+// ParseColumns turns a comma-separated list of column names into columns.
+// extra supplies columns computed outside the note, such as access counts.
+func ParseColumns(spec string, extra ...Column) []Column {
+	var columns []Column
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		columns = append(columns, lookupColumn(name, extra))
+	}
+	return columns
+}
+
+func lookupColumn(name string, extra []Column) Column {
+	for _, c := range extra {
+		if c.Name == name {
+			return c
+		}
+	}
+	if value, ok := listColumns[name]; ok {
+		return Column{Name: name, Value: value}
+	}
+	return Column{Name: name, Value: func(n *note.Note) string {
+		values, _ := n.Field(name)
+		return strings.Join(values, ", ")
+	}}
+}
+
+// DisplayNotesTable lists notes one per line with the number and title
+// followed by the chosen columns
+func DisplayNotesTable(notes []*note.Note, columns []Column) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := []string{"#", T("Title")}
+	for _, c := range columns {
+		first, size := utf8.DecodeRuneInString(c.Name)
+		header = append(header, string(unicode.ToUpper(first))+c.Name[size:])
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for i, n := range notes {
+		// No color: the tabwriter would count the escapes as text
+		title := n.Metadata.Title
+		if icon := n.Icon(); icon != "" {
+			title = icon + " " + title
+		}
+		row := []string{strconv.Itoa(i + 1), truncate(title, 40)}
+		for _, c := range columns {
+			row = append(row, truncate(cell(c.Value(n)), 30))
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+
+	fmt.Print(Tf("\nTip: Use 'memo read <number>' or 'memo edit <number>' with numbers 1-%d from this listing.\n", len(notes)))
+}
+
+// DisplayNotesCompact lists notes one per line with the number, title,
+// creation date and tags, without paging
+func DisplayNotesCompact(notes []*note.Note) {
+	for i, n := range notes {
+		line := fmt.Sprintf("%3d. %s (%s)", i+1, ListTitle(n), n.Metadata.Created.Format("2006-01-02"))
+		if len(n.Metadata.Tags) > 0 {
+			line += " [" + strings.Join(n.Metadata.Tags, ", ") + "]"
+		}
+		fmt.Println(line)
+	}
+}
+
+// DisplayNotesOneline writes each note as a tab-separated line of ID, title
+// and the chosen columns, with no header or tip, for tools such as fzf, grep
+// and cut
+func DisplayNotesOneline(notes []*note.Note, columns []Column) {
+	for _, n := range notes {
+		fields := []string{n.ID(), cell(n.Metadata.Title)}
+		for _, c := range columns {
+			fields = append(fields, cell(c.Value(n)))
+		}
+		fmt.Println(strings.Join(fields, "\t"))
+	}
+}
+
+// cell keeps a value on one line and out of the way of tab separators
+func cell(value string) string {
+	return strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+		return r == '\t' || r == '\n' || r == '\r'
+	}), " ")
+}
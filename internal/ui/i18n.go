@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Message catalogs map the English text of a message to its translation.
+// English is the source language and needs no catalog; messages missing
+// from a catalog are shown in English.
+//
+//go:embed locales/*.yaml
+var localeFiles embed.FS
+
+// DefaultLocale is used when no supported language is configured
+const DefaultLocale = "en"
+
+var catalog map[string]string
+
+var locale = DefaultLocale
+
+// Locales returns the supported language codes
+func Locales() []string {
+	locales := []string{DefaultLocale}
+	entries, _ := localeFiles.ReadDir("locales")
+	for _, e := range entries {
+		locales = append(locales, strings.TrimSuffix(e.Name(), ".yaml"))
+	}
+	return locales
+}
+
+// Locale returns the language code in use
+func Locale() string {
+	return locale
+}
+
+// SetLocale selects the language for user-facing text. It accepts POSIX
+// locale names such as "de_DE.UTF-8"; unsupported languages fall back to
+// English.
+func SetLocale(name string) {
+	lang := normalizeLocale(name)
+	catalog = nil
+	locale = DefaultLocale
+	if lang == "" || lang == DefaultLocale {
+		return
+	}
+
+	data, err := localeFiles.ReadFile("locales/" + lang + ".yaml")
+	if err != nil {
+		return
+	}
+	var messages map[string]string
+	if err := yaml.Unmarshal(data, &messages); err != nil {
+		return
+	}
+	catalog = messages
+	locale = lang
+}
+
+// DetectLocale returns the configured language, falling back to the
+// LC_ALL, LC_MESSAGES and LANG environment variables
+func DetectLocale(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(env); value != "" {
+			return value
+		}
+	}
+	return DefaultLocale
+}
+
+// normalizeLocale reduces "de_DE.UTF-8" to "de"
+func normalizeLocale(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if i := strings.IndexAny(name, "_-.@"); i >= 0 {
+		name = name[:i]
+	}
+	if name == "c" || name == "posix" {
+		return DefaultLocale
+	}
+	return name
+}
+
+// T translates a message
+func T(message string) string {
+	if translated, ok := catalog[message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Tf translates a format string and formats it with args
+func Tf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
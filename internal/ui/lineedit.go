@@ -0,0 +1,254 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInterrupted is returned by prompts the user left with Ctrl-C
+var ErrInterrupted = errors.New("interrupted")
+
+// CompleteFunc returns the candidates for completing the last word of line
+// and the byte offset in line where that word starts
+type CompleteFunc func(line string) (candidates []string, start int)
+
+// PromptWithCompletion reads a line like PromptForInput, but on a terminal
+// the Tab key completes the word being typed using complete. A unique
+// candidate is filled in; several are listed below the prompt. Ctrl-C
+// gives up with ErrInterrupted. When stdin is not a terminal it behaves
+// exactly like PromptForInput.
+func PromptWithCompletion(prompt string, complete CompleteFunc) (string, error) {
+	if !IsInteractive() {
+		return PromptForInput(prompt), nil
+	}
+	restore, err := makeRaw()
+	if err != nil {
+		return PromptForInput(prompt), nil
+	}
+	defer restore()
+
+	prompt = T(prompt)
+	fmt.Print(prompt)
+
+	var line []byte
+	for {
+		r, _, err := Stdin.ReadRune()
+		if err != nil {
+			fmt.Print("\r\n")
+			return string(line), nil
+		}
+
+		switch r {
+		case '\r', '\n':
+			fmt.Print("\r\n")
+			return string(line), nil
+		case 3: // Ctrl-C
+			fmt.Print("\r\n")
+			return "", ErrInterrupted
+		case 4: // Ctrl-D
+			if len(line) == 0 {
+				fmt.Print("\r\n")
+				return "", nil
+			}
+		case 127, 8: // Backspace
+			if len(line) > 0 {
+				_, size := utf8.DecodeLastRune(line)
+				line = line[:len(line)-size]
+				fmt.Print("\b \b")
+			}
+		case 21: // Ctrl-U
+			line = line[:0]
+			fmt.Print("\r\033[K" + prompt)
+		case '\t':
+			line = completeLine(prompt, line, complete)
+		default:
+			if r >= ' ' {
+				line = utf8.AppendRune(line, r)
+				fmt.Print(string(r))
+			}
+		}
+	}
+}
+
+// completeLine applies complete to line, redrawing the prompt as needed
+func completeLine(prompt string, line []byte, complete CompleteFunc) []byte {
+	candidates, start := complete(string(line))
+	if len(candidates) == 0 {
+		fmt.Print("\a")
+		return line
+	}
+
+	word := string(line[start:])
+	prefix := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		prefix = candidates[0]
+	}
+	if len(prefix) > len(word) {
+		line = append(line[:start], prefix...)
+		fmt.Print(prefix[len(word):])
+		return line
+	}
+
+	// Nothing more to fill in: show the choices and redraw the line
+	fmt.Print("\r\n" + strings.Join(candidates, "  ") + "\r\n" + prompt + string(line))
+	return line
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// SearchFunc returns the lines to list for the query typed so far
+type SearchFunc func(query string) []string
+
+// PromptLive reads a query on a terminal, listing below it the lines search
+// returns after every keystroke. The arrow keys, Tab, Ctrl-N and Ctrl-P move
+// the selection and Enter picks it. It returns the position of the picked
+// line among those listed for the final query, or -1 when nothing matched
+// or the user gave up with Esc, Ctrl-C or Ctrl-D.
+func PromptLive(prompt, query string, search SearchFunc) (int, error) {
+	restore, err := makeRaw()
+	if err != nil {
+		return -1, fmt.Errorf("cannot read keys from the terminal: %w", err)
+	}
+	defer restore()
+
+	prompt = T(prompt)
+	width := terminalWidth()
+	line := []byte(query)
+	lines := search(query)
+	selected := 0
+	redraw := func() {
+		var b strings.Builder
+		b.WriteString("\r\033[J" + prompt + string(line))
+		for i, l := range lines {
+			marker := "  "
+			if i == selected {
+				marker = "> "
+			}
+			b.WriteString("\r\n" + truncate(marker+l, width-1))
+		}
+		if len(lines) == 0 && len(line) > 0 {
+			b.WriteString("\r\n" + truncate("  "+T("No matching notes"), width-1))
+			b.WriteString("\033[1A")
+		} else if len(lines) > 0 {
+			b.WriteString("\033[" + strconv.Itoa(len(lines)) + "A")
+		}
+		b.WriteString("\r" + prompt + string(line))
+		fmt.Print(b.String())
+	}
+	done := func(picked int) (int, error) {
+		fmt.Print("\r\033[J" + prompt + string(line) + "\r\n")
+		return picked, nil
+	}
+	redraw()
+
+	for {
+		r, _, err := Stdin.ReadRune()
+		if err != nil {
+			return done(-1)
+		}
+
+		changed := false
+		switch r {
+		case '\r', '\n':
+			if len(lines) == 0 {
+				return done(-1)
+			}
+			return done(selected)
+		case 3, 4: // Ctrl-C, Ctrl-D
+			return done(-1)
+		case 27: // Esc, or the start of an arrow key
+			if Stdin.Buffered() == 0 {
+				return done(-1)
+			}
+			if next, _ := Stdin.ReadByte(); next != '[' && next != 'O' {
+				continue
+			}
+			switch key, _ := Stdin.ReadByte(); key {
+			case 'A':
+				selected = max(selected-1, 0)
+			case 'B':
+				selected = min(selected+1, max(len(lines)-1, 0))
+			}
+		case 16: // Ctrl-P
+			selected = max(selected-1, 0)
+		case 14, '\t': // Ctrl-N, Tab
+			selected = min(selected+1, max(len(lines)-1, 0))
+		case 127, 8: // Backspace
+			if len(line) > 0 {
+				_, size := utf8.DecodeLastRune(line)
+				line = line[:len(line)-size]
+				changed = true
+			}
+		case 21: // Ctrl-U
+			line = line[:0]
+			changed = true
+		default:
+			if r >= ' ' {
+				line = utf8.AppendRune(line, r)
+				changed = true
+			}
+		}
+		if changed {
+			lines = search(string(line))
+			selected = 0
+		}
+		redraw()
+	}
+}
+
+// terminalWidth returns the number of columns of the terminal, or 80 when
+// it cannot be told
+func terminalWidth() int {
+	out, err := stty("size")
+	if err != nil {
+		return 80
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 80
+	}
+	if cols, err := strconv.Atoi(fields[1]); err == nil && cols > 0 {
+		return cols
+	}
+	return 80
+}
+
+// makeRaw puts the terminal into raw mode, returning a function restoring
+// the previous settings
+func makeRaw() (func(), error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+	restored := false
+	return func() {
+		if !restored {
+			restored = true
+			stty(strings.TrimSpace(state))
+		}
+	}, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
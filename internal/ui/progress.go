@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// progressDelay keeps quick operations from flashing a progress bar
+	progressDelay = 300 * time.Millisecond
+	// progressInterval limits how often the progress line is redrawn
+	progressInterval = 100 * time.Millisecond
+	progressWidth    = 30
+)
+
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// Progress draws a progress bar (or a spinner when the total is unknown) on
+// stderr. It stays silent when stderr is not a terminal and for operations
+// that finish within progressDelay.
+type Progress struct {
+	label    string
+	total    int
+	done     int
+	enabled  bool
+	started  time.Time
+	lastDraw time.Time
+	drawn    bool
+	frame    int
+}
+
+// NewProgress starts tracking an operation over total items; use a total of
+// 0 when the number of items is not known in advance
+func NewProgress(label string, total int) *Progress {
+	return &Progress{
+		label:   label,
+		total:   total,
+		enabled: isTerminalFile(os.Stderr),
+		started: time.Now(),
+	}
+}
+
+// Increment records one more finished item
+func (p *Progress) Increment() {
+	p.Set(p.done + 1)
+}
+
+// Set records the number of finished items
+func (p *Progress) Set(done int) {
+	p.done = done
+	if !p.enabled {
+		return
+	}
+	now := time.Now()
+	if now.Sub(p.started) < progressDelay || now.Sub(p.lastDraw) < progressInterval {
+		return
+	}
+	p.lastDraw = now
+	p.draw()
+}
+
+// Finish clears the progress line
+func (p *Progress) Finish() {
+	if p.drawn {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+		p.drawn = false
+	}
+}
+
+func (p *Progress) draw() {
+	p.drawn = true
+	if p.total <= 0 {
+		p.frame = (p.frame + 1) % len(spinnerFrames)
+		fmt.Fprintf(os.Stderr, "\r\033[K%s %s %d", spinnerFrames[p.frame], p.label, p.done)
+		return
+	}
+
+	filled := p.done * progressWidth / p.total
+	if filled > progressWidth {
+		filled = progressWidth
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressWidth-filled)
+	fmt.Fprintf(os.Stderr, "\r\033[K%s [%s] %d/%d", p.label, bar, p.done, p.total)
+}
+
+// ProgressFunc adapts a Progress to the storage package's progress callback,
+// starting a new bar for every operation and clearing it once it completes
+func ProgressFunc(label string) func(done, total int) {
+	var p *Progress
+	return func(done, total int) {
+		if p == nil {
+			p = NewProgress(label, total)
+		}
+		p.Set(done)
+		if done >= total {
+			p.Finish()
+			p = nil
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"memo/internal/note"
+)
+
+// sizeLimits decides which notes listings mark as large
+var sizeLimits = note.DefaultSizeLimits
+
+// SetSizeLimits sets when listings mark a note as large
+func SetSizeLimits(limits note.SizeLimits) {
+	sizeLimits = limits
+}
+
+// displayLargeNote prints a hint under a listed note that is over the size
+// limits
+func displayLargeNote(n *note.Note) {
+	if !sizeLimits.Exceeds(n) {
+		return
+	}
+	bytes, lines := n.Size()
+	fmt.Print(Tf("    Large note: %s, %d lines ('memo split %s' breaks it up by headings)\n", formatSize(int64(bytes)), lines, n.ID()))
+}
+
+// DefaultSnippetLength is how many characters of a note search results show
+const DefaultSnippetLength = 100
+
+// snippet returns about limit characters of text on one line around the
+// first match of pattern, with the matches highlighted. Without a match in
+// text, such as when only the title matched, it falls back to the start.
+func snippet(text string, pattern *regexp.Regexp, limit int) string {
+	loc := []int(nil)
+	if pattern != nil {
+		loc = pattern.FindStringIndex(text)
+	}
+	if loc == nil {
+		return excerpt(text, limit)
+	}
+
+	// Take the same number of characters before and after the match, and
+	// give those one side lacks to the other
+	around := max(0, limit-utf8.RuneCountInString(text[loc[0]:loc[1]]))
+	before, after := around/2, around-around/2
+	if _, n := lastRunes(text[:loc[0]], before); n < before {
+		after += before - n
+	}
+	if _, n := firstRunes(text[loc[1]:], after); n < after {
+		before += after - n
+	}
+	start, _ := lastRunes(text[:loc[0]], before)
+	end, _ := firstRunes(text[loc[1]:], after)
+	end += loc[1]
+
+	window := text[start:end]
+	// Do not start or end in the middle of a word
+	if start > 0 {
+		if i := strings.IndexFunc(window, unicode.IsSpace); i >= 0 && i < loc[0]-start {
+			window = window[i:]
+		}
+	}
+	if end < len(text) {
+		if i := strings.LastIndexFunc(window, unicode.IsSpace); i >= 0 && i >= len(window)-(end-loc[1]) {
+			window = window[:i]
+		}
+	}
+
+	out := Highlight(strings.Join(strings.Fields(window), " "), pattern)
+	if start > 0 {
+		out = "..." + out
+	}
+	if end < len(text) {
+		out += "..."
+	}
+	return out
+}
+
+// lastRunes returns where the last n runes of s start, and how many runes
+// there were when s is shorter
+func lastRunes(s string, n int) (int, int) {
+	i, count := len(s), 0
+	for ; count < n && i > 0; count++ {
+		_, size := utf8.DecodeLastRuneInString(s[:i])
+		i -= size
+	}
+	return i, count
+}
+
+// firstRunes returns where the first n runes of s end, and how many runes
+// there were when s is shorter
+func firstRunes(s string, n int) (int, int) {
+	i, count := 0, 0
+	for ; count < n && i < len(s); count++ {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return i, count
+}
+
+// excerpt returns about the first max characters of text on one line,
+// cut at a word boundary. Only as much of text is looked at as needed, so
+// huge notes cost no more than small ones.
+func excerpt(text string, max int) string {
+	var b strings.Builder
+	count := 0
+	space := false
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			space = count > 0
+			continue
+		}
+		if count >= max {
+			out := b.String()
+			if i := strings.LastIndexByte(out, ' '); i > len(out)/2 {
+				out = out[:i]
+			}
+			return out + "..."
+		}
+		if space {
+			b.WriteByte(' ')
+			count++
+			space = false
+		}
+		b.WriteRune(r)
+		count++
+	}
+	return b.String()
+}
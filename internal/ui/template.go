@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"memo/internal/note"
+)
+
+// TemplateNote is what output templates see: the note, so {{.ID}} and
+// {{.Metadata.Title}} work, with its number in the listing and its notebook
+type TemplateNote struct {
+	*note.Note
+	Number   int
+	Notebook string
+}
+
+// templateEscapes turns the escapes people type on the command line into
+// the characters they mean
+var templateEscapes = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n")
+
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	// date formats a time with a Go layout, by default as 2006-01-02
+	"date": func(t time.Time, layout ...string) string {
+		if t.IsZero() {
+			return ""
+		}
+		if len(layout) > 0 {
+			return t.Format(layout[0])
+		}
+		return t.Format("2006-01-02")
+	},
+	// field returns the values of a metadata field, comma-separated
+	"field": func(n TemplateNote, name string) string {
+		values, _ := n.Field(name)
+		return strings.Join(values, ", ")
+	},
+	// truncate shortens s to at most max characters
+	"truncate": func(max int, s string) string {
+		if r := []rune(s); len(r) > max {
+			return string(r[:max])
+		}
+		return s
+	},
+}
+
+// ParseNoteTemplate parses a Go text/template for printing notes. \t and \n
+// in text stand for a tab and a newline.
+func ParseNoteTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Option("missingkey=zero").Parse(templateEscapes.Replace(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// DisplayNotesTemplate prints each note with tmpl, one per line
+func DisplayNotesTemplate(notes []*note.Note, tmpl *template.Template, notebook func(*note.Note) string) error {
+	for i, n := range notes {
+		if err := executeNoteTemplate(os.Stdout, tmpl, TemplateNote{Note: n, Number: i + 1, Notebook: notebook(n)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DisplayNoteTemplate prints one note with tmpl
+func DisplayNoteTemplate(n *note.Note, tmpl *template.Template, notebook string) error {
+	return executeNoteTemplate(os.Stdout, tmpl, TemplateNote{Note: n, Notebook: notebook})
+}
+
+// executeNoteTemplate writes the output for one note, ending it with a
+// newline unless the template already does
+func executeNoteTemplate(w io.Writer, tmpl *template.Template, data TemplateNote) error {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return fmt.Errorf("error in template for note %s: %w", data.ID(), err)
+	}
+	out := b.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err := io.WriteString(w, out)
+	return err
+}
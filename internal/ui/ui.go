@@ -4,36 +4,299 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
 
+	"memo/internal/analysis"
+	"memo/internal/audit"
+	"memo/internal/backup"
+	"memo/internal/config"
+	"memo/internal/doctor"
+	"memo/internal/history"
+	"memo/internal/lint"
+	"memo/internal/merge"
 	"memo/internal/note"
+	"memo/internal/notesync"
+	"memo/internal/recur"
+	"memo/internal/render"
+	"memo/internal/stats"
+	"memo/internal/storage"
+	"memo/internal/trash"
 )
 
+const (
+	colorReset     = "\033[0m"
+	colorHighlight = "\033[1;31m"
+)
+
+// IsTerminal reports whether stdout is attached to a terminal
+func IsTerminal() bool {
+	return isTerminalFile(os.Stdout)
+}
+
+func isTerminalFile(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Highlight wraps every match of pattern in text with terminal colors when
+// stdout is a terminal
+func Highlight(text string, pattern *regexp.Regexp) string {
+	if !IsTerminal() {
+		return text
+	}
+	return pattern.ReplaceAllStringFunc(text, func(match string) string {
+		return colorHighlight + match + colorReset
+	})
+}
+
+// IsInteractive reports whether input comes from a terminal
+func IsInteractive() bool {
+	return isTerminalFile(os.Stdin)
+}
+
+// Stdin is the buffered reader of standard input shared by every prompt,
+// so what one prompt reads ahead is not lost to the next
+var Stdin = bufio.NewReader(os.Stdin)
+
+// readLine reads a line from Stdin without its line ending
+func readLine() (string, error) {
+	line, err := Stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// PromptForInput prints a prompt, translated when the catalog knows it, and
+// reads a line from stdin
 func PromptForInput(prompt string) string {
-	fmt.Print(prompt)
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Scan()
-	return scanner.Text()
+	fmt.Print(T(prompt))
+	line, _ := readLine()
+	return line
+}
+
+// PromptForText prints a prompt on a line of its own and reads lines of
+// text up to one holding only "." or the end of input, which Ctrl-D gives
+// on a terminal
+func PromptForText(prompt string) string {
+	fmt.Println(T(prompt))
+	var lines []string
+	for {
+		line, err := readLine()
+		if err != nil || line == "." {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PromptForSecret reads a line without echoing it when stdin is a terminal
+func PromptForSecret(prompt string) string {
+	fmt.Print(T(prompt))
+
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		if err := setEcho(false); err == nil {
+			defer func() {
+				setEcho(true)
+				fmt.Println()
+			}()
+		}
+	}
+
+	line, _ := readLine()
+	return line
 }
 
+// setEcho toggles terminal echo using stty, which is available on Unix-like
+// systems; elsewhere the secret is simply echoed
+func setEcho(on bool) error {
+	arg := "-echo"
+	if on {
+		arg = "echo"
+	}
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+// helpEntry is one line of the help text; Description may span several
+// lines separated by "\n"
+type helpEntry struct {
+	Usage       string
+	Description string
+}
+
+var helpCommands = []helpEntry{
+	{"memo create [--encrypt] [--template <name>] [--expires <when>]", "Create a new note (optionally from a template);\n--expires takes a date or a duration like 12h, 7d, 2w"},
+	{"memo create --template <name> --var <name>=<value>", "Fill in a template variable instead of being asked\nfor it (repeatable)"},
+	{"memo create --notebook <name>", "Create the note in a notebook, using the notebook's\nconfigured ID prefix"},
+	{"memo create --location <place|lat,lon>", "Record where the note was taken; names of configured\nplaces get their coordinates (also for capture)"},
+	{"memo create --from-clipboard", "Create a note from the clipboard's text; the first line\nis the title"},
+	{"memo create --due <date> [--starts <date>] [--duration <length>]", "Give the note a due date, or a start and a duration;\ndates may read like tomorrow 5pm or next friday"},
+	{"memo list", "List all notes (with numbered references)"},
+	{"memo list --tag <tag>", "List notes with specific tag (including nested tags);\nrepeat --tag to require all of several tags"},
+	{"memo list --any-tag <a,b> --not-tag <c,d>", "List notes with at least one of some tags, or\nwithout any of others (combine with --tag)"},
+	{"memo list --notebook <name>", "List the notes in a notebook"},
+	{"memo list --author <name>", "List the notes written by an author"},
+	{"memo list --near <place> [--radius <km>]", "List notes taken within a distance (default 1 km) of a\nplace or lat,lon; places without coordinates match by name"},
+	{"memo list --lang <code>", "List the notes written in a language (en, de, fr, es,\nit, nl or pt), detected when notes are saved; set\nthe language field to correct it"},
+	{"memo list --format table|compact|oneline", "Choose a layout: an aligned table, one short line\nper note, or tab-separated ID and title for fzf/grep"},
+	{"memo list --where <field>=<value>", "List notes whose front matter field has a value (repeatable)"},
+	{"memo list --columns <list>", "List notes as a table with columns such as\nwords,chars,reading,modified,priority,status,reads,\nedits,notebook (other names show custom fields)"},
+	{"memo list --template '{{.ID}}\\t{{.Metadata.Title}}'", "Print each note with a Go template; also has .Number,\n.Notebook and the functions join, date, field, upper,\nlower and truncate (works for memo read, too)"},
+	{"memo read <note-id|number|title>", "Display a specific note"},
+	{"memo read <note> --related", "Display a note followed by the notes most like it"},
+	{"memo related [--top <n>] <note>", "List notes similar to a note by shared tags and\ndistinctive words (TF-IDF), with what they share"},
+	{"memo graph [--format dot|graphml] [--cluster tag|notebook] [<file>]", "Export the graph of notes, their [[links]] and tags\nfor Graphviz or Gephi (--no-tags leaves tags out)"},
+	{"memo copy [--title] <note>", "Copy a note's content to the clipboard (--title puts\nthe title first as a heading)"},
+	{"memo read <note> --plain [--sentences]", "Print only the text without Markdown, for piping into\nsay or other text-to-speech tools (one sentence per line)"},
+	{"memo edit [--force] <note-id|number|title>", "Edit a specific note (locks it while editing)"},
+	{"memo edit --append <note-id|number|title>", "Type lines to add to the end of a note, finishing\nwith a line holding only \".\" or Ctrl-D"},
+	{"memo edit --metadata <note-id|number|title>", "Edit title, status, priority, due date and other\nfields one by one without touching the content"},
+	{"memo edit --set <field>=<value> <note-id|number|title>", "Set a metadata field from a script (repeatable;\nan empty value clears the field)"},
+	{"memo edit --set color=<color> --set icon=<icon> <note>", "Show a note's title in a color (red, green, yellow,\nblue, magenta, cyan, white, gray or #rrggbb) and\nafter an icon such as an emoji in listings"},
+	{"memo edit --due|--starts|--duration|--completed <value> <note-id|number|title>", "Set a date field, written like 2026-03-01 14:00,\ntomorrow 5pm, next friday or in 2 weeks"},
+	{"memo append <note> [text|-]", "Add text to the end of a note (reads stdin without text)"},
+	{"memo attach <note> <file>...", "Attach files to a note; identical files are stored\nonce in .attachments and shared between notes"},
+	{"memo prepend <note> [text|-]", "Add text to the start of a note (reads stdin without text)"},
+	{"memo capture [--tag <tag>] [--notebook <name>] <text|->", "Create a note without prompts: the first line is the\ntitle and it is tagged 'inbox' (for keyboard launchers)"},
+	{"memo inbox [add <text|->|clear]", "Show the inbox note or add timestamped items to it\n(read or edit it like any note as 'inbox')"},
+	{"memo last [--edit]", "Show (or edit) the most recently used note"},
+	{"memo recent [n]", "List the last n notes read, edited or created (default 10)"},
+	{"memo back [<steps>]", "Read the note read before the current one, like a\nbrowser's back button"},
+	{"memo forward [<steps>]", "Read the next note again after going back"},
+	{"memo rename [--dry-run] [--no-links] <note> <new title>", "Change a note's title and update [[links]] to it in\nother notes (--dry-run previews the changes)"},
+	{"memo rename --id <note> <new-id>", "Give a note a new ID, moving its file, revisions and\nattachment references and updating [[links]]; the old\nID keeps working as an alias"},
+	{"memo delete [--force] <note-id|number|title>", "Delete a specific note (into the trash unless it is disabled)"},
+	{"memo trash status|list|empty", "Show the trash's usage and retention (trash: max_age_days,\nmax_size_mb in the config), list or empty it"},
+	{"memo trash restore <note-id>", "Put a deleted note back where it was"},
+	{"memo count-words <note>", "Show a note's words, characters, lines, paragraphs\nand estimated reading time"},
+	{"memo count [--tag <tag>] [--status <s>] [<terms>]", "Print only the number of matching notes (also takes\n--priority, --author, --notebook and --where)"},
+	{"memo exists [--title] <note-id|title>", "Exit with status 0 if the note exists, 1 if not"},
+	{"memo search [--case-sensitive] [--word] [--limit <n>] <query>", "Search notes for text (whole words only with --word);\nterms like priority:>=4 or status:active filter fields\n(custom fields need an operator, as in project:=alpha)"},
+	{"memo search --any <terms...>", "Find notes containing any of the terms instead of\nall of them; quote \"a phrase\" to keep words together\n(search_mode: any in the config makes this the default)"},
+	{"memo search --stem <query>", "Match words by their stems in each note's language, so\n\"running\" finds \"run\", ignoring accents and stop words\n(search_stemming: true in the config makes this the default;\n--no-stem turns it off)"},
+	{"memo search --context <n> <query>", "Show n characters around the first match in each\nresult instead of 100"},
+	{"memo search --save <name> <query>", "Save a search under a name and run it"},
+	{"memo search --saved <name>", "Run a saved search"},
+	{"memo search --list-saved", "List saved searches (--delete-saved <name> removes one)"},
+	{"memo encrypt <note-id|number|title>", "Encrypt a note's content (title and tags stay searchable)"},
+	{"memo decrypt <note-id|number|title>", "Store an encrypted note in plain text again"},
+	{"memo key store|forget|status", "Keep the encryption key in the OS keychain so it\nis not asked for (per profile)"},
+	{"memo find [<query>]", "Search interactively: matching notes are listed as\nyou type, and the one picked with the arrow keys and\nEnter is opened"},
+	{"memo grep [-i] [-F] [-A n] [-B n] [-C n] <pattern>", "Print matching lines with context"},
+	{"memo stats [--format text|json|csv]", "Display statistics about your notes"},
+	{"memo stats --since DATE [--until DATE]", "Count only notes created in a date range\n(YYYY-MM-DD, inclusive)"},
+	{"memo stats --since DATE [--until DATE] --compare", "Compare the range with the one of equal\nlength before it"},
+	{"memo report [--week|--month] [--previous] [--as-note]", "Write a Markdown review of the notes created and\nmodified this week or month, grouped by tag, with\ncompleted tasks (--as-note saves it as a note)"},
+	{"memo stats --words [--tag <tag>] [--top <n>]", "Show the most frequent words (without stopwords)\nto discover topics worth a tag"},
+	{"memo stats --authors [--top <n>]", "Break notes, words and most used tags down by author"},
+	{"memo stats --dashboard [--weeks <n>] [--top <n>]", "Show the store at a glance: notes created per week\nas a sparkline, the most used tags and the largest notes"},
+	{"memo stats --tags-over-time [--months <n>] [--top <n>]", "Chart how often the most used tags were used month\nby month (--format table shows the counts) and name\nnew and fading tags to see where your focus shifts"},
+	{"memo clock in <note-id|number|title>", "Start tracking time on a note, stopping the clock of\nany other"},
+	{"memo clock out [<note-id|number|title>]", "Stop the running clock (memo clock status shows it)"},
+	{"memo clock report [--week|--month] [--previous] [--tag <tag>]", "Sum up the hours tracked this week (the default) or\nmonth per note and per tag"},
+	{"memo dedupe [--threshold 0.8] [--list]", "Find duplicate notes and merge or delete them"},
+	{"memo purge-expired [--dry-run] [--yes]", "Delete notes whose 'expires' time has passed"},
+	{"memo split [--level <1-6>] [--dry-run] <note>", "Break a large note up by headings into linked notes"},
+	{"memo random [--tag <tag>] [--min-age <age>] [--count <n>]", "Show a random note to resurface old knowledge;\n--min-age skips notes younger than e.g. 30d, 6m or 1y.\nNotes with status or tag 'archived' are left out"},
+	{"memo audit [--id <note>] [--limit <n>] [--format text|json]", "Show who created, edited, tagged, renamed or deleted\nnotes, with the old and new metadata (from .audit.log)"},
+	{"memo lint [--strict] [<note>...]", "Check notes against the lint rules in the config (also\non save with lint.on_save); --strict makes warnings fail"},
+	{"memo doctor [--fix]", "Check notes for problems (and repair what can be fixed,\nincluding deleting unused attachments)"},
+	{"memo tag add|remove <note-id|number|title> <tag>...", "Add or remove tags on a note, keeping its other tags"},
+	{"memo autotag [--dry-run]", "Tag notes by the rules in .autotag.yaml in the store,\ne.g. \"- pattern: standup\" with \"tags: [meeting]\""},
+	{"memo tags [--tree]", "List tags with note counts (--tree shows nesting)"},
+	{"memo tags --merge-hashtags", "Add #hashtags written in note content to the notes'\ntags (set merge_hashtags: true in a profile to do\nthis on every save)"},
+	{"memo tasks [--all]", "List open checklist items across notes"},
+	{"memo tasks done <note> <n>", "Check off task n of a note"},
+	{"memo tasks undo <note> <n>", "Reopen task n of a note"},
+	{"memo revisions <note-id|number|title>", "List previous versions of a note"},
+	{"memo rollback <note-id|number|title> <revision>", "Restore a previous version of a note"},
+	{"memo history --global [--limit <n>] [--revert <number>]", "Show the latest changes to all notes, newest first,\nand revert one; reverting a revert redoes the change"},
+	{"memo history <note-id|number|title> [--revert <number>]", "Show and revert the changes to one note"},
+	{"memo recover [--rollback | --complete]", "Undo the changes of a rename, merge, import, split or\npurge that memo was interrupted in, and with --complete\nrun it again"},
+	{"memo export <note-id|number|title> <file.md|->", "Export a note as Markdown with front matter"},
+	{"memo export <note> <file.pdf|-> [--format pdf]", "Export a note as a printable PDF with its front matter\nand attachments (the format is implied by .pdf)"},
+	{"memo export --format ics [--batch-size <n>] <file.ics|->", "Export notes with a 'due' date as calendar events"},
+	{"memo export --format todotxt [--batch-size <n>] <todo.txt|->", "Export checklist tasks as todo.txt lines with the\nnote's priority, dates and tags"},
+	{"memo export --all [--batch-size <n>] <notes.zip|notes.tar.gz|->", "Export every note as a Markdown file into an archive;\nnotes are read --batch-size at a time (default 500)"},
+	{"memo import <file.md|->", "Import a Markdown file as a new note"},
+	{"memo import [--format csv|json] <file|->", "Create a note per row or object, with columns\ntitle, content, tags and created"},
+	{"memo import --format kindle [--per-highlight] <file>", "Import Kindle 'My Clippings.txt' highlights as\none note per book (or per highlight)"},
+	{"memo import --format todotxt <todo.txt>", "Create a note per todo.txt task, keeping priority,\ndates, +projects and @contexts"},
+	{"memo migrate [--status]", "Update every note's front matter to the current\nschema version (recorded in .version in the store)"},
+	{"memo migrate-store [--dry-run] [--remove] [<dir>...]", "Move notes from old stores (default ./.memo-notes)\ninto the current one; taken IDs get a new suffix"},
+	{"memo notebooks", "List notebooks with their note counts and ID prefixes"},
+	{"memo sync [--dry-run] [--prefer local|remote] <dir>", "Two-way sync with a directory (e.g. a cloud drive\nfolder); only notes whose content changed are copied"},
+	{"memo resolve [--target <dir>] [--keep local|remote|both] <note-id>", "Show a three-way diff of a note changed on both\nsides of a sync and merge it, or keep one or both versions"},
+	{"memo backup [--dir <dir>] [--format tar.gz|zip] [--keep <n>]", "Archive the notes store and configuration;\n--keep deletes all but the newest n backups\n(--list shows existing backups)"},
+	{"memo watch [--poll <duration>] [--snapshot-interval <duration>] [--keep <n>] [--no-snapshots]", "Report note files changing until interrupted, and back\nthe store up every 24h (keeping 7) while something changed"},
+	{"memo restore-backup [--with-config] [--dry-run] <archive>", "Verify a backup and replace the notes with it"},
+	{"memo recur add <name> --every <schedule> --template <name>", "Create notes from a template on a schedule"},
+	{"memo recur list|remove <name>|run", "Manage recurring notes; 'run' creates due notes (cron-friendly)"},
+	{"memo daemon [--socket <path>]", "Answer editor plugins over a Unix socket with one JSON\nrequest per line (methods list, search, get, create,\nupdate, delete, tags, ping)"},
+	{"memo serve [--addr host:port] [--web] [--webdav] [--grpc-addr host:port]", "Serve the REST API (and web UI with --web, WebDAV at /dav/ with --webdav,\ngRPC with --grpc-addr)"},
+	{"memo serve --openapi", "Print the OpenAPI document of the REST API (also served\nat /api/openapi.yaml and /api/openapi.json)"},
+	{"memo user add <name> [--store <profile>] [--admin]", "Add an account for memo serve; once one exists, every\nrequest needs its password or an API token. Users share\nthe store and change only notes they authored, unless\n--store gives them a profile's store of their own"},
+	{"memo user passwd|token|remove <name>", "Change a password, create an API token or remove a user\n('memo user list' lists the accounts)"},
+	{"memo share [--addr host:port] [--for duration] [--views n] <note>", "Serve one note as a web page at a secret URL until the limit is reached"},
+	{"memo profiles", "List configured profiles"},
+	{"memo init [<dir>]", "Create a project store (.memo-notes) in a directory;\nmemo finds it from there and below, like git"},
+	{"memo --help", "Display this help information"},
+}
+
+var helpGlobalOptions = []helpEntry{
+	{"--profile <name>", "Use the named profile from the config file"},
+	{"--global", "Use the profile's store even inside a project store"},
+	{"--read-only", "Refuse to create, edit or delete notes"},
+	{"--force", "Write even to notes locked by another edit session"},
+	{"--dry-run", "Show what a command would change without writing anything"},
+	{"-v, --verbose", "Print debug information about storage operations"},
+	{"-q, --quiet", "Suppress warnings (for scripting)"},
+}
+
+// helpColumn is where descriptions start in the help text
+const helpColumn = 32
+
 func PrintHelp() {
-	fmt.Println("Memo - Personal Notes Manager")
+	fmt.Println(T("Memo - Personal Notes Manager"))
+	fmt.Println("")
+	fmt.Println(T("Usage:"))
+	printHelpEntries(helpCommands)
 	fmt.Println("")
-	fmt.Println("Usage:")
-	fmt.Println("  memo create                     Create a new note")
-	fmt.Println("  memo list                       List all notes (with numbered references)")
-	fmt.Println("  memo list --tag <tag>           List notes with specific tag")
-	fmt.Println("  memo read <note-id|number>      Display a specific note")
-	fmt.Println("  memo edit <note-id|number>      Edit a specific note")
-	fmt.Println("  memo delete <note-id|number>    Delete a specific note")
-	fmt.Println("  memo search <query>             Search notes for text")
-	fmt.Println("  memo stats                      Display statistics about your notes")
-	fmt.Println("  memo --help                     Display this help information")
+	fmt.Println(T("Global options:"))
+	printHelpEntries(helpGlobalOptions)
 	fmt.Println("")
-	fmt.Println("Note: After running 'memo list', you can use numbers 1-N to reference notes")
-	fmt.Println("      instead of the full note ID (e.g., 'memo read 3' or 'memo edit 5')")
+	fmt.Println(T("Note: After running 'memo list', you can use numbers 1-N to reference notes\n      instead of the full note ID (e.g., 'memo read 3' or 'memo edit 5')"))
+}
+
+func printHelpEntries(entries []helpEntry) {
+	indent := strings.Repeat(" ", helpColumn+2)
+	for _, e := range entries {
+		lines := strings.Split(T(e.Description), "\n")
+		if len(e.Usage) < helpColumn {
+			fmt.Printf("  %-*s%s\n", helpColumn, e.Usage, lines[0])
+			lines = lines[1:]
+		} else {
+			fmt.Printf("  %s\n", e.Usage)
+		}
+		for _, line := range lines {
+			fmt.Printf("%s%s\n", indent, line)
+		}
+	}
 }
 
 func DisplayNotesWithPagination(notes []*note.Note) {
@@ -46,7 +309,7 @@ func DisplayNotesWithPagination(notes []*note.Note) {
 			endIndex = len(notes)
 		}
 
-		fmt.Printf("\nShowing notes %d-%d of %d:\n", startIndex+1, endIndex, len(notes))
+		fmt.Print(Tf("\nShowing notes %d-%d of %d:\n", startIndex+1, endIndex, len(notes)))
 		fmt.Println("========================================")
 
 		for i := startIndex; i < endIndex; i++ {
@@ -54,136 +317,1257 @@ func DisplayNotesWithPagination(notes []*note.Note) {
 			noteID := strings.TrimSuffix(filepath.Base(n.FilePath), ".note")
 			listNumber := i + 1
 
-			fmt.Printf("%2d. %s | Created: %s\n",
+			fmt.Print(Tf("%2d. %s | Created: %s\n",
 				listNumber,
-				n.Metadata.Title,
-				n.Metadata.Created.Format("2006-01-02 15:04"))
+				ListTitle(n),
+				n.Metadata.Created.Format("2006-01-02 15:04")))
 
 			if len(n.Metadata.Tags) > 0 {
-				fmt.Printf("    Tags: %s\n", strings.Join(n.Metadata.Tags, ", "))
+				fmt.Print(Tf("    Tags: %s\n", strings.Join(n.Metadata.Tags, ", ")))
+			}
+			if n.Expired(time.Now()) {
+				fmt.Print(Tf("    Expired: %s\n", n.Metadata.Expires.Format("2006-01-02 15:04")))
 			}
-			fmt.Printf("    ID: %s\n", noteID)
+			displayLargeNote(n)
+			fmt.Print(Tf("    ID: %s\n", noteID))
 			fmt.Println()
 		}
 
 		if endIndex >= len(notes) {
-			fmt.Println("End of notes.")
+			fmt.Println(T("End of notes."))
 			break
 		}
 
-		fmt.Printf("Show next %d notes? (y/N): ", pageSize)
-		response := PromptForInput("")
-
-		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+		fmt.Print(Tf("Show next %d notes? (y/N): ", pageSize))
+		if !isYes(PromptForInput("")) {
 			break
 		}
 
 		startIndex = endIndex
 	}
 
-	fmt.Println("\nTip: Use 'memo read <number>' or 'memo edit <number>' with numbers 1-" + strconv.Itoa(len(notes)) + " from this listing.")
+	fmt.Print(Tf("\nTip: Use 'memo read <number>' or 'memo edit <number>' with numbers 1-%d from this listing.\n", len(notes)))
+}
+
+// formatMoment shows a date field with its time of day, if it has one
+func formatMoment(m note.Moment) string {
+	if !m.Valid() || m.AllDay {
+		return m.String()
+	}
+	return m.Time.Format("2006-01-02 15:04")
 }
 
 func DisplayNote(n *note.Note) {
-	fmt.Printf("Title: %s\n", n.Metadata.Title)
-	fmt.Printf("Created: %s\n", n.Metadata.Created.Format("2006-01-02 15:04:05"))
-	fmt.Printf("Modified: %s\n", n.Metadata.Modified.Format("2006-01-02 15:04:05"))
+	fmt.Print(Tf("Title: %s\n", n.Metadata.Title))
+	fmt.Print(Tf("Created: %s\n", n.Metadata.Created.Format("2006-01-02 15:04:05")))
+	fmt.Print(Tf("Modified: %s\n", n.Metadata.Modified.Format("2006-01-02 15:04:05")))
 
 	if len(n.Metadata.Tags) > 0 {
-		fmt.Printf("Tags: %s\n", strings.Join(n.Metadata.Tags, ", "))
+		fmt.Print(Tf("Tags: %s\n", strings.Join(n.Metadata.Tags, ", ")))
 	}
 
 	if n.Metadata.Author != "" {
-		fmt.Printf("Author: %s\n", n.Metadata.Author)
+		fmt.Print(Tf("Author: %s\n", n.Metadata.Author))
 	}
 
 	if n.Metadata.Status != "" {
-		fmt.Printf("Status: %s\n", n.Metadata.Status)
+		fmt.Print(Tf("Status: %s\n", n.Metadata.Status))
 	}
 
 	if n.Metadata.Priority > 0 {
-		fmt.Printf("Priority: %d\n", n.Metadata.Priority)
+		fmt.Print(Tf("Priority: %d\n", n.Metadata.Priority))
 	}
 
-	fmt.Println("\nContent:")
+	if n.Metadata.Location != nil {
+		fmt.Print(Tf("Location: %s\n", n.Metadata.Location))
+	}
+	if !n.Metadata.Expires.IsZero() {
+		fmt.Print(Tf("Expires: %s\n", n.Metadata.Expires.Format("2006-01-02 15:04:05")))
+	}
+	if !n.Metadata.Due.IsZero() {
+		fmt.Print(Tf("Due: %s\n", formatMoment(n.Metadata.Due)))
+	}
+	if !n.Metadata.Starts.IsZero() {
+		fmt.Print(Tf("Starts: %s\n", formatMoment(n.Metadata.Starts)))
+	}
+	if !n.Metadata.Duration.IsZero() {
+		fmt.Print(Tf("Duration: %s\n", n.Metadata.Duration))
+	}
+	if !n.Metadata.CompletedAt.IsZero() {
+		fmt.Print(Tf("Completed: %s\n", formatMoment(n.Metadata.CompletedAt)))
+	}
+	if len(n.Metadata.Clock) > 0 {
+		spent := FormatClock(n.TimeSpent(time.Time{}, time.Time{}, time.Now()))
+		if n.Running() {
+			fmt.Print(Tf("Time spent: %s (clock running)\n", spent))
+		} else {
+			fmt.Print(Tf("Time spent: %s\n", spent))
+		}
+	}
+
+	for _, name := range n.FieldNames() {
+		values, _ := n.Field(name)
+		fmt.Printf("%s: %s\n", name, strings.Join(values, ", "))
+	}
+
+	if !n.Locked() {
+		counts := analysis.CountText(n.Content)
+		fmt.Print(Tf("Length: %d words, %s read\n", counts.Words, readingTime(counts)))
+	}
+
+	fmt.Println(T("\nContent:"))
 	fmt.Println("--------")
-	fmt.Println(n.Content)
+	if IsTerminal() {
+		fmt.Println(render.HighlightTerminal(n.Content))
+	} else {
+		fmt.Println(n.Content)
+	}
+}
+
+// DisplayTextCounts shows the length of a note for `memo count-words`
+func DisplayTextCounts(n *note.Note, counts analysis.TextCounts) {
+	fmt.Print(Tf("Title: %s\n", n.Metadata.Title))
+	fmt.Print(Tf("Words: %d\n", counts.Words))
+	fmt.Print(Tf("Characters: %d (%d without spaces)\n", counts.Characters, counts.Letters))
+	fmt.Print(Tf("Lines: %d\n", counts.Lines))
+	fmt.Print(Tf("Paragraphs: %d\n", counts.Paragraphs))
+	fmt.Print(Tf("Reading time: %s (at %d words per minute)\n", readingTime(counts), analysis.WordsPerMinute))
+}
+
+// readingTime formats the estimated reading time of a text
+func readingTime(counts analysis.TextCounts) string {
+	return Tf("%d min", int(counts.ReadingTime().Minutes()))
 }
 
-func DisplaySearchResults(notes []*note.Note, query string) {
+// DisplaySearchResults lists the notes found by query with a snippet of
+// length characters around the first match of pattern (which may be nil)
+func DisplaySearchResults(notes []*note.Note, query string, pattern *regexp.Regexp, length int) {
 	if len(notes) == 0 {
-		fmt.Printf("No notes found matching '%s'\n", query)
+		fmt.Print(Tf("No notes found matching '%s'\n", query))
 		return
 	}
 
-	fmt.Printf("Found %d note(s) matching '%s':\n\n", len(notes), query)
+	fmt.Print(Tf("Found %d note(s) matching '%s':\n\n", len(notes), query))
 
 	for _, n := range notes {
-		noteID := strings.TrimSuffix(filepath.Base(n.FilePath), ".note")
-		fmt.Printf("ID: %s | Title: %s\n", noteID, n.Metadata.Title)
+		DisplaySearchResult(n, pattern, length)
+	}
+}
+
+// DisplaySearchResult prints one note found by `memo search`, for results
+// shown as they are found
+func DisplaySearchResult(n *note.Note, pattern *regexp.Regexp, length int) {
+	noteID := strings.TrimSuffix(filepath.Base(n.FilePath), ".note")
+	fmt.Print(Tf("ID: %s | Title: %s\n", noteID, ListTitle(n)))
+
+	preview := snippet(n.Content, pattern, length)
+	if n.Locked() {
+		preview = T("(encrypted)")
+	}
+	fmt.Print(Tf("Preview: %s\n", preview))
+	displayLargeNote(n)
+	fmt.Println("--------")
+}
+
+// DisplaySearchCount closes results shown with DisplaySearchResult
+func DisplaySearchCount(count int, query string) {
+	if count == 0 {
+		fmt.Print(Tf("No notes found matching '%s'\n", query))
+		return
+	}
+	fmt.Print(Tf("%d note(s) found matching '%s'\n", count, query))
+}
+
+func DisplayStats(s *stats.Stats) {
+	if s.TotalNotes == 0 {
+		fmt.Println(T("No notes found."))
+		return
+	}
 
-		preview := n.Content
-		if len(preview) > 100 {
-			preview = preview[:100] + "..."
+	fmt.Println(T("Note Statistics:"))
+	if s.Period != nil {
+		fmt.Print(Tf("Notes created: %s\n", s.Period))
+	}
+	fmt.Print(Tf("Total notes: %d\n", s.TotalNotes))
+	fmt.Print(Tf("Total words: %d\n", s.TotalWords))
+	fmt.Print(Tf("Average words per note: %.1f\n", s.AverageWords))
+
+	if s.Oldest != nil {
+		fmt.Print(Tf("Oldest note: %s (%s)\n", s.Oldest.Title, s.Oldest.Created.Format("2006-01-02")))
+	}
+	if s.Newest != nil {
+		fmt.Print(Tf("Newest note: %s (%s)\n", s.Newest.Title, s.Newest.Created.Format("2006-01-02")))
+	}
+
+	if s.OpenTasks+s.DoneTasks > 0 {
+		fmt.Print(Tf("Tasks: %d open, %d done\n", s.OpenTasks, s.DoneTasks))
+	}
+
+	if len(s.Tags) > 0 {
+		fmt.Println(T("\nTag usage:"))
+		for _, tc := range s.Tags {
+			fmt.Printf("  %s: %d\n", tc.Tag, tc.Count)
 		}
-		fmt.Printf("Preview: %s\n", preview)
-		fmt.Println("--------")
 	}
 }
 
-func DisplayStats(notes []*note.Note) {
-	if len(notes) == 0 {
-		fmt.Println("No notes found.")
+// sparkLevels are the bars of a sparkline, from lowest to highest
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws values as a row of bars scaled to the largest one
+func sparkline(values []int) string {
+	top := slices.Max(append([]int{0}, values...))
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if top > 0 && v > 0 {
+			level = 1 + (v*(len(sparkLevels)-1)-1)/top
+		}
+		b.WriteRune(sparkLevels[level])
+	}
+	return b.String()
+}
+
+// dashboardBarWidth is the length of the longest bar on the dashboard
+const dashboardBarWidth = 30
+
+// DisplayDashboard shows the store overview of `memo stats --dashboard`,
+// clearing the screen first on a terminal
+func DisplayDashboard(d *stats.Dashboard) {
+	s := d.Summary
+	if s.TotalNotes == 0 {
+		fmt.Println(T("No notes found."))
 		return
 	}
+	if IsTerminal() {
+		fmt.Print("\033[H\033[2J")
+	}
 
-	fmt.Println("Note Statistics:")
-	fmt.Printf("Total notes: %d\n", len(notes))
+	fmt.Println(T("Store dashboard"))
+	fmt.Println("===============")
+	fmt.Print(Tf("Notes: %d   Words: %d   Average: %.0f words\n", s.TotalNotes, s.TotalWords, s.AverageWords))
+	if s.OpenTasks+s.DoneTasks > 0 {
+		fmt.Print(Tf("Tasks: %d open, %d done\n", s.OpenTasks, s.DoneTasks))
+	}
 
-	tagCount := make(map[string]int)
-	var totalWords int
-	var oldestNote, newestNote *note.Note
+	counts := make([]int, len(d.Weeks))
+	total, busiest := 0, 0
+	for i, w := range d.Weeks {
+		counts[i] = w.Notes
+		total += w.Notes
+		if w.Notes > d.Weeks[busiest].Notes {
+			busiest = i
+		}
+	}
+	fmt.Print(Tf("\nNotes created per week since %s:\n", d.Weeks[0].Start.Format("2006-01-02")))
+	fmt.Printf("  %s\n", sparkline(counts))
+	if total > 0 {
+		fmt.Print(Tf("  %d in %d weeks, most in the week of %s (%d)\n", total, len(d.Weeks), d.Weeks[busiest].Start.Format("2006-01-02"), d.Weeks[busiest].Notes))
+	} else {
+		fmt.Print(Tf("  none in %d weeks\n", len(d.Weeks)))
+	}
+
+	if len(d.Tags) > 0 {
+		fmt.Println(T("\nMost used tags:"))
+		width := 0
+		for _, tc := range d.Tags {
+			width = max(width, len([]rune(tc.Tag)))
+		}
+		for _, tc := range d.Tags {
+			bar := max(1, tc.Count*dashboardBarWidth/d.Tags[0].Count)
+			fmt.Printf("  %-*s %s %d\n", width, tc.Tag, strings.Repeat("█", bar), tc.Count)
+		}
+	}
+
+	if len(d.Largest) > 0 {
+		fmt.Println(T("\nLargest notes:"))
+		for i, n := range d.Largest {
+			fmt.Print(Tf("  %d. %s (%s) | %d words\n", i+1, n.Title, n.ID, n.Words))
+		}
+	}
+}
 
+// trendLabel names the trend of a tag in listings
+func trendLabel(trend stats.Trend) string {
+	switch trend {
+	case stats.New:
+		return T("new")
+	case stats.Fading:
+		return T("fading")
+	case stats.Rising:
+		return T("rising")
+	case stats.Falling:
+		return T("falling")
+	}
+	return ""
+}
+
+// DisplayTagTrends shows how often the most used tags were used month by
+// month, as a sparkline per tag or with table as a table of counts, and
+// names the tags that appeared or faded
+func DisplayTagTrends(t *stats.TagTrends, table bool) {
+	if len(t.Tags) == 0 {
+		fmt.Print(Tf("No tags used since %s.\n", t.Months[0].Format("2006-01")))
+		return
+	}
+
+	fmt.Print(Tf("Notes per tag and month from %s to %s:\n", t.Months[0].Format("2006-01"), t.Months[len(t.Months)-1].Format("2006-01")))
+	if table {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.AlignRight)
+		header := T("Tag") + "\t"
+		for _, m := range t.Months {
+			header += m.Format("Jan") + "\t"
+		}
+		fmt.Fprintln(w, header+T("Total")+"\t")
+		for _, tt := range t.Tags {
+			row := tt.Tag + "\t"
+			for _, count := range tt.Counts {
+				row += strconv.Itoa(count) + "\t"
+			}
+			fmt.Fprintf(w, "%s%d\t\n", row, tt.Total)
+		}
+		w.Flush()
+	} else {
+		width := 0
+		for _, tt := range t.Tags {
+			width = max(width, len([]rune(tt.Tag)))
+		}
+		for _, tt := range t.Tags {
+			line := fmt.Sprintf("  %-*s %s %4d", width, tt.Tag, sparkline(tt.Counts), tt.Total)
+			if tt.Trend != stats.Steady {
+				line += "  " + trendLabel(tt.Trend)
+			}
+			fmt.Println(line)
+		}
+	}
+
+	if len(t.New) > 0 {
+		fmt.Print(Tf("\nNew tags: %s\n", strings.Join(t.New, ", ")))
+	}
+	if len(t.Fading) > 0 {
+		fmt.Print(Tf("\nFading tags: %s\n", strings.Join(t.Fading, ", ")))
+	}
+}
+
+// DisplayClockReport shows the hours tracked per note and per tag
+func DisplayClockReport(r *stats.ClockReport) {
+	if len(r.Notes) == 0 {
+		fmt.Print(Tf("No time tracked %s.\n", r.Period))
+		return
+	}
+
+	fmt.Print(Tf("Time tracked %s: %s\n", r.Period, FormatClock(r.Total)))
+	fmt.Println(T("\nBy note:"))
+	for _, n := range r.Notes {
+		line := fmt.Sprintf("  %8s  %s (%s)", FormatClock(n.Time), n.Title, n.ID)
+		if n.Running {
+			line += " " + T("(running)")
+		}
+		fmt.Println(line)
+	}
+	if len(r.Tags) > 0 {
+		fmt.Println(T("\nBy tag:"))
+		for _, t := range r.Tags {
+			fmt.Printf("  %8s  %s\n", FormatClock(t.Time), t.Tag)
+		}
+	}
+}
+
+// FormatClock shows tracked time in hours and minutes, e.g. "12h05m"
+func FormatClock(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
+
+// DisplayWordFrequency lists the most frequent terms; terms that are
+// already tags are marked so new tag candidates stand out
+func DisplayWordFrequency(wf *stats.WordFrequency, tag string) {
+	if len(wf.Words) == 0 {
+		fmt.Println(T("No words found."))
+		return
+	}
+
+	if tag != "" {
+		fmt.Print(Tf("Most frequent words in %d note(s) tagged '%s':\n", wf.TotalNotes, tag))
+	} else {
+		fmt.Print(Tf("Most frequent words in %d note(s):\n", wf.TotalNotes))
+	}
+	for i, wc := range wf.Words {
+		line := Tf("%3d. %-20s %5d in %d note(s)", i+1, wc.Word, wc.Count, wc.Notes)
+		if wc.Tagged {
+			line += " " + T("(tag)")
+		}
+		fmt.Println(line)
+	}
+}
+
+func DisplayAuthorStats(ab *stats.AuthorBreakdown) {
+	if len(ab.Authors) == 0 {
+		fmt.Println(T("No notes found."))
+		return
+	}
+
+	if ab.Period != nil {
+		fmt.Print(Tf("Notes by author (created %s):\n", ab.Period))
+	} else {
+		fmt.Println(T("Notes by author:"))
+	}
+	for _, a := range ab.Authors {
+		author := a.Author
+		if author == "" {
+			author = T("(no author)")
+		}
+		fmt.Print(Tf("  %s: %d note(s), %d words (%.1f per note)\n", author, a.Notes, a.Words, a.AverageWords))
+		if len(a.Tags) > 0 {
+			var tags []string
+			for _, tc := range a.Tags {
+				tags = append(tags, fmt.Sprintf("%s (%d)", tc.Tag, tc.Count))
+			}
+			fmt.Print(Tf("    Most used tags: %s\n", strings.Join(tags, ", ")))
+		}
+	}
+}
+
+// DisplayStatsComparison shows two periods side by side with the change
+// between them
+func DisplayStatsComparison(c *stats.Comparison) {
+	fmt.Print(Tf("Comparing notes created %s with %s:\n", c.CurrentPeriod, c.PreviousPeriod))
+	fmt.Print(Tf("Notes: %d → %d (%s)\n", c.Previous.TotalNotes, c.Current.TotalNotes, growth(c.Previous.TotalNotes, c.Current.TotalNotes)))
+	fmt.Print(Tf("Words: %d → %d (%s)\n", c.Previous.TotalWords, c.Current.TotalWords, growth(c.Previous.TotalWords, c.Current.TotalWords)))
+	fmt.Print(Tf("Average words per note: %.1f → %.1f\n", c.Previous.AverageWords, c.Current.AverageWords))
+	if c.Previous.OpenTasks+c.Previous.DoneTasks+c.Current.OpenTasks+c.Current.DoneTasks > 0 {
+		fmt.Print(Tf("Tasks done: %d → %d (%s)\n", c.Previous.DoneTasks, c.Current.DoneTasks, growth(c.Previous.DoneTasks, c.Current.DoneTasks)))
+	}
+
+	if len(c.Tags) > 0 {
+		fmt.Print(Tf("\nTags: %d → %d\n", len(c.Previous.Tags), len(c.Current.Tags)))
+		for _, d := range c.Tags {
+			line := fmt.Sprintf("  %s: %d → %d (%s)", d.Tag, d.Previous, d.Current, growth(d.Previous, d.Current))
+			switch {
+			case d.Previous == 0:
+				line += " " + T("new")
+			case d.Current == 0:
+				line += " " + T("unused")
+			}
+			fmt.Println(line)
+		}
+	}
+}
+
+// growth formats the change from prev to cur, with a percentage when there
+// is a base to compare against
+func growth(prev, cur int) string {
+	delta := fmt.Sprintf("%+d", cur-prev)
+	if prev == 0 {
+		return delta
+	}
+	return fmt.Sprintf("%s, %+.0f%%", delta, float64(cur-prev)*100/float64(prev))
+}
+
+// ChooseNote lists notes matching query and asks which one was meant. It
+// returns nil when the answer is not one of the listed numbers.
+func ChooseNote(notes []*note.Note, query string) *note.Note {
+	fmt.Print(Tf("Several notes match '%s':\n", query))
 	for i, n := range notes {
-		words := strings.Fields(n.Content)
-		totalWords += len(words)
+		fmt.Printf("%2d. %s (%s) | %s\n", i+1, ListTitle(n), n.ID(), n.Metadata.Created.Format("2006-01-02 15:04"))
+	}
 
-		if i == 0 {
-			oldestNote = n
-			newestNote = n
-		} else {
-			if n.Metadata.Created.Before(oldestNote.Metadata.Created) {
-				oldestNote = n
+	choice, err := strconv.Atoi(strings.TrimSpace(PromptForInput(Tf("Select a note (1-%d): ", len(notes)))))
+	if err != nil || choice < 1 || choice > len(notes) {
+		return nil
+	}
+	return notes[choice-1]
+}
+
+// DisplaySavedSearches lists saved search names with their queries
+func DisplaySavedSearches(saved map[string]string) {
+	if len(saved) == 0 {
+		fmt.Println(T("No saved searches. Save one with: memo search --save <name> <query>"))
+		return
+	}
+
+	names := make([]string, 0, len(saved))
+	for name := range saved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println(T("Saved searches:"))
+	for _, name := range names {
+		fmt.Printf("  %-15s %s\n", name, saved[name])
+	}
+}
+
+// DisplayBackups lists backup archives, newest first
+func DisplayBackups(backups []backup.Info, dir string) {
+	if len(backups) == 0 {
+		fmt.Print(Tf("No backups in %s\n", dir))
+		return
+	}
+
+	fmt.Print(Tf("Backups in %s:\n", dir))
+	for _, b := range backups {
+		fmt.Printf("  %s  %s  %s\n", b.Created.Format("2006-01-02 15:04:05"), formatSize(b.Size), filepath.Base(b.Path))
+	}
+}
+
+// formatSize renders a byte count with a binary unit
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// DisplayTrashStatus shows how much the trash holds and how long it keeps
+// notes; a nil policy means deleted notes are not kept
+func DisplayTrashStatus(entries []trash.Entry, policy *trash.Policy) {
+	if policy == nil {
+		fmt.Println(T("The trash is disabled; deleted notes are removed right away."))
+		if len(entries) == 0 {
+			return
+		}
+	}
+	fmt.Print(Tf("Trash: %d note(s), %s\n", len(entries), formatSize(trash.Size(entries))))
+	if policy != nil {
+		age, size := T("until emptied"), T("no size limit")
+		if policy.MaxAge > 0 {
+			age = Tf("for %d day(s)", int(policy.MaxAge.Hours()/24))
+		}
+		if policy.MaxBytes > 0 {
+			size = Tf("up to %s", formatSize(policy.MaxBytes))
+		}
+		fmt.Print(Tf("Keeps deleted notes %s, %s\n", age, size))
+	}
+	if len(entries) == 0 {
+		return
+	}
+	oldest := entries[0]
+	fmt.Print(Tf("Oldest: %s (%s), deleted %s\n", oldest.Title, oldest.ID, oldest.Deleted.Format("2006-01-02 15:04")))
+	if policy == nil {
+		return
+	}
+	if expires, ok := policy.ExpiresAt(oldest); ok {
+		fmt.Print(Tf("Next removal: %s on %s\n", oldest.ID, expires.Format("2006-01-02 15:04")))
+	}
+}
+
+// DisplayTrash lists the notes in the trash, oldest first
+func DisplayTrash(entries []trash.Entry, policy *trash.Policy) {
+	if len(entries) == 0 {
+		fmt.Println(T("The trash is empty."))
+		return
+	}
+	fmt.Println(T("Deleted notes:"))
+	for _, e := range entries {
+		fmt.Printf("  %-22s %s  %9s  %s", e.ID, e.Deleted.Format("2006-01-02 15:04"), formatSize(e.Size), e.Title)
+		if e.Notebook != "" {
+			fmt.Printf(" [%s]", e.Notebook)
+		}
+		if policy != nil {
+			if expires, ok := policy.ExpiresAt(e); ok {
+				fmt.Print(Tf(" (removed %s)", expires.Format("2006-01-02")))
+			}
+		}
+		fmt.Println()
+	}
+	fmt.Println(T("Restore a note with: memo trash restore <note-id>"))
+}
+
+// DisplayInbox shows the items of the inbox note; nil means it does not
+// exist yet
+func DisplayInbox(n *note.Note) {
+	if n == nil || strings.TrimSpace(n.Content) == "" {
+		fmt.Println(T("The inbox is empty. Add to it with: memo inbox add <text>"))
+		return
+	}
+	fmt.Println(T("Inbox:"))
+	fmt.Println(n.Content)
+}
+
+// LinkUpdate is a note whose links to a renamed note were rewritten
+type LinkUpdate struct {
+	Note  *note.Note
+	Links int
+}
+
+// DisplayLinkUpdates lists the notes whose [[wikilinks]] follow a renamed
+// title, or would with dryRun
+func DisplayLinkUpdates(updates []LinkUpdate, dryRun bool) {
+	if len(updates) == 0 {
+		return
+	}
+	if dryRun {
+		fmt.Println(T("Links that would be updated:"))
+	} else {
+		fmt.Println(T("Updated links:"))
+	}
+	for _, u := range updates {
+		fmt.Print(Tf("  %s (%s): %d link(s)\n", u.Note.Metadata.Title, u.Note.ID(), u.Links))
+	}
+}
+
+// DisplayMigration reports what `memo migrate-store` did, or would do with
+// dryRun, with the notes of src and the auxiliary files copied along
+func DisplayMigration(src, target string, migrated []storage.MigratedNote, copied []string, dryRun bool) {
+	if dryRun {
+		fmt.Print(Tf("Migrating %s into %s would:\n", src, target))
+	} else {
+		fmt.Print(Tf("Migrated %s into %s:\n", src, target))
+	}
+
+	counts := make(map[string]int)
+	for _, m := range migrated {
+		counts[m.Outcome]++
+		id := m.ID
+		if m.Notebook != "" {
+			id = m.Notebook + "/" + id
+		}
+		switch m.Outcome {
+		case storage.MigrateRenamed:
+			fmt.Print(Tf("  %s -> %s (ID was taken)\n", id, m.NewID))
+		case storage.MigrateCopied:
+			fmt.Printf("  %s\n", id)
+		}
+	}
+	for _, file := range copied {
+		fmt.Printf("  %s\n", file)
+	}
+	fmt.Print(Tf("%d note(s) copied, %d renamed, %d already present\n",
+		counts[storage.MigrateCopied], counts[storage.MigrateRenamed], counts[storage.MigrateIdentical]))
+}
+
+// DisplayDryRunChange reports a change that --dry-run kept from being made;
+// it is installed as the store's dry-run callback
+func DisplayDryRunChange(action, target string) {
+	switch action {
+	case "create":
+		fmt.Print(Tf("Would create note '%s'\n", target))
+	case "save":
+		fmt.Print(Tf("Would save note %s\n", target))
+	case "delete":
+		fmt.Print(Tf("Would delete note %s\n", target))
+	case "rename":
+		fmt.Print(Tf("Would rename note %s\n", target))
+	case "repair":
+		fmt.Print(Tf("Would repair note %s\n", target))
+	case "write":
+		fmt.Print(Tf("Would replace note %s\n", target))
+	case "attach":
+		fmt.Print(Tf("Would attach %s\n", target))
+	case "schema":
+		fmt.Print(Tf("Would set the store's schema version to %s\n", target))
+	case "purge":
+		fmt.Print(Tf("Would remove note %s from the trash for good\n", target))
+	case "restore":
+		fmt.Print(Tf("Would restore note %s from the trash\n", target))
+	case "resolve":
+		fmt.Print(Tf("Would resolve the sync conflict of note %s\n", target))
+	default:
+		fmt.Print(Tf("Would %s %s\n", action, target))
+	}
+}
+
+// DisplaySyncPlan lists what a sync does, or would do with dryRun
+func DisplaySyncPlan(steps []notesync.Step, dryRun bool) {
+	if len(steps) == 0 {
+		fmt.Println(T("Everything is in sync."))
+		return
+	}
+	if dryRun {
+		fmt.Println(T("Sync would:"))
+	}
+	for _, step := range steps {
+		fmt.Printf("  %-14s %s\n", syncActionLabel(step.Action), step.ID)
+	}
+}
+
+func syncActionLabel(a notesync.Action) string {
+	switch a {
+	case notesync.Upload:
+		return T("upload")
+	case notesync.Download:
+		return T("download")
+	case notesync.DeleteRemote:
+		return T("delete remote")
+	case notesync.DeleteLocal:
+		return T("delete local")
+	}
+	return T("conflict")
+}
+
+// mergeContext is how many unchanged lines are shown around a change
+const mergeContext = 2
+
+// DisplayMerge shows a three-way merge: the changes each side made with a
+// few unchanged lines around them, and every conflict with the base,
+// local and remote lines, marked the way diff3 marks them
+func DisplayMerge(chunks []merge.Chunk) {
+	conflict := 0
+	for i, c := range chunks {
+		switch c.Source {
+		case merge.Unchanged:
+			lines := c.Base
+			head, tail := 0, 0
+			if i > 0 {
+				head = mergeContext
+			}
+			if i < len(chunks)-1 {
+				tail = mergeContext
+			}
+			if head+tail >= len(lines) {
+				printMergeLines("  ", lines)
+				continue
+			}
+			printMergeLines("  ", lines[:head])
+			fmt.Println("  ...")
+			printMergeLines("  ", lines[len(lines)-tail:])
+
+		case merge.Conflict:
+			conflict++
+			header := Tf("@@ conflict %d @@\n", conflict)
+			if IsTerminal() {
+				header = colorHighlight + header + colorReset
 			}
-			if n.Metadata.Created.After(newestNote.Metadata.Created) {
-				newestNote = n
+			fmt.Print(header)
+			fmt.Println("<<<<<<< " + T("local"))
+			printMergeLines("", c.Local)
+			fmt.Println("||||||| " + T("base"))
+			printMergeLines("", c.Base)
+			fmt.Println("=======")
+			printMergeLines("", c.Remote)
+			fmt.Println(">>>>>>> " + T("remote"))
+
+		default:
+			label := T("changed locally")
+			if c.Source == merge.Remote {
+				label = T("changed remotely")
+			} else if c.Source == merge.Both {
+				label = T("changed alike on both sides")
 			}
+			fmt.Printf("@@ %s @@\n", label)
+			printMergeLines("- ", c.Base)
+			printMergeLines("+ ", c.Lines())
+		}
+	}
+	if conflict == 0 {
+		fmt.Println(T("No conflicting changes; the versions merge cleanly."))
+	}
+}
+
+func printMergeLines(prefix string, lines []string) {
+	for _, line := range lines {
+		fmt.Println(prefix + line)
+	}
+}
+
+func ConfirmAction(prompt string) bool {
+	return isYes(PromptForInput(prompt))
+}
+
+// isYes accepts "y" and "yes" as well as their translations
+func isYes(response string) bool {
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes" || response == T("y") || response == T("yes")
+}
+func DisplayProfiles(cfg *config.Config, active string) {
+	names := cfg.ProfileNames()
+	if len(names) == 0 {
+		fmt.Print(Tf("No profiles configured (using '%s').\n", active))
+		fmt.Print(Tf("Add profiles to %s\n", config.Path()))
+		return
+	}
+
+	fmt.Println(T("Profiles:"))
+	for _, name := range names {
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		p, _ := cfg.Profile(name)
+		notesDir := p.NotesDir
+		if p.StoreFile != "" {
+			notesDir = p.StoreFile
+		} else if notesDir == "" {
+			notesDir = T("(default)")
+		}
+		fmt.Print(Tf("%s %s | Notes: %s\n", marker, name, notesDir))
+		if p.Author != "" {
+			fmt.Print(Tf("    Author: %s\n", p.Author))
+		}
+	}
+}
+
+// DisplayNotebooks lists notebooks with their note counts and ID prefixes;
+// counts[""] is the number of notes outside any notebook
+func DisplayNotebooks(notebooks []string, counts map[string]int, prefixes map[string]string) {
+	if len(notebooks) == 0 {
+		fmt.Println(T("No notebooks. Use 'memo create --notebook <name>' to start one."))
+		return
+	}
+	fmt.Println(T("Notebooks:"))
+	for _, name := range notebooks {
+		fmt.Print(Tf("  %s | Notes: %d\n", name, counts[name]))
+		if prefix := prefixes[name]; prefix != "" {
+			fmt.Print(Tf("    ID prefix: %s\n", prefix))
 		}
+	}
+	if counts[""] > 0 {
+		fmt.Print(Tf("  (no notebook) | Notes: %d\n", counts[""]))
+	}
+}
 
+func DisplayRevisions(n *note.Note, revisions []storage.Revision) {
+	fmt.Print(Tf("Revisions of '%s' (%s):\n", n.Metadata.Title, n.ID()))
+	if len(revisions) == 0 {
+		fmt.Println(T("No previous revisions."))
+		return
+	}
+
+	for _, rev := range revisions {
+		fmt.Print(Tf("%2d. Saved: %s\n", rev.Number, rev.Saved.Format("2006-01-02 15:04:05")))
+	}
+
+	fmt.Println(T("\nTip: Use 'memo rollback <note-id> <revision>' to restore a revision."))
+}
+
+func DisplayGrepResults(results []storage.GrepResult, pattern *regexp.Regexp, before, after int) {
+	if len(results) == 0 {
+		fmt.Print(Tf("No lines found matching '%s'\n", pattern.String()))
+		return
+	}
+
+	first := true
+	for _, r := range results {
+		noteID := r.Note.ID()
+		last := -1
+
+		for _, m := range r.Matches {
+			start := m - before
+			if start < 0 {
+				start = 0
+			}
+			if start <= last {
+				start = last + 1
+			}
+			end := m + after
+			if end >= len(r.Lines) {
+				end = len(r.Lines) - 1
+			}
+
+			if !first && (last < 0 || start > last+1) {
+				fmt.Println("--")
+			}
+			first = false
+
+			for i := start; i <= end; i++ {
+				if pattern.MatchString(r.Lines[i]) {
+					fmt.Printf("%s:%d:%s\n", noteID, i+1, Highlight(r.Lines[i], pattern))
+				} else {
+					fmt.Printf("%s-%d-%s\n", noteID, i+1, r.Lines[i])
+				}
+			}
+			if end > last {
+				last = end
+			}
+		}
+	}
+}
+
+func DisplayTasks(notes []*note.Note, includeDone bool) {
+	total := 0
+	for _, n := range notes {
+		var shown []note.Task
+		for _, task := range n.Tasks() {
+			if includeDone || !task.Done {
+				shown = append(shown, task)
+			}
+		}
+		if len(shown) == 0 {
+			continue
+		}
+
+		fmt.Printf("%s (%s)\n", ListTitle(n), n.ID())
+		for _, task := range shown {
+			mark := " "
+			if task.Done {
+				mark = "x"
+			}
+			fmt.Printf("  %2d. [%s] %s\n", task.Number, mark, task.Text)
+		}
+		fmt.Println()
+		total += len(shown)
+	}
+
+	if total == 0 {
+		fmt.Println(T("No open tasks."))
+		return
+	}
+
+	fmt.Println(T("Tip: Use 'memo tasks done <note-id> <n>' to check off a task."))
+}
+
+func DisplayRecurRules(rules []recur.Rule) {
+	if len(rules) == 0 {
+		fmt.Println(T("No recurring notes."))
+		return
+	}
+
+	fmt.Println(T("Recurring notes:"))
+	for _, r := range rules {
+		lastRun := T("never")
+		if !r.LastRun.IsZero() {
+			lastRun = r.LastRun.Format("2006-01-02 15:04")
+		}
+		fmt.Print(Tf("  %s | Every: %s | Template: %s | Last run: %s\n", r.Name, r.Every, r.Template, lastRun))
+	}
+}
+
+func DisplayTags(notes []*note.Note) {
+	counts := make(map[string]int)
+	for _, n := range notes {
 		for _, tag := range n.Metadata.Tags {
-			tagCount[tag]++
+			counts[tag]++
 		}
 	}
 
-	fmt.Printf("Total words: %d\n", totalWords)
-	fmt.Printf("Average words per note: %.1f\n", float64(totalWords)/float64(len(notes)))
+	if len(counts) == 0 {
+		fmt.Println(T("No tags found."))
+		return
+	}
 
-	if oldestNote != nil {
-		fmt.Printf("Oldest note: %s (%s)\n", oldestNote.Metadata.Title, oldestNote.Metadata.Created.Format("2006-01-02"))
+	var tags []string
+	for tag := range counts {
+		tags = append(tags, tag)
 	}
-	if newestNote != nil {
-		fmt.Printf("Newest note: %s (%s)\n", newestNote.Metadata.Title, newestNote.Metadata.Created.Format("2006-01-02"))
+	sort.Strings(tags)
+
+	fmt.Println(T("Tags:"))
+	for _, tag := range tags {
+		fmt.Printf("  %s: %d\n", tag, counts[tag])
 	}
+}
 
-	if len(tagCount) > 0 {
-		fmt.Printf("\nTag usage:\n")
-		for tag, count := range tagCount {
-			fmt.Printf("  %s: %d\n", tag, count)
+// tagNode is one level of the tag hierarchy
+type tagNode struct {
+	name     string
+	notes    map[*note.Note]bool
+	children map[string]*tagNode
+}
+
+func newTagNode(name string) *tagNode {
+	return &tagNode{name: name, notes: make(map[*note.Note]bool), children: make(map[string]*tagNode)}
+}
+
+// DisplayTagTree prints nested tags as a tree. Each level counts the notes
+// tagged with it or any tag below it; levels are merged ignoring case, as
+// in tag filtering.
+func DisplayTagTree(notes []*note.Note) {
+	root := newTagNode("")
+	for _, n := range notes {
+		for _, tag := range n.Metadata.Tags {
+			node := root
+			for _, part := range strings.Split(tag, note.TagSeparator) {
+				if part == "" {
+					continue
+				}
+				key := strings.ToLower(part)
+				child, ok := node.children[key]
+				if !ok {
+					child = newTagNode(part)
+					node.children[key] = child
+				}
+				child.notes[n] = true
+				node = child
+			}
 		}
 	}
+
+	if len(root.children) == 0 {
+		fmt.Println(T("No tags found."))
+		return
+	}
+
+	fmt.Println(T("Tags:"))
+	printTagNodes(root, 1)
 }
 
-func ConfirmAction(prompt string) bool {
-	response := PromptForInput(prompt)
-	return strings.ToLower(response) == "y" || strings.ToLower(response) == "yes"
-}
\ No newline at end of file
+func printTagNodes(node *tagNode, depth int) {
+	var names []string
+	for name := range node.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		child := node.children[name]
+		fmt.Printf("%s%s (%d)\n", strings.Repeat("  ", depth), child.name, len(child.notes))
+		printTagNodes(child, depth+1)
+	}
+}
+
+// DisplayDuplicatePair shows two similar notes side by side
+func DisplayDuplicatePair(pair analysis.DuplicatePair) {
+	const width = 38
+
+	kind := Tf("%.0f%% similar", pair.Similarity*100)
+	if pair.Identical {
+		kind = T("identical content")
+	}
+	fmt.Printf("(%s)\n", kind)
+
+	left := sideBySideLines(pair.A, "[1] ", width)
+	right := sideBySideLines(pair.B, "[2] ", width)
+	for len(left) < len(right) {
+		left = append(left, "")
+	}
+	for len(right) < len(left) {
+		right = append(right, "")
+	}
+
+	for i := range left {
+		fmt.Printf("%-*s | %s\n", width, left[i], right[i])
+	}
+}
+
+func sideBySideLines(n *note.Note, label string, width int) []string {
+	const maxLines = 8
+
+	lines := []string{
+		truncate(label+n.Metadata.Title, width),
+		truncate(Tf("ID: %s", n.ID()), width),
+		truncate(Tf("Modified: %s", n.Metadata.Modified.Format("2006-01-02 15:04")), width),
+		strings.Repeat("-", width),
+	}
+
+	for i, line := range strings.Split(n.Content, "\n") {
+		if i == maxLines {
+			lines = append(lines, "...")
+			break
+		}
+		lines = append(lines, truncate(line, width))
+	}
+	return lines
+}
+
+// ansiEscape matches the escape sequences coloring terminal output
+var ansiEscape = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// truncate shortens s to at most width runes. Color escapes take no room;
+// a color cut off is reset.
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(ansiEscape.ReplaceAllString(s, "")) <= width {
+		return s
+	}
+
+	var b strings.Builder
+	escapes := ansiEscape.FindAllStringIndex(s, -1)
+	visible := 0
+	for i := 0; i < len(s) && visible < width-3; {
+		if len(escapes) > 0 && escapes[0][0] == i {
+			b.WriteString(s[i:escapes[0][1]])
+			i = escapes[0][1]
+			escapes = escapes[1:]
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		b.WriteString(s[i : i+size])
+		i += size
+		visible++
+	}
+	b.WriteString("...")
+	if ansiEscape.MatchString(s) {
+		b.WriteString("\033[0m")
+	}
+	return b.String()
+}
+
+// noteColors are the ANSI codes of note.ColorNames
+var noteColors = map[string]string{"red": "31", "green": "32", "yellow": "33", "blue": "34", "magenta": "35", "cyan": "36", "white": "37", "gray": "90"}
+
+// ListTitle is a note's title as listings show it: after the note's icon
+// and, on a terminal, in its color
+func ListTitle(n *note.Note) string {
+	title := n.Metadata.Title
+	if icon := n.Icon(); icon != "" {
+		title = icon + " " + title
+	}
+	color := n.Color()
+	if color == "" || !IsTerminal() {
+		return title
+	}
+	code, ok := noteColors[color]
+	if !ok {
+		var r, g, b int
+		fmt.Sscanf(color, "#%02x%02x%02x", &r, &g, &b)
+		code = fmt.Sprintf("38;2;%d;%d;%d", r, g, b)
+	}
+	return "\033[" + code + "m" + title + "\033[0m"
+}
+
+func DisplayDoctorReport(report *doctor.Report, notesDir string) {
+	fmt.Print(Tf("Checked %d note file(s).\n", report.NotesChecked))
+	if len(report.Issues) == 0 {
+		fmt.Println(T("No problems found."))
+		return
+	}
+
+	for _, issue := range report.Issues {
+		path := issue.Path
+		if rel, err := filepath.Rel(notesDir, path); err == nil {
+			path = rel
+		}
+		mark := " "
+		if issue.Fixable {
+			mark = "*"
+		}
+		fmt.Printf("%s %-18s %s: %s\n", mark, issue.Kind, path, issue.Message)
+	}
+
+	fmt.Print(Tf("\n%d problem(s) found", len(report.Issues)))
+	if fixable := report.Fixable(); fixable > 0 {
+		fmt.Print(Tf(", %d marked * can be repaired with 'memo doctor --fix'", fixable))
+	}
+	fmt.Println(".")
+}
+
+// DisplayLintResults lists the lint issues of each note in notes, in order
+func DisplayLintResults(notes []*note.Note, results map[*note.Note][]lint.Issue) {
+	fmt.Print(Tf("Checked %d note(s).\n", len(notes)))
+	if len(results) == 0 {
+		fmt.Println(T("No problems found."))
+		return
+	}
+
+	warnings, errors := 0, 0
+	for _, n := range notes {
+		for _, issue := range results[n] {
+			if issue.Severity == lint.Error {
+				errors++
+			} else {
+				warnings++
+			}
+			fmt.Printf("%-5s %-19s %s: %s\n", issue.Severity, issue.Rule, n.ID(), issue.Message)
+		}
+	}
+	fmt.Print(Tf("\n%d warning(s), %d error(s)\n", warnings, errors))
+}
+
+func DisplayAuditLog(entries []audit.Entry) {
+	if len(entries) == 0 {
+		fmt.Println(T("No recorded changes."))
+		return
+	}
+
+	for _, e := range entries {
+		displayAuditEntry("", e)
+	}
+}
+
+// DisplayHistory lists changes newest first, numbered for `memo history
+// --revert`
+func DisplayHistory(entries []audit.Entry) {
+	if len(entries) == 0 {
+		fmt.Println(T("No recorded changes."))
+		return
+	}
+
+	width := len(strconv.Itoa(len(entries)))
+	for i := len(entries) - 1; i >= 0; i-- {
+		displayAuditEntry(fmt.Sprintf("%*d. ", width, len(entries)-i), entries[i])
+	}
+}
+
+// displayAuditEntry prints one change and what it changed, the first line
+// starting with prefix
+func displayAuditEntry(prefix string, e audit.Entry) {
+	line := fmt.Sprintf("%s%s  %-7s %s", prefix, e.Time.Local().Format("2006-01-02 15:04:05"), e.Op, e.ID)
+	if e.NewID != "" {
+		line += " → " + e.NewID
+	}
+	if e.User != "" {
+		line += " " + Tf("by %s", e.User)
+	}
+	fmt.Println(line)
+
+	indent := strings.Repeat(" ", len(prefix)+4)
+	if e.Op == audit.OpCreate && e.New != nil {
+		fmt.Printf("%stitle: %q\n", indent, e.New.Title)
+		if len(e.New.Tags) > 0 {
+			fmt.Printf("%stags: %s\n", indent, strings.Join(e.New.Tags, ", "))
+		}
+		return
+	}
+	if e.Op == audit.OpDelete && e.Old != nil {
+		fmt.Printf("%stitle: %q\n", indent, e.Old.Title)
+		return
+	}
+	if e.Op == audit.OpRepair {
+		return
+	}
+	for _, ch := range e.Changes() {
+		fmt.Printf("%s%s: %q → %q\n", indent, ch.Field, ch.Old, ch.New)
+	}
+	if e.ContentChanged {
+		fmt.Println(indent + T("content changed"))
+	}
+}
+
+// DisplayRandomNotes lists notes picked by `memo random`
+func DisplayRandomNotes(notes []*note.Note) {
+	fmt.Println(T("Random notes:"))
+	for i, n := range notes {
+		fmt.Printf("%2d. %s (%s) | %s\n", i+1, ListTitle(n), n.ID(), n.Metadata.Created.Format("2006-01-02 15:04"))
+	}
+	fmt.Print(Tf("\nTip: Use 'memo read <number>' or 'memo edit <number>' with numbers 1-%d from this listing.\n", len(notes)))
+}
+
+// DisplayRelated lists the notes related to n with what they have in common
+func DisplayRelated(n *note.Note, related []analysis.RelatedNote) {
+	if len(related) == 0 {
+		fmt.Print(Tf("No notes related to '%s' found.\n", n.Metadata.Title))
+		return
+	}
+
+	fmt.Print(Tf("Notes related to '%s':\n", n.Metadata.Title))
+	for i, r := range related {
+		fmt.Printf("%2d. %s (%s) | %.0f%%\n", i+1, ListTitle(r.Note), r.Note.ID(), r.Score*100)
+		if len(r.SharedTags) > 0 {
+			fmt.Print(Tf("    Shared tags: %s\n", strings.Join(r.SharedTags, ", ")))
+		}
+		if len(r.SharedWords) > 0 {
+			fmt.Print(Tf("    Common words: %s\n", strings.Join(r.SharedWords, ", ")))
+		}
+	}
+	fmt.Print(Tf("\nTip: Use 'memo read <number>' or 'memo edit <number>' with numbers 1-%d from this listing.\n", len(related)))
+}
+
+// DisplayRelatedSection ends a displayed note with links to related notes
+func DisplayRelatedSection(related []analysis.RelatedNote) {
+	if len(related) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println(T("Related:"))
+	for _, r := range related {
+		fmt.Printf("  - %s (%s)\n", ListTitle(r.Note), r.Note.ID())
+	}
+}
+
+func DisplayRecent(entries []history.Entry, notes []*note.Note) {
+	if len(notes) == 0 {
+		fmt.Println(T("No recently used notes."))
+		return
+	}
+
+	fmt.Println(T("Recently used notes:"))
+	for i, n := range notes {
+		fmt.Printf("%2d. %s (%s) | %s %s\n", i+1, ListTitle(n), n.ID(), T(entries[i].Action), entries[i].At.Format("2006-01-02 15:04"))
+	}
+}
@@ -0,0 +1,98 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// Prefix marks content produced by Encrypt
+	Prefix = "memo-vault:v1:"
+
+	saltSize   = 16
+	keySize    = 32
+	iterations = 600000
+)
+
+var ErrWrongKey = errors.New("wrong encryption key or corrupted content")
+
+// IsEncrypted reports whether text was produced by Encrypt
+func IsEncrypted(text string) bool {
+	return strings.HasPrefix(strings.TrimSpace(text), Prefix)
+}
+
+// Encrypt seals plaintext with AES-256-GCM using a key derived from passphrase
+// and returns a printable armored string
+func Encrypt(plaintext, passphrase string) (string, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generating salt: %w", err)
+	}
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nil, nonce, []byte(plaintext), nil)
+
+	data := append(append(salt, nonce...), sealed...)
+	return Prefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Decrypt reverses Encrypt
+func Decrypt(armored, passphrase string) (string, error) {
+	armored = strings.TrimSpace(armored)
+	if !strings.HasPrefix(armored, Prefix) {
+		return "", fmt.Errorf("content is not encrypted")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(armored, Prefix))
+	if err != nil {
+		return "", fmt.Errorf("error decoding encrypted content: %w", err)
+	}
+	if len(data) < saltSize {
+		return "", ErrWrongKey
+	}
+
+	salt, rest := data[:saltSize], data[saltSize:]
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	if len(rest) < gcm.NonceSize() {
+		return "", ErrWrongKey
+	}
+
+	nonce, sealed := rest[:gcm.NonceSize()], rest[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", ErrWrongKey
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,336 @@
+// Package zipstore keeps a whole notes store in one zip file, which syncs
+// well through Dropbox and the like and spares network filesystems
+// thousands of small files. Commands work on an extracted copy in a cache
+// directory that is written back to the zip file when they changed it.
+package zipstore
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// stateName is the file in the cache directory recording which version of
+// the zip file the cache holds
+const stateName = ".zipstore.yaml"
+
+// skipDirs only hold transient state and are not packed
+var skipDirs = map[string]bool{".locks": true}
+
+// state describes the zip file as last extracted or written, and the cache
+// contents at that moment
+type state struct {
+	Size        int64     `yaml:"size"`
+	ModTime     time.Time `yaml:"mod_time"`
+	Fingerprint string    `yaml:"fingerprint"`
+}
+
+// Store is a notes store kept in a zip file
+type Store struct {
+	archive string
+	dir     string
+	// fingerprint is the cache contents when the store was opened
+	fingerprint string
+}
+
+// CacheDir returns the cache directory used for archive inside base, one
+// per zip file
+func CacheDir(base, archive string) string {
+	abs, err := filepath.Abs(archive)
+	if err != nil {
+		abs = archive
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(base, strings.TrimSuffix(filepath.Base(archive), filepath.Ext(archive))+"-"+hex.EncodeToString(sum[:6]))
+}
+
+// Open makes the store in archive available in cacheDir. The cache is
+// reused while the zip file is unchanged; otherwise it is extracted again.
+// Changes left in the cache by a process that did not finish are written
+// to the zip file, unless it changed as well: then the cache is kept next
+// to it with a ".conflict-<time>" suffix.
+func Open(archive, cacheDir string) (*Store, error) {
+	s := &Store{archive: archive, dir: cacheDir}
+
+	saved, err := s.loadState()
+	if err != nil {
+		return nil, err
+	}
+	current, err := fingerprint(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	dirty := saved != nil && current != saved.Fingerprint
+
+	info, err := os.Stat(archive)
+	switch {
+	case os.IsNotExist(err):
+		// A new store; the zip file is written once it has notes
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return nil, fmt.Errorf("error creating store cache: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("error reading store file: %w", err)
+	case saved != nil && info.Size() == saved.Size && info.ModTime().Equal(saved.ModTime):
+		if dirty {
+			slog.Warn("writing back changes left unsaved in the store cache", "file", archive)
+			if err := s.pack(); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		if dirty {
+			aside := cacheDir + ".conflict-" + time.Now().Format("20060102-150405")
+			if err := os.Rename(cacheDir, aside); err != nil {
+				return nil, fmt.Errorf("error moving store cache aside: %w", err)
+			}
+			slog.Warn("store file and its cache both changed; kept the cache aside", "file", archive, "cache", aside)
+		}
+		if err := s.extract(info); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.fingerprint, err = fingerprint(cacheDir); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Dir returns the directory holding the extracted store
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Close writes the store back to the zip file if anything changed since it
+// was opened
+func (s *Store) Close() error {
+	current, err := fingerprint(s.dir)
+	if err != nil {
+		return err
+	}
+	if current == s.fingerprint {
+		return nil
+	}
+	return s.pack()
+}
+
+// extract replaces the cache with the contents of the zip file
+func (s *Store) extract(info os.FileInfo) error {
+	data, err := os.ReadFile(s.archive)
+	if err != nil {
+		return fmt.Errorf("error reading store file: %w", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("error reading store file %s: %w", s.archive, err)
+	}
+
+	staging := s.dir + ".extracting"
+	if err := os.RemoveAll(staging); err != nil {
+		return err
+	}
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() {
+			continue
+		}
+		name := filepath.FromSlash(zf.Name)
+		if !filepath.IsLocal(name) {
+			os.RemoveAll(staging)
+			return fmt.Errorf("store file %s has an invalid entry '%s'", s.archive, zf.Name)
+		}
+		if err := extractFile(zf, filepath.Join(staging, name)); err != nil {
+			os.RemoveAll(staging)
+			return fmt.Errorf("error extracting store file: %w", err)
+		}
+	}
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(s.dir); err != nil {
+		return fmt.Errorf("error replacing store cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.dir), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(staging, s.dir); err != nil {
+		return fmt.Errorf("error replacing store cache: %w", err)
+	}
+	return s.saveState(info)
+}
+
+func extractFile(zf *zip.File, target string) error {
+	rc, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, data, zf.Mode().Perm()|0600); err != nil {
+		return err
+	}
+	return os.Chtimes(target, zf.Modified, zf.Modified)
+}
+
+// pack writes the cache to the zip file, replacing it in one step so a
+// syncing client never sees half a file
+func (s *Store) pack() error {
+	if err := os.MkdirAll(filepath.Dir(s.archive), 0755); err != nil {
+		return fmt.Errorf("error creating store file directory: %w", err)
+	}
+	tmp := s.archive + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("error writing store file: %w", err)
+	}
+	err = s.writeZip(out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.archive)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error writing store file: %w", err)
+	}
+
+	info, err := os.Stat(s.archive)
+	if err != nil {
+		return fmt.Errorf("error writing store file: %w", err)
+	}
+	return s.saveState(info)
+}
+
+func (s *Store) writeZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	err := walk(s.dir, func(rel string, info fs.FileInfo) error {
+		data, err := os.ReadFile(filepath.Join(s.dir, rel))
+		if err != nil {
+			return err
+		}
+		hdr := &zip.FileHeader{Name: filepath.ToSlash(rel), Method: zip.Deflate, Modified: info.ModTime()}
+		hdr.SetMode(info.Mode().Perm())
+		out, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (s *Store) loadState() (*state, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, stateName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading store cache state: %w", err)
+	}
+	var st state
+	if err := yaml.Unmarshal(data, &st); err != nil {
+		// A damaged state file only costs extracting the store again
+		slog.Debug("ignoring store cache state", "error", err)
+		return nil, nil
+	}
+	return &st, nil
+}
+
+func (s *Store) saveState(info os.FileInfo) error {
+	fp, err := fingerprint(s.dir)
+	if err != nil {
+		return err
+	}
+	s.fingerprint = fp
+	data, err := yaml.Marshal(state{Size: info.Size(), ModTime: info.ModTime(), Fingerprint: fp})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, stateName), data, 0644); err != nil {
+		return fmt.Errorf("error writing store cache state: %w", err)
+	}
+	return nil
+}
+
+// walk calls fn for every regular file in dir that belongs in the zip file,
+// in a stable order
+func walk(dir string, fn func(rel string, info fs.FileInfo) error) error {
+	var rels []string
+	infos := make(map[string]fs.FileInfo)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || p == filepath.Join(dir, stateName) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rels = append(rels, rel)
+		infos[rel] = info
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(rels)
+	for _, rel := range rels {
+		if err := fn(rel, infos[rel]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fingerprint summarizes the names, sizes and modification times of the
+// files in dir; a missing directory has an empty fingerprint
+func fingerprint(dir string) (string, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return "", nil
+	}
+	h := sha256.New()
+	err := walk(dir, func(rel string, info fs.FileInfo) error {
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", filepath.ToSlash(rel), info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("error reading store cache: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -1,8 +1,12 @@
 package main
 
-import "memo/cmd"
+import (
+	"os"
+
+	"memo/cmd"
+)
 
 func main() {
 	app := cmd.NewApp()
-	app.Run()
-}
\ No newline at end of file
+	os.Exit(app.Run())
+}